@@ -0,0 +1,177 @@
+package gox
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BeamNode describes a partial solution considered by a beam search, see
+// WithBeam
+type BeamNode struct {
+	// Rows are the rows of the partial solution, the last being the row just
+	// added, after any rows given with RowIsSolution
+	Rows []string
+	// Remaining is the number of primary columns left to cover
+	Remaining int
+	// MinCandidates is the fewest rows left which could cover any of the
+	// remaining columns. It is at least one, as partial solutions which can
+	// no longer be completed are dropped without being scored.
+	MinCandidates int
+}
+
+// WithBeam searches breadth first, keeping only the width partial solutions
+// with the highest scores at each level and abandoning the rest. score is
+// called for each partial solution one row larger than those kept at the
+// level before. If score is nil, partial solutions leaving more candidates
+// for their most constrained column are preferred, as they are less likely
+// to reach a dead end. A width of less than one is taken as one.
+//
+// The search is incomplete: it finds solutions quickly on instances too large
+// to search exhaustively, but may miss some or all of them, so finding none
+// does not mean there are none. The solutions found are passed on as they are
+// found, and the search stops once WithLimit solutions have been found.
+func WithBeam(width int, score func(BeamNode) float64) Option {
+	if width < 1 {
+		width = 1
+	}
+	return func(c *config) {
+		c.beamWidth = width
+		c.beamScore = score
+	}
+}
+
+// beamState is a partial solution kept by searchBeam, which holds the rows
+// chosen after those already in the problem's solution
+type beamState struct {
+	rows  []*rowHeader
+	score float64
+}
+
+// searchBeam performs the search for WithBeam. Each state is applied to the
+// matrix in turn to find the rows which can extend it, then undone. It
+// returns true if the search was stopped before it ran out of states.
+func (p *exactCoverProblem) searchBeam(c *config) bool {
+	if p.root == p.root.right {
+		c.solutions++
+		p.emit(c, FoundSolution, nil, nil)
+		return !c.found(p.solutionRows)
+	}
+
+	score := c.beamScore
+	if score == nil {
+		score = func(n BeamNode) float64 { return float64(n.MinCandidates) }
+	}
+	level := []beamState{{}}
+	for len(level) > 0 {
+		var next []beamState
+		// keys holds the rows of the states and solutions of the next level,
+		// as the same rows may be reached in different orders
+		keys := make(map[string]bool)
+		for _, s := range level {
+			for _, r := range s.rows {
+				p.take(r)
+			}
+			colHead := p.nextCol(c)
+			p.chose(c, colHead)
+			for rowNode := colHead.down; rowNode != colHead; rowNode = rowNode.down {
+				if c.interrupted() {
+					p.untakeAll(s.rows)
+					return true
+				}
+				row := rowNode.rowHead
+				rows := append(append([]*rowHeader(nil), s.rows...), row)
+				key := rowsKey(rows)
+				if keys[key] {
+					continue
+				}
+				keys[key] = true
+				p.take(row)
+				p.emit(c, TryRow, nil, row)
+				node := p.beamNode()
+				if node.Remaining == 0 {
+					c.solutions++
+					p.emit(c, FoundSolution, nil, nil)
+					if !c.found(p.solutionRows) {
+						p.untake(row)
+						p.untakeAll(s.rows)
+						return true
+					}
+				} else if node.MinCandidates > 0 {
+					node.Rows = rowNames(p.solutionRows)
+					next = append(next, beamState{rows: rows, score: score(node)})
+				}
+				p.untake(row)
+				p.emit(c, UndoRow, nil, row)
+			}
+			p.untakeAll(s.rows)
+		}
+
+		sort.SliceStable(next, func(i, j int) bool {
+			return next[i].score > next[j].score
+		})
+		if len(next) > c.beamWidth {
+			next = next[:c.beamWidth]
+		}
+		level = next
+	}
+	return false
+}
+
+// beamNode describes the columns left in the matrix, leaving Rows to be set
+// by the caller
+func (p *exactCoverProblem) beamNode() BeamNode {
+	var ret BeamNode
+	for col := p.root.right; col != p.root; col = col.right {
+		if ret.Remaining == 0 || col.colCount < ret.MinCandidates {
+			ret.MinCandidates = col.colCount
+		}
+		ret.Remaining++
+	}
+	return ret
+}
+
+// take adds a row to the working solution, satisfying its columns. The row
+// must not conflict with the rows already in the solution.
+func (p *exactCoverProblem) take(r *rowHeader) {
+	p.pushRowToSolution(r)
+	for n := r.first; ; {
+		p.commit(n)
+		if n = n.right; n == r.first {
+			break
+		}
+	}
+}
+
+// untake is the reverse of take
+func (p *exactCoverProblem) untake(r *rowHeader) {
+	for n := r.first.left; ; n = n.left {
+		p.uncommit(n)
+		if n == r.first {
+			break
+		}
+	}
+	p.popRowFromSolution()
+}
+
+// untakeAll undoes take for each of rows, in reverse order
+func (p *exactCoverProblem) untakeAll(rows []*rowHeader) {
+	for i := len(rows) - 1; i >= 0; i-- {
+		p.untake(rows[i])
+	}
+}
+
+// rowsKey returns a string which is the same for the same set of rows,
+// whatever their order
+func rowsKey(rows []*rowHeader) string {
+	indexes := make([]int, len(rows))
+	for i, r := range rows {
+		indexes[i] = r.index
+	}
+	sort.Ints(indexes)
+	items := make([]string, len(indexes))
+	for i, index := range indexes {
+		items[i] = strconv.Itoa(index)
+	}
+	return strings.Join(items, " ")
+}
@@ -0,0 +1,72 @@
+package gox
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+func TestWithBeam(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		m, names := testutil.Random(rng, 2+rng.Intn(14), 1+rng.Intn(8), 0.3)
+		prob, err := NewExactCoverProblem(m, names, WithDebug())
+		if err != nil {
+			t.Fatalf("Error creating problem: %v", err)
+		}
+		all, err := prob.SolveContext(context.Background())
+		if _, ok := err.(*UncoverableError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+
+		// A beam wide enough to keep every partial solution is complete
+		solns, err := prob.SolveContext(context.Background(), WithBeam(1<<20, nil))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		assertStringSliceEqual(t, testutil.Canonical(solns), testutil.Canonical(all))
+
+		// A narrow beam finds only valid solutions
+		solns, err = prob.SolveContext(context.Background(), WithBeam(1, nil))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		if len(solns) > len(all) {
+			t.Fatalf("Trial %d: expected at most %d solutions, got %d", trial, len(all), len(solns))
+		}
+		for _, soln := range solns {
+			if err := prob.Verify(soln); err != nil {
+				t.Fatalf("Trial %d: invalid solution %v: %v", trial, soln, err)
+			}
+		}
+	}
+}
+
+func TestWithBeamScore(t *testing.T) {
+	prob := dominoProblem(t, 10)
+	var scored int
+	solns, err := prob.SolveContext(context.Background(), WithLimit(1), WithBeam(2, func(n BeamNode) float64 {
+		scored++
+		if n.MinCandidates < 1 || n.Remaining < 1 || len(n.Rows) == 0 {
+			t.Fatalf("Unexpected node %+v", n)
+		}
+		// Prefer vertical dominoes
+		if n.Rows[len(n.Rows)-1][0] == 'v' {
+			return 1
+		}
+		return 0
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(solns) != 1 || len(solns[0]) != 10 {
+		t.Fatalf("Expected the solution of vertical dominoes, got %v", solns)
+	}
+	if scored == 0 {
+		t.Fatalf("Expected the partial solutions to be scored")
+	}
+}
@@ -0,0 +1,192 @@
+// Package benchmarks bundles classic exact cover instances, with Go
+// benchmarks of building and solving them so that performance regressions in
+// the dancing links of gox are caught:
+//
+//	go test -run NONE -bench . ./benchmarks
+//	go test -run NONE -bench 'Solve/queens' ./benchmarks
+//
+// Instances which take minutes to solve are marked Long, and are only
+// benchmarked when the -long flag is given to go test.
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/langford"
+	"github.com/ifross89/gox/polyomino"
+	"github.com/ifross89/gox/queens"
+	"github.com/ifross89/gox/sudoku"
+	"github.com/ifross89/gox/x3c"
+)
+
+// Instance is a classic problem for benchmarking
+type Instance struct {
+	Name string
+	// Solutions is the number of solutions of the instance, added up over
+	// its problems, or -1 if it is not known
+	Solutions int64
+	// Long is set for instances which take minutes to solve
+	Long bool
+	// Problems creates the problems of the instance, a batch of puzzles
+	// having more than one
+	Problems func() ([]gox.Solver, error)
+}
+
+// single returns the Problems function for an instance made of one problem
+func single(f func() (gox.Solver, error)) func() ([]gox.Solver, error) {
+	return func() ([]gox.Solver, error) {
+		prob, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return []gox.Solver{prob}, nil
+	}
+}
+
+// Pentominoes returns the instance packing the 12 pentominoes in a w x h
+// rectangle. Each packing is counted once for each of its symmetries.
+func Pentominoes(w, h int) Instance {
+	return Instance{
+		Name:      fmt.Sprintf("pentomino-%dx%d", w, h),
+		Solutions: pentominoSolutions[[2]int{w, h}],
+		Problems: single(func() (gox.Solver, error) {
+			p, err := polyomino.New(polyomino.Rectangle(w, h), polyomino.Pentominoes())
+			if err != nil {
+				return nil, err
+			}
+			return p.Problem()
+		}),
+	}
+}
+
+// pentominoSolutions are the numbers of solutions of the pentomino rectangles,
+// four times the number of distinct packings
+var pentominoSolutions = map[[2]int]int64{
+	{20, 3}: 8, {3, 20}: 8,
+	{15, 4}: 1472, {4, 15}: 1472,
+	{12, 5}: 4040, {5, 12}: 4040,
+	{10, 6}: 9356, {6, 10}: 9356,
+}
+
+// Queens returns the instance placing n queens on an n x n board
+func Queens(n int) Instance {
+	solutions := int64(-1)
+	if n < len(queensSolutions) {
+		solutions = queensSolutions[n]
+	}
+	return Instance{
+		Name:      fmt.Sprintf("queens-%d", n),
+		Solutions: solutions,
+		Long:      n > 14,
+		Problems:  single(func() (gox.Solver, error) { return queens.Problem(n) }),
+	}
+}
+
+// queensSolutions are the numbers of solutions of the n queens problem
+var queensSolutions = []int64{1, 1, 0, 0, 2, 10, 4, 40, 92, 352, 724, 2680, 14200, 73712, 365596, 2279184}
+
+// Langford returns the instance finding the Langford pairings of order n.
+// Each pairing is counted twice, as its reverse is also found.
+func Langford(n int) Instance {
+	solutions := int64(-1)
+	if n < len(langfordSolutions) {
+		solutions = 2 * langfordSolutions[n]
+	}
+	return Instance{
+		Name:      fmt.Sprintf("langford-%d", n),
+		Solutions: solutions,
+		Long:      n > 13,
+		Problems:  single(func() (gox.Solver, error) { return langford.Problem(n) }),
+	}
+}
+
+// langfordSolutions are the numbers of Langford pairings of order n, up to
+// reversal
+var langfordSolutions = []int64{0, 0, 0, 1, 1, 0, 0, 26, 150, 0, 0, 17792, 108144, 0, 0, 39809640, 326721800}
+
+// Sudokus are well known puzzles, from easy to among the hardest known, each
+// with a single solution
+var Sudokus = []string{
+	"003020600900305001001806400008102900700000008006708200002609500800203009005010300",
+	"4.....8.5.3..........7......2.....6.....8.4......1.......6.3.7.5..2.....1.4......",
+	"8..........36......7..9.2...5...7.......457.....1...3...1....68..85...1..9....4..",
+	"..53.....8......2..7..1.5..4....53...1..7...6..32...8..6.5....9..4....3......97..",
+	"1....7.9..3..2...8..96..5....53..9...1..8...26....4...3......1..4......7..7...3..",
+}
+
+// SudokuBatch returns the instance solving each of Sudokus
+func SudokuBatch() Instance {
+	return Instance{
+		Name:      "sudoku-batch",
+		Solutions: int64(len(Sudokus)),
+		Problems: func() ([]gox.Solver, error) {
+			var ret []gox.Solver
+			for _, s := range Sudokus {
+				g, err := sudoku.Parse(s)
+				if err != nil {
+					return nil, err
+				}
+				prob, err := sudoku.Problem(g)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, prob)
+			}
+			return ret, nil
+		},
+	}
+}
+
+// RandomX3C returns the instance of exact cover by 3-sets with the given
+// number of elements and triples, with a solution planted, generated from
+// seed so that it is the same every time
+func RandomX3C(elements, triples int, seed int64) Instance {
+	solutions, ok := x3cSolutions[[3]int64{int64(elements), int64(triples), seed}]
+	if !ok {
+		solutions = -1
+	}
+	return Instance{
+		Name:      fmt.Sprintf("x3c-%d-%d-%d", elements, triples, seed),
+		Solutions: solutions,
+		Problems: single(func() (gox.Solver, error) {
+			inst, err := x3c.Generate(rand.New(rand.NewSource(seed)), x3c.Config{Elements: elements, Triples: triples, Planted: true})
+			if err != nil {
+				return nil, err
+			}
+			return inst.Problem()
+		}),
+	}
+}
+
+// x3cSolutions are the numbers of solutions of the random instances of
+// Classic, by their elements, triples and seed
+var x3cSolutions = map[[3]int64]int64{
+	{90, 240, 1}:  78,
+	{120, 300, 1}: 1,
+}
+
+// Classic returns the bundled instances, in order: the 6x10 pentomino
+// rectangle, n queens for n from 8 to 14, the Langford pairings of orders 11
+// to 15, the batch of sudokus and random instances of X3C
+func Classic() []Instance {
+	ret := []Instance{Pentominoes(10, 6)}
+	for n := 8; n <= 14; n++ {
+		ret = append(ret, Queens(n))
+	}
+	for n := 11; n <= 15; n++ {
+		ret = append(ret, Langford(n))
+	}
+	return append(ret, SudokuBatch(), RandomX3C(90, 240, 1), RandomX3C(120, 300, 1))
+}
+
+// Lookup returns the instance of Classic with the given name
+func Lookup(name string) (Instance, bool) {
+	for _, inst := range Classic() {
+		if inst.Name == name {
+			return inst, true
+		}
+	}
+	return Instance{}, false
+}
@@ -0,0 +1,155 @@
+package benchmarks
+
+import (
+	"context"
+	"flag"
+	"runtime"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/sudoku"
+)
+
+var long = flag.Bool("long", false, "also benchmark the instances which take minutes to solve")
+
+// solve finds every solution of the problems of an instance, returning the
+// work done
+func solve(tb testing.TB, probs []gox.Solver) gox.Stats {
+	var total gox.Stats
+	for _, prob := range probs {
+		var stats gox.Stats
+		if _, err := prob.SolveContext(context.Background(), gox.WithoutSolutions(), gox.WithStats(&stats)); err != nil {
+			tb.Fatalf("Error solving problem: %v", err)
+		}
+		total.Nodes += stats.Nodes
+		total.Updates += stats.Updates
+		total.Solutions += stats.Solutions
+	}
+	return total
+}
+
+func TestInstances(t *testing.T) {
+	for _, inst := range Classic() {
+		probs, err := inst.Problems()
+		if err != nil || len(probs) == 0 {
+			t.Fatalf("Error creating %s: %v", inst.Name, err)
+		}
+		if found, ok := Lookup(inst.Name); !ok || found.Name != inst.Name {
+			t.Fatalf("Expected to find %s", inst.Name)
+		}
+	}
+	if _, ok := Lookup("queens-99"); ok {
+		t.Fatal("Expected not to find queens-99")
+	}
+
+	// The quicker instances are solved to check their counts
+	for _, inst := range []Instance{Queens(8), Queens(11), Langford(8), Langford(11), Pentominoes(20, 3), SudokuBatch(), RandomX3C(90, 240, 1), RandomX3C(120, 300, 1)} {
+		probs, err := inst.Problems()
+		if err != nil {
+			t.Fatalf("Error creating %s: %v", inst.Name, err)
+		}
+		if stats := solve(t, probs); stats.Solutions != inst.Solutions {
+			t.Fatalf("Expected %d solutions for %s, got %d", inst.Solutions, inst.Name, stats.Solutions)
+		}
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	for _, inst := range Classic() {
+		inst := inst
+		b.Run(inst.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := inst.Problems(); err != nil {
+					b.Fatalf("Error creating %s: %v", inst.Name, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	for _, inst := range Classic() {
+		inst := inst
+		b.Run(inst.Name, func(b *testing.B) {
+			if inst.Long && !*long {
+				b.Skip("takes minutes, run with -long")
+			}
+			probs, err := inst.Problems()
+			if err != nil {
+				b.Fatalf("Error creating %s: %v", inst.Name, err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			var stats gox.Stats
+			for i := 0; i < b.N; i++ {
+				stats = solve(b, probs)
+			}
+			if inst.Solutions >= 0 && stats.Solutions != inst.Solutions {
+				b.Fatalf("Expected %d solutions, got %d", inst.Solutions, stats.Solutions)
+			}
+			// The work done per solve, which unlike the time does not depend
+			// on the machine
+			b.ReportMetric(float64(stats.Nodes), "nodes/op")
+			b.ReportMetric(float64(stats.Updates), "updates/op")
+		})
+	}
+}
+
+// BenchmarkApplyGivens stamps the clues of the sudokus of SudokuBatch onto
+// copies of the empty template with ApplyGivens, against giving them one at a
+// time with RowIsSolution
+func BenchmarkApplyGivens(b *testing.B) {
+	var clues [][]string
+	for _, s := range Sudokus {
+		g, err := sudoku.Parse(s)
+		if err != nil {
+			b.Fatalf("Error parsing sudoku: %v", err)
+		}
+		var names []string
+		for r := range g {
+			for c, d := range g[r] {
+				if d != 0 {
+					names = append(names, sudoku.RowName(r, c, d))
+				}
+			}
+		}
+		clues = append(clues, names)
+	}
+	templates := func(b *testing.B) []gox.Solver {
+		b.StopTimer()
+		defer b.StartTimer()
+		ret := make([]gox.Solver, len(clues))
+		for i := range ret {
+			prob, err := sudoku.Problem(sudoku.Grid{})
+			if err != nil {
+				b.Fatalf("Error creating template: %v", err)
+			}
+			ret[i] = prob
+		}
+		// Collect the garbage of building them before timing starts again
+		runtime.GC()
+		return ret
+	}
+
+	b.Run("ApplyGivens", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j, prob := range templates(b) {
+				if err := prob.ApplyGivens(clues[j]); err != nil {
+					b.Fatalf("Error giving clues: %v", err)
+				}
+			}
+		}
+	})
+	b.Run("RowIsSolution", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j, prob := range templates(b) {
+				for _, name := range clues[j] {
+					if err := prob.RowIsSolution(name); err != nil {
+						b.Fatalf("Error giving clue: %v", err)
+					}
+				}
+			}
+		}
+	})
+}
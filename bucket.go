@@ -0,0 +1,120 @@
+package gox
+
+import "fmt"
+
+// bucketIndex holds the primary columns which have not been covered by the
+// number of rows left in each, for BucketedMinRemaining. The columns with
+// the same count are kept in a doubly linked list, so that a column is moved
+// between lists in constant time as its count changes, and a column with the
+// fewest rows is at the head of the first list which is not empty.
+type bucketIndex struct {
+	// heads holds the first column with each count, and next and prev link
+	// the columns with the same count, -1 ending the lists
+	heads      []int32
+	next, prev []int32
+	// in records the columns in the index, the others being covered,
+	// secondary or removed by preprocessing
+	in []bool
+	// min is no more than the fewest rows of any column in the index
+	min int
+}
+
+// newBucketIndex indexes the primary columns not yet covered. No column can
+// have more rows during the search than it has when it starts.
+func (p *exactCoverProblem) newBucketIndex() *bucketIndex {
+	most := 0
+	for col := p.root.right; col != p.root; col = col.right {
+		if col.colCount > most {
+			most = col.colCount
+		}
+	}
+	b := &bucketIndex{
+		heads: make([]int32, most+1),
+		next:  make([]int32, p.numCols),
+		prev:  make([]int32, p.numCols),
+		in:    make([]bool, p.numCols),
+	}
+	for i := range b.heads {
+		b.heads[i] = -1
+	}
+	// Inserting from the right leaves the leftmost column first in each
+	// list, as MinRemaining would choose at the start of the search
+	for col := p.root.left; col != p.root; col = col.left {
+		b.insert(col.colIndex, col.colCount)
+	}
+	return b
+}
+
+// insert adds a column with count rows to the index
+func (b *bucketIndex) insert(col, count int) {
+	head := b.heads[count]
+	b.next[col], b.prev[col] = head, -1
+	if head >= 0 {
+		b.prev[head] = int32(col)
+	}
+	b.heads[count] = int32(col)
+	b.in[col] = true
+	if count < b.min {
+		b.min = count
+	}
+}
+
+// remove takes a column with count rows out of the index
+func (b *bucketIndex) remove(col, count int) {
+	next, prev := b.next[col], b.prev[col]
+	if prev >= 0 {
+		b.next[prev] = next
+	} else {
+		b.heads[count] = next
+	}
+	if next >= 0 {
+		b.prev[next] = prev
+	}
+	b.in[col] = false
+}
+
+// moved moves a column whose count has changed from the list of its old count
+// to that of its new count, if it is in the index
+func (b *bucketIndex) moved(col, from, to int) {
+	if b.in[col] {
+		b.remove(col, from)
+		b.insert(col, to)
+	}
+}
+
+// first returns the index of a column with the fewest rows, which must be
+// called with at least one column in the index
+func (b *bucketIndex) first() int {
+	for b.heads[b.min] < 0 {
+		b.min++
+	}
+	return int(b.heads[b.min])
+}
+
+// check makes sure the index holds the active columns, of which there are
+// active, each in the list of its count, see CheckInvariants
+func (b *bucketIndex) check(p *exactCoverProblem, active int) error {
+	indexed := 0
+	for count, col := range b.heads {
+		for prev := int32(-1); col >= 0; prev, col = col, b.next[col] {
+			h := p.colHeaders[col]
+			switch {
+			case b.prev[col] != prev:
+				return fmt.Errorf("Links of column %s in the index are not symmetric", p.colName(h))
+			case !b.in[col] || h.left.right != h:
+				return fmt.Errorf("Column %s is in the index but not active", p.colName(h))
+			case h.colCount != count:
+				return fmt.Errorf("Column %s is indexed with %d rows but has %d", p.colName(h), count, h.colCount)
+			case count < b.min:
+				return fmt.Errorf("Column %s has %d rows, fewer than the least indexed, %d", p.colName(h), count, b.min)
+			}
+			if indexed++; indexed > active {
+				return fmt.Errorf("Index holds more columns than the %d active", active)
+			}
+		}
+	}
+	if indexed != active {
+		return fmt.Errorf("Index holds %d columns but %d are active", indexed, active)
+	}
+	return nil
+}
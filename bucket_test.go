@@ -0,0 +1,67 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBucketedMinRemaining(t *testing.T) {
+	prob := dominoProblem(t, 8)
+	want, _ := prob.SolveContext(context.Background(), WithSortedOutput())
+
+	// Every column chosen has the fewest rows of those left
+	chosen := 0
+	trace := func(e Event) {
+		if e.Kind != ChooseColumn {
+			return
+		}
+		chosen++
+		for col := prob.root.right; col != prob.root; col = col.right {
+			if col.colCount < e.Size {
+				t.Fatalf("Chose column %s with %d rows, but %s has %d", e.Column, e.Size, prob.colName(col), col.colCount)
+			}
+		}
+		if err := prob.CheckInvariants(); err != nil {
+			t.Fatalf("Invariants broken: %v", err)
+		}
+	}
+	got, err := prob.SolveContext(context.Background(), WithHeuristic(BucketedMinRemaining), WithTrace(trace), WithSortedOutput())
+	if err != nil || chosen == 0 || len(got) != 34 || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v: %v", want, got, err)
+	}
+	if prob.buckets != nil {
+		t.Fatal("Expected the index to be dropped after the search")
+	}
+	// Searching in parts finds the same solutions, in another order
+	for op, solns := range splitSolutions(t, prob, WithHeuristic(BucketedMinRemaining)) {
+		if !reflect.DeepEqual(canonicalSolutions(solns), canonicalSolutions(want)) {
+			t.Fatalf("%s: expected the solutions %v in any order, got %v", op, want, solns)
+		}
+	}
+
+	// A branch covered before the search and the rows given are left out
+	// of the index
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	shards, err := prob.Shard()
+	if err != nil {
+		t.Fatalf("Error sharding problem: %v", err)
+	}
+	total := 0
+	for _, s := range shards {
+		solns, err := prob.SolveShard(context.Background(), s, WithHeuristic(BucketedMinRemaining), WithTrace(trace))
+		if err != nil {
+			t.Fatalf("Error solving shard: %v", err)
+		}
+		total += len(solns)
+	}
+	if total != 21 {
+		t.Fatalf("Expected 21 solutions with v0 given, got %d", total)
+	}
+
+	if h, err := ParseHeuristic("bucket"); err != nil || h != BucketedMinRemaining {
+		t.Fatalf("Expected to parse bucket, got %v: %v", h, err)
+	}
+}
@@ -0,0 +1,236 @@
+package gox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Builder constructs an exact cover problem from named columns and sparse
+// rows, rather than the full matrix of bools taken by NewExactCoverProblem.
+//
+// As well as the primary columns, which must be covered exactly once, the
+// builder supports secondary columns which may be covered at most once.
+// A row may also give a colour to a secondary column, in which case the column
+// may be covered by any number of rows as long as they all agree on the
+// colour. Colours make it simple to express constraints such as the letters of
+// crossing words in a crossword agreeing.
+type Builder struct {
+	primary, secondary []string
+	// colsByName maps the name of a column to its index in primary or
+	// secondary, secondaryByName records which of the slices it belongs to
+	colsByName      map[string]int
+	secondaryByName map[string]bool
+	rows            []builderRow
+	rowsByName      map[string]bool
+	// opts are the options given to NewBuilder, applied before those given
+	// to Build, and duplicateRows and validation the policy and mode they set
+	opts          []ProblemOption
+	duplicateRows DuplicateRowPolicy
+	validation    ValidationMode
+	// warnings holds the items ignored by LenientValidation, which are
+	// passed on to each problem built
+	warnings []string
+	// colorsByName maps the name of a colour to the value stored in the nodes,
+	// colour values start at 1 as zero means the node has no colour
+	colorsByName map[string]int
+}
+
+// builderRow holds a row until the problem is built. colors holds the colour
+// given to each of cols, or is nil if the row gives no colours.
+type builderRow struct {
+	name   string
+	cols   []column
+	colors []int
+}
+
+// column identifies a column in the builder
+type column struct {
+	index     int
+	secondary bool
+}
+
+// NewBuilder creates an empty builder. The options are applied to each problem
+// built, before those given to Build; WithDuplicateRows and WithValidation
+// must be given here, as each row is checked when it is added.
+func NewBuilder(opts ...ProblemOption) *Builder {
+	var scratch exactCoverProblem
+	for _, opt := range opts {
+		opt(&scratch)
+	}
+	return &Builder{
+		colsByName:      make(map[string]int),
+		secondaryByName: make(map[string]bool),
+		rowsByName:      make(map[string]bool),
+		colorsByName:    make(map[string]int),
+		opts:            opts,
+		duplicateRows:   scratch.duplicateRows,
+		validation:      scratch.validation,
+	}
+}
+
+// AddColumns adds primary columns to the problem. Every primary column must be
+// covered by exactly one row of a solution.
+func (b *Builder) AddColumns(names ...string) error {
+	for _, name := range names {
+		if err := b.addColumn(name, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSecondaryColumns adds secondary columns to the problem. A secondary column
+// is covered by at most one row in a solution, unless the rows covering it give
+// it the same colour.
+func (b *Builder) AddSecondaryColumns(names ...string) error {
+	for _, name := range names {
+		if err := b.addColumn(name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn checks the name of a column and records it
+func (b *Builder) addColumn(name string, secondary bool) error {
+	if name == "" {
+		return fmt.Errorf("Column names must not be empty")
+	}
+	if strings.Contains(name, ":") {
+		return fmt.Errorf("Column names must not contain ':': %s", name)
+	}
+	if _, ok := b.colsByName[name]; ok {
+		return fmt.Errorf("Duplicate column name present: %s", name)
+	}
+
+	if secondary {
+		b.colsByName[name] = len(b.secondary)
+		b.secondary = append(b.secondary, name)
+	} else {
+		b.colsByName[name] = len(b.primary)
+		b.primary = append(b.primary, name)
+	}
+	b.secondaryByName[name] = secondary
+	return nil
+}
+
+// AddRow adds a row to the problem which covers the columns named by items.
+// An item is either the name of a column, or for secondary columns, the name
+// followed by a colon and a colour, e.g. "r1c1:A". A name already given to a
+// row is an error unless NewBuilder was given another DuplicateRowPolicy.
+func (b *Builder) AddRow(name string, items ...string) error {
+	if b.rowsByName[name] && b.duplicateRows == RejectDuplicateRows {
+		return fmt.Errorf("Duplicate row name present: %s", name)
+	}
+
+	row := builderRow{name: name}
+	seen := make(map[string]bool, len(items))
+	colored := false
+	for _, item := range items {
+		colName, color := item, ""
+		if i := strings.Index(item, ":"); i >= 0 {
+			colName, color = item[:i], item[i+1:]
+		}
+
+		index, ok := b.colsByName[colName]
+		if !ok {
+			if b.validation == LenientValidation {
+				b.warn("Row %s refers to unknown column %s, which is ignored", name, colName)
+				continue
+			}
+			return fmt.Errorf("Row %s refers to unknown column %s", name, colName)
+		}
+		if seen[colName] {
+			if b.validation == LenientValidation {
+				b.warn("Row %s contains column %s more than once, which is ignored", name, colName)
+				continue
+			}
+			return fmt.Errorf("Row %s contains column %s more than once", name, colName)
+		}
+		seen[colName] = true
+
+		secondary := b.secondaryByName[colName]
+		colorValue := 0
+		if color != "" && !secondary && b.validation == LenientValidation {
+			b.warn("Row %s gives a colour to primary column %s, which is ignored", name, colName)
+			color = ""
+		}
+		if color != "" {
+			if !secondary {
+				return fmt.Errorf("Row %s gives a colour to primary column %s", name, colName)
+			}
+			colorValue = b.colorsByName[color]
+			if colorValue == 0 {
+				colorValue = len(b.colorsByName) + 1
+				b.colorsByName[color] = colorValue
+			}
+			colored = true
+		}
+
+		row.cols = append(row.cols, column{index: index, secondary: secondary})
+		row.colors = append(row.colors, colorValue)
+	}
+	if !colored {
+		row.colors = nil
+	}
+
+	b.rowsByName[name] = true
+	b.rows = append(b.rows, row)
+	return nil
+}
+
+// Build creates the exact cover problem from the columns and rows added to the
+// builder. The builder may be used to build further problems, each of which is
+// independent of the others.
+func (b *Builder) Build(opts ...ProblemOption) (*exactCoverProblem, error) {
+	ret := &exactCoverProblem{
+		numCols:    len(b.primary) + len(b.secondary),
+		numPrimary: len(b.primary),
+		rowsByName: make(map[string]*rowHeader, len(b.rows)),
+		colNames:   append(append([]string(nil), b.primary...), b.secondary...),
+		colorNames: make([]string, len(b.colorsByName)),
+	}
+	for name, v := range b.colorsByName {
+		ret.colorNames[v-1] = name
+	}
+	for _, opt := range b.opts {
+		opt(ret)
+	}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	ret.warnings = append([]string(nil), b.warnings...)
+	span := ret.startBuild()
+
+	// Create root, ensure the column index is invalid
+	ret.root = &node{colIndex: -1}
+	ret.root.right = ret.root
+	ret.root.left = ret.root
+
+	ret.allocateColHeaders()
+	ret.initializeColHeaders()
+
+	rows := make([]sparseRow, len(b.rows))
+	for i, row := range b.rows {
+		rows[i] = sparseRow{name: row.name, cols: make([]int, len(row.cols)), colors: row.colors}
+		for j, c := range row.cols {
+			rows[i].cols[j] = c.index
+			if c.secondary {
+				rows[i].cols[j] += len(b.primary)
+			}
+		}
+	}
+	if err := ret.addRows(rows); err != nil {
+		ret.endBuild(span, err)
+		return nil, err
+	}
+	ret.numRows = len(ret.rowHeaders)
+	ret.endBuild(span, nil)
+	ret.setState(Ready)
+	return ret, nil
+}
+
+// warn records an item ignored by LenientValidation
+func (b *Builder) warn(format string, args ...interface{}) {
+	b.warnings = append(b.warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,157 @@
+package gox
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+type builderRowSpec struct {
+	name  string
+	items []string
+}
+
+type builderTest struct {
+	primary, secondary []string
+	rows               []builderRowSpec
+	solns              []string
+}
+
+var builderTests = []builderTest{
+	// Secondary columns are covered at most once
+	builderTest{
+		primary:   []string{"a", "b"},
+		secondary: []string{"s"},
+		rows: []builderRowSpec{
+			builderRowSpec{"R1", []string{"a", "s"}},
+			builderRowSpec{"R2", []string{"b", "s"}},
+			builderRowSpec{"R3", []string{"a"}},
+			builderRowSpec{"R4", []string{"b"}},
+		},
+		solns: []string{"R1 R4", "R2 R3", "R3 R4"},
+	},
+	// The example of colours given by Knuth in The Art of Computer
+	// Programming, Volume 4B
+	builderTest{
+		primary:   []string{"p", "q", "r"},
+		secondary: []string{"x", "y"},
+		rows: []builderRowSpec{
+			builderRowSpec{"1", []string{"p", "q", "x", "y:A"}},
+			builderRowSpec{"2", []string{"p", "r", "x:A", "y"}},
+			builderRowSpec{"3", []string{"p", "x:B"}},
+			builderRowSpec{"4", []string{"q", "x:A"}},
+			builderRowSpec{"5", []string{"r", "y:B"}},
+		},
+		solns: []string{"2 4"},
+	},
+}
+
+// canonicalSolutions sorts each solution and then the solutions themselves so
+// that they can be compared regardless of the order they were found in
+func canonicalSolutions(solns [][]string) []string {
+	var ret []string
+	for _, s := range solns {
+		s = append([]string(nil), s...)
+		sort.Strings(s)
+		ret = append(ret, strings.Join(s, " "))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestBuilderSolve(t *testing.T) {
+	for i, test := range builderTests {
+		b := NewBuilder()
+		if err := b.AddColumns(test.primary...); err != nil {
+			t.Fatalf("Error adding columns: %v", err)
+		}
+		if err := b.AddSecondaryColumns(test.secondary...); err != nil {
+			t.Fatalf("Error adding secondary columns: %v", err)
+		}
+		for _, r := range test.rows {
+			if err := b.AddRow(r.name, r.items...); err != nil {
+				t.Fatalf("Error adding row %s: %v", r.name, err)
+			}
+		}
+		prob, err := b.Build()
+		if err != nil {
+			t.Fatalf("Error building problem %d: %v", i, err)
+		}
+		assertStringSliceEqual(t, test.solns, canonicalSolutions(prob.Solve()))
+	}
+}
+
+// A Builder is not frozen by Build, unlike the problems it builds, which are
+// Ready once built: rows added afterwards go into the next problem only
+func TestBuilderAddRowAfterBuild(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("a", "b")
+	b.AddRow("ab", "a", "b")
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if s := first.State(); s != Ready {
+		t.Fatalf("Expected the built problem to be ready, got %v", s)
+	}
+	if err := b.AddRow("a", "a"); err != nil {
+		t.Fatalf("Error adding a row after building: %v", err)
+	}
+	b.AddRow("b", "b")
+	second, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	assertStringSliceEqual(t, []string{"ab"}, canonicalSolutions(first.Solve()))
+	assertStringSliceEqual(t, []string{"a b", "ab"}, canonicalSolutions(second.Solve()))
+}
+
+func TestBuilderGivenColoredRow(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("p", "q", "r")
+	b.AddSecondaryColumns("x", "y")
+	b.AddRow("1", "p", "q", "x", "y:A")
+	b.AddRow("2", "p", "r", "x:A", "y")
+	b.AddRow("4", "q", "x:A")
+	b.AddRow("6", "q", "x:B")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if err := prob.RowIsSolution("2"); err != nil {
+		t.Fatalf("Error adding given row: %v", err)
+	}
+	assertStringSliceEqual(t, []string{"2 4"}, canonicalSolutions(prob.Solve()))
+}
+
+func TestBuilderErrors(t *testing.T) {
+	b := NewBuilder()
+	if err := b.AddColumns("a", "b"); err != nil {
+		t.Fatalf("Error adding columns: %v", err)
+	}
+	if err := b.AddSecondaryColumns("s"); err != nil {
+		t.Fatalf("Error adding secondary columns: %v", err)
+	}
+	if err := b.AddRow("R1", "a"); err != nil {
+		t.Fatalf("Error adding row: %v", err)
+	}
+
+	if err := b.AddColumns("a"); err == nil {
+		t.Fatal("Expected error adding duplicate column")
+	}
+	if err := b.AddColumns("c:d"); err == nil {
+		t.Fatal("Expected error adding column containing a colon")
+	}
+	if err := b.AddRow("R1", "b"); err == nil {
+		t.Fatal("Expected error adding duplicate row")
+	}
+	if err := b.AddRow("R2", "z"); err == nil {
+		t.Fatal("Expected error adding row with unknown column")
+	}
+	if err := b.AddRow("R3", "a", "a"); err == nil {
+		t.Fatal("Expected error adding row with repeated column")
+	}
+	if err := b.AddRow("R4", "a:X"); err == nil {
+		t.Fatal("Expected error colouring a primary column")
+	}
+}
@@ -0,0 +1,69 @@
+package gox
+
+// The nodes and row headers of a problem are allocated in blocks, sized by
+// counting the rows and true cells before any is linked, rather than one at a
+// time. Constructors given the whole matrix or every row count them exactly,
+// NewExactCoverProblemReader relies on the hints of WithCapacity.
+
+// WithCapacity hints the number of rows and of true cells, the nodes linked
+// into the matrix, of a problem read by NewExactCoverProblemReader, which
+// cannot count them until every row has been read. The node and row slices
+// are then allocated once at this capacity, rather than grown as the rows are
+// read. The hints need not be exact: rows beyond them are allocated one at a
+// time, each with its nodes in a block of their own, and any capacity left
+// over is wasted. The other constructors count the
+// rows and cells themselves, so ignore the hints.
+func WithCapacity(rows, cells int) ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.rowsHint, p.cellsHint = rows, cells
+	}
+}
+
+// reserve allocates room for rows more row headers covering cells nodes in
+// all, so that linking them allocates nothing more
+func (p *exactCoverProblem) reserve(rows, cells int) {
+	if rows > cap(p.rowHeaders)-len(p.rowHeaders) {
+		p.rowHeaders = append(make([]*rowHeader, 0, len(p.rowHeaders)+rows), p.rowHeaders...)
+	}
+	if rows > len(p.rowPool) {
+		p.rowPool = make([]rowHeader, rows)
+	}
+	if cells > len(p.nodePool) {
+		p.nodePool = make([]node, cells)
+	}
+}
+
+// dropReserved drops the room reserved but not used
+func (p *exactCoverProblem) dropReserved() {
+	p.rowPool, p.nodePool = nil, nil
+}
+
+// newNodes returns n nodes for a row of the matrix, next to one another, from
+// the room reserved if enough is left
+func (p *exactCoverProblem) newNodes(n int) []node {
+	if len(p.nodePool) < n {
+		return make([]node, n)
+	}
+	ret := p.nodePool[:n:n]
+	p.nodePool = p.nodePool[n:]
+	return ret
+}
+
+// newRowHeader returns a row header, from the room reserved if any is left
+func (p *exactCoverProblem) newRowHeader() *rowHeader {
+	if len(p.rowPool) == 0 {
+		return &rowHeader{}
+	}
+	ret := &p.rowPool[0]
+	p.rowPool = p.rowPool[1:]
+	return ret
+}
+
+// countCells returns the number of columns covered by the rows altogether
+func countCells(rows []sparseRow) int {
+	ret := 0
+	for _, r := range rows {
+		ret += len(r.cols)
+	}
+	return ret
+}
@@ -0,0 +1,68 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dominoDLX is the problem of tiling a 2xn board with dominoes in the format
+// read by NewExactCoverProblemReader
+func dominoDLX(n int) string {
+	var cols, rows []string
+	for c := 0; c < n; c++ {
+		cols = append(cols, fmt.Sprintf("0,%d 1,%d", c, c))
+		rows = append(rows, fmt.Sprintf("0,%d 1,%d", c, c))
+		if c > 0 {
+			for r := 0; r < 2; r++ {
+				rows = append(rows, fmt.Sprintf("%d,%d %d,%d", r, c-1, r, c))
+			}
+		}
+	}
+	return strings.Join(cols, " ") + "\n" + strings.Join(rows, "\n") + "\n"
+}
+
+func TestCapacity(t *testing.T) {
+	// Each row of a matrix of 200 rows covers 3 of 50 columns, so nodes
+	// allocated one at a time would take at least 600 allocations
+	m := make([][]bool, 200)
+	names := make([]string, len(m))
+	for i := range m {
+		m[i] = make([]bool, 50)
+		for j := 0; j < 3; j++ {
+			m[i][(i+17*j)%50] = true
+		}
+		names[i] = fmt.Sprint(i)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := NewExactCoverProblem(m, names); err != nil {
+			t.Fatalf("Error creating problem: %v", err)
+		}
+	})
+	if allocs >= 400 {
+		t.Fatalf("Expected nodes to be allocated together, got %v allocations", allocs)
+	}
+
+	// The hints of a stream need not be exact
+	text := dominoDLX(40)
+	read := func(opts ...ProblemOption) *exactCoverProblem {
+		prob, err := NewExactCoverProblemReader(strings.NewReader(text), opts...)
+		if err != nil {
+			t.Fatalf("Error reading problem: %v", err)
+		}
+		return prob
+	}
+	without := testing.AllocsPerRun(10, func() { read() })
+	with := testing.AllocsPerRun(10, func() { read(WithCapacity(118, 236)) })
+	if with >= without {
+		t.Fatalf("Expected fewer allocations with hints, got %v and %v without", with, without)
+	}
+	for _, opt := range []ProblemOption{WithCapacity(10, 20), WithCapacity(1000, 2000)} {
+		prob := read(opt, WithDebug())
+		solns, err := prob.SolveContext(context.Background(), WithLimit(5))
+		if err != nil || len(solns) != 5 || prob.Report().Nodes != 236 {
+			t.Fatalf("Unexpected problem read: %v %v", prob.Report(), err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Command capi is a C interface to gox, built as a shared library for
+// embedding the solver in programs in other languages, such as Python through
+// ctypes or Rust through bindgen:
+//
+//	go build -buildmode=c-shared -o libgox.so .
+//
+// which also writes the header libgox.h. The Makefile in this directory does
+// this and builds the example in example/example.c against the library.
+//
+// Problems and sets of solutions are referred to by handles, which must be
+// freed. Columns and rows are referred to by index, the primary columns
+// coming before the secondary columns. A problem is created from sparse
+// arrays: the columns of row i are cols[offsets[i]] to cols[offsets[i+1]-1],
+// and colors, if not NULL, gives the colour each row gives to each of its
+// columns, 0 meaning none. Functions which may fail return 0 on success, or
+// -1 after setting *err, if err is not NULL, to a message to be freed with
+// gox_string_free. The API is versioned by gox_api_version, which only
+// changes when the API does.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// gox_problem and gox_solutions are handles to a problem and a set of
+// solutions, 0 being no handle
+typedef uintptr_t gox_problem;
+typedef uintptr_t gox_solutions;
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"runtime/cgo"
+	"strconv"
+	"unsafe"
+
+	"github.com/ifross89/gox"
+)
+
+// apiVersion is the version of the C API
+const apiVersion = 1
+
+func main() {}
+
+// problem is the value behind a gox_problem handle
+type problem struct {
+	prob gox.Solver
+}
+
+//export gox_api_version
+func gox_api_version() C.int {
+	return apiVersion
+}
+
+// gox_problem_new creates a problem with the given numbers of primary and
+// secondary columns and rows, see the package documentation, storing its
+// handle in *out.
+//
+//export gox_problem_new
+func gox_problem_new(numPrimary, numSecondary, numRows C.int, offsets, cols, colors *C.int, out *C.gox_problem, err **C.char) C.int {
+	if numRows < 0 || offsets == nil && numRows > 0 {
+		return fail(err, fmt.Errorf("Row offsets must be given for %d rows", numRows))
+	}
+	var offs []int
+	if numRows > 0 {
+		offs = ints(offsets, int(numRows)+1)
+	}
+	n := 0
+	if len(offs) > 0 {
+		n = offs[len(offs)-1]
+	}
+	if n > 0 && cols == nil {
+		return fail(err, fmt.Errorf("Columns must be given for %d cells", n))
+	}
+	var cs, colours []int
+	if n > 0 {
+		cs = ints(cols, n)
+		if colors != nil {
+			colours = ints(colors, n)
+		}
+	}
+	p, e := newProblem(int(numPrimary), int(numSecondary), offs, cs, colours)
+	if e != nil {
+		return fail(err, e)
+	}
+	*out = C.gox_problem(cgo.NewHandle(p))
+	return 0
+}
+
+// gox_problem_free frees a problem
+//
+//export gox_problem_free
+func gox_problem_free(p C.gox_problem) {
+	cgo.Handle(p).Delete()
+}
+
+// gox_problem_give gives a row as part of every solution, see RowIsSolution
+//
+//export gox_problem_give
+func gox_problem_give(p C.gox_problem, row C.int, err **C.char) C.int {
+	if e := cgo.Handle(p).Value().(*problem).give(int(row)); e != nil {
+		return fail(err, e)
+	}
+	return 0
+}
+
+// gox_solve finds at most limit solutions to a problem, or all of them if
+// limit is 0, storing the handle of the set of solutions in *out
+//
+//export gox_solve
+func gox_solve(p C.gox_problem, limit C.int, out *C.gox_solutions, err **C.char) C.int {
+	solns, e := cgo.Handle(p).Value().(*problem).solve(int(limit))
+	if e != nil {
+		return fail(err, e)
+	}
+	*out = C.gox_solutions(cgo.NewHandle(solns))
+	return 0
+}
+
+// gox_solutions_count returns the number of solutions in a set
+//
+//export gox_solutions_count
+func gox_solutions_count(s C.gox_solutions) C.int {
+	return C.int(len(cgo.Handle(s).Value().([][]int)))
+}
+
+// gox_solutions_get copies the indexes of the rows of the i-th solution of a
+// set into rows, which has room for size of them, returning the number of
+// rows of the solution, which may be more than size, or -1 if there is no
+// i-th solution
+//
+//export gox_solutions_get
+func gox_solutions_get(s C.gox_solutions, i C.int, rows *C.int, size C.int) C.int {
+	solns := cgo.Handle(s).Value().([][]int)
+	if i < 0 || int(i) >= len(solns) {
+		return -1
+	}
+	soln := solns[i]
+	if rows != nil && size > 0 {
+		out := unsafe.Slice(rows, int(size))
+		for j := 0; j < len(soln) && j < int(size); j++ {
+			out[j] = C.int(soln[j])
+		}
+	}
+	return C.int(len(soln))
+}
+
+// gox_solutions_free frees a set of solutions
+//
+//export gox_solutions_free
+func gox_solutions_free(s C.gox_solutions) {
+	cgo.Handle(s).Delete()
+}
+
+// gox_string_free frees a string returned by the API
+//
+//export gox_string_free
+func gox_string_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// ints copies n C ints into a slice
+func ints(p *C.int, n int) []int {
+	ret := make([]int, n)
+	for i, v := range unsafe.Slice(p, n) {
+		ret[i] = int(v)
+	}
+	return ret
+}
+
+// fail sets *err to the message of e, if err is not NULL, and returns -1
+func fail(err **C.char, e error) C.int {
+	if err != nil {
+		*err = C.CString(e.Error())
+	}
+	return -1
+}
+
+// newProblem creates a problem from the sparse arrays of gox_problem_new
+func newProblem(numPrimary, numSecondary int, offsets, cols, colors []int) (*problem, error) {
+	if numPrimary < 0 || numSecondary < 0 {
+		return nil, fmt.Errorf("Numbers of columns must not be negative")
+	}
+	numCols := numPrimary + numSecondary
+	b := gox.NewBuilder()
+	for i := 0; i < numCols; i++ {
+		add := b.AddColumns
+		if i >= numPrimary {
+			add = b.AddSecondaryColumns
+		}
+		if err := add(strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i+1 < len(offsets); i++ {
+		start, end := offsets[i], offsets[i+1]
+		if start < 0 || end < start || end > len(cols) {
+			return nil, fmt.Errorf("Invalid offsets for row %d: %d to %d", i, start, end)
+		}
+		var items []string
+		for j := start; j < end; j++ {
+			if cols[j] < 0 || cols[j] >= numCols {
+				return nil, fmt.Errorf("Row %d refers to column %d, but there are %d", i, cols[j], numCols)
+			}
+			item := strconv.Itoa(cols[j])
+			if colors != nil && colors[j] != 0 {
+				item += ":" + strconv.Itoa(colors[j])
+			}
+			items = append(items, item)
+		}
+		if err := b.AddRow(strconv.Itoa(i), items...); err != nil {
+			return nil, err
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &problem{prob: prob}, nil
+}
+
+// give gives the row with the given index
+func (p *problem) give(row int) error {
+	return p.prob.RowIsSolution(strconv.Itoa(row))
+}
+
+// solve finds at most limit solutions, as the indexes of their rows. A
+// problem with an uncoverable column has no solutions.
+func (p *problem) solve(limit int) ([][]int, error) {
+	solns, err := p.prob.SolveIndices(context.Background(), gox.WithLimit(limit))
+	if _, ok := err.(*gox.UncoverableError); ok {
+		return nil, nil
+	}
+	return solns, err
+}
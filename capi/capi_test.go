@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProblem(t *testing.T) {
+	// Rows 0: {0, 1}, 1: {2}, 2: {0}, 3: {1, 2}, 4: {0, s:1}, 5: {s:2}
+	offsets := []int{0, 2, 3, 4, 6, 8, 9}
+	cols := []int{0, 1, 2, 0, 1, 2, 0, 3, 3}
+	colors := []int{0, 0, 0, 0, 0, 0, 0, 1, 2}
+	p, err := newProblem(3, 1, offsets, cols, colors)
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	solns, err := p.solve(0)
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if want := [][]int{{0, 1}, {3, 2}, {3, 4}}; !reflect.DeepEqual(solns, want) {
+		t.Fatalf("Expected %v, got %v", want, solns)
+	}
+	if err := p.give(3); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if solns, err := p.solve(1); err != nil || len(solns) != 1 {
+		t.Fatalf("Expected one solution, got %v, %v", solns, err)
+	}
+	if err := p.give(10); err == nil {
+		t.Fatalf("Expected an error giving an unknown row")
+	}
+
+	for _, bad := range [][]int{{0, 3}, {1, 0}, {0, 2}} {
+		if _, err := newProblem(3, 0, bad, []int{0, 5}, nil); err == nil {
+			t.Errorf("Expected an error with offsets %v", bad)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Solves a small problem through the C interface to gox, printing each
+// solution as the indexes of its rows
+#include <stdio.h>
+#include "../libgox.h"
+
+int main(void) {
+	// Rows 0: {0, 1}, 1: {2}, 2: {0}, 3: {1, 2} over three primary columns
+	int offsets[] = {0, 2, 3, 4, 6};
+	int cols[] = {0, 1, 2, 0, 1, 2};
+	gox_problem prob;
+	gox_solutions solns;
+	char *err = NULL;
+	if (gox_problem_new(3, 0, 4, offsets, cols, NULL, &prob, &err) != 0) {
+		fprintf(stderr, "Error creating problem: %s\n", err);
+		gox_string_free(err);
+		return 1;
+	}
+	if (gox_solve(prob, 0, &solns, &err) != 0) {
+		fprintf(stderr, "Error solving problem: %s\n", err);
+		gox_string_free(err);
+		return 1;
+	}
+	for (int i = 0; i < gox_solutions_count(solns); i++) {
+		int rows[8];
+		int n = gox_solutions_get(solns, i, rows, 8);
+		for (int j = 0; j < n && j < 8; j++) {
+			printf("%s%d", j > 0 ? " " : "", rows[j]);
+		}
+		printf("\n");
+	}
+	gox_solutions_free(solns);
+	gox_problem_free(prob);
+	return 0;
+}
@@ -0,0 +1,51 @@
+package gox
+
+import "sort"
+
+// ColumnChoices counts the times a column was chosen to branch on, see
+// Stats.Columns
+type ColumnChoices struct {
+	Column string
+	// Total is the number of times the column was chosen, and Depths the
+	// number at each depth of the search, Depths[d] counting the choices
+	// made with d rows in the partial solution, including the rows given
+	// with RowIsSolution
+	Total  int64
+	Depths []int64
+}
+
+// chose records the column chosen to branch on, if the search records
+// statistics, and sends the ChooseColumn event
+func (p *exactCoverProblem) chose(c *config, colHead *node) {
+	p.column = colHead
+	if c.choices != nil {
+		depths := c.choices[colHead.colIndex]
+		d := len(p.solutionRows)
+		for len(depths) <= d {
+			depths = append(depths, 0)
+		}
+		depths[d]++
+		c.choices[colHead.colIndex] = depths
+	}
+	p.emit(c, ChooseColumn, colHead, nil)
+}
+
+// columnChoices returns the choices recorded by chose for the columns chosen
+// at least once, the columns chosen most often first
+func (p *exactCoverProblem) columnChoices(choices [][]int64) []ColumnChoices {
+	var cols []ColumnChoices
+	for i, depths := range choices {
+		if depths == nil {
+			continue
+		}
+		cc := ColumnChoices{Column: p.colName(p.colHeaders[i]), Depths: depths}
+		for _, n := range depths {
+			cc.Total += n
+		}
+		cols = append(cols, cc)
+	}
+	sort.SliceStable(cols, func(i, j int) bool {
+		return cols[i].Total > cols[j].Total
+	})
+	return cols
+}
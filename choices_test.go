@@ -0,0 +1,56 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestColumnChoices(t *testing.T) {
+	prob := branchingProblem(t)
+	var stats Stats
+	if _, err := prob.SolveContext(context.Background(), WithHeuristic(FirstColumn), WithStats(&stats)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	expected := []ColumnChoices{
+		{Column: "a", Total: 1, Depths: []int64{1}},
+		{Column: "b", Total: 1, Depths: []int64{0, 1}},
+		{Column: "c", Total: 1, Depths: []int64{0, 1}},
+	}
+	if !reflect.DeepEqual(stats.Columns, expected) {
+		t.Fatalf("Expected choices %+v, got %+v", expected, stats.Columns)
+	}
+
+	// The choices match the columns chosen in the trace, the columns chosen
+	// most often first
+	prob = dominoProblem(t, 6)
+	traced := make(map[string]map[int]int64)
+	if _, err := prob.SolveContext(context.Background(), WithStats(&stats), WithTrace(func(e Event) {
+		if e.Kind == ChooseColumn {
+			if traced[e.Column] == nil {
+				traced[e.Column] = make(map[int]int64)
+			}
+			traced[e.Column][e.Depth]++
+		}
+	})); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(stats.Columns) != len(traced) {
+		t.Fatalf("Expected %d columns chosen, got %+v", len(traced), stats.Columns)
+	}
+	for i, cc := range stats.Columns {
+		if i > 0 && cc.Total > stats.Columns[i-1].Total {
+			t.Fatalf("Columns are not ordered by total: %+v", stats.Columns)
+		}
+		total := int64(0)
+		for d, n := range cc.Depths {
+			if n != traced[cc.Column][d] {
+				t.Fatalf("Expected column %s chosen %d times at depth %d, got %d", cc.Column, traced[cc.Column][d], d, n)
+			}
+			total += n
+		}
+		if total != cc.Total {
+			t.Fatalf("Expected total %d for column %s, got %d", total, cc.Column, cc.Total)
+		}
+	}
+}
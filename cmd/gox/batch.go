@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+func init() {
+	commands["batch"] = command{
+		summary: "solve every problem in a directory, optionally watching for new files, writing the results as JSON lines",
+		run:     runBatch,
+	}
+}
+
+// batchResult is the line written for each file solved by gox batch
+type batchResult struct {
+	File string `json:"file"`
+	solveResult
+	// Seconds is the time taken to read and solve the problem
+	Seconds float64 `json:"seconds"`
+}
+
+// batch holds the settings of gox batch and the files it has already solved
+type batch struct {
+	dir       string
+	limit     int
+	timeout   time.Duration
+	heuristic gox.Heuristic
+	enc       *json.Encoder
+	done      map[string]bool
+}
+
+// pending returns the names of the files in the directory which have not yet
+// been solved, in order. Files without the extension of a format which can be
+// read are ignored.
+func (b *batch) pending() ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, entry := range entries {
+		if entry.IsDir() || b.done[entry.Name()] {
+			continue
+		}
+		if f, err := format.FromFilename(entry.Name()); err != nil || !f.Readable() {
+			continue
+		}
+		ret = append(ret, entry.Name())
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// solve solves a file and writes its result. Errors reading or solving the
+// file are reported in the result, only errors writing it are returned.
+func (b *batch) solve(ctx context.Context, name string) error {
+	b.done[name] = true
+	start := time.Now()
+	res := batchResult{File: name}
+	in, err := readFile(filepath.Join(b.dir, name))
+	if err == nil {
+		res.solveResult, err = solveInstance(ctx, in, b.limit, b.timeout, b.heuristic)
+	}
+	if err != nil {
+		res.Solutions = [][]string{}
+		res.Error = err.Error()
+	}
+	res.Seconds = time.Since(start).Seconds()
+	return b.enc.Encode(res)
+}
+
+// run solves the pending files, then if interval is positive checks for new
+// files that often until ctx is done
+func (b *batch) run(ctx context.Context, interval time.Duration) error {
+	for {
+		names, err := b.pending()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := b.solve(ctx, name); err != nil {
+				return err
+			}
+		}
+		if interval <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// readFile reads an instance from a file in the format given by its extension
+func readFile(filename string) (*format.Instance, error) {
+	f, err := format.FromFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return format.Read(file, f)
+}
+
+func runBatch(e *env, args []string) error {
+	fs := newFlagSet(e, "batch", "dir")
+	limit := fs.Int("limit", 0, "stop after finding this many solutions to a problem, 0 for no limit")
+	timeout := fs.Duration("timeout", 10*time.Second, "stop searching for the solutions to a problem after this long, 0 for no timeout")
+	heuristic := fs.String("heuristic", gox.MinRemaining.String(), "column choice heuristic: mrv, first, wdeg or bucket")
+	watch := fs.Bool("watch", false, "keep checking for new files until interrupted")
+	interval := fs.Duration("interval", time.Second, "how often to check for new files when watching")
+	output := fs.String("o", "", "file to append the results to (default stdout)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return usage(fs, "expected a directory")
+	}
+	h, err := gox.ParseHeuristic(*heuristic)
+	if err != nil {
+		return usage(fs, "%v", err)
+	}
+	if *watch && *interval <= 0 {
+		return usage(fs, "interval must be positive")
+	}
+
+	var w io.Writer = e.stdout
+	if *output != "" {
+		file, err := os.OpenFile(*output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	b := &batch{
+		dir:       fs.Arg(0),
+		limit:     *limit,
+		timeout:   *timeout,
+		heuristic: h,
+		enc:       json.NewEncoder(w),
+		done:      make(map[string]bool),
+	}
+	if !*watch {
+		return b.run(context.Background(), 0)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return b.run(ctx, *interval)
+}
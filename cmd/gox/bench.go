@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/benchmarks"
+)
+
+func init() {
+	commands["bench"] = command{
+		summary: "solve the bundled classic instances and compare the work done by each heuristic",
+		run:     runBench,
+	}
+}
+
+// benchInstances are the instances run by gox bench, in order
+var benchInstances = []benchmarks.Instance{
+	benchmarks.Pentominoes(20, 3),
+	benchmarks.Pentominoes(10, 6),
+	benchmarks.Queens(8),
+	benchmarks.Queens(10),
+	benchmarks.Queens(12),
+	benchmarks.Langford(8),
+	benchmarks.Langford(11),
+	benchmarks.Langford(12),
+	benchmarks.SudokuBatch(),
+}
+
+// benchResult is the outcome of solving an instance with one heuristic
+type benchResult struct {
+	stats    gox.Stats
+	duration time.Duration
+	// alloc is the number of bytes allocated while solving
+	alloc uint64
+	err   error
+}
+
+// runInstance solves each problem of the instance, adding up the work done
+func runInstance(inst benchmarks.Instance, h gox.Heuristic, timeout time.Duration) benchResult {
+	var res benchResult
+	probs, err := inst.Problems()
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for _, prob := range probs {
+		var stats gox.Stats
+		_, err := prob.SolveContext(ctx, gox.WithHeuristic(h), gox.WithStats(&stats))
+		res.stats.Nodes += stats.Nodes
+		res.stats.Updates += stats.Updates
+		res.stats.Solutions += stats.Solutions
+		if err != nil {
+			res.err = err
+			break
+		}
+	}
+	res.duration = time.Since(start)
+	runtime.ReadMemStats(&after)
+	res.alloc = after.TotalAlloc - before.TotalAlloc
+	return res
+}
+
+// formatBytes returns a number of bytes in the largest unit which keeps it
+// above one
+func formatBytes(n uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+	f := float64(n)
+	i := 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", f, units[i])
+}
+
+func runBench(e *env, args []string) error {
+	fs := newFlagSet(e, "bench", "")
+	names := fs.String("instances", "", "comma separated instances to run (default all)")
+	heuristics := fs.String("heuristics", "mrv,first", "comma separated heuristics to compare")
+	timeout := fs.Duration("timeout", 30*time.Second, "stop solving an instance after this long, 0 for no timeout")
+	list := fs.Bool("list", false, "list the instances and exit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usage(fs, "unexpected arguments")
+	}
+
+	if *list {
+		for _, inst := range benchInstances {
+			fmt.Fprintln(e.stdout, inst.Name)
+		}
+		return nil
+	}
+
+	instances := benchInstances
+	if *names != "" {
+		byName := make(map[string]benchmarks.Instance)
+		for _, inst := range benchInstances {
+			byName[inst.Name] = inst
+		}
+		instances = nil
+		for _, name := range strings.Split(*names, ",") {
+			inst, ok := byName[strings.TrimSpace(name)]
+			if !ok {
+				return usage(fs, "unknown instance %q, see gox bench -list", name)
+			}
+			instances = append(instances, inst)
+		}
+	}
+
+	var hs []gox.Heuristic
+	for _, name := range strings.Split(*heuristics, ",") {
+		h, err := gox.ParseHeuristic(strings.TrimSpace(name))
+		if err != nil {
+			return usage(fs, "%v", err)
+		}
+		hs = append(hs, h)
+	}
+
+	tw := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "instance\theuristic\tsolutions\tnodes\tupdates\ttime\talloc\t")
+	for _, inst := range instances {
+		for _, h := range hs {
+			res := runInstance(inst, h, *timeout)
+			status := ""
+			if res.err == context.DeadlineExceeded {
+				status = " (timeout)"
+			} else if res.err != nil {
+				return fmt.Errorf("Error solving %s: %v", inst.Name, res.err)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d%s\t%d\t%d\t%v\t%s\t\n", inst.Name, h, res.stats.Solutions, status,
+				res.stats.Nodes, res.stats.Updates, res.duration.Round(time.Microsecond), formatBytes(res.alloc))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+func init() {
+	commands["browse"] = command{
+		summary: "browse the solutions to a problem interactively, filtering, showing and comparing them, type help for the commands",
+		run:     runBrowse,
+	}
+}
+
+// browseHelp describes the commands of gox browse
+const browseHelp = `commands:
+  list [first]        list the solutions kept by the filter, a page at a time,
+                      or from the first numbered
+  filter <row>        keep only the solutions including a row
+  unfilter <row>      stop filtering on a row
+  clear               remove the filter
+  show <n>            show the matrix with the rows of solution n marked
+  coverage <n>        list the rows of solution n covering each column
+  diff <n> <m>        compare solutions n and m side by side
+  help                show this message
+  quit                leave the browser
+`
+
+// browsePage is the number of solutions listed at a time
+const browsePage = 20
+
+// browsable is what gox browse needs of a problem
+type browsable interface {
+	gox.Solver
+	gox.Inspector
+}
+
+// browser is the state of gox browse: a problem, its solutions and the rows
+// the solutions listed must include. Solutions are numbered from 1 in the
+// order they were found, whatever the filter.
+type browser struct {
+	in    *format.Instance
+	prob  browsable
+	solns [][]string
+	// filter holds the rows the solutions listed must include, and matches
+	// the indexes of those solutions
+	filter  []string
+	matches []int
+	// next is the position in matches of the first solution listed next
+	next int
+}
+
+// newBrowser creates a browser of the solutions to a problem, which must be
+// valid solutions to it
+func newBrowser(in *format.Instance, solns [][]string) (*browser, error) {
+	p, err := in.Problem()
+	if err != nil {
+		return nil, err
+	}
+	prob, ok := p.(browsable)
+	if !ok {
+		return nil, fmt.Errorf("Problem cannot be inspected")
+	}
+	for i, soln := range solns {
+		if err := prob.Verify(soln); err != nil {
+			return nil, fmt.Errorf("Solution %d is invalid: %v", i+1, err)
+		}
+	}
+	b := &browser{in: in, prob: prob, solns: solns}
+	b.apply()
+	return b, nil
+}
+
+// apply finds the solutions including every row of the filter, listing them
+// from the start
+func (b *browser) apply() {
+	b.matches, b.next = b.matches[:0], 0
+	for i, soln := range b.solns {
+		if includes(soln, b.filter) {
+			b.matches = append(b.matches, i)
+		}
+	}
+}
+
+// includes reports whether a solution includes every row given
+func includes(soln, rows []string) bool {
+	for _, row := range rows {
+		found := false
+		for _, r := range soln {
+			found = found || r == row
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// addFilter keeps only the solutions including a row
+func (b *browser) addFilter(row string) error {
+	if _, err := b.prob.RowColumns(row); err != nil {
+		return err
+	}
+	for _, r := range b.filter {
+		if r == row {
+			return fmt.Errorf("Already filtering on row %s", row)
+		}
+	}
+	b.filter = append(b.filter, row)
+	b.apply()
+	return nil
+}
+
+// removeFilter stops filtering on a row
+func (b *browser) removeFilter(row string) error {
+	for i, r := range b.filter {
+		if r == row {
+			b.filter = append(b.filter[:i], b.filter[i+1:]...)
+			b.apply()
+			return nil
+		}
+	}
+	return fmt.Errorf("Not filtering on row %s", row)
+}
+
+// solution returns the solution numbered n, as typed by the user
+func (b *browser) solution(arg string) (*gox.Solution, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(b.solns) {
+		return nil, fmt.Errorf("Expected a solution from 1 to %d, got %q", len(b.solns), arg)
+	}
+	return b.prob.Solution(b.solns[n-1]), nil
+}
+
+// list writes the next page of the solutions kept by the filter, each as its
+// number followed by its rows
+func (b *browser) list(w io.Writer) {
+	end := b.next + browsePage
+	if end > len(b.matches) {
+		end = len(b.matches)
+	}
+	for _, i := range b.matches[b.next:end] {
+		fmt.Fprintf(w, "%d: %s\n", i+1, strings.Join(b.solns[i], ", "))
+	}
+	fmt.Fprintf(w, "%d to %d of %d solutions", b.next+1, end, len(b.matches))
+	if b.filter != nil {
+		fmt.Fprintf(w, " including %s", strings.Join(b.filter, ", "))
+	}
+	fmt.Fprintln(w)
+	if b.next = end; b.next == len(b.matches) {
+		b.next = 0
+	}
+}
+
+// coverage writes each column of the problem with the rows of a solution
+// covering it, in the order of the columns of the problem
+func (b *browser) coverage(w io.Writer, s *gox.Solution) {
+	cover := s.Coverage()
+	for _, col := range append(b.in.Primary[:len(b.in.Primary):len(b.in.Primary)], b.in.Secondary...) {
+		fmt.Fprintf(w, "%s: %s\n", col, strings.Join(cover[col], ", "))
+	}
+}
+
+// diff writes two solutions side by side, the rows they share first and then
+// the rows in only one of them, marked by - and +
+func diff(w io.Writer, a, b *gox.Solution) {
+	d := gox.Diff(a, b)
+	width := 0
+	for _, row := range append(d.Shared[:len(d.Shared):len(d.Shared)], d.OnlyA...) {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	for _, row := range d.Shared {
+		fmt.Fprintf(w, "  %-*s |   %s\n", width, row, row)
+	}
+	for i := 0; i < len(d.OnlyA) || i < len(d.OnlyB); i++ {
+		left, right := "", ""
+		if i < len(d.OnlyA) {
+			left = "- " + d.OnlyA[i]
+		}
+		if i < len(d.OnlyB) {
+			right = "+ " + d.OnlyB[i]
+		}
+		fmt.Fprintf(w, "%-*s | %s\n", width+2, left, right)
+	}
+	fmt.Fprintf(w, "%d shared, distance %d, Jaccard distance %.2f\n", len(d.Shared), d.Distance, d.Jaccard())
+}
+
+// exec runs a line typed into the browser, returning false if the browser
+// should exit. Errors are reported to the user rather than stopping it.
+func (b *browser) exec(e *env, line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+	// Names of rows may contain spaces, e.g. the rows of the DLX format
+	arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+
+	var err error
+	switch fields[0] {
+	case "quit", "exit":
+		return false
+	case "help":
+		fmt.Fprint(e.stdout, browseHelp)
+	case "list":
+		if arg != "" {
+			n, convErr := strconv.Atoi(arg)
+			if convErr != nil || n < 1 || n > len(b.matches) {
+				err = fmt.Errorf("Expected a position from 1 to %d, got %q", len(b.matches), arg)
+				break
+			}
+			b.next = n - 1
+		}
+		b.list(e.stdout)
+	case "filter":
+		err = b.addFilter(arg)
+	case "unfilter":
+		err = b.removeFilter(arg)
+	case "clear":
+		b.filter = nil
+		b.apply()
+	case "show":
+		var s *gox.Solution
+		if s, err = b.solution(arg); err == nil {
+			err = s.Format(e.stdout)
+		}
+	case "coverage":
+		var s *gox.Solution
+		if s, err = b.solution(arg); err == nil {
+			b.coverage(e.stdout, s)
+		}
+	case "diff":
+		if len(fields) != 3 {
+			err = fmt.Errorf("usage: diff <n> <m>")
+			break
+		}
+		var s, t *gox.Solution
+		if s, err = b.solution(fields[1]); err != nil {
+			break
+		}
+		if t, err = b.solution(fields[2]); err == nil {
+			diff(e.stdout, s, t)
+		}
+	default:
+		err = fmt.Errorf("Unknown command %q, type help for the commands", fields[0])
+	}
+	if err != nil {
+		fmt.Fprintf(e.stdout, "error: %v\n", err)
+	}
+	return true
+}
+
+// browse reads commands from r until it is exhausted or quit is typed
+func (b *browser) browse(e *env, r io.Reader, prompt bool) error {
+	scanner := bufio.NewScanner(r)
+	for {
+		if prompt {
+			fmt.Fprint(e.stdout, "browse> ")
+		}
+		if !scanner.Scan() {
+			if prompt {
+				fmt.Fprintln(e.stdout)
+			}
+			return scanner.Err()
+		}
+		if !b.exec(e, scanner.Text()) {
+			return nil
+		}
+	}
+}
+
+func runBrowse(e *env, args []string) error {
+	fs := newFlagSet(e, "browse", "<file>")
+	formatName := fs.String("format", "", "format of the problem: csv, json or dlx (default from the file extension)")
+	solutions := fs.String("solutions", "", "read the solutions from this file, written by gox solve as json or text, rather than solving the problem")
+	limit := fs.Int("limit", 100000, "stop after finding this many solutions, 0 for no limit")
+	timeout := fs.Duration("timeout", 0, "stop searching after this long, 0 for no timeout")
+	quiet := fs.Bool("quiet", false, "do not print a prompt, e.g. when reading commands from a script")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || fs.Arg(0) == "-" || *solutions == "-" {
+		return usage(fs, "expected a problem in a file, commands are read from stdin")
+	}
+
+	in, err := readInstance(e, fs.Arg(0), *formatName)
+	if err != nil {
+		return err
+	}
+	var solns [][]string
+	if *solutions != "" {
+		file, err := os.Open(*solutions)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if solns, err = readSolutions(file, strings.EqualFold(filepath.Ext(*solutions), ".json")); err != nil {
+			return err
+		}
+	} else {
+		start := time.Now()
+		res, err := solveInstance(context.Background(), in, *limit, *timeout, gox.MinRemaining)
+		if err != nil {
+			return err
+		}
+		solns = res.Solutions
+		fmt.Fprintf(e.stdout, "found %d solutions in %v", res.Count, time.Since(start).Round(time.Millisecond))
+		if !res.Complete {
+			fmt.Fprint(e.stdout, ", not all of them")
+		}
+		fmt.Fprintln(e.stdout)
+	}
+
+	b, err := newBrowser(in, solns)
+	if err != nil {
+		return err
+	}
+	return b.browse(e, e.stdin, !*quiet)
+}
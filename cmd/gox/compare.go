@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ifross89/gox"
+)
+
+func init() {
+	commands["compare"] = command{
+		summary: "compare solutions written in the layout of Knuth's dlx1 and xcc, e.g. by them and by gox solve -output knuth",
+		run:     runCompare,
+	}
+}
+
+func runCompare(e *env, args []string) error {
+	fs := newFlagSet(e, "compare", "want got")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usage(fs, "expected two files of solutions")
+	}
+	if fs.Arg(0) == "-" && fs.Arg(1) == "-" {
+		return usage(fs, "only one of the files may be read from stdin")
+	}
+
+	var files [2]io.Reader
+	for i := range files {
+		files[i] = e.stdin
+		if fs.Arg(i) == "-" {
+			continue
+		}
+		file, err := os.Open(fs.Arg(i))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		files[i] = file
+	}
+	if err := gox.CompareKnuth(files[0], files[1]); err != nil {
+		return err
+	}
+	fmt.Fprintln(e.stdout, "The solutions are the same")
+	return nil
+}
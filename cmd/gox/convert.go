@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+
+	"github.com/ifross89/gox/format"
+)
+
+func init() {
+	commands["convert"] = command{
+		summary: "translate a problem between csv, json and dlx, or export it as cnf, lp or cpsat for other solvers",
+		run:     runConvert,
+	}
+}
+
+func runConvert(e *env, args []string) error {
+	fs := newFlagSet(e, "convert", "[file]")
+	from := fs.String("from", "", "format to read: csv, json, dlx or cnf (default from the file extension)")
+	to := fs.String("to", "", "format to write: csv, json, dlx, cnf, lp or cpsat (default from the output file)")
+	output := fs.String("o", "", "file to write the problem to (default stdout)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usage(fs, "expected at most one file")
+	}
+	filename := "-"
+	if fs.NArg() == 1 {
+		filename = fs.Arg(0)
+	}
+
+	var f format.Format
+	var err error
+	switch {
+	case *to != "":
+		f, err = format.Parse(*to)
+	case *output != "":
+		f, err = format.FromFilename(*output)
+	default:
+		return usage(fs, "the format to write must be given when writing to stdout")
+	}
+	if err != nil {
+		return usage(fs, "%v", err)
+	}
+	if *from != "" {
+		if g, err := format.Parse(*from); err != nil || !g.Readable() {
+			return usage(fs, "cannot read format %q", *from)
+		}
+	}
+
+	in, err := readInstance(e, filename, *from)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return format.Write(e.stdout, f, in)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := format.Write(file, f, in); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+func init() {
+	commands["demo"] = command{
+		summary: "serve a web page for solving problems in the browser, animating each search step by step",
+		run:     runDemo,
+	}
+}
+
+// demoPage is the page served by gox demo, which solves problems through
+// /solve and offers the puzzles of /puzzles
+//
+//go:embed demo.html
+var demoPage []byte
+
+// demoConfig holds the limits applied by the demo server
+type demoConfig struct {
+	// limit and timeout bound the search for the solutions to a problem,
+	// and events the steps of a search sent to be animated
+	limit   int
+	timeout time.Duration
+	events  int
+}
+
+// demoPuzzle is a bundled problem offered by the demo page
+type demoPuzzle struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Text   string `json:"text"`
+}
+
+// demoPuzzles returns the bundled problems, written as JSON so that the rows
+// keep their names
+func demoPuzzles() ([]demoPuzzle, error) {
+	var puzzles []demoPuzzle
+	for _, p := range []struct {
+		name string
+		gen  string
+		c    generateConfig
+	}{
+		{"Knuth's example of colours", "", generateConfig{}},
+		{"6 queens", "queens", generateConfig{n: 6}},
+		{"Sudoku with 30 clues", "sudoku", generateConfig{clues: 30}},
+		{"Random with a planted solution", "random", generateConfig{columns: 12, rows: 30, density: 0.25, planted: true}},
+	} {
+		in := knuthExample()
+		if p.gen != "" {
+			var err error
+			if in, err = generators[p.gen](rand.New(rand.NewSource(1)), p.c); err != nil {
+				return nil, err
+			}
+		}
+		var b strings.Builder
+		if err := format.WriteJSON(&b, in); err != nil {
+			return nil, err
+		}
+		puzzles = append(puzzles, demoPuzzle{Name: p.name, Format: "json", Text: b.String()})
+	}
+	return puzzles, nil
+}
+
+// knuthExample returns the example of colours given by Knuth in The Art of
+// Computer Programming, Volume 4B
+func knuthExample() *format.Instance {
+	in := &format.Instance{Primary: []string{"p", "q", "r"}, Secondary: []string{"x", "y"}}
+	for _, row := range [][]string{{"p", "q", "x", "y:A"}, {"p", "r", "x:A", "y"}, {"p", "x:B"}, {"q", "x:A"}, {"r", "y:B"}} {
+		in.Rows = append(in.Rows, format.Row{Name: strings.Join(row, " "), Items: row})
+	}
+	return in
+}
+
+// demoMessage is a line of the stream written by /solve: a step of the search
+// with its narrative, see gox.Explainer, a note that the steps were cut
+// short, or the result once the search has finished
+type demoMessage struct {
+	Event     *gox.Event   `json:"event,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Truncated bool         `json:"truncated,omitempty"`
+	Done      *solveResult `json:"done,omitempty"`
+}
+
+// newDemoHandler returns the handler of the demo server:
+//
+//	GET  /          the page
+//	GET  /puzzles   the bundled problems as JSON
+//	POST /solve     solve the problem in the body, in the format given by
+//	                the query parameter "format", streaming JSON lines
+func newDemoHandler(c demoConfig) (http.Handler, error) {
+	puzzles, err := demoPuzzles()
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(demoPage)
+	})
+	mux.HandleFunc("/puzzles", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(puzzles)
+	})
+	mux.HandleFunc("/solve", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Expected POST", http.StatusMethodNotAllowed)
+			return
+		}
+		c.solve(w, r)
+	})
+	return mux, nil
+}
+
+// demoMaxBody is the size of the largest problem accepted by the demo
+const demoMaxBody = 1 << 20
+
+// demoFlush is the number of messages written between flushes of the stream
+const demoFlush = 256
+
+// solve solves the problem posted to /solve, streaming the steps of the search
+// followed by the result
+func (c demoConfig) solve(w http.ResponseWriter, r *http.Request) {
+	f, err := format.Parse(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	in, err := format.Read(http.MaxBytesReader(w, r.Body, demoMaxBody), f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := c.limit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err != nil || n < 1 {
+			http.Error(w, fmt.Sprintf("Invalid limit %q", s), http.StatusBadRequest)
+			return
+		} else if limit <= 0 || n < limit {
+			limit = n
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	flusher, _ := w.(http.Flusher)
+	var x gox.Explainer
+	sent := 0
+	var writeErr error
+	write := func(m demoMessage) {
+		if writeErr == nil {
+			writeErr = enc.Encode(m)
+		}
+		if sent++; sent%demoFlush == 0 && writeErr == nil {
+			if writeErr = bw.Flush(); flusher != nil {
+				flusher.Flush()
+			}
+		}
+		// The browser has gone, so there is no one to show the search to
+		if writeErr != nil {
+			cancel()
+		}
+	}
+	trace := gox.WithTrace(func(e gox.Event) {
+		switch {
+		case c.events > 0 && sent > c.events:
+		case c.events > 0 && sent == c.events:
+			write(demoMessage{Truncated: true})
+		default:
+			write(demoMessage{Event: &e, Text: x.Explain(e)})
+		}
+	})
+	res, err := solveInstance(ctx, in, limit, c.timeout, gox.MinRemaining, trace)
+	if err != nil {
+		res = solveResult{Solutions: [][]string{}, Error: err.Error()}
+	}
+	write(demoMessage{Done: &res})
+	bw.Flush()
+}
+
+func runDemo(e *env, args []string) error {
+	fs := newFlagSet(e, "demo", "")
+	addr := fs.String("http", "localhost:8080", "serve the page on this address")
+	limit := fs.Int("limit", 100, "most solutions found for a problem, 0 for no limit")
+	timeout := fs.Duration("timeout", 10*time.Second, "longest time spent searching for the solutions to a problem, 0 for no limit")
+	events := fs.Int("events", 20000, "most steps of a search sent to the page to be animated, 0 for no limit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usage(fs, "expected no arguments")
+	}
+
+	h, err := newDemoHandler(demoConfig{limit: *limit, timeout: *timeout, events: *events})
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "serving the demo on http://%s/\n", l.Addr())
+	hs := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	if err := hs.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
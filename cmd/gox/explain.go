@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ifross89/gox"
+)
+
+func init() {
+	commands["explain"] = command{
+		summary: "narrate a search in plain English, from a trace recorded by gox solve -trace",
+		run:     runExplain,
+	}
+}
+
+// errExplained stops reading a trace once the solutions asked for have been
+// explained
+var errExplained = errors.New("explained")
+
+func runExplain(e *env, args []string) error {
+	fs := newFlagSet(e, "explain", "[trace]")
+	solutions := fs.Int("solutions", 1, "stop after explaining this many solutions, 0 to explain the whole search")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usage(fs, "expected at most one trace")
+	}
+	if *solutions < 0 {
+		return usage(fs, "solutions must not be negative")
+	}
+
+	r := e.stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	var x gox.Explainer
+	found := 0
+	err := readTrace(r, func(ev gox.Event) error {
+		if s := x.Explain(ev); s != "" {
+			fmt.Fprintln(e.stdout, s)
+		}
+		if ev.Kind == gox.FoundSolution {
+			if found++; found == *solutions {
+				return errExplained
+			}
+		}
+		return nil
+	})
+	if err == errExplained {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/ifross89/gox/format"
+	"github.com/ifross89/gox/queens"
+	"github.com/ifross89/gox/sudoku"
+	"github.com/ifross89/gox/x3c"
+)
+
+func init() {
+	commands["generate"] = command{
+		summary: "write a random instance of a kind of problem: random, x3c, sudoku or queens",
+		run:     runGenerate,
+	}
+}
+
+// generateConfig holds the flags of gox generate, each kind of problem uses
+// some of them
+type generateConfig struct {
+	columns, rows int
+	density       float64
+	planted       bool
+	clues         int
+	n             int
+}
+
+// generators create an instance of each kind of problem accepted by gox
+// generate
+var generators = map[string]func(rng *rand.Rand, c generateConfig) (*format.Instance, error){
+	"random": generateRandom,
+	"x3c":    generateX3C,
+	"sudoku": generateSudoku,
+	"queens": generateQueens,
+}
+
+// generateRandom creates a problem in which each row covers each column with
+// probability c.density. If c.planted is set, some of the rows partition the
+// columns so that the problem has a solution.
+func generateRandom(rng *rand.Rand, c generateConfig) (*format.Instance, error) {
+	if c.columns < 1 || c.rows < 0 {
+		return nil, fmt.Errorf("Problem must have at least one column and no negative number of rows: columns=%d rows=%d", c.columns, c.rows)
+	}
+	if c.density <= 0 || c.density > 1 {
+		return nil, fmt.Errorf("Density must be greater than 0 and at most 1: %v", c.density)
+	}
+
+	in := &format.Instance{}
+	for i := 0; i < c.columns; i++ {
+		in.Primary = append(in.Primary, fmt.Sprintf("c%d", i+1))
+	}
+
+	var rows [][]string
+	if c.planted {
+		// The columns are shuffled then cut into rows which cover the
+		// same number of columns on average as the random rows
+		size := c.density * float64(c.columns)
+		var row []string
+		for _, i := range rng.Perm(c.columns) {
+			if len(row) > 0 && rng.Float64()*size < 1 {
+				rows = append(rows, row)
+				row = nil
+			}
+			row = append(row, in.Primary[i])
+		}
+		rows = append(rows, row)
+	}
+	for len(rows) < c.rows {
+		var row []string
+		for _, col := range in.Primary {
+			if rng.Float64() < c.density {
+				row = append(row, col)
+			}
+		}
+		// Rows which cover no columns are never part of a solution
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	in.Rows = make([]format.Row, len(rows))
+	for i, j := range rng.Perm(len(rows)) {
+		in.Rows[j] = format.Row{Name: fmt.Sprintf("R%d", j+1), Items: rows[i]}
+	}
+	return in, nil
+}
+
+// generateX3C creates an instance of exact cover by 3-sets with c.columns
+// elements and c.rows triples
+func generateX3C(rng *rand.Rand, c generateConfig) (*format.Instance, error) {
+	inst, err := x3c.Generate(rng, x3c.Config{Elements: c.columns, Triples: c.rows, Planted: c.planted})
+	if err != nil {
+		return nil, err
+	}
+
+	in := &format.Instance{}
+	for i := 0; i < inst.Elements; i++ {
+		in.Primary = append(in.Primary, fmt.Sprint(i))
+	}
+	for i, t := range inst.Triples {
+		in.Rows = append(in.Rows, format.Row{
+			Name:  inst.Name(i),
+			Items: []string{fmt.Sprint(t[0]), fmt.Sprint(t[1]), fmt.Sprint(t[2])},
+		})
+	}
+	return in, nil
+}
+
+// generateSudoku creates a sudoku with c.clues clues and a unique solution.
+// The columns and rows are those of sudoku.Problem, leaving out the rows for
+// the digits which are not given in the cells with clues.
+func generateSudoku(rng *rand.Rand, c generateConfig) (*format.Instance, error) {
+	g, err := sudoku.Generate(rng, c.clues)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &format.Instance{}
+	for i := 1; i <= sudoku.Size; i++ {
+		for j := 1; j <= sudoku.Size; j++ {
+			in.Primary = append(in.Primary,
+				fmt.Sprintf("r%dc%d", i, j),
+				fmt.Sprintf("r%d#%d", i, j),
+				fmt.Sprintf("c%d#%d", i, j),
+				fmt.Sprintf("b%d#%d", i, j),
+			)
+		}
+	}
+	for r := 0; r < sudoku.Size; r++ {
+		for c := 0; c < sudoku.Size; c++ {
+			for d := 1; d <= sudoku.Size; d++ {
+				if g[r][c] != 0 && g[r][c] != d {
+					continue
+				}
+				b := r/sudoku.BoxSize*sudoku.BoxSize + c/sudoku.BoxSize
+				in.Rows = append(in.Rows, format.Row{
+					Name: sudoku.RowName(r, c, d),
+					Items: []string{
+						fmt.Sprintf("r%dc%d", r+1, c+1),
+						fmt.Sprintf("r%d#%d", r+1, d),
+						fmt.Sprintf("c%d#%d", c+1, d),
+						fmt.Sprintf("b%d#%d", b+1, d),
+					},
+				})
+			}
+		}
+	}
+	return in, nil
+}
+
+// generateQueens creates the problem placing c.n queens, with the columns and
+// rows of queens.Problem. There is nothing random about the problem.
+func generateQueens(rng *rand.Rand, c generateConfig) (*format.Instance, error) {
+	if c.n < 1 {
+		return nil, fmt.Errorf("Board must have at least one square: n=%d", c.n)
+	}
+
+	in := &format.Instance{}
+	for i := 0; i < c.n; i++ {
+		in.Primary = append(in.Primary, fmt.Sprintf("r%d", i), fmt.Sprintf("f%d", i))
+	}
+	for i := 0; i < 2*c.n-1; i++ {
+		in.Secondary = append(in.Secondary, fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i))
+	}
+	for r := 0; r < c.n; r++ {
+		for f := 0; f < c.n; f++ {
+			in.Rows = append(in.Rows, format.Row{
+				Name: queens.RowName(r, f),
+				Items: []string{
+					fmt.Sprintf("r%d", r),
+					fmt.Sprintf("f%d", f),
+					fmt.Sprintf("a%d", r+f),
+					fmt.Sprintf("b%d", r-f+c.n-1),
+				},
+			})
+		}
+	}
+	return in, nil
+}
+
+func runGenerate(e *env, args []string) error {
+	fs := newFlagSet(e, "generate", "random|x3c|sudoku|queens")
+	var c generateConfig
+	fs.IntVar(&c.columns, "columns", 30, "number of columns, or elements for x3c")
+	fs.IntVar(&c.rows, "rows", 60, "number of rows, or triples for x3c")
+	fs.Float64Var(&c.density, "density", 0.1, "probability of each row of a random problem covering each column")
+	fs.BoolVar(&c.planted, "planted", false, "plant a solution in a random or x3c problem")
+	fs.IntVar(&c.clues, "clues", 30, "number of clues of a sudoku")
+	fs.IntVar(&c.n, "n", 8, "number of queens")
+	seed := fs.Int64("seed", 0, "seed of the random numbers, 0 for a seed from the time")
+	formatName := fs.String("format", "", "format to write: csv, json or dlx (default from the output file, or dlx)")
+	output := fs.String("o", "", "file to write the problem to (default stdout)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return usage(fs, "expected the kind of problem to generate")
+	}
+	generate, ok := generators[fs.Arg(0)]
+	if !ok {
+		return usage(fs, "unknown kind of problem %q", fs.Arg(0))
+	}
+
+	f := format.DLX
+	var err error
+	if *formatName != "" {
+		f, err = format.Parse(*formatName)
+	} else if *output != "" {
+		f, err = format.FromFilename(*output)
+	}
+	if err != nil {
+		return usage(fs, "%v", err)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	in, err := generate(rand.New(rand.NewSource(*seed)), c)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return format.Write(e.stdout, f, in)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := format.Write(file, f, in); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
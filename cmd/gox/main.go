@@ -0,0 +1,135 @@
+// Command gox solves exact cover problems from the command line.
+//
+// Usage:
+//
+//	gox <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	batch     solve every problem in a directory, writing JSON lines
+//	browse    browse the solutions to a problem, filtering and comparing them
+//	bench     solve the bundled classic instances, comparing heuristics
+//	compare   compare solutions written in the layout of Knuth's dlx1 and xcc
+//	convert   translate a problem between formats, or export it as CNF, LP or CP-SAT
+//	demo      serve a web page which solves problems and animates the search
+//	explain   narrate a recorded search in plain English
+//	generate  write a random instance of a kind of problem
+//	repl      load a problem and explore it interactively
+//	serve     solve problems sent as JSON-RPC over stdin and stdout, or over HTTP
+//	solve     find the solutions to a problem read from a file
+//	tracediff compare the trees explored by two recorded searches
+//	tree      export the tree explored by a recorded search as GraphML or JSON
+//	validate  check solutions against a problem
+//	visualize animate a search in the terminal
+//
+// Problems are read in any of the formats supported by the format package. Run
+// gox <command> -h for the flags accepted by a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// env holds the standard streams used by a command, so that commands can be
+// run by the tests
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// command is a subcommand of gox. run is given the arguments following the
+// name of the command.
+type command struct {
+	summary string
+	run     func(e *env, args []string) error
+}
+
+// commands are the subcommands of gox, by name
+var commands = map[string]command{}
+
+// errUsage is returned by a command which was given invalid flags or
+// arguments, once the usage of the command has been printed
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(&env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}, os.Args[1:]))
+}
+
+// run runs the command named by the first argument, returning the exit status
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		printUsage(e.stderr)
+		return 2
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printUsage(e.stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "gox: unknown command %q\n", args[0])
+		printUsage(e.stderr)
+		return 2
+	}
+
+	switch err := cmd.run(e, args[1:]); err {
+	case nil, flag.ErrHelp:
+		return 0
+	case errUsage:
+		return 2
+	default:
+		fmt.Fprintf(e.stderr, "gox %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+// newFlagSet creates the flag set for a command, args describes the arguments
+// taken after the flags
+func newFlagSet(e *env, name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet("gox "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gox %s [flags] %s\n\n%s\n\nflags:\n", name, args, commands[name].summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the arguments of a command, returning errUsage if they
+// are invalid as the flag package has already reported the error
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// usage reports a problem with the arguments of a command and prints its
+// usage
+func usage(fs *flag.FlagSet, format string, a ...interface{}) error {
+	fmt.Fprintf(fs.Output(), "%s: %s\n", fs.Name(), fmt.Sprintf(format, a...))
+	fs.Usage()
+	return errUsage
+}
+
+// printUsage prints the commands available
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "usage: gox <command> [flags] [arguments]\n\ncommands:\n")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
@@ -0,0 +1,649 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+// runCommand runs gox with the arguments given, returning the exit status and
+// the output written to stdout and stderr
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	e := &env{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	status := run(e, args)
+	return status, stdout.String(), stderr.String()
+}
+
+// writeFile writes a file to a temporary directory, returning its path
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Error writing %s: %v", path, err)
+	}
+	return path
+}
+
+func TestUsage(t *testing.T) {
+	if status, _, _ := runCommand(""); status != 2 {
+		t.Fatalf("Expected status 2 without a command, got %d", status)
+	}
+	if status, _, stderr := runCommand("", "frobnicate"); status != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Fatalf("Expected unknown command, got %d: %s", status, stderr)
+	}
+	if status, stdout, _ := runCommand("", "help"); status != 0 || !strings.Contains(stdout, "solve") {
+		t.Fatalf("Expected help listing solve, got %d: %s", status, stdout)
+	}
+}
+
+func TestSolveText(t *testing.T) {
+	path := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	status, stdout, stderr := runCommand("", "solve", path)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 4 || lines[3] != "1 solutions" {
+		t.Fatalf("Unexpected output:\n%s", stdout)
+	}
+}
+
+func TestSolveJSON(t *testing.T) {
+	status, stdout, stderr := runCommand(testutil.Knuth.DLX(), "solve", "-format", "dlx", "-output", "json", "-heuristic", "first")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	if !strings.Contains(stdout, `"count":1,"complete":true`) {
+		t.Fatalf("Unexpected output: %s", stdout)
+	}
+}
+
+func TestSolveMerge(t *testing.T) {
+	// B and D duplicate A and C, so the single solution found stands for 4
+	path := writeFile(t, "dups.json", `{"primary": ["a", "b", "c"], "rows": [
+		{"name": "A", "items": ["a"]}, {"name": "B", "items": ["a"]},
+		{"name": "C", "items": ["b", "c"]}, {"name": "D", "items": ["c", "b"]}]}`)
+	status, stdout, stderr := runCommand("", "solve", "-merge", path)
+	if status != 0 || stdout != "A\nC\n(stands for 4 solutions)\n\n1 solutions\n" {
+		t.Fatalf("Unexpected output, status %d: %s\n%s", status, stderr, stdout)
+	}
+	status, stdout, _ = runCommand("", "solve", "-merge", "-output", "json", path)
+	if status != 0 || !strings.Contains(stdout, `"multiplicity":[4]`) {
+		t.Fatalf("Expected multiplicity in JSON, got %d: %s", status, stdout)
+	}
+	if status, _, _ := runCommand("", "solve", "-merge", "-output", "jsonl", path); status != 2 {
+		t.Fatalf("Expected status 2 merging with jsonl output, got %d", status)
+	}
+}
+
+func TestSolveJSONL(t *testing.T) {
+	status, stdout, stderr := runCommand(testutil.Knuth.DLX(), "solve", "-format", "dlx", "-output", "jsonl")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"index":1,"rows":["q x:A","p r x:A y"]`) {
+		t.Fatalf("Unexpected output: %s", stdout)
+	}
+}
+
+func TestSolveHTML(t *testing.T) {
+	status, stdout, stderr := runCommand(testutil.Knuth.DLX(), "solve", "-format", "dlx", "-output", "html")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	for _, want := range []string{"<title>stdin</title>", "<td>q x:A p r x:A y</td>", "Showing 1 of 1"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("Expected output to contain %q: %s", want, stdout)
+		}
+	}
+}
+
+func TestSolveLimit(t *testing.T) {
+	csv := "row,a,b\nA,1,0\nB,0,1\nC,1,0\nD,0,1\n"
+	status, stdout, stderr := runCommand(csv, "solve", "-format", "csv", "-limit", "3", "-output", "json")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	if !strings.Contains(stdout, `"count":3,"complete":false`) {
+		t.Fatalf("Unexpected output: %s", stdout)
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	if status, _, _ := runCommand(testutil.Knuth.DLX(), "solve"); status != 1 {
+		t.Fatalf("Expected status 1 reading stdin without a format, got %d", status)
+	}
+	if status, _, _ := runCommand(testutil.Knuth.DLX(), "solve", "-output", "xml", "-format", "dlx"); status != 2 {
+		t.Fatalf("Expected status 2 for unknown output, got %d", status)
+	}
+	if status, _, _ := runCommand("", "solve", "-nonsense"); status != 2 {
+		t.Fatalf("Expected status 2 for unknown flag, got %d", status)
+	}
+	if status, _, stderr := runCommand("a\nb\n", "solve", "-format", "dlx"); status != 1 || !strings.Contains(stderr, "unknown column") {
+		t.Fatalf("Expected error for unknown column, got %d: %s", status, stderr)
+	}
+}
+
+func TestBench(t *testing.T) {
+	status, stdout, stderr := runCommand("", "bench", "-instances", "queens-8,langford-8", "-heuristics", "mrv,first")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected header and 4 results, got:\n%s", stdout)
+	}
+	for _, line := range lines[1:3] {
+		if f := strings.Fields(line); f[0] != "queens-8" || f[2] != "92" {
+			t.Fatalf("Expected 92 solutions for queens-8, got: %s", line)
+		}
+	}
+
+	if status, stdout, _ := runCommand("", "bench", "-list"); status != 0 || !strings.Contains(stdout, "sudoku-batch") {
+		t.Fatalf("Expected list of instances, got %d: %s", status, stdout)
+	}
+	if status, _, _ := runCommand("", "bench", "-instances", "chess"); status != 2 {
+		t.Fatalf("Expected status 2 for unknown instance, got %d", status)
+	}
+	if status, _, _ := runCommand("", "bench", "-heuristics", "random"); status != 2 {
+		t.Fatalf("Expected status 2 for unknown heuristic, got %d", status)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, c := range []struct {
+		args      []string
+		solutions string
+	}{
+		{[]string{"-n", "6", "queens"}, "4 solutions"},
+		{[]string{"-clues", "30", "-seed", "1", "sudoku"}, "1 solutions"},
+		{[]string{"-columns", "30", "-rows", "10", "-planted", "-seed", "1", "x3c"}, "1 solutions"},
+	} {
+		status, stdout, stderr := runCommand("", append([]string{"generate", "-format", "json"}, c.args...)...)
+		if status != 0 {
+			t.Fatalf("Expected status 0 generating %v, got %d: %s", c.args, status, stderr)
+		}
+		status, stdout, stderr = runCommand(stdout, "solve", "-format", "json")
+		if status != 0 || !strings.HasSuffix(stdout, c.solutions+"\n") {
+			t.Fatalf("Expected %s for %v, got %d: %s%s", c.solutions, c.args, status, stdout, stderr)
+		}
+	}
+
+	// A planted random problem always has a solution
+	path := filepath.Join(t.TempDir(), "random.csv")
+	status, _, stderr := runCommand("", "generate", "-o", path, "-planted", "-seed", "2", "random")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	status, stdout, stderr := runCommand("", "solve", "-limit", "1", path)
+	if status != 0 || strings.HasSuffix(stdout, "0 solutions\n") {
+		t.Fatalf("Expected a solution to planted problem, got %d: %s%s", status, stdout, stderr)
+	}
+
+	if status, _, _ := runCommand("", "generate", "chess"); status != 2 {
+		t.Fatalf("Expected status 2 for unknown kind, got %d", status)
+	}
+	if status, _, _ := runCommand("", "generate", "-density", "2", "random"); status != 1 {
+		t.Fatalf("Expected status 1 for invalid density, got %d", status)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"a.dlx":     testutil.Knuth.DLX(),
+		"b.csv":     "row,a,b\nA,1,0\nB,0,1\n",
+		"c.dlx":     "a\nb\n",
+		"notes.txt": "not a problem",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Error writing %s: %v", name, err)
+		}
+	}
+
+	status, stdout, stderr := runCommand("", "batch", "-timeout", "1s", dir)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a line for each problem, got:\n%s", stdout)
+	}
+	for i, expected := range []string{
+		`{"file":"a.dlx","solutions":[["q x:A","p r x:A y"]],"count":1,"complete":true,`,
+		`{"file":"b.csv","solutions":[["A","B"]],"count":1,"complete":true,`,
+		`{"file":"c.dlx","solutions":[],"count":0,"complete":false,"error":"`,
+	} {
+		if !strings.HasPrefix(lines[i], expected) {
+			t.Fatalf("Expected line %d to start with %s, got %s", i, expected, lines[i])
+		}
+	}
+
+	if status, _, _ := runCommand("", "batch"); status != 2 {
+		t.Fatalf("Expected status 2 without a directory, got %d", status)
+	}
+}
+
+func TestBatchWatch(t *testing.T) {
+	dir := t.TempDir()
+	r, w := io.Pipe()
+	b := &batch{dir: dir, enc: json.NewEncoder(w), done: make(map[string]bool)}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- b.run(ctx, time.Millisecond) }()
+
+	if err := os.WriteFile(filepath.Join(dir, "a.dlx"), []byte(testutil.Knuth.DLX()), 0644); err != nil {
+		t.Fatalf("Error writing problem: %v", err)
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Error reading result: %v", err)
+	}
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatalf("Error watching directory: %v", err)
+	}
+	if !strings.HasPrefix(line, `{"file":"a.dlx"`) {
+		t.Fatalf("Expected result for new file, got: %s", line)
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	path := writeFile(t, "dominoes.csv", "row,a,b,c,d\nab,1,1,0,0\nbc,0,1,1,0\ncd,0,0,1,1\nad,1,0,0,1\na,1,0,0,0\n")
+	script := strings.Join([]string{
+		"list",
+		"filter bc",
+		"list",
+		"filter zz",
+		"clear",
+		"coverage 1",
+		"diff 1 2",
+		"show 3",
+		"quit",
+		"list",
+	}, "\n")
+	status, stdout, stderr := runCommand(script, "browse", "-quiet", path)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	for _, expected := range []string{
+		"found 2 solutions",
+		"1: ab, cd\n2: bc, ad\n1 to 2 of 2 solutions\n",
+		"2: bc, ad\n1 to 1 of 1 solutions including bc\n",
+		"error: No row found with name zz\n",
+		"a: ab\nb: ab\nc: cd\nd: cd\n",
+		"- ab | + bc\n- cd | + ad\n0 shared, distance 4, Jaccard distance 1.00\n",
+		"error: Expected a solution from 1 to 2, got \"3\"\n",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("Expected output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+	if !strings.HasSuffix(stdout, "got \"3\"\n") {
+		t.Fatalf("Expected commands after quit to be ignored, got:\n%s", stdout)
+	}
+}
+
+func TestREPL(t *testing.T) {
+	path := writeFile(t, "dominoes.csv", "row,a,b,c,d\nab,1,1,0,0\nbc,0,1,1,0\ncd,0,0,1,1\nad,1,0,0,1\na,1,0,0,0\n")
+	script := strings.Join([]string{
+		"solve",
+		"candidates a",
+		"give ab",
+		"candidates",
+		"forbid cd",
+		"give bc",
+		"solve 5",
+		"stats",
+		"reset",
+		"heuristic first",
+		"solve 1",
+		"frobnicate",
+		"quit",
+		"solve",
+	}, "\n")
+	status, stdout, stderr := runCommand(script, "repl", "-quiet", path)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	for _, expected := range []string{
+		"ab\ncd\n\nbc\nad\n\n2 solutions\n",
+		"ab\nad\na\n3 candidates\n",
+		"cd\n1 candidates\n",
+		"error: Row bc conflicts with given row ab\n",
+		"0 solutions (Columns c, d have no candidate rows)\nsolutions 0\n",
+		"heuristic first\n",
+		"1 solutions (stopped at limit)\n",
+		"error: Unknown command \"frobnicate\"",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("Expected output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+	if !strings.HasSuffix(stdout, "type help for the commands\n") {
+		t.Fatalf("Expected commands after quit to be ignored, got:\n%s", stdout)
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	problem := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	trace := filepath.Join(t.TempDir(), "trace.jsonl")
+	if status, _, stderr := runCommand("", "solve", "-trace", trace, problem); status != 0 {
+		t.Fatalf("Expected status 0 recording trace, got %d: %s", status, stderr)
+	}
+
+	status, replayed, stderr := runCommand("", "visualize", "-replay", "-plain", "-delay", "0", "-width", "6", trace)
+	if status != 0 {
+		t.Fatalf("Expected status 0 replaying trace, got %d: %s", status, stderr)
+	}
+	if !strings.HasPrefix(replayed, "step 1  depth 0  solutions 0\ncolumns [######] 3/3\ncolumn  q (2 rows)\n") {
+		t.Fatalf("Unexpected first frame:\n%s", replayed)
+	}
+	if !strings.Contains(replayed, "columns [......] 0/3\ncolumn  p (1 rows)\nlast    solution found\npartial solution:\n  q x:A\n  p r x:A y\n") {
+		t.Fatalf("Expected frame showing the solution, got:\n%s", replayed)
+	}
+
+	// Solving live draws the same frames as replaying the trace
+	status, live, stderr := runCommand("", "visualize", "-plain", "-delay", "0", "-width", "6", "-limit", "0", problem)
+	if status != 0 || live != replayed {
+		t.Fatalf("Expected live frames to match replay, got %d: %s\n%s", status, stderr, live)
+	}
+
+	if status, _, _ := runCommand("{", "visualize", "-replay", "-"); status != 1 {
+		t.Fatalf("Expected status 1 for invalid trace, got %d", status)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	problem := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	trace := filepath.Join(t.TempDir(), "trace.jsonl")
+	if status, _, stderr := runCommand("", "solve", "-trace", trace, problem); status != 0 {
+		t.Fatalf("Expected status 0 recording trace, got %d: %s", status, stderr)
+	}
+
+	status, stdout, stderr := runCommand("", "explain", trace)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	if !strings.HasPrefix(stdout, "Column 'q' has 2 candidates, trying row p q x y:A (1 of 2).\n") {
+		t.Fatalf("Unexpected first step:\n%s", stdout)
+	}
+	if !strings.HasSuffix(stdout, "Every column is covered: solution 1 is q x:A, p r x:A y.\n") {
+		t.Fatalf("Expected the narrative to stop at the first solution, got:\n%s", stdout)
+	}
+
+	if status, _, _ := runCommand("", "explain", "-solutions", "-1", trace); status != 2 {
+		t.Fatalf("Expected status 2 for negative solutions, got %d", status)
+	}
+}
+
+func TestTree(t *testing.T) {
+	problem := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	trace := filepath.Join(t.TempDir(), "trace.jsonl")
+	if status, _, stderr := runCommand("", "solve", "-trace", trace, problem); status != 0 {
+		t.Fatalf("Expected status 0 recording trace, got %d: %s", status, stderr)
+	}
+
+	status, stdout, stderr := runCommand("", "tree", "-output", "json", trace)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	var nodes []gox.TreeNode
+	if err := json.Unmarshal([]byte(stdout), &nodes); err != nil {
+		t.Fatalf("Error decoding tree: %v\n%s", err, stdout)
+	}
+	if len(nodes) != 4 || nodes[0].Column != "q" || nodes[0].Nodes != 4 || nodes[0].Solutions != 1 {
+		t.Fatalf("Unexpected tree %+v", nodes)
+	}
+
+	status, stdout, _ = runCommand("", "tree", trace)
+	if status != 0 || !strings.Contains(stdout, `<edge source="n2" target="n3">`) {
+		t.Fatalf("Expected GraphML with an edge from n2 to n3, got %d:\n%s", status, stdout)
+	}
+	if status, _, _ := runCommand("", "tree", "-output", "dot", trace); status != 2 {
+		t.Fatalf("Expected status 2 for unknown output, got %d", status)
+	}
+}
+
+func TestTraceDiff(t *testing.T) {
+	problem := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	dir := t.TempDir()
+	first, mrv := filepath.Join(dir, "first.jsonl"), filepath.Join(dir, "mrv.jsonl")
+	for _, c := range []struct{ heuristic, trace string }{{"first", first}, {"mrv", mrv}} {
+		if status, _, stderr := runCommand("", "solve", "-heuristic", c.heuristic, "-trace", c.trace, problem); status != 0 {
+			t.Fatalf("Expected status 0 recording trace, got %d: %s", status, stderr)
+		}
+	}
+
+	status, stdout, stderr := runCommand("", "tracediff", first, mrv)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	for _, expected := range []string{
+		"nodes      5  4  -1\n",
+		"solutions  1  1  +0\n",
+		"1 divergences\n",
+		"(root)  p (3 rows)  q (2 rows)  5        4        -1\n",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("Expected output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+
+	status, stdout, _ = runCommand("", "tracediff", mrv, mrv)
+	if status != 0 || !strings.Contains(stdout, "the searches explored the same tree") {
+		t.Fatalf("Expected the same tree, got %d:\n%s", status, stdout)
+	}
+	if status, _, _ := runCommand("", "tracediff", mrv); status != 2 {
+		t.Fatalf("Expected status 2 for a single trace, got %d", status)
+	}
+}
+
+func TestDemo(t *testing.T) {
+	h, err := newDemoHandler(demoConfig{limit: 10, events: 5})
+	if err != nil {
+		t.Fatalf("Error creating handler: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("Error fetching page: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), "<textarea") {
+		t.Fatalf("Expected the page, got %d:\n%s", resp.StatusCode, page)
+	}
+
+	// Each bundled puzzle can be solved
+	resp, err = http.Get(srv.URL + "/puzzles")
+	if err != nil {
+		t.Fatalf("Error fetching puzzles: %v", err)
+	}
+	var puzzles []demoPuzzle
+	err = json.NewDecoder(resp.Body).Decode(&puzzles)
+	resp.Body.Close()
+	if err != nil || len(puzzles) == 0 {
+		t.Fatalf("Expected puzzles, got %v: %v", puzzles, err)
+	}
+	for _, p := range puzzles {
+		in, err := format.ReadJSON(strings.NewReader(p.Text))
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", p.Name, err)
+		}
+		if res, err := solveInstance(context.Background(), in, 1, 0, gox.MinRemaining); err != nil || res.Count != 1 {
+			t.Fatalf("Expected a solution to %s, got %+v: %v", p.Name, res, err)
+		}
+	}
+
+	// The steps of the search are streamed up to the limit, then the result
+	resp, err = http.Post(srv.URL+"/solve?format=dlx", "text/plain", strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	var msgs []demoMessage
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var m demoMessage
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Error decoding stream: %v", err)
+		}
+		msgs = append(msgs, m)
+	}
+	resp.Body.Close()
+	if len(msgs) != 7 || msgs[0].Event == nil || msgs[0].Event.Column != "q" || msgs[0].Text != "" || !msgs[5].Truncated {
+		t.Fatalf("Unexpected stream %+v", msgs)
+	}
+	if !strings.HasPrefix(msgs[1].Text, "Column 'q' has 2 candidates") {
+		t.Fatalf("Expected narrative of the first row tried, got %+v", msgs[1])
+	}
+	if done := msgs[6].Done; done == nil || done.Count != 1 || !done.Complete || !reflect.DeepEqual(done.Solutions, [][]string{{"q x:A", "p r x:A y"}}) {
+		t.Fatalf("Unexpected result %+v", msgs[6])
+	}
+
+	for _, c := range []struct {
+		method, url string
+		status      int
+	}{
+		{"GET", "/solve", http.StatusMethodNotAllowed},
+		{"POST", "/solve?format=yaml", http.StatusBadRequest},
+		{"POST", "/solve?format=dlx&limit=0", http.StatusBadRequest},
+		{"GET", "/nowhere", http.StatusNotFound},
+	} {
+		req, _ := http.NewRequest(c.method, srv.URL+c.url, strings.NewReader(testutil.Knuth.DLX()))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error requesting %s: %v", c.url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Fatalf("Expected status %d for %s %s, got %d", c.status, c.method, c.url, resp.StatusCode)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	problem := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	_, output, _ := runCommand("", "solve", "-output", "json", problem)
+	_, text, _ := runCommand("", "solve", problem)
+	for _, c := range []struct {
+		name, solutions string
+	}{
+		{"solve.json", output},
+		{"solve.txt", text},
+		{"array.json", `[["q x:A", "p r x:A y"]]`},
+		{"single.json", `["q x:A", "p r x:A y"]`},
+	} {
+		status, stdout, stderr := runCommand("", "validate", problem, writeFile(t, c.name, c.solutions))
+		if status != 0 || stdout != "1 solutions are valid\n" {
+			t.Fatalf("Expected %s to be valid, got %d: %s%s", c.name, status, stdout, stderr)
+		}
+	}
+
+	invalid := "p q x y:A\n\nq x:A\np r x:A y\n\np x:B\nq x:A\nr y:B\n"
+	status, stdout, stderr := runCommand(invalid, "validate", "-v", "-solutions", "text", problem, "-")
+	if status != 1 || !strings.Contains(stderr, "2 of 3 solutions are invalid") {
+		t.Fatalf("Expected status 1 for invalid solutions, got %d: %s", status, stderr)
+	}
+	for _, expected := range []string{
+		"solution 1: column r is not covered\n",
+		"solution 2: ok\n",
+		"solution 3: column x is covered by more than one row: p x:B, q x:A\n",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("Expected output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+
+	if status, _, _ := runCommand("", "validate", problem); status != 2 {
+		t.Fatalf("Expected status 2 without solutions, got %d", status)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	path := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	out := filepath.Join(t.TempDir(), "knuth.json")
+	if status, _, stderr := runCommand("", "convert", "-o", out, path); status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	status, stdout, stderr := runCommand("", "solve", out)
+	if status != 0 || !strings.Contains(stdout, "1 solutions") {
+		t.Fatalf("Expected 1 solution to the converted problem, got %d: %s%s", status, stdout, stderr)
+	}
+
+	status, stdout, stderr = runCommand(testutil.Knuth.DLX(), "convert", "-from", "dlx", "-to", "cnf")
+	if status != 0 || !strings.Contains(stdout, "p cnf 5 ") {
+		t.Fatalf("Expected CNF, got %d: %s%s", status, stdout, stderr)
+	}
+
+	for _, args := range [][]string{
+		{"convert", path},
+		{"convert", "-to", "xml", path},
+		{"convert", "-from", "lp", "-to", "dlx", path},
+	} {
+		if status, _, _ := runCommand("", args...); status != 2 {
+			t.Fatalf("Expected status 2 for %q, got %d", args, status)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	data, _ := json.Marshal(testutil.Knuth.DLX())
+	stdin := `{"jsonrpc": "2.0", "id": 1, "method": "load", "params": {"format": "dlx", "data": ` + string(data) + `}}
+{"jsonrpc": "2.0", "id": 2, "method": "solve", "params": {"problem": "1"}}
+`
+	status, stdout, stderr := runCommand(stdin, "serve", "-stdio")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"id":2,"result":{"solutions":[[`) {
+		t.Fatalf("Expected responses to load and solve, got %s", stdout)
+	}
+
+	for _, args := range [][]string{
+		{"serve"},
+		{"serve", "-stdio", "-http", ":0"},
+		{"serve", "-stdio", "x"},
+	} {
+		if status, _, _ := runCommand("", args...); status != 2 {
+			t.Fatalf("Expected status 2 for %q, got %d", args, status)
+		}
+	}
+}
+
+func TestSolveKnuth(t *testing.T) {
+	path := writeFile(t, "knuth.dlx", testutil.Knuth.DLX())
+	status, stdout, stderr := runCommand("", "solve", "-output", "knuth", path)
+	if status != 0 || stdout != "1:\n q x:A (2 of 2)\n p r x:A y (1 of 1)\n" || !strings.HasPrefix(stderr, "Altogether 1 solution,") {
+		t.Fatalf("Expected the layout of xcc, got %d: %s%s", status, stdout, stderr)
+	}
+
+	out := writeFile(t, "gox.txt", stdout)
+	reference := "1:\n p x:A y r (1 of 1)\n q x:A (2 of 2)\nAltogether 1 solution, 99+99 mems.\n"
+	if status, stdout, stderr := runCommand(reference, "compare", "-", out); status != 0 {
+		t.Fatalf("Expected the solutions to be the same, got %d: %s%s", status, stdout, stderr)
+	}
+	if status, _, stderr := runCommand("Altogether 2 solutions.\n", "compare", "-", out); status != 1 || !strings.Contains(stderr, "Expected 0 solutions to be written, got 1") {
+		t.Fatalf("Expected the solutions to differ, got %d: %s", status, stderr)
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+func init() {
+	commands["repl"] = command{
+		summary: "load a problem and explore it interactively, type help for the commands",
+		run:     runREPL,
+	}
+}
+
+// replHelp describes the commands of gox repl
+const replHelp = `commands:
+  load <file> [format]   load a problem, replacing the current one
+  give <row>             make a row part of every solution
+  forbid <row>           leave a row out of every solution
+  reset                  remove the givens and forbidden rows
+  candidates [column]    list the rows which may still be chosen, or only
+                         those covering a column
+  solve [limit]          find the solutions, or at most limit of them
+  heuristic [mrv|first]  show or set the column choice heuristic
+  timeout [duration]     show or set the time allowed for solve, 0 for none
+  stats                  show the work done by the last solve
+  show                   show the problem, givens and forbidden rows
+  help                   show this message
+  quit                   leave the shell
+`
+
+// session is the state of gox repl: a problem and the choices made about
+// its rows
+type session struct {
+	in *format.Instance
+	// rows maps the name of each row to its index in in.Rows
+	rows      map[string]int
+	givens    []string
+	forbidden map[string]bool
+	heuristic gox.Heuristic
+	timeout   time.Duration
+	// stats and elapsed describe the last solve
+	stats   *gox.Stats
+	elapsed time.Duration
+}
+
+// load replaces the problem of the session
+func (s *session) load(in *format.Instance) error {
+	rows := make(map[string]int)
+	for i, r := range in.Rows {
+		if _, ok := rows[r.Name]; ok {
+			return fmt.Errorf("Duplicate row name: %s", r.Name)
+		}
+		rows[r.Name] = i
+	}
+	s.in, s.rows, s.stats = in, rows, nil
+	s.reset()
+	return nil
+}
+
+// reset removes the givens and forbidden rows
+func (s *session) reset() {
+	s.givens = nil
+	s.forbidden = make(map[string]bool)
+}
+
+// row returns the index of the row with the given name
+func (s *session) row(name string) (int, error) {
+	if s.in == nil {
+		return 0, fmt.Errorf("No problem loaded")
+	}
+	i, ok := s.rows[name]
+	if !ok {
+		return 0, fmt.Errorf("Unknown row: %s", name)
+	}
+	return i, nil
+}
+
+// conflict reports whether two rows cannot both be in a solution, because
+// they cover the same primary column or give a secondary column different
+// colours
+func (s *session) conflict(a, b int) bool {
+	colours := make(map[string]string)
+	for _, item := range s.in.Rows[a].Items {
+		col, colour := splitItem(item)
+		colours[col] = colour
+	}
+	for _, item := range s.in.Rows[b].Items {
+		col, colour := splitItem(item)
+		if other, ok := colours[col]; ok && (colour == "" || colour != other) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitItem splits an item of a row into its column and colour
+func splitItem(item string) (col, colour string) {
+	if i := strings.Index(item, ":"); i >= 0 {
+		return item[:i], item[i+1:]
+	}
+	return item, ""
+}
+
+// give makes a row part of every solution
+func (s *session) give(name string) error {
+	i, err := s.row(name)
+	if err != nil {
+		return err
+	}
+	if s.forbidden[name] {
+		return fmt.Errorf("Row is forbidden: %s", name)
+	}
+	for _, g := range s.givens {
+		if g == name {
+			return fmt.Errorf("Row is already given: %s", name)
+		}
+		if s.conflict(s.rows[g], i) {
+			return fmt.Errorf("Row %s conflicts with given row %s", name, g)
+		}
+	}
+	s.givens = append(s.givens, name)
+	return nil
+}
+
+// forbid leaves a row out of every solution
+func (s *session) forbid(name string) error {
+	if _, err := s.row(name); err != nil {
+		return err
+	}
+	for _, g := range s.givens {
+		if g == name {
+			return fmt.Errorf("Row is given: %s", name)
+		}
+	}
+	s.forbidden[name] = true
+	return nil
+}
+
+// candidates returns the names of the rows which are neither given,
+// forbidden nor in conflict with a given row. If col is not empty only the
+// rows covering it are returned.
+func (s *session) candidates(col string) ([]string, error) {
+	if s.in == nil {
+		return nil, fmt.Errorf("No problem loaded")
+	}
+	given := make(map[string]bool)
+	for _, g := range s.givens {
+		given[g] = true
+	}
+
+	var ret []string
+rows:
+	for i, r := range s.in.Rows {
+		if given[r.Name] || s.forbidden[r.Name] {
+			continue
+		}
+		if col != "" {
+			covers := false
+			for _, item := range r.Items {
+				if c, _ := splitItem(item); c == col {
+					covers = true
+				}
+			}
+			if !covers {
+				continue
+			}
+		}
+		for _, g := range s.givens {
+			if s.conflict(s.rows[g], i) {
+				continue rows
+			}
+		}
+		ret = append(ret, r.Name)
+	}
+	return ret, nil
+}
+
+// solve finds at most limit solutions, or all of them if limit is not
+// positive. The solutions found are returned along with any error which
+// stopped the search.
+func (s *session) solve(limit int) ([][]string, error) {
+	if s.in == nil {
+		return nil, fmt.Errorf("No problem loaded")
+	}
+	in := &format.Instance{Primary: s.in.Primary, Secondary: s.in.Secondary}
+	for _, r := range s.in.Rows {
+		if !s.forbidden[r.Name] {
+			in.Rows = append(in.Rows, r)
+		}
+	}
+	prob, err := in.Problem()
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range s.givens {
+		if err := prob.RowIsSolution(g); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	s.stats = &gox.Stats{}
+	start := time.Now()
+	solns, err := prob.SolveContext(ctx, gox.WithLimit(limit), gox.WithHeuristic(s.heuristic), gox.WithStats(s.stats))
+	s.elapsed = time.Since(start)
+	return solns, err
+}
+
+// exec runs a line typed into the shell, returning false if the shell should
+// exit. Errors are reported to the user rather than stopping the shell.
+func (s *session) exec(e *env, line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+	// Names of rows may contain spaces, e.g. the rows of the DLX format
+	arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+
+	var err error
+	switch fields[0] {
+	case "quit", "exit":
+		return false
+	case "help":
+		fmt.Fprint(e.stdout, replHelp)
+	case "load":
+		if len(fields) < 2 || len(fields) > 3 {
+			err = fmt.Errorf("usage: load <file> [format]")
+			break
+		}
+		if fields[1] == "-" {
+			err = fmt.Errorf("Commands are read from stdin, so a problem cannot be")
+			break
+		}
+		formatName := ""
+		if len(fields) == 3 {
+			formatName = fields[2]
+		}
+		var in *format.Instance
+		if in, err = readInstance(e, fields[1], formatName); err == nil {
+			if err = s.load(in); err == nil {
+				fmt.Fprintf(e.stdout, "loaded %d primary and %d secondary columns, %d rows\n", len(in.Primary), len(in.Secondary), len(in.Rows))
+			}
+		}
+	case "give":
+		err = s.give(arg)
+	case "forbid":
+		err = s.forbid(arg)
+	case "reset":
+		s.reset()
+	case "candidates":
+		var names []string
+		if names, err = s.candidates(arg); err == nil {
+			for _, name := range names {
+				fmt.Fprintln(e.stdout, name)
+			}
+			fmt.Fprintf(e.stdout, "%d candidates\n", len(names))
+		}
+	case "solve":
+		limit := 0
+		if arg != "" {
+			if limit, err = strconv.Atoi(arg); err != nil {
+				err = fmt.Errorf("Invalid limit: %s", arg)
+				break
+			}
+		}
+		solns, solveErr := s.solve(limit)
+		uerr, infeasible := solveErr.(*gox.UncoverableError)
+		if solns == nil && solveErr != nil && !infeasible {
+			err = solveErr
+			break
+		}
+		res := solveResult{
+			Solutions: solns,
+			Count:     len(solns),
+			Complete:  solveErr == nil && (limit <= 0 || len(solns) < limit),
+		}
+		if infeasible {
+			res.Complete = true
+			res.Infeasible = uerr.Error()
+		} else if solveErr != nil {
+			res.Error = solveErr.Error()
+		}
+		err = writeText(e.stdout, res)
+	case "heuristic":
+		if arg != "" {
+			s.heuristic, err = gox.ParseHeuristic(arg)
+		}
+		if err == nil {
+			fmt.Fprintf(e.stdout, "heuristic %s\n", s.heuristic)
+		}
+	case "timeout":
+		if arg != "" {
+			s.timeout, err = time.ParseDuration(arg)
+		}
+		if err == nil {
+			fmt.Fprintf(e.stdout, "timeout %v\n", s.timeout)
+		}
+	case "stats":
+		if s.stats == nil {
+			err = fmt.Errorf("Nothing has been solved")
+			break
+		}
+		fmt.Fprintf(e.stdout, "solutions %d\nnodes %d\nupdates %d\ntime %v\n",
+			s.stats.Solutions, s.stats.Nodes, s.stats.Updates, s.elapsed.Round(time.Microsecond))
+	case "show":
+		if s.in == nil {
+			err = fmt.Errorf("No problem loaded")
+			break
+		}
+		var forbidden []string
+		for name := range s.forbidden {
+			forbidden = append(forbidden, name)
+		}
+		sort.Strings(forbidden)
+		fmt.Fprintf(e.stdout, "%d primary and %d secondary columns, %d rows\n", len(s.in.Primary), len(s.in.Secondary), len(s.in.Rows))
+		fmt.Fprintf(e.stdout, "given: %s\n", strings.Join(s.givens, ", "))
+		fmt.Fprintf(e.stdout, "forbidden: %s\n", strings.Join(forbidden, ", "))
+	default:
+		err = fmt.Errorf("Unknown command %q, type help for the commands", fields[0])
+	}
+	if err != nil {
+		fmt.Fprintf(e.stdout, "error: %v\n", err)
+	}
+	return true
+}
+
+// repl reads commands from r until it is exhausted or quit is typed
+func (s *session) repl(e *env, r io.Reader, prompt bool) error {
+	scanner := bufio.NewScanner(r)
+	for {
+		if prompt {
+			fmt.Fprint(e.stdout, "gox> ")
+		}
+		if !scanner.Scan() {
+			if prompt {
+				fmt.Fprintln(e.stdout)
+			}
+			return scanner.Err()
+		}
+		if !s.exec(e, scanner.Text()) {
+			return nil
+		}
+	}
+}
+
+func runREPL(e *env, args []string) error {
+	fs := newFlagSet(e, "repl", "[file]")
+	formatName := fs.String("format", "", "format of the problem: csv, json or dlx (default from the file extension)")
+	quiet := fs.Bool("quiet", false, "do not print a prompt, e.g. when reading commands from a script")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 || fs.Arg(0) == "-" {
+		return usage(fs, "expected at most one file, commands are read from stdin")
+	}
+
+	s := &session{forbidden: make(map[string]bool)}
+	if fs.NArg() == 1 {
+		in, err := readInstance(e, fs.Arg(0), *formatName)
+		if err != nil {
+			return err
+		}
+		if err := s.load(in); err != nil {
+			return err
+		}
+	}
+	return s.repl(e, e.stdin, !*quiet)
+}
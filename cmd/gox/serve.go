@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ifross89/gox/jsonrpc"
+	"github.com/ifross89/gox/server"
+)
+
+func init() {
+	commands["serve"] = command{
+		summary: "solve problems sent as JSON-RPC over stdin and stdout, or over HTTP",
+		run:     runServe,
+	}
+}
+
+func runServe(e *env, args []string) error {
+	fs := newFlagSet(e, "serve", "")
+	stdio := fs.Bool("stdio", false, "speak JSON-RPC over stdin and stdout, see the jsonrpc package")
+	addr := fs.String("http", "", "serve the HTTP API of the server package on this address, e.g. :8080")
+	limit := fs.Int("limit", 0, "most solutions found for a problem, 0 for no limit")
+	timeout := fs.Duration("timeout", 0, "longest time spent searching for the solutions to a problem, 0 for no limit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usage(fs, "expected no arguments")
+	}
+	if *stdio == (*addr != "") {
+		return usage(fs, "expected exactly one of -stdio and -http")
+	}
+
+	if *stdio {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		s := jsonrpc.NewServer(jsonrpc.Config{MaxSolutions: *limit, MaxTimeout: *timeout})
+		if err := s.Serve(ctx, e.stdin, e.stdout); err != nil && err != context.Canceled {
+			return err
+		}
+		return nil
+	}
+	s := server.New(server.Config{MaxSolutions: *limit, MaxTimeout: *timeout})
+	defer s.Close()
+	hs := &http.Server{Addr: *addr, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	return hs.ListenAndServe()
+}
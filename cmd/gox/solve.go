@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+func init() {
+	commands["solve"] = command{
+		summary: "find the solutions to a problem read from a file, or stdin if the file is - or omitted",
+		run:     runSolve,
+	}
+}
+
+// readInstance reads an instance from the file named, or from stdin if the
+// name is "-". The format is taken from the extension of the file unless
+// formatName is given.
+func readInstance(e *env, filename, formatName string) (*format.Instance, error) {
+	var f format.Format
+	var err error
+	if formatName != "" {
+		f, err = format.Parse(formatName)
+	} else if filename == "-" {
+		err = fmt.Errorf("The format must be given when reading from stdin")
+	} else {
+		f, err = format.FromFilename(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := e.stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+	return format.Read(r, f)
+}
+
+// solveResult is the result of solving a problem as written by the json output
+type solveResult struct {
+	Solutions [][]string `json:"solutions"`
+	Count     int        `json:"count"`
+	// Complete is false if the search was stopped by the limit or timeout
+	// before every solution was found
+	Complete bool   `json:"complete"`
+	Error    string `json:"error,omitempty"`
+	// Infeasible explains why the problem has no solutions, if that was
+	// found without searching
+	Infeasible string `json:"infeasible,omitempty"`
+	// Multiplicity is the number of solutions to the problem each solution
+	// stands for when duplicate rows were merged, see -merge
+	Multiplicity []int `json:"multiplicity,omitempty"`
+}
+
+// solveInstance finds the solutions to an instance, stopping after limit
+// solutions or once timeout has passed if they are positive. An error is
+// returned if the instance is not a valid problem, errors which stop the
+// search are reported in the result.
+func solveInstance(ctx context.Context, in *format.Instance, limit int, timeout time.Duration, h gox.Heuristic, opts ...gox.Option) (solveResult, error) {
+	prob, err := in.Problem()
+	if err != nil {
+		return solveResult{}, err
+	}
+	return solveProblem(ctx, prob, limit, timeout, h, opts...), nil
+}
+
+// solveMerged finds the solutions to an instance like solveInstance, once its
+// duplicate rows and columns have been merged, giving the multiplicity of each
+// solution
+func solveMerged(in *format.Instance, limit int, timeout time.Duration, h gox.Heuristic, opts ...gox.Option) (solveResult, error) {
+	b, err := in.Builder()
+	if err != nil {
+		return solveResult{}, err
+	}
+	prob, err := b.Build(gox.WithMergeDuplicates())
+	if err != nil {
+		return solveResult{}, err
+	}
+	res := solveProblem(context.Background(), prob, limit, timeout, h, opts...)
+	res.Multiplicity = make([]int, len(res.Solutions))
+	for i, soln := range res.Solutions {
+		res.Multiplicity[i] = prob.Preprocessed().Multiplicity(soln)
+	}
+	return res, nil
+}
+
+// solveProblem finds the solutions to a problem like solveInstance
+func solveProblem(ctx context.Context, prob gox.Solver, limit int, timeout time.Duration, h gox.Heuristic, opts ...gox.Option) solveResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	opts = append([]gox.Option{gox.WithLimit(limit), gox.WithHeuristic(h)}, opts...)
+	solns, err := prob.SolveContext(ctx, opts...)
+
+	res := solveResult{
+		Solutions: solns,
+		Count:     len(solns),
+		Complete:  err == nil && (limit <= 0 || len(solns) < limit),
+	}
+	if uerr, ok := err.(*gox.UncoverableError); ok {
+		res.Complete = true
+		res.Infeasible = uerr.Error()
+	} else if err != nil {
+		res.Error = err.Error()
+	}
+	if res.Solutions == nil {
+		res.Solutions = [][]string{}
+	}
+	return res
+}
+
+func runSolve(e *env, args []string) error {
+	fs := newFlagSet(e, "solve", "[file]")
+	formatName := fs.String("format", "", "format of the problem: csv, json or dlx (default from the file extension)")
+	limit := fs.Int("limit", 0, "stop after finding this many solutions, 0 for no limit")
+	timeout := fs.Duration("timeout", 0, "stop searching after this long, 0 for no timeout")
+	heuristic := fs.String("heuristic", gox.MinRemaining.String(), "column choice heuristic: mrv, first, wdeg or bucket")
+	output := fs.String("output", "text", "output format: text, json, html for a page to share, jsonl to write each solution as it is found, or knuth for the layout of Knuth's dlx1 and xcc")
+	trace := fs.String("trace", "", "record the steps of the search in this file as JSON lines, see gox visualize")
+	merge := fs.Bool("merge", false, "merge rows covering the same columns into one, reporting how many solutions each solution found stands for")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	filename := "-"
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		filename = fs.Arg(0)
+	default:
+		return usage(fs, "expected at most one file")
+	}
+	if *output != "text" && *output != "json" && *output != "jsonl" && *output != "html" && *output != "knuth" {
+		return usage(fs, "unknown output format %q", *output)
+	}
+	if *output == "knuth" && *trace != "" {
+		return usage(fs, "cannot trace the search with -output knuth")
+	}
+	if *merge && (*output == "jsonl" || *output == "knuth") {
+		return usage(fs, "cannot merge rows with -output %s", *output)
+	}
+	h, err := gox.ParseHeuristic(*heuristic)
+	if err != nil {
+		return usage(fs, "%v", err)
+	}
+
+	in, err := readInstance(e, filename, *formatName)
+	if err != nil {
+		return err
+	}
+	var opts []gox.Option
+	var tw *traceWriter
+	if *trace != "" {
+		file, err := os.Create(*trace)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		tw = newTraceWriter(file)
+		opts = append(opts, gox.WithTrace(tw.event))
+	}
+	var res solveResult
+	var stats gox.Stats
+	start := time.Now()
+	switch *output {
+	case "jsonl":
+		err = solveJSONL(e, in, *limit, *timeout, h, opts...)
+	case "knuth":
+		err = solveKnuth(e, in, *limit, *timeout, h)
+	case "json", "text", "html":
+		opts = append(opts, gox.WithStats(&stats))
+		if *merge {
+			res, err = solveMerged(in, *limit, *timeout, h, opts...)
+		} else {
+			res, err = solveInstance(context.Background(), in, *limit, *timeout, h, opts...)
+		}
+	}
+	elapsed := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if tw != nil {
+		if err := tw.flush(); err != nil {
+			return err
+		}
+	}
+	switch *output {
+	case "json":
+		return json.NewEncoder(e.stdout).Encode(res)
+	case "jsonl", "knuth":
+		return nil
+	case "html":
+		title := filename
+		if title == "-" {
+			title = "stdin"
+		}
+		return writeHTML(e.stdout, title, in, res, &stats, elapsed)
+	}
+	return writeText(e.stdout, res)
+}
+
+// writeHTML writes the result of solving an instance as an HTML page, see
+// gox.HTMLReport
+func writeHTML(w io.Writer, title string, in *format.Instance, res solveResult, stats *gox.Stats, elapsed time.Duration) error {
+	b, err := in.Builder()
+	if err != nil {
+		return err
+	}
+	prob, err := b.Build()
+	if err != nil {
+		return err
+	}
+	r := &gox.HTMLReport{
+		Title:     title,
+		Instance:  prob.Report(),
+		Stats:     stats,
+		Elapsed:   elapsed,
+		Complete:  res.Complete,
+		Solutions: res.Solutions,
+	}
+	return r.Write(w)
+}
+
+// solveJSONL writes the solutions to an instance to stdout as JSON lines as
+// they are found, without keeping them, see gox.SolutionWriter. The reason
+// the search stopped early, if it did, is written to stderr.
+func solveJSONL(e *env, in *format.Instance, limit int, timeout time.Duration, h gox.Heuristic, opts ...gox.Option) error {
+	prob, err := in.Problem()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	sw := gox.NewSolutionWriter(e.stdout, prob)
+	opts = append([]gox.Option{gox.WithLimit(limit), gox.WithHeuristic(h), gox.WithSolutionFunc(sw.Write), gox.WithoutSolutions()}, opts...)
+	_, err = prob.SolveContext(ctx, opts...)
+	if _, ok := err.(*gox.UncoverableError); ok {
+		fmt.Fprintf(e.stderr, "%d solutions (%v)\n", sw.Count(), err)
+	} else if err != nil {
+		fmt.Fprintf(e.stderr, "%d solutions (stopped: %v)\n", sw.Count(), err)
+	}
+	return sw.Err()
+}
+
+// solveKnuth writes the solutions to an instance to stdout as they are found
+// in the layout of Knuth's dlx1 and xcc programs, and like them writes the
+// number of solutions to stderr, see gox.KnuthWriter
+func solveKnuth(e *env, in *format.Instance, limit int, timeout time.Duration, h gox.Heuristic) error {
+	prob, err := in.Problem()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	kw := gox.NewKnuthWriter(e.stdout, prob)
+	var stats gox.Stats
+	_, err = prob.SolveContext(ctx, gox.WithLimit(limit), gox.WithHeuristic(h), gox.WithTrace(kw.Trace), gox.WithoutSolutions(), gox.WithStats(&stats))
+	if _, ok := err.(*gox.UncoverableError); ok {
+		err = nil
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "Stopped: %v\n", err)
+	}
+	fmt.Fprintln(e.stderr, kw.Summary(&stats))
+	return kw.Err()
+}
+
+// writeText writes each solution as its rows, one per line, followed by a
+// blank line, then a summary of the search
+func writeText(w io.Writer, res solveResult) error {
+	for i, soln := range res.Solutions {
+		for _, row := range soln {
+			fmt.Fprintln(w, row)
+		}
+		if res.Multiplicity != nil && res.Multiplicity[i] > 1 {
+			fmt.Fprintf(w, "(stands for %d solutions)\n", res.Multiplicity[i])
+		}
+		fmt.Fprintln(w)
+	}
+	var err error
+	switch {
+	case res.Error != "":
+		_, err = fmt.Fprintf(w, "%d solutions (stopped: %s)\n", res.Count, res.Error)
+	case !res.Complete:
+		_, err = fmt.Fprintf(w, "%d solutions (stopped at limit)\n", res.Count)
+	case res.Infeasible != "":
+		_, err = fmt.Fprintf(w, "%d solutions (%s)\n", res.Count, res.Infeasible)
+	default:
+		_, err = fmt.Fprintf(w, "%d solutions\n", res.Count)
+	}
+	return err
+}
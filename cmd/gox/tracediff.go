@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ifross89/gox"
+)
+
+func init() {
+	commands["tracediff"] = command{
+		summary: "compare the trees explored by two searches recorded by gox solve -trace, e.g. with different heuristics",
+		run:     runTraceDiff,
+	}
+}
+
+// readTree reads the tree of the search recorded in a trace file
+func readTree(filename string) (*gox.SearchTree, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var tree gox.SearchTree
+	err = readTrace(file, func(e gox.Event) error {
+		tree.Event(e)
+		return nil
+	})
+	return &tree, err
+}
+
+// describeColumn describes the column a search branched on at a divergence
+func describeColumn(column string, candidates, nodes int) string {
+	switch {
+	case nodes == 0:
+		return "not reached"
+	case column == "":
+		return "no branch"
+	}
+	return fmt.Sprintf("%s (%d rows)", column, candidates)
+}
+
+func runTraceDiff(e *env, args []string) error {
+	fs := newFlagSet(e, "tracediff", "<trace a> <trace b>")
+	top := fs.Int("top", 10, "list this many divergences, those with the greatest difference in nodes first, 0 for all")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usage(fs, "expected two traces")
+	}
+	if *top < 0 {
+		return usage(fs, "top must not be negative")
+	}
+	a, err := readTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := readTree(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	c := gox.CompareTrees(a, b)
+	tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\ta\tb\tdelta")
+	fmt.Fprintf(tw, "nodes\t%d\t%d\t%+d\n", c.NodesA, c.NodesB, c.NodesB-c.NodesA)
+	fmt.Fprintf(tw, "solutions\t%d\t%d\t%+d\n", c.SolutionsA, c.SolutionsB, c.SolutionsB-c.SolutionsA)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(c.Divergences) == 0 {
+		fmt.Fprintln(e.stdout, "\nthe searches explored the same tree")
+		return nil
+	}
+
+	fmt.Fprintf(e.stdout, "\n%d divergences\n", len(c.Divergences))
+	divs := c.Divergences
+	if *top > 0 && len(divs) > *top {
+		divs = divs[:*top]
+	}
+	fmt.Fprintln(tw, "path\tcolumn a\tcolumn b\tnodes a\tnodes b\tdelta")
+	for _, d := range divs {
+		path := strings.Join(d.Path, ", ")
+		if path == "" {
+			path = "(root)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%+d\n", path,
+			describeColumn(d.ColumnA, d.CandidatesA, d.NodesA), describeColumn(d.ColumnB, d.CandidatesB, d.NodesB),
+			d.NodesA, d.NodesB, d.Delta())
+	}
+	return tw.Flush()
+}
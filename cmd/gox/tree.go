@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+
+	"github.com/ifross89/gox"
+)
+
+func init() {
+	commands["tree"] = command{
+		summary: "export the tree explored by a search, from a trace recorded by gox solve -trace, as GraphML or JSON",
+		run:     runTree,
+	}
+}
+
+func runTree(e *env, args []string) error {
+	fs := newFlagSet(e, "tree", "[trace]")
+	output := fs.String("output", "graphml", "output format: graphml, e.g. for Gephi, or json")
+	depth := fs.Int("depth", 0, "record the nodes down to this depth, counting those below in their ancestors, 0 for every node")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usage(fs, "expected at most one trace")
+	}
+	if *output != "graphml" && *output != "json" {
+		return usage(fs, "unknown output format %q", *output)
+	}
+	if *depth < 0 {
+		return usage(fs, "depth must not be negative")
+	}
+
+	r := e.stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	tree := gox.SearchTree{MaxDepth: *depth}
+	if err := readTrace(r, func(ev gox.Event) error {
+		tree.Event(ev)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if *output == "json" {
+		return tree.WriteJSON(e.stdout)
+	}
+	return tree.WriteGraphML(e.stdout)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+func init() {
+	commands["validate"] = command{
+		summary: "check solutions against a problem, exiting with status 1 if any are invalid",
+		run:     runValidate,
+	}
+}
+
+// summaryLine matches the last line written by gox solve in the text format
+var summaryLine = regexp.MustCompile(`^\d+ solutions( \(.*\))?$`)
+
+// readSolutions reads solutions in the JSON or text output of gox solve. As
+// well as the output of gox solve -output json, JSON may be an array of
+// solutions or a single solution. The text format lists the rows of each
+// solution on their own lines, with a blank line after each solution.
+func readSolutions(r io.Reader, isJSON bool) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		data = bytes.TrimSpace(data)
+		if len(data) > 0 && data[0] == '{' {
+			var res solveResult
+			err := json.Unmarshal(data, &res)
+			return res.Solutions, err
+		}
+		var solns [][]string
+		if err := json.Unmarshal(data, &solns); err == nil {
+			return solns, nil
+		}
+		var soln []string
+		if err := json.Unmarshal(data, &soln); err != nil {
+			return nil, fmt.Errorf("Solutions must be an array of arrays of row names, or an array of row names: %v", err)
+		}
+		return [][]string{soln}, nil
+	}
+
+	var solns [][]string
+	var soln []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			if soln != nil {
+				solns = append(solns, soln)
+				soln = nil
+			}
+		case soln == nil && summaryLine.MatchString(line):
+		default:
+			soln = append(soln, line)
+		}
+	}
+	if soln != nil {
+		solns = append(solns, soln)
+	}
+	return solns, scanner.Err()
+}
+
+func runValidate(e *env, args []string) error {
+	fs := newFlagSet(e, "validate", "problem solutions")
+	formatName := fs.String("format", "", "format of the problem: csv, json or dlx (default from the file extension)")
+	solutionFormat := fs.String("solutions", "", "format of the solutions: json or text (default json for .json files, otherwise text)")
+	verbose := fs.Bool("v", false, "report the valid solutions as well as the invalid")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usage(fs, "expected a problem and a file of solutions")
+	}
+	if fs.Arg(0) == "-" && fs.Arg(1) == "-" {
+		return usage(fs, "only one of the problem and solutions may be read from stdin")
+	}
+	isJSON := strings.EqualFold(filepath.Ext(fs.Arg(1)), ".json")
+	switch *solutionFormat {
+	case "":
+	case "json":
+		isJSON = true
+	case "text":
+		isJSON = false
+	default:
+		return usage(fs, "unknown format of solutions %q", *solutionFormat)
+	}
+
+	in, err := readInstance(e, fs.Arg(0), *formatName)
+	if err != nil {
+		return err
+	}
+	prob, err := in.Problem()
+	if err != nil {
+		return err
+	}
+
+	r := e.stdin
+	if fs.Arg(1) != "-" {
+		file, err := os.Open(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	solns, err := readSolutions(r, isJSON)
+	if err != nil {
+		return err
+	}
+	if len(solns) == 0 {
+		return fmt.Errorf("No solutions found in %s", fs.Arg(1))
+	}
+
+	invalid := 0
+	for i, soln := range solns {
+		err := prob.Verify(soln)
+		if verr, ok := err.(*gox.VerifyError); ok {
+			invalid++
+			for _, v := range verr.Violations {
+				fmt.Fprintf(e.stdout, "solution %d: %v\n", i+1, v)
+			}
+		} else if err != nil {
+			return err
+		} else if *verbose {
+			fmt.Fprintf(e.stdout, "solution %d: ok\n", i+1)
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d solutions are invalid", invalid, len(solns))
+	}
+	fmt.Fprintf(e.stdout, "%d solutions are valid\n", len(solns))
+	return nil
+}
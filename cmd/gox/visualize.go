@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ifross89/gox"
+)
+
+func init() {
+	commands["visualize"] = command{
+		summary: "animate a search in the terminal, replaying a trace recorded by gox solve -trace or solving a problem live",
+		run:     runVisualize,
+	}
+}
+
+// traceWriter writes the events of a search as JSON lines, keeping the first
+// error so that it can be reported once the search has finished
+type traceWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+// newTraceWriter creates a trace writer which writes to w
+func newTraceWriter(w io.Writer) *traceWriter {
+	bw := bufio.NewWriter(w)
+	return &traceWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+// event writes an event, it is passed to gox.WithTrace
+func (t *traceWriter) event(e gox.Event) {
+	if t.err == nil {
+		t.err = t.enc.Encode(e)
+	}
+}
+
+// flush writes any buffered events, returning the first error
+func (t *traceWriter) flush() error {
+	if t.err != nil {
+		return t.err
+	}
+	return t.w.Flush()
+}
+
+// readTrace reads the events written by a traceWriter, calling f with each
+func readTrace(r io.Reader, f func(gox.Event) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var e gox.Event
+		if err := dec.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("Invalid trace: %v", err)
+		}
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+}
+
+// clearScreen moves the cursor to the top left of the terminal and clears it
+const clearScreen = "\x1b[H\x1b[2J"
+
+// animation draws a frame in the terminal for each event of a search
+type animation struct {
+	w     io.Writer
+	delay time.Duration
+	// plain separates the frames with a blank line rather than clearing the
+	// screen, for terminals which do not understand escape codes
+	plain bool
+	width int
+
+	// columns is the number of primary columns when the search started,
+	// taken from the first event
+	columns   int
+	steps     int
+	solutions int
+	partial   []string
+	// chosen describes the last column chosen
+	chosen string
+}
+
+// show updates the state of the search from an event and draws it, pausing
+// for the delay afterwards
+func (a *animation) show(e gox.Event) error {
+	a.steps++
+	if a.steps == 1 {
+		a.columns = e.Remaining
+	}
+	last := fmt.Sprintf("%s %s", e.Kind, e.Row)
+	switch e.Kind {
+	case gox.ChooseColumn:
+		a.chosen = fmt.Sprintf("%s (%d rows)", e.Column, e.Size)
+		last = fmt.Sprintf("choose %s", e.Column)
+	case gox.DeadEnd:
+		last = fmt.Sprintf("dead end: %s has no rows left", e.Column)
+	case gox.TryRow:
+		a.partial = append(a.partial, e.Row)
+	case gox.UndoRow:
+		if len(a.partial) > 0 {
+			a.partial = a.partial[:len(a.partial)-1]
+		}
+	case gox.FoundSolution:
+		a.solutions++
+		last = "solution found"
+	}
+
+	var b strings.Builder
+	if a.plain {
+		if a.steps > 1 {
+			b.WriteString("\n")
+		}
+	} else {
+		b.WriteString(clearScreen)
+	}
+	fmt.Fprintf(&b, "step %d  depth %d  solutions %d\n", a.steps, e.Depth, a.solutions)
+	fmt.Fprintf(&b, "columns %s %d/%d\n", a.bar(e.Remaining), e.Remaining, a.columns)
+	fmt.Fprintf(&b, "column  %s\n", a.chosen)
+	fmt.Fprintf(&b, "last    %s\n", last)
+	b.WriteString("partial solution:\n")
+	for _, row := range a.partial {
+		fmt.Fprintf(&b, "  %s\n", row)
+	}
+	if _, err := io.WriteString(a.w, b.String()); err != nil {
+		return err
+	}
+	if a.delay > 0 {
+		time.Sleep(a.delay)
+	}
+	return nil
+}
+
+// bar draws the proportion of the columns remaining, so that the matrix can
+// be seen to shrink as rows are chosen
+func (a *animation) bar(remaining int) string {
+	filled := a.width
+	if a.columns > 0 {
+		filled = remaining * a.width / a.columns
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", a.width-filled) + "]"
+}
+
+func runVisualize(e *env, args []string) error {
+	fs := newFlagSet(e, "visualize", "file")
+	replay := fs.Bool("replay", false, "the file is a trace recorded by gox solve -trace rather than a problem")
+	formatName := fs.String("format", "", "format of the problem: csv, json or dlx (default from the file extension)")
+	limit := fs.Int("limit", 1, "stop a live search after finding this many solutions, 0 for no limit")
+	heuristic := fs.String("heuristic", gox.MinRemaining.String(), "column choice heuristic of a live search: mrv or first")
+	delay := fs.Duration("delay", 200*time.Millisecond, "pause between the steps of the search")
+	plain := fs.Bool("plain", false, "print each step below the last rather than redrawing the screen")
+	width := fs.Int("width", 40, "width of the bar showing the columns remaining")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usage(fs, "expected a file")
+	}
+	if *width < 1 {
+		return usage(fs, "width must be positive")
+	}
+	h, err := gox.ParseHeuristic(*heuristic)
+	if err != nil {
+		return usage(fs, "%v", err)
+	}
+
+	a := &animation{w: e.stdout, delay: *delay, plain: *plain, width: *width}
+	if *replay {
+		r := e.stdin
+		if fs.Arg(0) != "-" {
+			file, err := os.Open(fs.Arg(0))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			r = file
+		}
+		return readTrace(r, a.show)
+	}
+
+	in, err := readInstance(e, fs.Arg(0), *formatName)
+	if err != nil {
+		return err
+	}
+	prob, err := in.Problem()
+	if err != nil {
+		return err
+	}
+	var showErr error
+	_, err = prob.SolveContext(context.Background(), gox.WithLimit(*limit), gox.WithHeuristic(h), gox.WithTrace(func(ev gox.Event) {
+		if showErr == nil {
+			showErr = a.show(ev)
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	return showErr
+}
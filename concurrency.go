@@ -0,0 +1,44 @@
+package gox
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ConcurrentUseError is returned when a problem is given rows or solved while
+// it is already being given rows or solved, such as by two goroutines at
+// once. The links of the matrix are rewritten by these calls, so running two
+// of them together would corrupt the problem in ways which only show up
+// later. Problems are not safe for concurrent use: use a problem per
+// goroutine, or SolveParallel.
+type ConcurrentUseError struct {
+	// Op is the call which was refused, and Running the call already using
+	// the problem
+	Op, Running string
+}
+
+func (e *ConcurrentUseError) Error() string {
+	return fmt.Sprintf("%s called while %s is running on the same problem, which is not safe for concurrent use", e.Op, e.Running)
+}
+
+// acquire marks the problem as in use by the call op, returning a
+// *ConcurrentUseError if it is already in use, or a *StateError if its state
+// does not allow it to be used, see State. Each successful call must be
+// followed by release.
+func (p *exactCoverProblem) acquire(op string) error {
+	if !atomic.CompareAndSwapInt32(&p.inUse, 0, 1) {
+		running, _ := p.running.Load().(string)
+		return &ConcurrentUseError{Op: op, Running: running}
+	}
+	if err := p.checkState(op); err != nil {
+		p.release()
+		return err
+	}
+	p.running.Store(op)
+	return nil
+}
+
+// release marks the problem as no longer in use
+func (p *exactCoverProblem) release() {
+	atomic.StoreInt32(&p.inUse, 0)
+}
@@ -0,0 +1,56 @@
+package gox
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConcurrentUse(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	started, finish := make(chan bool), make(chan bool)
+	done := make(chan error)
+	go func() {
+		_, err := prob.SolveContext(context.Background(), WithLimit(1), WithSolutionFunc(func([]string) {
+			started <- true
+			<-finish
+		}))
+		done <- err
+	}()
+	<-started
+
+	err := prob.RowIsSolution("v0")
+	cerr, ok := err.(*ConcurrentUseError)
+	if !ok || cerr.Op != "RowIsSolution" || cerr.Running != "SolveContext" {
+		t.Fatalf("Expected *ConcurrentUseError for RowIsSolution during SolveContext, got %v", err)
+	}
+	if _, err := prob.SolveIndices(context.Background()); err == nil {
+		t.Fatalf("Expected error solving during SolveContext")
+	}
+	// Shards and cursors are checked against the given rows only once the
+	// problem is acquired
+	if _, err := prob.SolveShard(context.Background(), Shard{}); !errors.As(err, new(*ConcurrentUseError)) {
+		t.Fatalf("Expected *ConcurrentUseError for SolveShard during SolveContext, got %v", err)
+	}
+	if _, _, err := prob.SolvePage(context.Background(), "bad", 1); !errors.As(err, new(*ConcurrentUseError)) {
+		t.Fatalf("Expected *ConcurrentUseError for SolvePage during SolveContext, got %v", err)
+	}
+	if solns := prob.Solve(); solns != nil {
+		t.Fatalf("Expected no solutions from Solve during SolveContext, got %v", solns)
+	}
+	if _, ok := prob.Err().(*ConcurrentUseError); !ok {
+		t.Fatalf("Expected Err to return a *ConcurrentUseError from Solve during SolveContext, got %v", prob.Err())
+	}
+
+	close(finish)
+	if err := <-done; err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	// The problem can be used again once the search has finished
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if solns, err := prob.SolveContext(context.Background()); err != nil || len(solns) != 3 {
+		t.Fatalf("Expected 3 solutions, got %v, %v", solns, err)
+	}
+}
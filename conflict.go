@@ -0,0 +1,26 @@
+package gox
+
+// nextConflictCol is nextCol for the ConflictWeighted heuristic. It chooses
+// the column with the fewest rows left for its weight, the number of dead
+// ends it has caused plus one, comparing the ratios by cross multiplying.
+// Ties are broken by taking the leftmost column. Like nextTiedCol it chooses
+// among ret and the columns of the priority class top to its right.
+func (p *exactCoverProblem) nextConflictCol(c *config, ret *node, top int) *node {
+	if c.conflicts == nil {
+		c.conflicts = make([]int64, p.numCols)
+	}
+	for n := ret.right; n != p.root; n = n.right {
+		if p.inClass(n, top) && int64(n.colCount)*(c.conflicts[ret.colIndex]+1) < int64(ret.colCount)*(c.conflicts[n.colIndex]+1) {
+			ret = n
+		}
+	}
+	return ret
+}
+
+// conflict records that the search reached a dead end at a column, which
+// makes ConflictWeighted branch on it earlier from then on
+func (c *config) conflict(col *node) {
+	if c.conflicts != nil {
+		c.conflicts[col.colIndex]++
+	}
+}
@@ -0,0 +1,84 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// splitSolutions returns the solutions of prob found with opts by each way
+// of searching it in parts: SolveParallel, SolveShard and SolvePage
+func splitSolutions(t *testing.T, prob *exactCoverProblem, opts ...Option) map[string][][]string {
+	ret := make(map[string][][]string)
+	solns, err := prob.SolveParallel(context.Background(), 4, opts...)
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	ret["SolveParallel"] = solns
+	shards, err := prob.Shard(opts...)
+	if err != nil {
+		t.Fatalf("Error sharding problem: %v", err)
+	}
+	for _, s := range shards {
+		solns, err := prob.SolveShard(context.Background(), s, opts...)
+		if err != nil {
+			t.Fatalf("Error solving shard: %v", err)
+		}
+		ret["SolveShard"] = append(ret["SolveShard"], solns...)
+	}
+	ret["SolvePage"] = pagedSolutions(t, prob, 5, 100, opts...)
+	return ret
+}
+
+func TestConflictWeighted(t *testing.T) {
+	prob := dominoProblem(t, 6)
+	solns, err := prob.SolveContext(context.Background(), WithHeuristic(ConflictWeighted))
+	if err != nil || len(solns) != 13 {
+		t.Fatalf("Expected 13 solutions, got %d: %v", len(solns), err)
+	}
+	// Searching in parts finds the same solutions, in another order
+	for op, got := range splitSolutions(t, prob, WithHeuristic(ConflictWeighted)) {
+		if !reflect.DeepEqual(canonicalSolutions(got), canonicalSolutions(solns)) {
+			t.Fatalf("%s: expected the solutions %v in any order, got %v", op, solns, got)
+		}
+	}
+
+	// Tiling a 2x10 board alongside a part which cannot be covered, and only
+	// fails once its columns are reached. MinRemaining prefers the leftmost
+	// columns of the board, while ConflictWeighted learns to try the part
+	// which fails first.
+	b := NewBuilder()
+	for c := 0; c < 10; c++ {
+		b.AddColumns(fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+	}
+	b.AddColumns("x", "y", "z")
+	for c := 0; c < 10; c++ {
+		b.AddRow(fmt.Sprintf("v%d", c), fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+		if c < 9 {
+			for r := 0; r < 2; r++ {
+				b.AddRow(fmt.Sprintf("h%d,%d", r, c), fmt.Sprintf("%d,%d", r, c), fmt.Sprintf("%d,%d", r, c+1))
+			}
+		}
+	}
+	b.AddRow("xy", "x", "y")
+	b.AddRow("xz", "x", "z")
+	b.AddRow("yz", "y", "z")
+	if prob, err = b.Build(); err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var mrv, wdeg Stats
+	if solns, err := prob.SolveContext(context.Background(), WithStats(&mrv)); err != nil || len(solns) != 0 {
+		t.Fatalf("Expected no solutions, got %v: %v", solns, err)
+	}
+	if solns, err := prob.SolveContext(context.Background(), WithHeuristic(ConflictWeighted), WithStats(&wdeg)); err != nil || len(solns) != 0 {
+		t.Fatalf("Expected no solutions, got %v: %v", solns, err)
+	}
+	if wdeg.Nodes*10 > mrv.Nodes {
+		t.Fatalf("Expected ConflictWeighted to visit far fewer nodes than %d, got %d", mrv.Nodes, wdeg.Nodes)
+	}
+
+	if h, err := ParseHeuristic("wdeg"); err != nil || h != ConflictWeighted {
+		t.Fatalf("Expected to parse wdeg, got %v: %v", h, err)
+	}
+}
@@ -0,0 +1,213 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// SetRowCost sets the cost of the named row, which SolveByCost adds up to
+// find the cost of each solution. Rows cost nothing until a cost is set.
+// Costs may not be negative.
+func (p *exactCoverProblem) SetRowCost(row string, cost float64) error {
+	if err := p.acquire("SetRowCost"); err != nil {
+		return err
+	}
+	defer p.release()
+	header := p.row(row)
+	if header == nil {
+		return fmt.Errorf("No row found with name %s", row)
+	}
+	if cost < 0 || math.IsNaN(cost) || math.IsInf(cost, 0) {
+		return fmt.Errorf("Invalid cost %v for row %s, costs must be finite and not negative", cost, row)
+	}
+	if p.rowCosts == nil {
+		p.rowCosts = make([]float64, len(p.rowHeaders))
+	}
+	p.rowCosts[header.index] = cost
+	return nil
+}
+
+// RowCost returns the cost of the named row, see SetRowCost
+func (p *exactCoverProblem) RowCost(row string) (float64, error) {
+	header := p.row(row)
+	if header == nil {
+		return 0, fmt.Errorf("No row found with name %s", row)
+	}
+	return p.rowCost(header), nil
+}
+
+// rowCost returns the cost of a row
+func (p *exactCoverProblem) rowCost(r *rowHeader) float64 {
+	if p.rowCosts == nil {
+		return 0
+	}
+	return p.rowCosts[r.index]
+}
+
+// Cost returns the total cost of the rows of the solution, see SetRowCost.
+// Rows which are not in the problem cost nothing.
+func (s *Solution) Cost() float64 {
+	cost := 0.0
+	for _, name := range s.Rows {
+		if r := s.p.row(name); r != nil {
+			cost += s.p.rowCost(r)
+		}
+	}
+	return cost
+}
+
+// SolveByCost finds the solutions to the problem in order of their total
+// cost, cheapest first, so that the k cheapest solutions can be found with
+// WithLimit(k) without finding them all. The cost of a solution is the sum of
+// the costs of its rows set by SetRowCost, including the rows given with
+// RowIsSolution. Solutions of the same cost are found in the order of
+// SolveContext.
+//
+// The search is iterative deepening on the cost: each pass searches for the
+// solutions costing up to a bound, abandoning partial solutions which must
+// cost more, then the bound is raised to the cheapest partial solution
+// abandoned. As the earlier passes are repeated this does more work than
+// SolveContext when there are many distinct costs, but needs no more memory.
+// Otherwise the options apply as for SolveContext.
+//
+// A search can be warm started with WithIncumbent or WithCostBound, so that
+// it only looks for solutions cheaper than one already known, abandoning the
+// passes which could only find dearer ones. The cheapest solution returned
+// can be passed to WithIncumbent when solving the problem again, e.g. after
+// editing it, so that successive searches keep improving on it, and its cost
+// is reported as the Bound of WithStats for WithCostBound. An incumbent which
+// is not a solution is refused before searching.
+func (p *exactCoverProblem) SolveByCost(ctx context.Context, opts ...Option) ([][]string, error) {
+	var ret [][]string
+	opts = append(opts, func(c *config) {
+		c.byCost, c.sorted = true, false
+		c.prepare = func(c *config) error {
+			if c.costBound <= 0 {
+				c.costBound = math.Inf(1)
+			}
+			if c.incumbent != nil {
+				if err := p.Verify(c.incumbent); err != nil {
+					return fmt.Errorf("Invalid incumbent: %v", err)
+				}
+				c.costBound = (&Solution{p: p, Rows: c.incumbent}).Cost()
+			}
+			c.cheapest = c.costBound
+			return nil
+		}
+	})
+	err := p.solve(ctx, "SolveByCost", opts, func(c *config, rows []*rowHeader) {
+		soln := rowNames(rows)
+		if !c.discard {
+			ret = append(ret, soln)
+		}
+		if c.onSolution != nil {
+			c.onSolution(soln)
+		}
+	})
+	return ret, err
+}
+
+// WithCostBound makes SolveByCost look only for solutions costing less than
+// cost, counting the rows given with RowIsSolution. A bound of zero or less
+// means there is no bound.
+func WithCostBound(cost float64) Option {
+	return func(c *config) {
+		c.incumbent = nil
+		c.costBound = cost
+	}
+}
+
+// searchByCost is search for SolveByCost, making a pass of searchCost for
+// each bound until a pass abandons nothing, or the bound reaches that of
+// WithCostBound or WithIncumbent. done is the bound of the previous pass, the
+// solutions costing up to which have already been found.
+func (p *exactCoverProblem) searchByCost(c *config) {
+	given := 0.0
+	for _, r := range p.solutionRows {
+		given += p.rowCost(r)
+	}
+	done, bound := math.Inf(-1), given
+	for bound < c.costBound {
+		next := math.Inf(1)
+		if p.searchCost(c, given, done, bound, &next) || math.IsInf(next, 1) {
+			return
+		}
+		done, bound = bound, next
+	}
+}
+
+// searchCost searches for the solutions costing more than done but no more
+// than bound, cost being that of the partial solution. Partial solutions
+// which must cost more than bound are abandoned, the least that any of them
+// must cost being left in next. As next is the cheapest any solution not yet
+// found can cost, every solution found by a pass costs its bound. searchCost
+// returns true if the search was stopped, the matrix is restored either way.
+func (p *exactCoverProblem) searchCost(c *config, cost, done, bound float64, next *float64) bool {
+	if c.interrupted() {
+		return true
+	}
+	if p.root == p.root.right {
+		if cost > bound {
+			*next = math.Min(*next, cost)
+			return false
+		}
+		if cost <= done {
+			return false
+		}
+		c.solutions++
+		c.cheapest = math.Min(c.cheapest, cost)
+		p.emit(c, FoundSolution, nil, nil)
+		return !c.found(p.solutionRows)
+	}
+
+	colHead := p.nextCol(c)
+	p.chose(c, colHead)
+	if colHead.colCount == 0 {
+		p.emit(c, DeadEnd, colHead, nil)
+		return false
+	}
+	if least := cost + p.remainingCost(); least > bound {
+		*next = math.Min(*next, least)
+		p.emit(c, DeadEnd, colHead, nil)
+		return false
+	}
+
+	p.cover(colHead)
+	stopped := false
+	for rowNode := colHead.down; rowNode != colHead && !stopped; rowNode = rowNode.down {
+		p.pushRowToSolution(rowNode.rowHead)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		p.emit(c, TryRow, nil, rowNode.rowHead)
+		stopped = p.searchCost(c, cost+p.rowCost(rowNode.rowHead), done, bound, next)
+		p.popRowFromSolution()
+		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+			p.uncommit(leftNode)
+		}
+		p.emit(c, UndoRow, nil, rowNode.rowHead)
+	}
+	p.uncover(colHead)
+	return stopped
+}
+
+// remainingCost returns the least that covering the primary columns left must
+// add to the cost of a partial solution: the most that the cheapest row left
+// in any one column costs
+func (p *exactCoverProblem) remainingCost() float64 {
+	if p.rowCosts == nil {
+		return 0
+	}
+	ret := 0.0
+	for colHead := p.root.right; colHead != p.root; colHead = colHead.right {
+		cheapest := math.Inf(1)
+		for n := colHead.down; n != colHead; n = n.down {
+			cheapest = math.Min(cheapest, p.rowCosts[n.rowHead.index])
+		}
+		if !math.IsInf(cheapest, 1) {
+			ret = math.Max(ret, cheapest)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,131 @@
+package gox
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSolveByCost(t *testing.T) {
+	prob := dominoProblem(t, 8)
+	for i, row := range prob.Rows() {
+		// Vertical dominoes cost more towards the right of the board
+		cost := 1.0
+		if strings.HasPrefix(row, "v") {
+			cost = float64(i%5) + 0.5
+		}
+		if err := prob.SetRowCost(row, cost); err != nil {
+			t.Fatalf("Error setting cost: %v", err)
+		}
+	}
+	all, err := prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	var want []float64
+	for _, soln := range all {
+		want = append(want, prob.Solution(soln).Cost())
+	}
+	sort.Float64s(want)
+
+	solns, err := prob.SolveByCost(context.Background())
+	if err != nil || len(solns) != len(all) {
+		t.Fatalf("Expected %d solutions, got %d: %v", len(all), len(solns), err)
+	}
+	seen := make(map[string]bool)
+	for i, soln := range solns {
+		if cost := prob.Solution(soln).Cost(); math.Abs(cost-want[i]) > 1e-9 {
+			t.Fatalf("Expected solution %d to cost %v, got %v", i, want[i], cost)
+		}
+		seen[strings.Join(soln, " ")] = true
+	}
+	if len(seen) != len(all) {
+		t.Fatalf("Expected %d distinct solutions, got %d", len(all), len(seen))
+	}
+
+	// The cheapest solutions, counting the cost of a given row
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	solns, err = prob.SolveByCost(context.Background(), WithLimit(2))
+	if err != nil || len(solns) != 2 {
+		t.Fatalf("Expected 2 solutions, got %v: %v", solns, err)
+	}
+	if first, second := prob.Solution(solns[0]).Cost(), prob.Solution(solns[1]).Cost(); first > second || first < 0.5 {
+		t.Fatalf("Expected solutions in order of cost, got %v and %v", first, second)
+	}
+}
+
+func TestSolveByCostWarmStart(t *testing.T) {
+	prob := dominoProblem(t, 8)
+	for i, row := range prob.Rows() {
+		cost := 1.0
+		if strings.HasPrefix(row, "v") {
+			cost = float64(i%5) + 0.5
+		}
+		if err := prob.SetRowCost(row, cost); err != nil {
+			t.Fatalf("Error setting cost: %v", err)
+		}
+	}
+	var stats Stats
+	all, err := prob.SolveByCost(context.Background(), WithStats(&stats))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	// The final bound is the cost of the cheapest solution, and nothing is
+	// cheaper
+	if least := prob.Solution(all[0]).Cost(); stats.Bound != least {
+		t.Fatalf("Expected the bound to be %v, got %v", least, stats.Bound)
+	}
+	if solns, err := prob.SolveByCost(context.Background(), WithCostBound(stats.Bound), WithStats(&stats)); err != nil || solns != nil || stats.Bound != prob.Solution(all[0]).Cost() {
+		t.Fatalf("Expected no solutions cheaper than the bound and the bound kept, got %v, %v: %v", solns, stats.Bound, err)
+	}
+	// Only the solutions cheaper than the incumbent are found
+	incumbent := all[len(all)/2]
+	cost := prob.Solution(incumbent).Cost()
+	cheaper := 0
+	for cheaper < len(all) && prob.Solution(all[cheaper]).Cost() < cost {
+		cheaper++
+	}
+	if cheaper == 0 {
+		t.Fatalf("Expected solutions cheaper than %v", cost)
+	}
+	solns, err := prob.SolveByCost(context.Background(), WithIncumbent(incumbent))
+	if err != nil || len(solns) != cheaper {
+		t.Fatalf("Expected %d solutions cheaper than %v, got %d: %v", cheaper, cost, len(solns), err)
+	}
+	solns, err = prob.SolveByCost(context.Background(), WithCostBound(cost))
+	if err != nil || len(solns) != cheaper {
+		t.Fatalf("Expected %d solutions cheaper than %v, got %d: %v", cheaper, cost, len(solns), err)
+	}
+	// Nothing is cheaper than the cheapest
+	if solns, err := prob.SolveByCost(context.Background(), WithIncumbent(all[0])); err != nil || solns != nil {
+		t.Fatalf("Expected no solutions cheaper than the cheapest, got %v: %v", solns, err)
+	}
+	if solns, err := prob.SolveByCost(context.Background(), WithIncumbent([]string{"v0"})); err == nil || solns != nil || !strings.HasPrefix(err.Error(), "Invalid incumbent") {
+		t.Fatalf("Expected an error for an incumbent which is not a solution, got %v, %v", solns, err)
+	}
+}
+
+func TestSetRowCost(t *testing.T) {
+	prob := dominoProblem(t, 2)
+	if err := prob.SetRowCost("v0", -1); err == nil {
+		t.Fatal("Expected error for negative cost")
+	}
+	if err := prob.SetRowCost("v9", 1); err == nil {
+		t.Fatal("Expected error for unknown row")
+	}
+	if err := prob.SetRowCost("v1", 2.5); err != nil {
+		t.Fatalf("Error setting cost: %v", err)
+	}
+	if cost, err := prob.RowCost("v1"); err != nil || cost != 2.5 {
+		t.Fatalf("Expected cost 2.5, got %v: %v", cost, err)
+	}
+	// Without costs every solution costs nothing, so all are found in one pass
+	solns, err := dominoProblem(t, 5).SolveByCost(context.Background())
+	if err != nil || len(solns) != 8 {
+		t.Fatalf("Expected 8 solutions, got %d: %v", len(solns), err)
+	}
+}
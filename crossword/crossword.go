@@ -0,0 +1,327 @@
+// Package crossword fills crossword grids from a list of words using the exact
+// cover solver in gox.
+//
+// Each slot in the grid, a horizontal or vertical run of two or more open
+// cells, is a primary column so that exactly one word is placed in it. Every
+// cell where two slots cross is a secondary column, which the rows placing a
+// word colour with the letter the word puts in the cell. As the rows covering a
+// coloured column must agree on its colour, the words placed in crossing slots
+// agree on the letter they share. Each word is also a secondary column, so no
+// word is used more than once in a grid.
+package crossword
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+const (
+	// Block marks a cell of the grid which is not filled
+	Block = '#'
+	// Empty marks a cell of the grid which is to be filled by the solver
+	Empty = '.'
+)
+
+// Grid is a crossword grid. Each cell is either a Block, Empty, or an upper
+// case letter which is given in the puzzle.
+type Grid [][]byte
+
+// Parse reads a grid from a string with one line per row of the grid, e.g.
+//
+//	..#
+//	...
+//	#..
+//
+// Letters are converted to upper case. Blank lines are ignored.
+func Parse(s string) (Grid, error) {
+	var g Grid
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		row := []byte(strings.ToUpper(line))
+		for _, c := range row {
+			if c != Block && c != Empty && (c < 'A' || c > 'Z') {
+				return nil, fmt.Errorf("Invalid cell in grid: %q", c)
+			}
+		}
+		if len(g) > 0 && len(row) != len(g[0]) {
+			return nil, fmt.Errorf("All rows must be same length: rows[0]=%d, rows[%d] = %d", len(g[0]), len(g), len(row))
+		}
+		g = append(g, row)
+	}
+	if len(g) == 0 {
+		return nil, fmt.Errorf("Grid must have at least one row")
+	}
+	return g, nil
+}
+
+// String returns the grid in the format read by Parse
+func (g Grid) String() string {
+	lines := make([]string, len(g))
+	for i, row := range g {
+		lines[i] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Slot is a run of cells which is filled by a single word
+type Slot struct {
+	// Name is the clue number followed by A for across or D for down, e.g. 1A
+	Name string
+	// Row and Col are the position of the first cell of the slot
+	Row, Col int
+	Length   int
+	Across   bool
+}
+
+// cell returns the position of the i-th cell of the slot
+func (s Slot) cell(i int) (row, col int) {
+	if s.Across {
+		return s.Row, s.Col + i
+	}
+	return s.Row + i, s.Col
+}
+
+// Slots returns the slots in the grid, numbered in the conventional way:
+// reading the grid row by row, each cell starting an across or down slot is
+// given the next number.
+func (g Grid) Slots() []Slot {
+	open := func(r, c int) bool {
+		return r >= 0 && r < len(g) && c >= 0 && c < len(g[r]) && g[r][c] != Block
+	}
+
+	var slots []Slot
+	number := 0
+	for r := range g {
+		for c := range g[r] {
+			if !open(r, c) {
+				continue
+			}
+			across := !open(r, c-1) && open(r, c+1)
+			down := !open(r-1, c) && open(r+1, c)
+			if !across && !down {
+				continue
+			}
+			number++
+			if across {
+				n := 0
+				for open(r, c+n) {
+					n++
+				}
+				slots = append(slots, Slot{Name: fmt.Sprintf("%dA", number), Row: r, Col: c, Length: n, Across: true})
+			}
+			if down {
+				n := 0
+				for open(r+n, c) {
+					n++
+				}
+				slots = append(slots, Slot{Name: fmt.Sprintf("%dD", number), Row: r, Col: c, Length: n})
+			}
+		}
+	}
+	return slots
+}
+
+// placement is a word placed in a slot, represented by a row of the problem
+type placement struct {
+	slot Slot
+	word string
+}
+
+// Puzzle is a grid to be filled from a list of words
+type Puzzle struct {
+	grid  Grid
+	slots []Slot
+	words []string
+	// placements maps the names of the rows in the problem to the word
+	// placements they represent
+	placements map[string]placement
+	builder    *gox.Builder
+}
+
+// New creates a puzzle to fill the grid from the words given. Words are
+// converted to upper case and repeated words are ignored.
+func New(g Grid, words []string) (*Puzzle, error) {
+	p := &Puzzle{
+		grid:       g,
+		slots:      g.Slots(),
+		placements: make(map[string]placement),
+		builder:    gox.NewBuilder(),
+	}
+
+	seen := make(map[string]bool)
+	for _, w := range words {
+		w = strings.ToUpper(strings.TrimSpace(w))
+		if w == "" || seen[w] {
+			continue
+		}
+		for i := 0; i < len(w); i++ {
+			if w[i] < 'A' || w[i] > 'Z' {
+				return nil, fmt.Errorf("Word contains invalid letter: %s", w)
+			}
+		}
+		seen[w] = true
+		p.words = append(p.words, w)
+	}
+
+	if err := p.addColumns(); err != nil {
+		return nil, err
+	}
+	if err := p.addRows(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// cellName is the name of the secondary column for a cell
+func cellName(row, col int) string {
+	return fmt.Sprintf("r%dc%d", row, col)
+}
+
+// wordName is the name of the secondary column for a word
+func wordName(word string) string {
+	return "word=" + word
+}
+
+// crossings returns the number of slots each cell of the grid belongs to
+func (p *Puzzle) crossings() [][]int {
+	ret := make([][]int, len(p.grid))
+	for r := range p.grid {
+		ret[r] = make([]int, len(p.grid[r]))
+	}
+	for _, s := range p.slots {
+		for i := 0; i < s.Length; i++ {
+			r, c := s.cell(i)
+			ret[r][c]++
+		}
+	}
+	return ret
+}
+
+// addColumns adds a primary column for each slot, and secondary columns for
+// the crossing cells and the words
+func (p *Puzzle) addColumns() error {
+	for _, s := range p.slots {
+		if err := p.builder.AddColumns(s.Name); err != nil {
+			return err
+		}
+	}
+
+	crossings := p.crossings()
+	for r := range crossings {
+		for c, n := range crossings[r] {
+			if n > 1 {
+				if err := p.builder.AddSecondaryColumns(cellName(r, c)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, w := range p.words {
+		if err := p.builder.AddSecondaryColumns(wordName(w)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addRows adds a row for each word which fits in each slot, taking into
+// account the letters given in the grid
+func (p *Puzzle) addRows() error {
+	crossings := p.crossings()
+	for _, s := range p.slots {
+		for _, w := range p.words {
+			if !p.fits(s, w) {
+				continue
+			}
+
+			items := []string{s.Name, wordName(w)}
+			for i := 0; i < s.Length; i++ {
+				r, c := s.cell(i)
+				if crossings[r][c] > 1 {
+					items = append(items, cellName(r, c)+":"+w[i:i+1])
+				}
+			}
+
+			name := s.Name + "=" + w
+			if err := p.builder.AddRow(name, items...); err != nil {
+				return err
+			}
+			p.placements[name] = placement{slot: s, word: w}
+		}
+	}
+	return nil
+}
+
+// fits reports whether the word can be placed in the slot
+func (p *Puzzle) fits(s Slot, w string) bool {
+	if len(w) != s.Length {
+		return false
+	}
+	for i := 0; i < s.Length; i++ {
+		r, c := s.cell(i)
+		if given := p.grid[r][c]; given != Empty && given != w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Slots returns the slots of the puzzle's grid
+func (p *Puzzle) Slots() []Slot {
+	return p.slots
+}
+
+// Problem creates the exact cover problem for the puzzle. The rows of the
+// problem are named after the slot and the word placed in it, e.g. "1A=CAT".
+func (p *Puzzle) Problem() (gox.Solver, error) {
+	prob, err := p.builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode fills a copy of the puzzle's grid with the words placed by a solution
+// to the puzzle's problem
+func (p *Puzzle) Decode(solution []string) (Grid, error) {
+	g := make(Grid, len(p.grid))
+	for r := range p.grid {
+		g[r] = append([]byte(nil), p.grid[r]...)
+	}
+
+	for _, name := range solution {
+		pl, ok := p.placements[name]
+		if !ok {
+			return nil, fmt.Errorf("No placement found with name %s", name)
+		}
+		for i := 0; i < pl.slot.Length; i++ {
+			r, c := pl.slot.cell(i)
+			g[r][c] = pl.word[i]
+		}
+	}
+	return g, nil
+}
+
+// Solve finds every way of filling the puzzle's grid
+func (p *Puzzle) Solve() ([]Grid, error) {
+	prob, err := p.Problem()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Grid
+	for _, soln := range prob.Solve() {
+		g, err := p.Decode(soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, g)
+	}
+	return ret, nil
+}
@@ -0,0 +1,100 @@
+package crossword
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSlots(t *testing.T) {
+	g, err := Parse(`
+		..#
+		...
+		#..`)
+	if err != nil {
+		t.Fatalf("Error parsing grid: %v", err)
+	}
+
+	expected := []Slot{
+		Slot{Name: "1A", Row: 0, Col: 0, Length: 2, Across: true},
+		Slot{Name: "1D", Row: 0, Col: 0, Length: 2},
+		Slot{Name: "2D", Row: 0, Col: 1, Length: 3},
+		Slot{Name: "3A", Row: 1, Col: 0, Length: 3, Across: true},
+		Slot{Name: "4D", Row: 1, Col: 2, Length: 2},
+		Slot{Name: "5A", Row: 2, Col: 1, Length: 2, Across: true},
+	}
+	slots := g.Slots()
+	if len(slots) != len(expected) {
+		t.Fatalf("Expected %d slots, got %#v", len(expected), slots)
+	}
+	for i, s := range slots {
+		if s != expected[i] {
+			t.Fatalf("Slot %d: expected %#v, got %#v", i, expected[i], s)
+		}
+	}
+}
+
+func TestSolveWordSquare(t *testing.T) {
+	g, _ := Parse("...\n...\n...")
+	p, err := New(g, []string{"cat", "ore", "wed", "cow", "are", "ted", "dog"})
+	if err != nil {
+		t.Fatalf("Error creating puzzle: %v", err)
+	}
+	grids, err := p.Solve()
+	if err != nil {
+		t.Fatalf("Error solving puzzle: %v", err)
+	}
+
+	// The square may be filled either way round
+	var got []string
+	for _, g := range grids {
+		got = append(got, g.String())
+	}
+	sort.Strings(got)
+	expected := []string{"CAT\nORE\nWED", "COW\nARE\nTED"}
+	if len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+		t.Fatalf("Expected grids %q, got %q", expected, got)
+	}
+}
+
+func TestSolveGivenLetters(t *testing.T) {
+	g, _ := Parse("C..\n...\n...")
+	p, err := New(g, []string{"cat", "ore", "wed", "cow", "are", "ted"})
+	if err != nil {
+		t.Fatalf("Error creating puzzle: %v", err)
+	}
+	grids, err := p.Solve()
+	if err != nil {
+		t.Fatalf("Error solving puzzle: %v", err)
+	}
+	if len(grids) != 2 {
+		t.Fatalf("Expected 2 grids, got %d", len(grids))
+	}
+
+	g, _ = Parse("..T\n...\n...")
+	p, _ = New(g, []string{"cat", "ore", "wed", "cow", "are", "ted"})
+	grids, _ = p.Solve()
+	if len(grids) != 1 || grids[0].String() != "CAT\nORE\nWED" {
+		t.Fatalf("Expected a single grid, got %q", grids)
+	}
+}
+
+func TestWordsUsedOnce(t *testing.T) {
+	// The only way to fill the grid would be to use each word twice
+	g, _ := Parse("..\n..")
+	p, _ := New(g, []string{"at", "to"})
+	grids, err := p.Solve()
+	if err != nil {
+		t.Fatalf("Error solving puzzle: %v", err)
+	}
+	if len(grids) != 0 {
+		t.Fatalf("Expected no grids, got %q", grids)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{"", "..\n...", "a1"} {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("Expected error parsing %q", s)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package gox
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+func TestBruteForceDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, h := range []Heuristic{MinRemaining, FirstColumn, BucketedMinRemaining} {
+		testutil.Compare(t, rng, 200, 12, 8, func(m [][]bool, names []string) ([][]string, error) {
+			prob, err := NewExactCoverProblem(m, names, WithDebug())
+			if err != nil {
+				return nil, err
+			}
+			solns, err := prob.SolveContext(context.Background(), WithHeuristic(h))
+			if _, ok := err.(*UncoverableError); ok {
+				return nil, nil
+			}
+			return solns, err
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package gox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dumpRows is the number of rows listed by String
+const dumpRows = 10
+
+// String describes the problem, its columns and its first rows, see Dump
+func (p *exactCoverProblem) String() string {
+	var b strings.Builder
+	p.dump(&b, false)
+	return b.String()
+}
+
+// Dump writes a description of the problem for debugging: its size and
+// density, the number of rows left in each column which has not been
+// covered, and the rows given with RowIsSolution, then the first rows of the
+// problem. If verbose is set every row is written, followed by the nodes
+// still active in each column.
+func (p *exactCoverProblem) Dump(w io.Writer, verbose bool) error {
+	bw := bufio.NewWriter(w)
+	p.dump(bw, verbose)
+	return bw.Flush()
+}
+
+// dump writes the description of Dump to w
+func (p *exactCoverProblem) dump(w io.Writer, verbose bool) {
+	r := p.Report()
+	fmt.Fprintf(w, "%d rows, %d primary and %d secondary columns, %d nodes, density %.3f\n",
+		r.Rows, r.PrimaryColumns, r.SecondaryColumns, r.Nodes, r.Density)
+
+	// Primary columns which are not linked to the root have been covered
+	active := make(map[*node]bool)
+	for h := p.root.right; h != p.root; h = h.right {
+		active[h] = true
+	}
+	cols := make([]string, p.numCols)
+	for i, h := range p.colHeaders {
+		if i < p.numPrimary && !active[h] {
+			cols[i] = p.colName(h) + "*"
+			continue
+		}
+		cols[i] = fmt.Sprintf("%s=%d", p.colName(h), h.colCount)
+	}
+	fmt.Fprintf(w, "columns (rows left, * covered): %s\n", strings.Join(cols, " "))
+	if len(p.solutionRows) > 0 {
+		var given []string
+		for _, r := range p.solutionRows {
+			given = append(given, r.label())
+		}
+		fmt.Fprintf(w, "given: %s\n", strings.Join(given, ", "))
+	}
+
+	fmt.Fprintln(w, "rows:")
+	for i, r := range p.rowHeaders {
+		if !verbose && i == dumpRows {
+			fmt.Fprintf(w, "  ... and %d more\n", len(p.rowHeaders)-dumpRows)
+			break
+		}
+		fmt.Fprintf(w, "  %s: %s\n", r.label(), strings.Join(p.rowItems(r), " "))
+	}
+	if !verbose {
+		return
+	}
+
+	fmt.Fprintln(w, "active nodes:")
+	for i, h := range p.colHeaders {
+		if i < p.numPrimary && !active[h] {
+			continue
+		}
+		var rows []string
+		for n := h.down; n != h; n = n.down {
+			rows = append(rows, n.rowHead.label())
+		}
+		fmt.Fprintf(w, "  %s: %s\n", p.colName(h), strings.Join(rows, ", "))
+	}
+}
+
+// rowItems returns the items of a row as given to the Builder, the names of
+// its columns followed by their colours
+func (p *exactCoverProblem) rowItems(r *rowHeader) []string {
+	var ret []string
+	for n := r.first; n != nil; {
+		item := p.colName(n.colHead)
+		if color := n.color; color != 0 {
+			if color < 0 {
+				color = -color
+			}
+			item += ":" + p.colorName(color)
+		}
+		ret = append(ret, item)
+		if n = n.right; n == r.first {
+			n = nil
+		}
+	}
+	return ret
+}
@@ -0,0 +1,41 @@
+package gox
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	prob := dominoProblem(t, 6)
+	s := fmt.Sprint(prob)
+	for _, expected := range []string{
+		"16 rows, 12 primary and 0 secondary columns, 32 nodes, density 0.167\n",
+		"columns (rows left, * covered): 0,0=2 1,0=2 0,1=3",
+		"  v0: 0,0 1,0\n",
+		"  ... and 6 more\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Fatalf("Expected %q in:\n%s", expected, s)
+		}
+	}
+
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := prob.Dump(&buf, true); err != nil {
+		t.Fatalf("Error dumping problem: %v", err)
+	}
+	for _, expected := range []string{
+		"0,0* 1,0* 0,1=2",
+		"given: v0\n",
+		"  h1,4: 1,4 1,5\n",
+		"active nodes:\n  0,1: v1, h0,1\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Fatalf("Expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package edgematch solves edge-matching puzzles, such as MacMahon's squares,
+// using the exact cover solver in gox.
+//
+// Square tiles with a colour on each edge are placed on a rectangular board so
+// that tiles which are next to each other have the same colour on the edge they
+// share. Each cell of the board is a primary column, as is each tile when every
+// tile must be used. The edges between cells are secondary columns, and the row
+// placing a rotation of a tile in a cell gives each of the cell's edges the
+// colour of the tile's edge. As the rows covering a coloured column must agree
+// on its colour, neighbouring tiles agree on the colour of their shared edge.
+package edgematch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+// The edges of a tile, in clockwise order
+const (
+	Top = iota
+	Right
+	Bottom
+	Left
+)
+
+// Tile is a square tile with a colour on each edge
+type Tile struct {
+	Name string
+	// Edges holds the colours of the edges, indexed by Top, Right, Bottom and
+	// Left
+	Edges [4]string
+}
+
+// Rotate turns the tile clockwise by 90 degrees
+func (t Tile) Rotate() Tile {
+	t.Edges = [4]string{t.Edges[Left], t.Edges[Top], t.Edges[Right], t.Edges[Bottom]}
+	return t
+}
+
+// Rotations returns the distinct rotations of the tile, starting with the tile
+// as given. A tile whose colours are symmetric has fewer than 4 rotations.
+func (t Tile) Rotations() []Tile {
+	ret := []Tile{t}
+	for r := t.Rotate(); r.Edges != t.Edges; r = r.Rotate() {
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// AllTiles returns every tile which can be made with the colours given,
+// counting tiles which are rotations of each other once. With three colours
+// these are MacMahon's 24 colored squares. Each tile is named after its edges.
+func AllTiles(colors []string) []Tile {
+	var ret []Tile
+	seen := make(map[[4]string]bool)
+	n := len(colors)
+	for i := 0; i < n*n*n*n; i++ {
+		t := Tile{Edges: [4]string{colors[i/(n*n*n)], colors[i/(n*n)%n], colors[i/n%n], colors[i%n]}}
+		if seen[t.Edges] {
+			continue
+		}
+		for _, r := range t.Rotations() {
+			seen[r.Edges] = true
+		}
+		t.Name = strings.Join(t.Edges[:], "")
+		ret = append(ret, t)
+	}
+	return ret
+}
+
+// Placed is a tile placed on the board, rotated so that its edges are as they
+// appear on the board
+type Placed struct {
+	Tile
+	// Rotation is the number of times the tile was turned clockwise
+	Rotation int
+}
+
+// placement is a tile placed in a cell, represented by a row of the problem
+type placement struct {
+	row, col int
+	placed   Placed
+}
+
+// Puzzle is the problem of placing tiles on a board so that their edges match
+type Puzzle struct {
+	width, height int
+	tiles         []Tile
+	border        string
+	// placements maps the names of the rows in the problem to the placements
+	// they represent
+	placements map[string]placement
+	builder    *gox.Builder
+}
+
+// cellName is the name of the column for a cell of the board
+func cellName(row, col int) string {
+	return fmt.Sprintf("%d,%d", row, col)
+}
+
+// New creates a puzzle to place tiles on a board with width columns and height
+// rows. If there are as many tiles as cells every tile must be used, otherwise
+// each tile may be used at most once. If border is not empty, every edge on the
+// border of the board must have that colour.
+func New(width, height int, tiles []Tile, border string) (*Puzzle, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("Board must have at least one cell: width=%d, height=%d", width, height)
+	}
+	p := &Puzzle{
+		width:      width,
+		height:     height,
+		tiles:      tiles,
+		border:     border,
+		placements: make(map[string]placement),
+		builder:    gox.NewBuilder(),
+	}
+
+	for r := 0; r < height; r++ {
+		for c := 0; c < width; c++ {
+			if err := p.builder.AddColumns(cellName(r, c)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	addTile := p.builder.AddSecondaryColumns
+	if len(tiles) == width*height {
+		addTile = p.builder.AddColumns
+	}
+	for _, t := range tiles {
+		for _, color := range t.Edges {
+			if color == "" {
+				return nil, fmt.Errorf("Tile %s has an edge with no colour", t.Name)
+			}
+		}
+		if err := addTile(t.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	for r := 0; r < height; r++ {
+		for c := 0; c < width; c++ {
+			if r < height-1 {
+				if err := p.builder.AddSecondaryColumns(p.edgeName(r, c, Bottom)); err != nil {
+					return nil, err
+				}
+			}
+			if c < width-1 {
+				if err := p.builder.AddSecondaryColumns(p.edgeName(r, c, Right)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, t := range tiles {
+		if err := p.addTile(t); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// edgeName returns the name of the column for an edge of a cell, or "" if the
+// edge is on the border of the board. Each edge between two cells is named
+// after the cell above or to the left of it.
+func (p *Puzzle) edgeName(row, col, edge int) string {
+	switch edge {
+	case Top:
+		if row == 0 {
+			return ""
+		}
+		return fmt.Sprintf("h%d,%d", row-1, col)
+	case Right:
+		if col == p.width-1 {
+			return ""
+		}
+		return fmt.Sprintf("v%d,%d", row, col)
+	case Bottom:
+		if row == p.height-1 {
+			return ""
+		}
+		return fmt.Sprintf("h%d,%d", row, col)
+	default:
+		if col == 0 {
+			return ""
+		}
+		return fmt.Sprintf("v%d,%d", row, col-1)
+	}
+}
+
+// addTile adds a row for each rotation of the tile in each cell where it
+// matches the border
+func (p *Puzzle) addTile(t Tile) error {
+	for rotation, rotated := range t.Rotations() {
+		for r := 0; r < p.height; r++ {
+			for c := 0; c < p.width; c++ {
+				items := []string{cellName(r, c), t.Name}
+				fits := true
+				for edge, color := range rotated.Edges {
+					name := p.edgeName(r, c, edge)
+					if name == "" {
+						fits = p.border == "" || p.border == color
+						if !fits {
+							break
+						}
+						continue
+					}
+					items = append(items, name+":"+color)
+				}
+				if !fits {
+					continue
+				}
+
+				name := fmt.Sprintf("%s/%d@%d,%d", t.Name, rotation, r, c)
+				if err := p.builder.AddRow(name, items...); err != nil {
+					return err
+				}
+				p.placements[name] = placement{row: r, col: c, placed: Placed{Tile: rotated, Rotation: rotation}}
+			}
+		}
+	}
+	return nil
+}
+
+// Problem creates the exact cover problem for the puzzle. The rows of the
+// problem are named after the tile, the number of times it is rotated and the
+// cell it is placed in, e.g. "abca/1@2,3".
+func (p *Puzzle) Problem() (gox.Solver, error) {
+	prob, err := p.builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode returns the tile placed in each cell of the board by a solution to the
+// puzzle's problem, indexed by row then column
+func (p *Puzzle) Decode(solution []string) ([][]Placed, error) {
+	ret := make([][]Placed, p.height)
+	for r := range ret {
+		ret[r] = make([]Placed, p.width)
+	}
+	for _, name := range solution {
+		pl, ok := p.placements[name]
+		if !ok {
+			return nil, fmt.Errorf("No placement found with name %s", name)
+		}
+		ret[pl.row][pl.col] = pl.placed
+	}
+	return ret, nil
+}
+
+// Render draws a solution to the puzzle's problem. Each tile is drawn as a 3x3
+// block of characters showing the first character of the colour of each edge.
+func (p *Puzzle) Render(solution []string) (string, error) {
+	board, err := p.Decode(solution)
+	if err != nil {
+		return "", err
+	}
+
+	first := func(s string) byte {
+		if s == "" {
+			return ' '
+		}
+		return s[0]
+	}
+	lines := make([]string, 0, 3*p.height)
+	for _, row := range board {
+		var top, middle, bottom []byte
+		for _, t := range row {
+			top = append(top, ' ', first(t.Edges[Top]), ' ')
+			middle = append(middle, first(t.Edges[Left]), '+', first(t.Edges[Right]))
+			bottom = append(bottom, ' ', first(t.Edges[Bottom]), ' ')
+		}
+		lines = append(lines, string(top), string(middle), string(bottom))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Solve finds every solution to the puzzle, returning the tile placed in each
+// cell as given by Decode
+func (p *Puzzle) Solve() ([][][]Placed, error) {
+	prob, err := p.Problem()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret [][][]Placed
+	for _, soln := range prob.Solve() {
+		board, err := p.Decode(soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, board)
+	}
+	return ret, nil
+}
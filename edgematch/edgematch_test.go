@@ -0,0 +1,130 @@
+package edgematch
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRotations(t *testing.T) {
+	for _, test := range []struct {
+		edges [4]string
+		n     int
+	}{
+		{[4]string{"a", "a", "a", "a"}, 1},
+		{[4]string{"a", "b", "a", "b"}, 2},
+		{[4]string{"a", "a", "b", "b"}, 4},
+		{[4]string{"a", "b", "c", "d"}, 4},
+	} {
+		if n := len(Tile{Edges: test.edges}.Rotations()); n != test.n {
+			t.Fatalf("Expected %d rotations of %v, got %d", test.n, test.edges, n)
+		}
+	}
+
+	tile := Tile{Edges: [4]string{"a", "b", "c", "d"}}
+	if r := tile.Rotate(); r.Edges != [4]string{"d", "a", "b", "c"} {
+		t.Fatalf("Unexpected rotation %v", r.Edges)
+	}
+}
+
+func TestMacMahonSquares(t *testing.T) {
+	tiles := AllTiles([]string{"r", "g", "b"})
+	if len(tiles) != 24 {
+		t.Fatalf("Expected 24 tiles, got %d", len(tiles))
+	}
+	names := make(map[string]bool)
+	for _, tile := range tiles {
+		names[tile.Name] = true
+	}
+	if len(names) != 24 {
+		t.Fatalf("Expected tiles to have distinct names, got %v", names)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	// Tiles cut from a 2x2 board with a red border and a blue cross in the
+	// middle, given in a scrambled order and rotation
+	tiles := []Tile{
+		{"A", [4]string{"b", "b", "r", "r"}},
+		{"B", [4]string{"r", "b", "b", "r"}},
+		{"C", [4]string{"r", "r", "b", "b"}},
+		{"D", [4]string{"b", "r", "r", "b"}},
+	}
+	p, err := New(2, 2, tiles, "r")
+	if err != nil {
+		t.Fatalf("Error creating puzzle: %v", err)
+	}
+	solns, err := p.Solve()
+	if err != nil {
+		t.Fatalf("Error solving puzzle: %v", err)
+	}
+	// Each tile fits in one corner only, but the tiles are all rotations of
+	// each other so there is one solution for each arrangement
+	if len(solns) != 24 {
+		t.Fatalf("Expected 24 solutions, got %d", len(solns))
+	}
+	for _, board := range solns {
+		if board[0][0].Edges != [4]string{"r", "b", "b", "r"} || board[1][1].Edges != [4]string{"b", "r", "r", "b"} {
+			t.Fatalf("Unexpected solution %v", board)
+		}
+	}
+
+	// A different border has no solutions
+	p, _ = New(2, 2, tiles, "g")
+	if solns, _ := p.Solve(); len(solns) != 0 {
+		t.Fatalf("Expected no solutions, got %d", len(solns))
+	}
+}
+
+func TestSolveMismatch(t *testing.T) {
+	tiles := []Tile{
+		{"A", [4]string{"x", "a", "x", "x"}},
+		{"B", [4]string{"x", "x", "x", "b"}},
+		{"C", [4]string{"x", "x", "x", "a"}},
+	}
+	// A and B cannot be next to each other, but A and C can
+	p, err := New(2, 1, tiles, "x")
+	if err != nil {
+		t.Fatalf("Error creating puzzle: %v", err)
+	}
+	prob, err := p.Problem()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	// The solution can also be turned around, with C on the left
+	solns := canonical(prob.Solve())
+	if len(solns) != 2 || solns[0] != "A/0@0,0 C/0@0,1" || solns[1] != "A/2@0,1 C/2@0,0" {
+		t.Fatalf("Unexpected solutions %q", solns)
+	}
+	s, err := p.Render(strings.Fields(solns[0]))
+	if err != nil {
+		t.Fatalf("Error rendering solution: %v", err)
+	}
+	if expected := " x  x \nx+aa+x\n x  x "; s != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, s)
+	}
+}
+
+// canonical sorts the rows of each solution, and then the solutions
+func canonical(solns [][]string) []string {
+	var ret []string
+	for _, s := range solns {
+		sort.Strings(s)
+		ret = append(ret, strings.Join(s, " "))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(0, 1, nil, ""); err == nil {
+		t.Fatal("Expected error for empty board")
+	}
+	if _, err := New(1, 1, []Tile{{"A", [4]string{"a", "", "a", "a"}}}, ""); err == nil {
+		t.Fatal("Expected error for tile with missing colour")
+	}
+	tile := Tile{"A", [4]string{"a", "a", "a", "a"}}
+	if _, err := New(2, 1, []Tile{tile, tile}, ""); err == nil {
+		t.Fatal("Expected error for duplicate tile names")
+	}
+}
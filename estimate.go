@@ -0,0 +1,118 @@
+package gox
+
+import (
+	"context"
+	"math"
+	"math/rand"
+)
+
+// Estimate is an approximate count of the solutions to a problem, see
+// EstimateSolutions
+type Estimate struct {
+	// Count is the estimated number of solutions, and Low and High the
+	// bounds of the 95% confidence interval around it
+	Count, Low, High float64
+	// StdErr is the standard error of Count
+	StdErr float64
+	// Nodes is the estimated number of nodes of the search tree, which is
+	// the work an exact count would take
+	Nodes float64
+	// Probes is the number of random paths taken through the search tree
+	Probes int
+}
+
+// EstimateSolutions estimates the number of solutions to the problem by
+// Knuth's method, for problems with too many solutions to count exactly. Each
+// probe follows a random path down the search tree, choosing one row at
+// random from the column chosen by the heuristic, and estimates the count as
+// the product of the number of rows it could have chosen at each step if it
+// reaches a solution, or zero if it reaches a dead end. The average of the
+// probes is an unbiased estimate, and the more probes are taken the tighter
+// the confidence interval. The heuristic set by WithHeuristic is used, and
+// the default of choosing the column with the fewest rows gives the least
+// variance. Other options have no effect.
+//
+// If the context is cancelled the estimate from the probes taken so far is
+// returned with the context's error.
+func (p *exactCoverProblem) EstimateSolutions(ctx context.Context, probes int, rng *rand.Rand, opts ...Option) (_ *Estimate, err error) {
+	if err := p.startSolve("EstimateSolutions"); err != nil {
+		return nil, err
+	}
+	defer p.finishSolve(&err)
+	c := newConfig(ctx, opts)
+	ret := &Estimate{}
+	if p.uncoverable() != nil {
+		return ret, nil
+	}
+	var sum, sumSq, nodes float64
+	for ; ret.Probes < probes; ret.Probes++ {
+		if err := ctx.Err(); err != nil {
+			ret.summarise(sum, sumSq, nodes)
+			return ret, err
+		}
+		count, size := p.probe(c, rng)
+		sum += count
+		sumSq += count * count
+		nodes += size
+	}
+	ret.summarise(sum, sumSq, nodes)
+	return ret, nil
+}
+
+// summarise fills in the estimate from the sums of the counts, their squares
+// and the tree sizes estimated by its probes
+func (e *Estimate) summarise(sum, sumSq, nodes float64) {
+	if e.Probes == 0 {
+		return
+	}
+	n := float64(e.Probes)
+	e.Count = sum / n
+	e.Nodes = nodes / n
+	if e.Probes > 1 {
+		variance := (sumSq - sum*sum/n) / (n - 1)
+		if variance > 0 {
+			e.StdErr = math.Sqrt(variance / n)
+		}
+	}
+	e.Low = math.Max(0, e.Count-1.96*e.StdErr)
+	e.High = e.Count + 1.96*e.StdErr
+}
+
+// probe follows a random path down the search tree, returning the estimate
+// of the number of solutions and of the size of the tree it gives. The
+// matrix is restored.
+func (p *exactCoverProblem) probe(c *config, rng *rand.Rand) (count, nodes float64) {
+	var path []*node
+	weight := 1.0
+	nodes = 1
+	for p.root != p.root.right {
+		colHead := p.nextCol(c)
+		if colHead.colCount == 0 {
+			weight = 0
+			break
+		}
+		rowNode := colHead.down
+		for i := rng.Intn(colHead.colCount); i > 0; i-- {
+			rowNode = rowNode.down
+		}
+		weight *= float64(colHead.colCount)
+		nodes += weight
+
+		p.cover(colHead)
+		p.pushRowToSolution(rowNode.rowHead)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		path = append(path, rowNode)
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		rowNode := path[i]
+		p.popRowFromSolution()
+		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+			p.uncommit(leftNode)
+		}
+		p.uncover(rowNode.colHead)
+	}
+	return weight, nodes
+}
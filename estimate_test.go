@@ -0,0 +1,54 @@
+package gox
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestEstimateSolutions(t *testing.T) {
+	// A 2x20 board has F(21) = 10946 tilings by dominoes
+	prob := dominoProblem(t, 20)
+	est, err := prob.EstimateSolutions(context.Background(), 5000, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Error estimating solutions: %v", err)
+	}
+	if est.Probes != 5000 {
+		t.Fatalf("Expected 5000 probes, got %d", est.Probes)
+	}
+	if est.Low > 10946 || est.High < 10946 || est.Low >= est.High {
+		t.Fatalf("Expected 10946 to be within the bounds of %+v", est)
+	}
+	if est.Nodes < est.Count {
+		t.Fatalf("Expected the tree to have at least a node per solution: %+v", est)
+	}
+	// The matrix is restored after each probe
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Matrix not restored: %v", err)
+	}
+	if count, err := prob.CountSolutions(context.Background()); err != nil || count != 10946 {
+		t.Fatalf("Expected 10946 solutions after estimating, got %d, %v", count, err)
+	}
+
+	// With one row in each column the estimate is exact
+	prob, err = NewExactCoverProblem(successfulCoverTests[1].mat, successfulCoverTests[1].names)
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	if err := prob.RowIsSolution("B"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	est, err = prob.EstimateSolutions(context.Background(), 10, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Error estimating solutions: %v", err)
+	}
+	if est.Count != 1 || est.StdErr != 0 || est.Low != 1 || est.High != 1 {
+		t.Fatalf("Expected an exact estimate of 1, got %+v", est)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if est, err := prob.EstimateSolutions(ctx, 10, rand.New(rand.NewSource(1))); err != context.Canceled || est.Probes != 0 {
+		t.Fatalf("Expected no probes and context.Canceled, got %+v, %v", est, err)
+	}
+}
@@ -0,0 +1,43 @@
+package gox
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// WithEventStream writes an Event for each step of the search to w as JSON
+// lines, for programs outside gox to replay, such as an animation of dancing
+// links in a browser. As well as the events passed to WithTrace, the stream
+// has an event for each column covered and uncovered, see Cover. The events
+// are buffered and flushed when the search ends; an error writing them stops
+// the search, and is returned by the call which made it. Like WithTrace, it
+// slows the search down and is ignored by SolveParallel.
+func WithEventStream(w io.Writer) Option {
+	return func(c *config) {
+		bw := bufio.NewWriter(w)
+		c.events = &eventStream{w: bw, enc: json.NewEncoder(bw)}
+	}
+}
+
+// eventStream writes the events of a search for WithEventStream
+type eventStream struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// write writes an event, setting the error of the search if it fails
+func (s *eventStream) write(c *config, e Event) {
+	if c.err != nil {
+		return
+	}
+	c.err = s.enc.Encode(e)
+}
+
+// flush writes the events buffered, setting the error of the search if it
+// fails and there was none
+func (s *eventStream) flush(c *config) {
+	if err := s.w.Flush(); err != nil && c.err == nil {
+		c.err = err
+	}
+}
@@ -0,0 +1,77 @@
+package gox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEventStream(t *testing.T) {
+	var traced []Event
+	var buf bytes.Buffer
+	prob := dominoProblem(t, 3)
+	solns, err := prob.SolveContext(context.Background(), WithEventStream(&buf), WithTrace(func(e Event) {
+		traced = append(traced, e)
+	}))
+	if err != nil || len(solns) != 3 {
+		t.Fatalf("Expected 3 solutions, got %d: %v", len(solns), err)
+	}
+
+	// The columns covered and uncovered must balance, and the other events
+	// are those passed to WithTrace
+	var streamed, covered []Event
+	var stack []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e Event
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("Error decoding event: %v", err)
+		}
+		switch e.Kind {
+		case Cover:
+			stack = append(stack, e.Column)
+			covered = append(covered, e)
+		case Uncover:
+			if len(stack) == 0 || stack[len(stack)-1] != e.Column {
+				t.Fatalf("Uncovered %s which was not the last column covered: %v", e.Column, stack)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			streamed = append(streamed, e)
+		}
+	}
+	if len(stack) != 0 || len(covered) == 0 {
+		t.Fatalf("Expected columns to be covered and all uncovered, left %v", stack)
+	}
+	if !reflect.DeepEqual(streamed, traced) {
+		t.Fatalf("Expected the traced events %v, got %v", traced, streamed)
+	}
+	if e := covered[0]; e.Column != "0,0" || e.Size != 2 || e.Remaining != 5 {
+		t.Fatalf("Unexpected first column covered: %+v", e)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestEventStreamError(t *testing.T) {
+	prob := dominoProblem(t, 12)
+	var stats Stats
+	_, err := prob.SolveContext(context.Background(), WithEventStream(failingWriter{}), WithStats(&stats))
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("Expected the error writing events, got %v", err)
+	}
+	if stats.Solutions == 233 {
+		t.Fatalf("Expected the search to stop, got %+v", stats)
+	}
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Matrix not restored: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package gox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explainer turns the events of a search, see WithTrace, into a narrative of
+// the search in plain English, a sentence for each step, e.g. for teaching how
+// dancing links works or for the hints of a puzzle. The zero value is ready to
+// use; an Explainer explains a single search.
+type Explainer struct {
+	// base is the depth of the first event, so that the rows given with
+	// RowIsSolution do not indent the narrative
+	base    int
+	started bool
+	// choices holds the column chosen at each depth, by depth
+	choices map[int]*explainedChoice
+	// partial holds the rows tried, not including the rows given
+	partial   []string
+	solutions int
+}
+
+// explainedChoice is a column the search branched on and the number of its
+// rows tried so far
+type explainedChoice struct {
+	column string
+	size   int
+	tried  int
+}
+
+// Explain returns a sentence describing an event, indented by two spaces for
+// each row in the partial solution, or "" for events which add nothing to the
+// narrative, such as a column covered.
+func (x *Explainer) Explain(e Event) string {
+	if !x.started {
+		x.base, x.started = e.Depth, true
+		x.choices = make(map[int]*explainedChoice)
+	}
+	var s string
+	switch e.Kind {
+	case ChooseColumn:
+		x.choices[e.Depth] = &explainedChoice{column: e.Column, size: e.Size}
+		return ""
+	case DeadEnd:
+		if e.Size > 0 {
+			s = "This position is known to lead nowhere, backtracking."
+		} else {
+			s = fmt.Sprintf("Contradiction: column '%s' has no candidates left, backtracking.", e.Column)
+		}
+	case TryRow:
+		x.partial = append(x.partial, e.Row)
+		choice := x.choices[e.Depth-1]
+		if choice == nil {
+			s = fmt.Sprintf("Choosing row %s.", e.Row)
+			break
+		}
+		choice.tried++
+		switch {
+		case choice.size == 1:
+			s = fmt.Sprintf("Column '%s' has only one candidate, choosing row %s.", choice.column, e.Row)
+		case choice.tried == 1:
+			s = fmt.Sprintf("Column '%s' has %d candidates, trying row %s (1 of %d).", choice.column, choice.size, e.Row, choice.size)
+		default:
+			s = fmt.Sprintf("Trying row %s for column '%s' instead (%d of %d).", e.Row, choice.column, choice.tried, choice.size)
+		}
+		// The row itself is not indented by the depth it adds
+		return x.indent(e.Depth-1) + s
+	case UndoRow:
+		if n := len(x.partial); n > 0 {
+			x.partial = x.partial[:n-1]
+		}
+		s = fmt.Sprintf("Taking back row %s.", e.Row)
+	case FoundSolution:
+		x.solutions++
+		s = fmt.Sprintf("Every column is covered: solution %d is %s.", x.solutions, strings.Join(x.partial, ", "))
+	default:
+		return ""
+	}
+	return x.indent(e.Depth) + s
+}
+
+// indent returns the indentation of the sentences at a depth of the search
+func (x *Explainer) indent(depth int) string {
+	if depth <= x.base {
+		return ""
+	}
+	return strings.Repeat("  ", depth-x.base)
+}
+
+// Explain returns the narrative of a search from its events, a sentence for
+// each step, see Explainer
+func Explain(events []Event) []string {
+	var x Explainer
+	var lines []string
+	for _, e := range events {
+		if s := x.Explain(e); s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,36 @@
+package gox
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	prob := branchingProblem(t)
+	var events []Event
+	if _, err := prob.SolveContext(context.Background(), WithHeuristic(FirstColumn), WithTrace(func(e Event) {
+		events = append(events, e)
+	})); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	expected := []string{
+		"Column 'a' has 2 candidates, trying row ab (1 of 2).",
+		"  Contradiction: column 'c' has no candidates left, backtracking.",
+		"Taking back row ab.",
+		"Trying row ac for column 'a' instead (2 of 2).",
+		"  Column 'b' has only one candidate, choosing row b.",
+		"    Every column is covered: solution 1 is ac, b.",
+		"  Taking back row b.",
+		"Taking back row ac.",
+	}
+	if got := Explain(events); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Expected narrative:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// Events which add nothing to the narrative are skipped
+	var x Explainer
+	if s := x.Explain(Event{Kind: Cover, Column: "a"}); s != "" {
+		t.Fatalf("Expected no sentence for a column covered, got %q", s)
+	}
+}
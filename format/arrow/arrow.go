@@ -0,0 +1,138 @@
+// Package arrow reads exact cover problems from the columnar formats of data
+// pipelines, Arrow IPC streams and Parquet files, so that instances generated
+// with Spark or pandas can be solved without converting them to CSV. The
+// cells of the matrix are read from two columns of a table in long form, one
+// holding the name of a row and the other a column it covers, and the
+// instance is made from them by format.FromPairs:
+//
+//	in, err := arrow.ReadParquet(f, "row_name", "column_id")
+//
+// Either column may hold strings or integers, or dictionaries of them such as
+// pandas categoricals, and neither may hold nulls.
+//
+// The package is kept apart from format as it needs the Arrow library,
+// github.com/apache/arrow/go/v14, which it is tested with at v14.0.2. gox has
+// no module file, so the library must be required by the module which builds
+// it.
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	goarrow "github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+
+	"github.com/ifross89/gox/format"
+)
+
+// pairs collects the pairs of a row name and a column name read from the
+// records of a table
+type pairs struct {
+	rowField, colField string
+	rows, cols         []string
+}
+
+// add appends the pairs held by a record
+func (p *pairs) add(rec goarrow.Record) error {
+	rows, err := values(rec, p.rowField)
+	if err != nil {
+		return err
+	}
+	cols, err := values(rec, p.colField)
+	if err != nil {
+		return err
+	}
+	p.rows = append(p.rows, rows...)
+	p.cols = append(p.cols, cols...)
+	return nil
+}
+
+// instance creates the instance from the pairs read
+func (p *pairs) instance() (*format.Instance, error) {
+	return format.FromPairs(p.rows, p.cols)
+}
+
+// values returns the values of the named column of a record as strings
+func values(rec goarrow.Record, field string) ([]string, error) {
+	indices := rec.Schema().FieldIndices(field)
+	if len(indices) != 1 {
+		return nil, fmt.Errorf("Expected one field named %s, found %d", field, len(indices))
+	}
+	a := rec.Column(indices[0])
+	t := a.DataType()
+	if d, ok := t.(*goarrow.DictionaryType); ok {
+		t = d.ValueType
+	}
+	if id := t.ID(); id != goarrow.STRING && id != goarrow.LARGE_STRING && !goarrow.IsInteger(id) {
+		return nil, fmt.Errorf("Expected strings or integers in field %s, got %s", field, a.DataType())
+	}
+	ret := make([]string, a.Len())
+	for i := range ret {
+		if a.IsNull(i) {
+			return nil, fmt.Errorf("Null in field %s at row %d of the record", field, i)
+		}
+		// The strings of an array share its buffers, which are copied so
+		// that the record can be released
+		ret[i] = string(append([]byte(nil), a.ValueStr(i)...))
+	}
+	return ret, nil
+}
+
+// FromRecords creates an instance from the pairs held by the fields named
+// rowField and colField of each record in turn
+func FromRecords(recs []goarrow.Record, rowField, colField string) (*format.Instance, error) {
+	p := &pairs{rowField: rowField, colField: colField}
+	for _, rec := range recs {
+		if err := p.add(rec); err != nil {
+			return nil, err
+		}
+	}
+	return p.instance()
+}
+
+// ReadIPC reads an instance from the record batches of an Arrow IPC stream,
+// as written by pyarrow.ipc.new_stream, taking the pairs from the fields
+// named rowField and colField
+func ReadIPC(r io.Reader, rowField, colField string) (*format.Instance, error) {
+	rdr, err := ipc.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading Arrow stream: %v", err)
+	}
+	defer rdr.Release()
+	p := &pairs{rowField: rowField, colField: colField}
+	for rdr.Next() {
+		if err := p.add(rdr.Record()); err != nil {
+			return nil, err
+		}
+	}
+	if err := rdr.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading Arrow stream: %v", err)
+	}
+	return p.instance()
+}
+
+// ReadParquet reads an instance from a Parquet file, taking the pairs from
+// the columns named rowField and colField
+func ReadParquet(r parquet.ReaderAtSeeker, rowField, colField string) (*format.Instance, error) {
+	mem := memory.DefaultAllocator
+	tbl, err := pqarrow.ReadTable(context.Background(), r, parquet.NewReaderProperties(mem), pqarrow.ArrowReadProperties{}, mem)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading Parquet file: %v", err)
+	}
+	defer tbl.Release()
+	rdr := array.NewTableReader(tbl, 0)
+	defer rdr.Release()
+	p := &pairs{rowField: rowField, colField: colField}
+	for rdr.Next() {
+		if err := p.add(rdr.Record()); err != nil {
+			return nil, err
+		}
+	}
+	return p.instance()
+}
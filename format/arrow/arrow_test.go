@@ -0,0 +1,126 @@
+package arrow
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	goarrow "github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+
+	"github.com/ifross89/gox/format"
+)
+
+// want is the instance held by the records of records
+var want = &format.Instance{
+	Primary: []string{"1", "2", "3"},
+	Rows: []format.Row{
+		{Name: "r1", Items: []string{"1", "2"}},
+		{Name: "r2", Items: []string{"3"}},
+		{Name: "r3", Items: []string{"1", "2"}},
+	},
+}
+
+// records returns two record batches of pairs, the names of the rows given
+// as a dictionary and the columns as integers
+func records(t *testing.T) []goarrow.Record {
+	mem := memory.NewGoAllocator()
+	schema := goarrow.NewSchema([]goarrow.Field{
+		{Name: "row_name", Type: &goarrow.DictionaryType{IndexType: goarrow.PrimitiveTypes.Int32, ValueType: goarrow.BinaryTypes.String}},
+		{Name: "column_id", Type: goarrow.PrimitiveTypes.Int64},
+	}, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	var recs []goarrow.Record
+	for _, batch := range []struct {
+		rows []string
+		cols []int64
+	}{
+		{[]string{"r1", "r1", "r2"}, []int64{1, 2, 3}},
+		{[]string{"r3", "r1", "r3"}, []int64{1, 1, 2}},
+	} {
+		rows := b.Field(0).(*array.BinaryDictionaryBuilder)
+		for _, row := range batch.rows {
+			if err := rows.AppendString(row); err != nil {
+				t.Fatalf("Error appending row: %v", err)
+			}
+		}
+		b.Field(1).(*array.Int64Builder).AppendValues(batch.cols, nil)
+		recs = append(recs, b.NewRecord())
+	}
+	t.Cleanup(func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	})
+	return recs
+}
+
+func TestFromRecords(t *testing.T) {
+	in, err := FromRecords(records(t), "row_name", "column_id")
+	if err != nil {
+		t.Fatalf("Error creating instance: %v", err)
+	}
+	if !reflect.DeepEqual(in, want) {
+		t.Fatalf("Expected %+v, got %+v", want, in)
+	}
+	if _, err := FromRecords(records(t), "row", "column_id"); err == nil {
+		t.Fatalf("Expected an error for a missing field")
+	}
+
+	// A column of another type is refused
+	schema := goarrow.NewSchema([]goarrow.Field{
+		{Name: "row_name", Type: goarrow.BinaryTypes.String},
+		{Name: "column_id", Type: goarrow.PrimitiveTypes.Float64},
+	}, nil)
+	b := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).Append("r1")
+	b.Field(1).(*array.Float64Builder).Append(1)
+	rec := b.NewRecord()
+	defer rec.Release()
+	if _, err := FromRecords([]goarrow.Record{rec}, "row_name", "column_id"); err == nil {
+		t.Fatalf("Expected an error for a column of floats")
+	}
+}
+
+func TestReadIPC(t *testing.T) {
+	recs := records(t)
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(recs[0].Schema()))
+	for _, rec := range recs {
+		if err := w.Write(rec); err != nil {
+			t.Fatalf("Error writing record: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing stream: %v", err)
+	}
+	in, err := ReadIPC(&buf, "row_name", "column_id")
+	if err != nil {
+		t.Fatalf("Error reading stream: %v", err)
+	}
+	if !reflect.DeepEqual(in, want) {
+		t.Fatalf("Expected %+v, got %+v", want, in)
+	}
+}
+
+func TestReadParquet(t *testing.T) {
+	recs := records(t)
+	tbl := array.NewTableFromRecords(recs[0].Schema(), recs)
+	defer tbl.Release()
+	var buf bytes.Buffer
+	if err := pqarrow.WriteTable(tbl, &buf, 2, nil, pqarrow.DefaultWriterProps()); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	in, err := ReadParquet(bytes.NewReader(buf.Bytes()), "row_name", "column_id")
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	if !reflect.DeepEqual(in, want) {
+		t.Fatalf("Expected %+v, got %+v", want, in)
+	}
+}
@@ -0,0 +1,258 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// columnRows returns the rows covering each column of the instance, by name,
+// along with the colour each gives to the column
+func columnRows(in *Instance) (map[string][]coveringRow, error) {
+	ret := make(map[string][]coveringRow)
+	for _, name := range in.Primary {
+		ret[name] = nil
+	}
+	for _, name := range in.Secondary {
+		ret[name] = nil
+	}
+	for i, r := range in.Rows {
+		for _, item := range r.Items {
+			col, color := splitItem(item)
+			if _, ok := ret[col]; !ok {
+				return nil, fmt.Errorf("Row %s refers to unknown column %s", r.Name, col)
+			}
+			ret[col] = append(ret[col], coveringRow{index: i, color: color})
+		}
+	}
+	return ret, nil
+}
+
+// coveringRow is a row covering a column, by its index in the instance
+type coveringRow struct {
+	index int
+	color string
+}
+
+// conflicts reports whether two rows covering the same column cannot both be
+// chosen, because they do not agree on its colour
+func (a coveringRow) conflicts(b coveringRow) bool {
+	return a.color == "" || a.color != b.color
+}
+
+// WriteCNF writes an instance as a boolean formula in conjunctive normal form,
+// in the DIMACS format read by SAT solvers. Variable i is true if the i-th row
+// is chosen, and comments at the start of the file name the rows. Each
+// primary column gives a clause requiring one of its rows to be chosen, and
+// each pair of rows which cannot both be chosen gives a clause forbidding it.
+// The satisfying assignments of the formula are the solutions of the
+// instance.
+func WriteCNF(w io.Writer, in *Instance) error {
+	cols, err := columnRows(in)
+	if err != nil {
+		return err
+	}
+
+	var clauses [][]int
+	for _, name := range in.Primary {
+		clause := []int{}
+		for _, r := range cols[name] {
+			clause = append(clause, r.index+1)
+		}
+		clauses = append(clauses, clause)
+	}
+	seen := make(map[[2]int]bool)
+	for _, names := range [][]string{in.Primary, in.Secondary} {
+		for _, name := range names {
+			rows := cols[name]
+			for i, a := range rows {
+				for _, b := range rows[i+1:] {
+					pair := [2]int{a.index, b.index}
+					if seen[pair] || !a.conflicts(b) {
+						continue
+					}
+					seen[pair] = true
+					clauses = append(clauses, []int{-(a.index + 1), -(b.index + 1)})
+				}
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, r := range in.Rows {
+		fmt.Fprintf(bw, "c %d %s\n", i+1, r.Name)
+	}
+	fmt.Fprintf(bw, "p cnf %d %d\n", len(in.Rows), len(clauses))
+	for _, clause := range clauses {
+		for _, lit := range clause {
+			fmt.Fprintf(bw, "%d ", lit)
+		}
+		fmt.Fprintln(bw, "0")
+	}
+	return bw.Flush()
+}
+
+// cnfClause is a clause read by ReadCNF, with the line it ends on
+type cnfClause struct {
+	lits []int
+	line int
+}
+
+// ReadCNF reads a formula in the DIMACS format and converts it to an
+// instance, if it is a conjunction of exactly-one and at-most-one
+// constraints, as WriteCNF writes. Variable i is the i-th row, named by a
+// comment "c i name" as WriteCNF writes them, or "x<i>" otherwise.
+//
+// Each clause must either hold only positive literals, or be two negative
+// literals. Clause k gives column "c<k>": a positive clause gives a primary
+// column covered by its variables, which must also be forbidden from being
+// true in pairs by negative clauses, so that exactly one of them is true. A
+// negative clause gives a secondary column covered by its two variables,
+// unless a positive clause already holds both. Any other formula is rejected
+// with an error saying why, as exact cover cannot express it.
+func ReadCNF(r io.Reader) (*Instance, error) {
+	names := make(map[int]string)
+	vars, declared := -1, 0
+	var clauses []cnfClause
+	var clause []int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "c":
+			if len(fields) >= 3 {
+				if v, err := strconv.Atoi(fields[1]); err == nil {
+					names[v] = strings.Join(fields[2:], " ")
+				}
+			}
+			continue
+		case "p":
+			if vars >= 0 {
+				return nil, fmt.Errorf("CNF must have one header line: line %d", line)
+			}
+			var err error
+			if len(fields) != 4 || fields[1] != "cnf" {
+				err = fmt.Errorf("expected p cnf <variables> <clauses>")
+			} else if vars, err = strconv.Atoi(fields[2]); err == nil {
+				declared, err = strconv.Atoi(fields[3])
+			}
+			if err != nil || vars < 0 || declared < 0 {
+				return nil, fmt.Errorf("Invalid CNF header %q: line %d", scanner.Text(), line)
+			}
+			continue
+		}
+		if vars < 0 {
+			return nil, fmt.Errorf("Clause before CNF header: line %d", line)
+		}
+
+		for _, f := range fields {
+			lit, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid literal %q: line %d", f, line)
+			}
+			if lit == 0 {
+				clauses = append(clauses, cnfClause{lits: clause, line: line})
+				clause = nil
+				continue
+			}
+			if lit > vars || -lit > vars {
+				return nil, fmt.Errorf("Literal %d refers to a variable after the %d declared: line %d", lit, vars, line)
+			}
+			clause = append(clause, lit)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	switch {
+	case vars < 0:
+		return nil, fmt.Errorf("CNF must have a header line")
+	case clause != nil:
+		return nil, fmt.Errorf("Last clause is not terminated by 0")
+	case len(clauses) != declared:
+		return nil, fmt.Errorf("CNF header declares %d clauses, but there are %d", declared, len(clauses))
+	}
+
+	// pairs holds the pairs of variables which may not both be true, and
+	// positive the variables of each positive clause, by clause, being nil
+	// for the negative clauses
+	pairs := make(map[[2]int]bool)
+	positive := make([][]int, len(clauses))
+	for k, c := range clauses {
+		// An empty clause gives a column no row covers, which like the
+		// clause cannot be satisfied
+		if len(c.lits) == 0 || c.lits[0] > 0 {
+			positive[k] = []int{}
+			seen := make(map[int]bool)
+			for _, lit := range c.lits {
+				if lit < 0 {
+					return nil, fmt.Errorf("Clause %d mixes positive and negative literals: line %d", k+1, c.line)
+				}
+				if !seen[lit] {
+					seen[lit] = true
+					positive[k] = append(positive[k], lit)
+				}
+			}
+			continue
+		}
+		if len(c.lits) != 2 || c.lits[1] > 0 || c.lits[0] == c.lits[1] {
+			return nil, fmt.Errorf("Clause %d is neither a positive clause nor two negative literals, so is not an exactly-one or at-most-one constraint: line %d", k+1, c.line)
+		}
+		pairs[cnfPair(-c.lits[0], -c.lits[1])] = true
+	}
+
+	// Pairs within a positive clause are covered by its column
+	grouped := make(map[[2]int]bool)
+	for k, vs := range positive {
+		for i, a := range vs {
+			for _, b := range vs[i+1:] {
+				pair := cnfPair(a, b)
+				if !pairs[pair] {
+					return nil, fmt.Errorf("Clause %d requires one of its variables to be true, but nothing stops variables %d and %d both being true, so it is not an exactly-one constraint: line %d",
+						k+1, a, b, clauses[k].line)
+				}
+				grouped[pair] = true
+			}
+		}
+	}
+
+	in := &Instance{Rows: make([]Row, vars)}
+	for v := 1; v <= vars; v++ {
+		name, ok := names[v]
+		if !ok {
+			name = fmt.Sprintf("x%d", v)
+		}
+		in.Rows[v-1].Name = name
+	}
+	for k, c := range clauses {
+		col := fmt.Sprintf("c%d", k+1)
+		vs := positive[k]
+		switch {
+		case vs != nil:
+			in.Primary = append(in.Primary, col)
+		case !grouped[cnfPair(-c.lits[0], -c.lits[1])]:
+			in.Secondary = append(in.Secondary, col)
+			// A repeated pair needs only one column
+			grouped[cnfPair(-c.lits[0], -c.lits[1])] = true
+			vs = []int{-c.lits[0], -c.lits[1]}
+		}
+		for _, v := range vs {
+			in.Rows[v-1].Items = append(in.Rows[v-1].Items, col)
+		}
+	}
+	return in, nil
+}
+
+// cnfPair returns the pair of two variables, smallest first
+func cnfPair(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
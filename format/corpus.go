@@ -0,0 +1,198 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/ifross89/gox"
+)
+
+// Case is an instance of a test corpus shared with exact cover libraries in
+// other languages, along with its solutions, so that the same files check
+// every implementation. Rows are named by their index, and are called options
+// as in Python libraries such as xcover, whose arguments and results a case
+// mirrors: the options are lists of items, a colour following a colon, and
+// each solution lists the indexes of its options.
+type Case struct {
+	Name     string
+	Instance *Instance
+	// Solutions holds every solution of the instance as the indexes of its
+	// rows, in canonical order, see CanonicalSolutions
+	Solutions [][]int
+}
+
+// corpusCase is the JSON representation of a case, such as
+//
+//	{
+//	  "name": "xcc",
+//	  "primary": ["p", "q", "r"],
+//	  "secondary": ["x", "y"],
+//	  "options": [["p", "q", "x", "y:A"], ["p", "r", "x:A", "y"], ["q", "x:A"]],
+//	  "solutions": [[1, 2]]
+//	}
+type corpusCase struct {
+	Name      string     `json:"name"`
+	Primary   []string   `json:"primary"`
+	Secondary []string   `json:"secondary,omitempty"`
+	Options   [][]string `json:"options"`
+	Solutions [][]int    `json:"solutions"`
+}
+
+// ReadCorpus reads the cases of a corpus file, which holds either a single
+// case or a JSON array of cases. The solutions of each case are put in
+// canonical order.
+func ReadCorpus(r io.Reader) ([]Case, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var cases []corpusCase
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		cases = make([]corpusCase, 1)
+		err = json.Unmarshal(data, &cases[0])
+	} else {
+		err = json.Unmarshal(data, &cases)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Case, len(cases))
+	for i, c := range cases {
+		in := &Instance{Primary: c.Primary, Secondary: c.Secondary, Rows: make([]Row, len(c.Options))}
+		for j, items := range c.Options {
+			in.Rows[j] = Row{Name: strconv.Itoa(j), Items: items}
+		}
+		for _, soln := range c.Solutions {
+			for _, j := range soln {
+				if j < 0 || j >= len(c.Options) {
+					return nil, fmt.Errorf("Solution of case %s refers to option %d of %d", c.Name, j, len(c.Options))
+				}
+			}
+		}
+		ret[i] = Case{Name: c.Name, Instance: in, Solutions: CanonicalSolutions(c.Solutions)}
+	}
+	return ret, nil
+}
+
+// WriteCorpus writes cases as a JSON array in the format read by ReadCorpus.
+// The names of the rows of the instances are not written, as rows are named
+// by their index.
+func WriteCorpus(w io.Writer, cases []Case) error {
+	out := make([]corpusCase, len(cases))
+	for i, c := range cases {
+		out[i] = corpusCase{
+			Name:      c.Name,
+			Primary:   c.Instance.Primary,
+			Secondary: c.Instance.Secondary,
+			Options:   make([][]string, len(c.Instance.Rows)),
+			Solutions: CanonicalSolutions(c.Solutions),
+		}
+		for j, r := range c.Instance.Rows {
+			out[i].Options[j] = append([]string{}, r.Items...)
+		}
+		if out[i].Solutions == nil {
+			out[i].Solutions = [][]int{}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// NewCase creates a case for an instance, finding its solutions with gox
+func NewCase(name string, in *Instance) (*Case, error) {
+	solns, err := solveCase(in)
+	if err != nil {
+		return nil, err
+	}
+	return &Case{Name: name, Instance: in, Solutions: solns}, nil
+}
+
+// Check solves the instance of the case with gox, returning an error
+// describing the difference if the solutions are not those of the case
+func (c *Case) Check() error {
+	got, err := solveCase(c.Instance)
+	if err != nil {
+		return fmt.Errorf("Error solving case %s: %v", c.Name, err)
+	}
+	want := CanonicalSolutions(c.Solutions)
+	missing, extra := diffSolutions(want, got)
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Case %s has %d solutions, gox found %d: missing %v, extra %v",
+		c.Name, len(want), len(got), missing, extra)
+}
+
+// solveCase returns the solutions of an instance as the indexes of their
+// rows, in canonical order
+func solveCase(in *Instance) ([][]int, error) {
+	prob, err := in.Problem()
+	if err != nil {
+		return nil, err
+	}
+	solns, err := prob.SolveIndices(context.Background())
+	var uncoverable *gox.UncoverableError
+	if errors.As(err, &uncoverable) {
+		// Other libraries search and find nothing
+		return [][]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return CanonicalSolutions(solns), nil
+}
+
+// CanonicalSolutions returns a copy of solutions given as the indexes of
+// their rows, with the indexes of each solution in increasing order and the
+// solutions in lexicographic order, so that solutions found in different
+// orders can be compared
+func CanonicalSolutions(solns [][]int) [][]int {
+	if solns == nil {
+		return nil
+	}
+	ret := make([][]int, len(solns))
+	for i, soln := range solns {
+		ret[i] = append([]int{}, soln...)
+		sort.Ints(ret[i])
+	}
+	sort.Slice(ret, func(i, j int) bool { return lessSolution(ret[i], ret[j]) })
+	return ret
+}
+
+// lessSolution orders solutions lexicographically by their indexes
+func lessSolution(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// diffSolutions returns the solutions in want but not got, and in got but not
+// want, both being in canonical order
+func diffSolutions(want, got [][]int) (missing, extra [][]int) {
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case j == len(got) || i < len(want) && lessSolution(want[i], got[j]):
+			missing = append(missing, want[i])
+			i++
+		case i == len(want) || lessSolution(got[j], want[i]):
+			extra = append(extra, got[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return missing, extra
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+// TestCorpus checks gox against every case of the corpus in
+// testdata/corpus, whose files may be shared with other implementations
+func TestCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected corpus files, got %v, %v", files, err)
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("Error opening %s: %v", name, err)
+		}
+		cases, err := ReadCorpus(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", name, err)
+		}
+		for _, c := range cases {
+			if err := c.Check(); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestCorpusRoundTrip(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading instance: %v", err)
+	}
+	c, err := NewCase("knuth", in)
+	if err != nil {
+		t.Fatalf("Error creating case: %v", err)
+	}
+	if want := [][]int{{1, 3}}; !reflect.DeepEqual(c.Solutions, want) {
+		t.Fatalf("Expected solutions %v, got %v", want, c.Solutions)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCorpus(&buf, []Case{*c}); err != nil {
+		t.Fatalf("Error writing corpus: %v", err)
+	}
+	cases, err := ReadCorpus(&buf)
+	if err != nil {
+		t.Fatalf("Error reading corpus: %v", err)
+	}
+	if len(cases) != 1 || cases[0].Name != "knuth" || !reflect.DeepEqual(cases[0].Solutions, c.Solutions) {
+		t.Fatalf("Expected case to round trip, got %+v", cases)
+	}
+	if err := cases[0].Check(); err != nil {
+		t.Fatalf("Error checking case: %v", err)
+	}
+
+	cases[0].Solutions = [][]int{{0, 2}}
+	if err := cases[0].Check(); err == nil || !strings.Contains(err.Error(), "missing [[0 2]], extra [[1 3]]") {
+		t.Fatalf("Expected check to find the wrong solution, got %v", err)
+	}
+
+	single := `{"name": "one", "primary": ["a"], "options": [["a"]], "solutions": [[0]]}`
+	if cases, err := ReadCorpus(strings.NewReader(single)); err != nil || len(cases) != 1 || cases[0].Check() != nil {
+		t.Fatalf("Expected single case, got %+v, %v", cases, err)
+	}
+	bad := `{"name": "bad", "primary": ["a"], "options": [["a"]], "solutions": [[1]]}`
+	if _, err := ReadCorpus(strings.NewReader(bad)); err == nil {
+		t.Fatal("Expected error reading solution with unknown option")
+	}
+}
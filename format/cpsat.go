@@ -0,0 +1,72 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCPSAT writes an instance as a CP-SAT model, the CpModelProto of
+// OR-Tools in the protobuf text format, which the CP-SAT solver reads with
+// its --input flag and its Go package with prototext.Unmarshal. Variable i is
+// the boolean for whether the i-th row is chosen, and is named after the row.
+// Each primary column gives an exactly_one constraint over its rows, and each
+// secondary column an at_most_one constraint. For a secondary column given
+// colours, there is a boolean variable for each colour: a row giving the
+// colour is only chosen if it is set, and the colours and the rows giving no
+// colour are at most one. There is nothing to optimise, so the model has no
+// objective, but one can be added to the model before it is solved.
+func WriteCPSAT(w io.Writer, in *Instance) error {
+	cols, err := columnRows(in)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d rows, %d primary and %d secondary columns\n", len(in.Rows), len(in.Primary), len(in.Secondary))
+	for _, r := range in.Rows {
+		fmt.Fprintf(bw, "variables { name: %s domain: [0, 1] }\n", strconv.Quote(r.Name))
+	}
+
+	next := len(in.Rows)
+	var constraints []string
+	for j, name := range append(append([]string(nil), in.Primary...), in.Secondary...) {
+		primary := j < len(in.Primary)
+		var literals []string
+		// colors gives the variable of each colour of the column
+		colors := make(map[string]int)
+		for _, r := range cols[name] {
+			if r.color == "" {
+				literals = append(literals, strconv.Itoa(r.index))
+				continue
+			}
+			v, ok := colors[r.color]
+			if !ok {
+				v = next
+				next++
+				colors[r.color] = v
+				fmt.Fprintf(bw, "variables { name: %s domain: [0, 1] }\n", strconv.Quote(name+":"+r.color))
+				literals = append(literals, strconv.Itoa(v))
+			}
+			constraints = append(constraints, fmt.Sprintf("constraints { name: %s enforcement_literal: %d bool_and { literals: %d } }",
+				strconv.Quote(name+":"+r.color+" "+in.Rows[r.index].Name), r.index, v))
+		}
+
+		switch {
+		case primary:
+			// An empty exactly_one cannot be satisfied, as no row covers
+			// the column
+			constraints = append(constraints, fmt.Sprintf("constraints { name: %s exactly_one { literals: [%s] } }",
+				strconv.Quote(name), strings.Join(literals, ", ")))
+		case len(literals) > 1:
+			constraints = append(constraints, fmt.Sprintf("constraints { name: %s at_most_one { literals: [%s] } }",
+				strconv.Quote(name), strings.Join(literals, ", ")))
+		}
+	}
+	for _, c := range constraints {
+		fmt.Fprintln(bw, c)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,110 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// separator is the heading which separates the primary columns from the
+// secondary columns, as in the DLX format
+const separator = "|"
+
+// ReadCSV reads an instance from a CSV file. The first line holds the names of
+// the columns after a heading for the row names, which is ignored. The primary
+// columns are followed by the secondary columns, separated by a column headed
+// "|" which is otherwise left empty, e.g.
+//
+//	row,a,b,|,s
+//	R1,1,0,,A
+//	R2,0,1,,
+//
+// Each following line gives the name of a row and then its cells. An empty
+// cell or 0 means the row does not cover the column and 1 means it does. Any
+// other value is the colour the row gives to a secondary column.
+func ReadCSV(r io.Reader) (*Instance, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV must have a heading line")
+	}
+
+	in := &Instance{}
+	heading := records[0]
+	sep := -1
+	for i, name := range heading[1:] {
+		switch {
+		case name == separator && sep < 0:
+			sep = i + 1
+		case sep < 0:
+			in.Primary = append(in.Primary, name)
+		default:
+			in.Secondary = append(in.Secondary, name)
+		}
+	}
+
+	for line, record := range records[1:] {
+		row := Row{Name: record[0]}
+		for i, cell := range record[1:] {
+			col := heading[i+1]
+			switch {
+			case i+1 == sep:
+				if cell != "" {
+					return nil, fmt.Errorf("Separator column must be empty: line %d", line+2)
+				}
+			case cell == "" || cell == "0":
+			case cell == "1":
+				row.Items = append(row.Items, col)
+			default:
+				row.Items = append(row.Items, col+":"+cell)
+			}
+		}
+		in.Rows = append(in.Rows, row)
+	}
+	return in, nil
+}
+
+// WriteCSV writes an instance in the format read by ReadCSV
+func WriteCSV(w io.Writer, in *Instance) error {
+	heading := append([]string{"row"}, in.Primary...)
+	if len(in.Secondary) > 0 {
+		heading = append(heading, separator)
+		heading = append(heading, in.Secondary...)
+	}
+	index := make(map[string]int)
+	for i, name := range heading[1:] {
+		index[name] = i + 1
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(heading); err != nil {
+		return err
+	}
+	for _, r := range in.Rows {
+		record := make([]string, len(heading))
+		record[0] = r.Name
+		for _, item := range r.Items {
+			col, color := splitItem(item)
+			i, ok := index[col]
+			if !ok || col == separator {
+				return fmt.Errorf("Row %s refers to unknown column %s", r.Name, col)
+			}
+			record[i] = "1"
+			if color != "" {
+				record[i] = color
+			}
+		}
+		for i := 1; i < len(record); i++ {
+			if record[i] == "" && heading[i] != separator {
+				record[i] = "0"
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,100 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadDLX reads an instance in the format used by Knuth's dlx and xcc
+// programs. The first line lists the primary columns, then "|" and the
+// secondary columns. Each following line lists the items of a row. Lines
+// starting with "|" are comments. For example
+//
+//	| A comment
+//	a b | s
+//	a s:A
+//	b
+//
+// Rows have no names in this format, so each row is named after its items
+// separated by single spaces, e.g. "a s:A".
+func ReadDLX(r io.Reader) (*Instance, error) {
+	in := &Instance{}
+	headed := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], separator) {
+			continue
+		}
+
+		if !headed {
+			headed = true
+			secondary := false
+			for _, f := range fields {
+				switch {
+				case f == separator && !secondary:
+					secondary = true
+				case secondary:
+					in.Secondary = append(in.Secondary, f)
+				default:
+					in.Primary = append(in.Primary, f)
+				}
+			}
+			continue
+		}
+
+		for _, f := range fields {
+			if f == separator {
+				return nil, fmt.Errorf("Unexpected %q in row: line %d", separator, line)
+			}
+		}
+		in.Rows = append(in.Rows, Row{Name: strings.Join(fields, " "), Items: fields})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !headed {
+		return nil, fmt.Errorf("DLX must have a line listing the columns")
+	}
+	return in, nil
+}
+
+// WriteDLX writes an instance in the format read by ReadDLX. The names of the
+// rows are not written. Names of columns and colours must not contain spaces.
+func WriteDLX(w io.Writer, in *Instance) error {
+	bw := bufio.NewWriter(w)
+	check := func(s string) error {
+		if s == "" || strings.ContainsAny(s, " \t\n") || strings.HasPrefix(s, separator) {
+			return fmt.Errorf("Cannot write %q in DLX format", s)
+		}
+		return nil
+	}
+
+	cols := append([]string(nil), in.Primary...)
+	if len(in.Secondary) > 0 {
+		cols = append(cols, separator)
+		cols = append(cols, in.Secondary...)
+	}
+	for i, c := range cols {
+		if c == separator && i == len(in.Primary) {
+			continue
+		}
+		if err := check(c); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(bw, strings.Join(cols, " "))
+
+	for _, r := range in.Rows {
+		for _, item := range r.Items {
+			if err := check(item); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(bw, strings.Join(r.Items, " "))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,178 @@
+// Package format reads and writes exact cover problems in the interchange
+// formats supported by gox:
+//
+//   - CSV, a matrix with one row of the problem per line
+//   - JSON, the columns and the items of each row as a JSON object
+//   - DLX, the text format read by Knuth's dlx and xcc programs
+//
+// Instances may also be exported, but not read, as CNF for SAT solvers and LP
+// for integer programming solvers.
+//
+// Test corpora of instances and their solutions, shared with exact cover
+// libraries in other languages, are read and written by ReadCorpus and
+// WriteCorpus.
+//
+// Each format is read into an Instance, which holds the names of the columns
+// and the items of each row in the same way as gox.Builder, so that an
+// instance can be converted to a problem or written in another format.
+package format
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+// Format identifies an interchange format
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+	DLX  Format = "dlx"
+	// CNF, LP and CPSAT are export formats, written for other solvers. Only
+	// CNF can be read, see ReadCNF.
+	CNF   Format = "cnf"
+	LP    Format = "lp"
+	CPSAT Format = "cpsat"
+)
+
+// Formats lists the supported formats which can be read and written
+var Formats = []Format{CSV, JSON, DLX}
+
+// Exports lists the formats which are written so that an instance can be
+// solved by other tools. They can only be read if they are also in Imports.
+var Exports = []Format{CNF, LP, CPSAT}
+
+// Imports lists the export formats which can be read, if what is read can be
+// expressed as an exact cover problem
+var Imports = []Format{CNF}
+
+// Parse returns the format with the given name, which may be an export format
+func Parse(name string) (Format, error) {
+	for _, fs := range [][]Format{Formats, Exports} {
+		for _, f := range fs {
+			if string(f) == strings.ToLower(name) {
+				return f, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Unknown format: %s", name)
+}
+
+// Readable reports whether instances can be read in the format, rather than
+// only written
+func (f Format) Readable() bool {
+	for _, fs := range [][]Format{Formats, Imports} {
+		for _, g := range fs {
+			if f == g {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FromFilename returns the format of a file from its extension, e.g. ".csv"
+func FromFilename(filename string) (Format, error) {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return "", fmt.Errorf("Cannot determine format of file without extension: %s", filename)
+	}
+	return Parse(ext[1:])
+}
+
+// Instance is an exact cover problem in a form which can be read and written
+type Instance struct {
+	// Primary and Secondary are the names of the columns, see
+	// gox.Builder.AddColumns and gox.Builder.AddSecondaryColumns
+	Primary   []string
+	Secondary []string
+	Rows      []Row
+}
+
+// Row is a named row of an instance. Each item names a column, or for
+// secondary columns may be followed by a colon and a colour, as accepted by
+// gox.Builder.AddRow.
+type Row struct {
+	Name  string
+	Items []string
+}
+
+// Builder creates a builder holding the columns and rows of the instance
+func (in *Instance) Builder() (*gox.Builder, error) {
+	b := gox.NewBuilder()
+	if err := b.AddColumns(in.Primary...); err != nil {
+		return nil, err
+	}
+	if err := b.AddSecondaryColumns(in.Secondary...); err != nil {
+		return nil, err
+	}
+	for _, r := range in.Rows {
+		if err := b.AddRow(r.Name, r.Items...); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Problem creates the exact cover problem for the instance
+func (in *Instance) Problem() (gox.Solver, error) {
+	b, err := in.Builder()
+	if err != nil {
+		return nil, err
+	}
+	prob, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// splitItem splits an item into the name of its column and its colour, which
+// is empty if the item has no colour
+func splitItem(item string) (col, color string) {
+	if i := strings.Index(item, ":"); i >= 0 {
+		return item[:i], item[i+1:]
+	}
+	return item, ""
+}
+
+// Read reads an instance in the given format
+func Read(r io.Reader, f Format) (*Instance, error) {
+	switch f {
+	case CSV:
+		return ReadCSV(r)
+	case JSON:
+		return ReadJSON(r)
+	case DLX:
+		return ReadDLX(r)
+	case CNF:
+		return ReadCNF(r)
+	case LP, CPSAT:
+		return nil, fmt.Errorf("Format %s can only be written", f)
+	}
+	return nil, fmt.Errorf("Unknown format: %s", f)
+}
+
+// Write writes an instance in the given format
+func Write(w io.Writer, f Format, in *Instance) error {
+	switch f {
+	case CSV:
+		return WriteCSV(w, in)
+	case JSON:
+		return WriteJSON(w, in)
+	case DLX:
+		return WriteDLX(w, in)
+	case CNF:
+		return WriteCNF(w, in)
+	case LP:
+		return WriteLP(w, in)
+	case CPSAT:
+		return WriteCPSAT(w, in)
+	}
+	return fmt.Errorf("Unknown format: %s", f)
+}
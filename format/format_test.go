@@ -0,0 +1,332 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+func solve(t *testing.T, in *Instance) []string {
+	prob, err := in.Problem()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	var ret []string
+	for _, s := range prob.Solve() {
+		sort.Strings(s)
+		ret = append(ret, strings.Join(s, ", "))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestReadDLX(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading DLX: %v", err)
+	}
+	if !reflect.DeepEqual(in.Primary, []string{"p", "q", "r"}) || !reflect.DeepEqual(in.Secondary, []string{"x", "y"}) {
+		t.Fatalf("Unexpected columns: %q, %q", in.Primary, in.Secondary)
+	}
+	expected := []string{"p r x:A y, q x:A"}
+	if solns := solve(t, in); !reflect.DeepEqual(solns, expected) {
+		t.Fatalf("Expected solutions %q, got %q", expected, solns)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading DLX: %v", err)
+	}
+	for _, f := range Formats {
+		var buf bytes.Buffer
+		if err := Write(&buf, f, in); err != nil {
+			t.Fatalf("Error writing %s: %v", f, err)
+		}
+		read, err := Read(&buf, f)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", f, err)
+		}
+		if !reflect.DeepEqual(in, read) {
+			t.Fatalf("Instance changed by writing and reading %s: %+v, %+v", f, in, read)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	in, err := ReadCSV(strings.NewReader("row,a,b,|,s\nR1,1,0,,A\nR2,0,1,,\nR3,1,1,,1\n"))
+	if err != nil {
+		t.Fatalf("Error reading CSV: %v", err)
+	}
+	expected := &Instance{
+		Primary:   []string{"a", "b"},
+		Secondary: []string{"s"},
+		Rows: []Row{
+			Row{"R1", []string{"a", "s:A"}},
+			Row{"R2", []string{"b"}},
+			Row{"R3", []string{"a", "b", "s"}},
+		},
+	}
+	if !reflect.DeepEqual(in, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, in)
+	}
+	if solns := solve(t, in); !reflect.DeepEqual(solns, []string{"R1, R2", "R3"}) {
+		t.Fatalf("Unexpected solutions: %q", solns)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("row,a,|,s\nR1,1,1,0\n")); err == nil {
+		t.Fatal("Expected error for value in separator column")
+	}
+}
+
+func TestFromFilename(t *testing.T) {
+	for name, expected := range map[string]Format{"a.csv": CSV, "dir/b.JSON": JSON, "c.dlx": DLX} {
+		if f, err := FromFilename(name); err != nil || f != expected {
+			t.Fatalf("Expected %s for %s, got %s: %v", expected, name, f, err)
+		}
+	}
+	for _, name := range []string{"a", "a.txt"} {
+		if _, err := FromFilename(name); err == nil {
+			t.Fatalf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestWriteDLXErrors(t *testing.T) {
+	in := &Instance{Primary: []string{"a b"}}
+	if err := WriteDLX(&bytes.Buffer{}, in); err == nil {
+		t.Fatal("Expected error writing column containing a space")
+	}
+}
+
+func TestWriteCNF(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading DLX: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, CNF, in); err != nil {
+		t.Fatalf("Error writing CNF: %v", err)
+	}
+
+	// Read the formula back and check its satisfying assignments are the
+	// solutions of the instance
+	var clauses [][]int
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "c":
+			continue
+		case "p":
+			expected := fmt.Sprintf("p cnf %d", len(in.Rows))
+			if !strings.HasPrefix(line, expected) {
+				t.Fatalf("Expected header starting %q, got %q", expected, line)
+			}
+			continue
+		}
+		var clause []int
+		for _, f := range fields {
+			lit, err := strconv.Atoi(f)
+			if err != nil {
+				t.Fatalf("Invalid literal in %q", line)
+			}
+			if lit != 0 {
+				clause = append(clause, lit)
+			}
+		}
+		clauses = append(clauses, clause)
+	}
+
+	var solns []string
+	for set := 0; set < 1<<len(in.Rows); set++ {
+		satisfied := true
+		for _, clause := range clauses {
+			sat := false
+			for _, lit := range clause {
+				chosen := set&(1<<(abs(lit)-1)) != 0
+				if chosen == (lit > 0) {
+					sat = true
+				}
+			}
+			satisfied = satisfied && sat
+		}
+		if !satisfied {
+			continue
+		}
+		var rows []string
+		for i, r := range in.Rows {
+			if set&(1<<i) != 0 {
+				rows = append(rows, r.Name)
+			}
+		}
+		sort.Strings(rows)
+		solns = append(solns, strings.Join(rows, ", "))
+	}
+	if expected := solve(t, in); !reflect.DeepEqual(solns, expected) {
+		t.Fatalf("Expected satisfying assignments %q, got %q", expected, solns)
+	}
+}
+
+func TestReadCNF(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading DLX: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, CNF, in); err != nil {
+		t.Fatalf("Error writing CNF: %v", err)
+	}
+	read, err := Read(&buf, CNF)
+	if err != nil {
+		t.Fatalf("Error reading CNF: %v", err)
+	}
+	// The columns are renamed after the clauses, but the rows keep their
+	// names and the solutions are the same
+	if got, want := solve(t, read), solve(t, in); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected solutions %q, got %q", want, got)
+	}
+
+	read, err = ReadCNF(strings.NewReader("c a comment\np cnf 3 4\n1 2 0\n-1 -2 0\n-2\n-3 0\n0\n"))
+	if err != nil {
+		t.Fatalf("Error reading CNF: %v", err)
+	}
+	want := &Instance{
+		Primary:   []string{"c1", "c4"},
+		Secondary: []string{"c3"},
+		Rows: []Row{
+			{Name: "x1", Items: []string{"c1"}},
+			{Name: "x2", Items: []string{"c1", "c3"}},
+			{Name: "x3", Items: []string{"c3"}},
+		},
+	}
+	if !reflect.DeepEqual(read, want) {
+		t.Fatalf("Expected %+v, got %+v", want, read)
+	}
+
+	for _, text := range []string{
+		"",
+		"1 0\n",
+		"p cnf 2 1\n1 2 0\n",
+		"p cnf 2 1\n1 -2 0\n",
+		"p cnf 2 1\n-1 0\n",
+		"p cnf 3 1\n-1 -2 -3 0\n",
+		"p cnf 2 1\n3 0\n",
+		"p cnf 2 2\n1 2 0\n",
+		"p cnf 2 1\n1 2\n",
+	} {
+		if _, err := ReadCNF(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error reading %q", text)
+		}
+	}
+}
+
+func TestFromPairs(t *testing.T) {
+	in, err := FromPairs(
+		[]string{"r1", "r1", "r2", "r3", "r1", "r3"},
+		[]string{"a", "b", "c", "a", "a", "b"},
+	)
+	if err != nil {
+		t.Fatalf("Error creating instance: %v", err)
+	}
+	want := &Instance{
+		Primary: []string{"a", "b", "c"},
+		Rows: []Row{
+			{Name: "r1", Items: []string{"a", "b"}},
+			{Name: "r2", Items: []string{"c"}},
+			{Name: "r3", Items: []string{"a", "b"}},
+		},
+	}
+	if !reflect.DeepEqual(in, want) {
+		t.Fatalf("Expected %+v, got %+v", want, in)
+	}
+	if got := solve(t, in); !reflect.DeepEqual(got, []string{"r1, r2", "r2, r3"}) {
+		t.Fatalf("Expected two solutions, got %q", got)
+	}
+	if _, err := FromPairs([]string{"r1"}, nil); err == nil {
+		t.Fatalf("Expected an error with fewer columns than rows")
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func TestWriteLP(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading DLX: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, LP, in); err != nil {
+		t.Fatalf("Error writing LP: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`\ x2 p r x:A y`,
+		" c1: x1 + x2 + x3 = 1",
+		" c4_2: x2 - y4_1 <= 0",
+		" c4: x1 + y4_1 + y4_2 <= 1",
+		" c5: y5_1 + x2 + y5_2 <= 1",
+		" y5_1",
+		"End",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("Expected line %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestWriteCPSAT(t *testing.T) {
+	in, err := ReadDLX(strings.NewReader(testutil.Knuth.DLX()))
+	if err != nil {
+		t.Fatalf("Error reading DLX: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, CPSAT, in); err != nil {
+		t.Fatalf("Error writing CP-SAT: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`variables { name: "p r x:A y" domain: [0, 1] }`,
+		`variables { name: "x:A" domain: [0, 1] }`,
+		`constraints { name: "p" exactly_one { literals: [0, 1, 2] } }`,
+		`constraints { name: "x:A p r x:A y" enforcement_literal: 1 bool_and { literals: 5 } }`,
+		`constraints { name: "x" at_most_one { literals: [0, 5, 6] } }`,
+		`constraints { name: "y" at_most_one { literals: [7, 1, 8] } }`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("Expected line %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	for _, f := range Exports {
+		// Only CNF can be read back, see TestReadCNF
+		if f.Readable() != (f == CNF) {
+			t.Fatalf("Expected %s readable to be %t", f, f == CNF)
+		}
+		if _, err := Read(strings.NewReader(""), f); err == nil {
+			t.Fatalf("Expected error reading %s", f)
+		}
+		if g, err := FromFilename("a." + string(f)); err != nil || g != f {
+			t.Fatalf("Expected format %s, got %s, %v", f, g, err)
+		}
+	}
+	in := &Instance{Primary: []string{"a"}, Rows: []Row{{Name: "r", Items: []string{"b"}}}}
+	for _, f := range Exports {
+		if err := Write(&bytes.Buffer{}, f, in); err == nil {
+			t.Fatalf("Expected error writing %s with an unknown column", f)
+		}
+	}
+}
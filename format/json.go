@@ -0,0 +1,51 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonInstance is the JSON representation of an instance
+type jsonInstance struct {
+	Primary   []string  `json:"primary"`
+	Secondary []string  `json:"secondary,omitempty"`
+	Rows      []jsonRow `json:"rows"`
+}
+
+type jsonRow struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+// ReadJSON reads an instance from a JSON object such as
+//
+//	{
+//	  "primary": ["a", "b"],
+//	  "secondary": ["s"],
+//	  "rows": [
+//	    {"name": "R1", "items": ["a", "s:A"]},
+//	    {"name": "R2", "items": ["b"]}
+//	  ]
+//	}
+func ReadJSON(r io.Reader) (*Instance, error) {
+	var j jsonInstance
+	if err := json.NewDecoder(r).Decode(&j); err != nil {
+		return nil, err
+	}
+	in := &Instance{Primary: j.Primary, Secondary: j.Secondary}
+	for _, r := range j.Rows {
+		in.Rows = append(in.Rows, Row{Name: r.Name, Items: r.Items})
+	}
+	return in, nil
+}
+
+// WriteJSON writes an instance in the format read by ReadJSON
+func WriteJSON(w io.Writer, in *Instance) error {
+	j := jsonInstance{Primary: in.Primary, Secondary: in.Secondary, Rows: []jsonRow{}}
+	for _, r := range in.Rows {
+		j.Rows = append(j.Rows, jsonRow{Name: r.Name, Items: r.Items})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j)
+}
@@ -0,0 +1,79 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteLP writes an instance as a 0-1 integer program in the CPLEX LP format,
+// read by most integer programming solvers. Variable x<i> is 1 if the i-th row
+// is chosen, and comments at the start of the file name the rows. The
+// constraint for each primary column requires its rows to sum to 1, and for
+// each secondary column to at most 1. For a secondary column given colours,
+// variable y<j>_<k> is 1 if the column takes its k-th colour: a row giving
+// the colour may only be chosen if it is, and the colours and the rows giving
+// no colour sum to at most 1. There is nothing to optimise, so the objective
+// is zero.
+func WriteLP(w io.Writer, in *Instance) error {
+	cols, err := columnRows(in)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, r := range in.Rows {
+		fmt.Fprintf(bw, "\\ x%d %s\n", i+1, r.Name)
+	}
+	fmt.Fprintln(bw, "Minimize")
+	fmt.Fprint(bw, " obj:")
+	for i := range in.Rows {
+		fmt.Fprintf(bw, " + 0 x%d", i+1)
+	}
+	fmt.Fprintln(bw)
+
+	fmt.Fprintln(bw, "Subject To")
+	var colorVars []string
+	for j, name := range append(append([]string(nil), in.Primary...), in.Secondary...) {
+		primary := j < len(in.Primary)
+		var terms []string
+		// colors gives the index of each colour of the column
+		colors := make(map[string]int)
+		for _, r := range cols[name] {
+			if r.color == "" {
+				terms = append(terms, fmt.Sprintf("x%d", r.index+1))
+				continue
+			}
+			k, ok := colors[r.color]
+			if !ok {
+				k = len(colors) + 1
+				colors[r.color] = k
+				y := fmt.Sprintf("y%d_%d", j+1, k)
+				colorVars = append(colorVars, y)
+				terms = append(terms, y)
+			}
+			fmt.Fprintf(bw, " c%d_%d: x%d - y%d_%d <= 0\n", j+1, r.index+1, r.index+1, j+1, k)
+		}
+
+		switch {
+		case primary && len(terms) == 0:
+			// No row covers the column, so the problem is infeasible
+			fmt.Fprintf(bw, " c%d: 0 x1 = 1\n", j+1)
+		case primary:
+			fmt.Fprintf(bw, " c%d: %s = 1\n", j+1, strings.Join(terms, " + "))
+		case len(terms) > 1:
+			fmt.Fprintf(bw, " c%d: %s <= 1\n", j+1, strings.Join(terms, " + "))
+		}
+	}
+
+	fmt.Fprintln(bw, "Binary")
+	for i := range in.Rows {
+		fmt.Fprintf(bw, " x%d\n", i+1)
+	}
+	for _, y := range colorVars {
+		fmt.Fprintf(bw, " %s\n", y)
+	}
+	fmt.Fprintln(bw, "End")
+	return bw.Flush()
+}
@@ -0,0 +1,40 @@
+package format
+
+import "fmt"
+
+// FromPairs creates an instance from the cells of the matrix given as pairs
+// of a row name and a column name, rows[i] covering cols[i], as held by the
+// two columns of a table in long form, such as an Arrow record batch or a
+// Parquet file read by a data pipeline, see the format/arrow package. The rows
+// and columns are ordered by their first appearance, and every column is
+// primary. A pair may appear more than once.
+func FromPairs(rows, cols []string) (*Instance, error) {
+	if len(rows) != len(cols) {
+		return nil, fmt.Errorf("Expected as many columns as rows, got %d rows and %d columns", len(rows), len(cols))
+	}
+	in := &Instance{}
+	rowIndex := make(map[string]int)
+	colSeen := make(map[string]bool)
+	cellSeen := make(map[[2]string]bool)
+	for i, row := range rows {
+		col := cols[i]
+		if col == "" {
+			return nil, fmt.Errorf("Empty column name for row %s: pair %d", row, i)
+		}
+		if !colSeen[col] {
+			colSeen[col] = true
+			in.Primary = append(in.Primary, col)
+		}
+		r, ok := rowIndex[row]
+		if !ok {
+			r = len(in.Rows)
+			rowIndex[row] = r
+			in.Rows = append(in.Rows, Row{Name: row})
+		}
+		if cell := [2]string{row, col}; !cellSeen[cell] {
+			cellSeen[cell] = true
+			in.Rows[r].Items = append(in.Rows[r].Items, col)
+		}
+	}
+	return in, nil
+}
@@ -0,0 +1,137 @@
+package gox_test
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/internal/testutil"
+	"github.com/ifross89/gox/testgen"
+)
+
+// checker is implemented by the problems created by gox
+type checker interface {
+	CheckInvariants() error
+}
+
+// FuzzSolve generates a problem, preprocesses it with the options chosen by
+// the bits of opts, gives some of its planted rows and solves it, checking
+// every solution and the matrix afterwards
+func FuzzSolve(f *testing.F) {
+	f.Add(int64(1), uint8(10), uint8(6), uint8(0), uint8(0), uint8(30), uint8(0), true, uint8(0), uint8(0))
+	f.Add(int64(2), uint8(12), uint8(8), uint8(2), uint8(2), uint8(40), uint8(1), true, uint8(1), uint8(7))
+	f.Add(int64(3), uint8(15), uint8(9), uint8(0), uint8(0), uint8(20), uint8(2), false, uint8(0), uint8(6))
+	f.Add(int64(4), uint8(8), uint8(5), uint8(1), uint8(3), uint8(50), uint8(3), false, uint8(0), uint8(1))
+	f.Fuzz(func(t *testing.T, seed int64, rows, cols, secondary, colors, density, pattern uint8, planted bool, givens, opts uint8) {
+		c := testgen.Config{
+			Rows:      int(rows % 16),
+			Columns:   1 + int(cols%10),
+			Secondary: int(secondary % 4),
+			Colors:    int(colors % 4),
+			Density:   float64(density%101) / 100,
+			Planted:   planted,
+			Pattern:   testgen.Patterns[int(pattern)%len(testgen.Patterns)],
+		}
+		inst, err := testgen.Generate(rand.New(rand.NewSource(seed)), c)
+		if err != nil {
+			t.Fatalf("Error generating %+v: %v", c, err)
+		}
+
+		options := []gox.ProblemOption{gox.WithDebug()}
+		if opts&1 != 0 {
+			options = append(options, gox.WithMergeDuplicates())
+		}
+		if opts&2 != 0 {
+			options = append(options, gox.WithDominatedRows())
+		}
+		if opts&4 != 0 {
+			options = append(options, gox.WithForcedRows())
+		}
+		prob, err := inst.Problem(options...)
+		if err != nil {
+			t.Fatalf("Error creating problem: %v", err)
+		}
+
+		// Forced rows may already include some of the planted rows, perhaps
+		// under the name of a duplicate, so rows are only given without them
+		if opts&4 == 0 {
+			for i := 0; i < int(givens) && i < len(inst.Planted); i++ {
+				if err := prob.RowIsSolution(inst.Planted[i]); err != nil {
+					t.Fatalf("Error giving planted row %s: %v", inst.Planted[i], err)
+				}
+			}
+		}
+
+		solns, err := prob.SolveContext(context.Background(), gox.WithLimit(100))
+		if _, ok := err.(*gox.UncoverableError); ok && !planted {
+			err = nil
+		}
+		if err != nil {
+			t.Fatalf("Error solving: %v", err)
+		}
+		if planted && len(solns) == 0 {
+			t.Fatal("Expected a solution to a planted problem")
+		}
+		for _, soln := range solns {
+			if err := prob.Verify(soln); err != nil {
+				t.Fatalf("Invalid solution %q: %v", soln, err)
+			}
+		}
+		if err := prob.(checker).CheckInvariants(); err != nil {
+			t.Fatalf("Invariant broken after solving: %v", err)
+		}
+	})
+}
+
+// FuzzNewExactCoverProblem creates a problem from a matrix given by the bits of
+// data, checking that construction never panics and that the solutions match
+// those found by brute force
+func FuzzNewExactCoverProblem(f *testing.F) {
+	f.Add(uint8(4), []byte{0x9, 0x7, 0xa, 0xc, 0x8})
+	f.Add(uint8(1), []byte{})
+	f.Add(uint8(7), []byte{0x0, 0x0, 0x7f, 0x1})
+	f.Fuzz(func(t *testing.T, cols uint8, data []byte) {
+		if len(data) > testutil.MaxRows {
+			data = data[:testutil.MaxRows]
+		}
+		m := make([][]bool, len(data))
+		names := make([]string, len(data))
+		for i, b := range data {
+			m[i] = make([]bool, cols%9)
+			for j := range m[i] {
+				m[i][j] = b&(1<<j) != 0
+			}
+			names[i] = strconv.Itoa(i)
+		}
+		prob, err := gox.NewExactCoverProblem(m, names, gox.WithDebug())
+		if err != nil {
+			if _, ok := err.(*gox.InputError); !ok {
+				t.Fatalf("Expected *InputError, got %v", err)
+			}
+			return
+		}
+
+		solns, err := prob.SolveContext(context.Background())
+		if _, ok := err.(*gox.UncoverableError); ok {
+			err = nil
+		}
+		if err != nil {
+			t.Fatalf("Error solving: %v", err)
+		}
+		expected, err := testutil.BruteForce(m, names)
+		if err != nil {
+			t.Fatalf("Error solving by brute force: %v", err)
+		}
+		got := testutil.Canonical(solns)
+		if len(got) != len(expected) {
+			t.Fatalf("Expected solutions %q, got %q", expected, got)
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("Expected solutions %q, got %q", expected, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestApplyGivens(t *testing.T) {
+	prob := dominoProblem(t, 6)
+	want, _ := prob.SolveContext(context.Background(), WithSortedOutput())
+	each := dominoProblem(t, 6)
+	for _, name := range []string{"v4", "h0,0", "h1,0"} {
+		if err := each.RowIsSolution(name); err != nil {
+			t.Fatalf("Error giving %s: %v", name, err)
+		}
+	}
+	if err := prob.ApplyGivens([]string{"v4", "h0,0", "h1,0"}); err != nil {
+		t.Fatalf("Error giving rows: %v", err)
+	}
+	if current := prob.CurrentSolution(); !reflect.DeepEqual(current, []string{"v4", "h0,0", "h1,0"}) {
+		t.Fatalf("Unexpected rows given %v", current)
+	}
+	got, _ := prob.SolveContext(context.Background(), WithSortedOutput())
+	wantGiven, _ := each.SolveContext(context.Background(), WithSortedOutput())
+	if len(got) != 2 || len(got) >= len(want) || !reflect.DeepEqual(got, wantGiven) {
+		t.Fatalf("Expected %v, got %v", wantGiven, got)
+	}
+
+	// Every problem is reported, and nothing is given
+	for _, tc := range []struct {
+		names []string
+		rows  []int
+	}{
+		{[]string{"v2", "missing", "v3", "nope"}, []int{1, 3}},
+		{[]string{"v2", "v1"}, []int{1}},
+		{[]string{"v2", "v2"}, []int{1}},
+		{[]string{"v2", "h0,2"}, []int{1}},
+		{[]string{"h0,2", "v3", "h1,2"}, []int{1}},
+	} {
+		err := prob.ApplyGivens(tc.names)
+		inputErr, ok := err.(*InputError)
+		if !ok {
+			t.Fatalf("Expected an *InputError giving %v, got %v", tc.names, err)
+		}
+		var rows []int
+		for _, problem := range inputErr.Problems {
+			rows = append(rows, problem.Row)
+		}
+		if !reflect.DeepEqual(rows, tc.rows) {
+			t.Fatalf("Expected problems with %v giving %v, got %v", tc.rows, tc.names, err)
+		}
+		if current := prob.CurrentSolution(); len(current) != 3 {
+			t.Fatalf("Expected no rows to be given, got %v", current)
+		}
+	}
+
+	// Rows giving a secondary column the same colour may be given together
+	b := NewBuilder()
+	b.AddColumns("p", "q")
+	b.AddSecondaryColumns("x")
+	b.AddRow("A", "p", "x:A")
+	b.AddRow("B", "q", "x:A")
+	b.AddRow("C", "q", "x:B")
+	colored, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if err := colored.ApplyGivens([]string{"A", "C"}); err == nil {
+		t.Fatal("Expected colours to conflict")
+	}
+	if err := colored.ApplyGivens([]string{"B", "A"}); err != nil {
+		t.Fatalf("Error giving rows: %v", err)
+	}
+}
@@ -12,7 +12,11 @@
 package gox
 
 import (
+	"context"
 	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
 )
 
 // node is fundemental in the dancing links implementation of x. It serves three
@@ -39,6 +43,10 @@ type node struct {
 	// value is only relevant in the column header
 	colCount int
 	colIndex int
+	// color is the colour of a node in a secondary column, or zero if the node
+	// has no colour. The colour is negated to mark a node whose colour has
+	// already been satisfied by purifying its column, see purify.
+	color int
 }
 
 // rowHeader contains information about each row and an entry point to the row
@@ -46,8 +54,14 @@ type node struct {
 // the problem
 type rowHeader struct {
 	name  string
+	id    int64
 	index int
 	first *node
+	// given is set for a row given with RowIsSolution, rather than found by
+	// the search or forced by ForceRows
+	given bool
+	// forbidden is set for a row left out of every solution by Forbid
+	forbidden bool
 }
 
 // exactCoverProblem encapsulates all the information needed to solve the exact
@@ -58,6 +72,10 @@ type exactCoverProblem struct {
 	colHeaders       []*node
 	rowHeaders       []*rowHeader
 	numRows, numCols int
+	// numPrimary is the number of primary columns, which must be covered
+	// exactly once. The primary columns come first in colHeaders, the
+	// remaining columns are secondary and may be covered at most once.
+	numPrimary int
 	// solutionRows contains the current attempt at a solution, rows are pushed
 	// and popped from the slice as attempts are made at solving the problem
 	solutionRows []*rowHeader
@@ -68,25 +86,162 @@ type exactCoverProblem struct {
 	// is useful for e.g. sudoku, which starts with the same matrix for all
 	// the puzzles but the numbers that are given can be added to the solution.
 	rowsByName map[string]*rowHeader
+	// names holds the names of the rows, see intern.go
+	names nameTable
+	// rowsByID holds the row headers by identifier for a problem created by
+	// NewExactCoverProblemIDs, and is nil otherwise, see ids.go
+	rowsByID map[int64]*rowHeader
+	// updates counts the nodes unlinked from their columns, see Stats
+	updates int64
+	// colNames holds the names of the columns given to the Builder, or is
+	// nil if the problem was created from a matrix, see colName
+	colNames []string
+	// colorNames holds the name of each colour given to the Builder, the
+	// name of colour value v being colorNames[v-1]
+	colorNames []string
+	// emptyRows is the policy for rows which cover no columns, and
+	// droppedRows the names of the rows it dropped
+	emptyRows   EmptyRowPolicy
+	droppedRows []string
+	// duplicateRows is the policy for rows named like an earlier row
+	duplicateRows DuplicateRowPolicy
+	// validation is set by WithValidation, and warnings holds the inputs it
+	// fixed or ignored, see validation.go
+	validation ValidationMode
+	warnings   []string
+	// debug is set by WithDebug, see invariants.go
+	debug bool
+	// inUse is set while the problem is being given rows or solved, by the
+	// call named by running, see concurrency.go
+	inUse   int32
+	running atomic.Value
+	// state is the State of the problem, and solveDepth the length of
+	// solutionRows when the search running began, see state.go
+	state      int32
+	solveDepth int
+	// column is the column last chosen to branch on, for InternalError
+	column *node
+	// solveErr holds the solveError of the last call to Solve, see Err
+	solveErr atomic.Value
+	// mergeDuplicates, removeDominated and forceRows are set by the options
+	// of preprocess.go
+	mergeDuplicates, removeDominated, forceRows bool
+	// report describes the preprocessing of the problem, and removedCols
+	// records the columns it removed. Both are nil if there was none.
+	report      *PreprocessReport
+	removedCols []bool
+	// tracer and traceCtx are set by WithBuildTracer, traceCtx then holding
+	// the span of the creation of the problem
+	tracer   Tracer
+	traceCtx context.Context
+	// colWeights holds the weight of each column set by SetColumnWeight, or
+	// is nil if none has been set, see weights.go
+	colWeights []float64
+	// rowCosts holds the cost of each row set by SetRowCost, or is nil if
+	// none has been set, see cost.go
+	rowCosts []float64
+	// colPriorities holds the priority class of each column set by
+	// SetColumnPriority, or is nil if none has been set, see priority.go
+	colPriorities []int
+	// rowWidth is the number of columns covered by every row, or 0 if the
+	// rows differ or have colours, see uniform.go
+	rowWidth int
+	// coverEvents is the configuration of a search with WithEventStream,
+	// which is sent an event for each column covered and uncovered, or nil
+	coverEvents *config
+	// buckets indexes the columns by their number of rows during a search
+	// with BucketedMinRemaining, and is nil otherwise, see bucket.go
+	buckets *bucketIndex
+	// rowPool and nodePool hold the room reserved for the rows and nodes
+	// being added, and rowsHint and cellsHint are set by WithCapacity, see
+	// capacity.go
+	rowPool             []rowHeader
+	nodePool            []node
+	rowsHint, cellsHint int
+}
+
+// EmptyRowPolicy says what is done with a row which covers no columns, i.e. a
+// row of NewExactCoverProblem with no true cells or a row added to a Builder
+// without items. Such a row can never be part of a solution.
+type EmptyRowPolicy int
+
+const (
+	// KeepEmptyRows keeps empty rows in the problem as inert rows, which are
+	// listed by Rows but never chosen. Giving one to RowIsSolution is an
+	// error. This is the default.
+	KeepEmptyRows EmptyRowPolicy = iota
+	// RejectEmptyRows fails to create a problem with empty rows
+	RejectEmptyRows
+	// DropEmptyRows leaves empty rows out of the problem, listing their names
+	// in DroppedRows
+	DropEmptyRows
+)
+
+// DuplicateRowPolicy says what is done with a row named like an earlier row of
+// the problem. Generated encodings often produce the same label more than
+// once, e.g. a piece placed in a symmetric orientation.
+type DuplicateRowPolicy int
+
+const (
+	// RejectDuplicateRows fails to create a problem with two rows of the same
+	// name. This is the default.
+	RejectDuplicateRows DuplicateRowPolicy = iota
+	// SuffixDuplicateRows renames each row named like an earlier row by
+	// appending "#" and the number of the occurrence, e.g. the rows named
+	// "a", "a" and "a" become "a", "a#2" and "a#3", skipping any name which
+	// is already taken
+	SuffixDuplicateRows
+	// AliasDuplicateRows treats rows of the same name as the same row,
+	// keeping the first, as long as they cover the same columns with the
+	// same colours. Rows of the same name which cover different columns are
+	// still an error.
+	AliasDuplicateRows
+)
+
+// WithDuplicateRows sets the policy for rows named like an earlier row. To
+// build a problem with a Builder, which checks the name of each row as it is
+// added, give it to NewBuilder.
+func WithDuplicateRows(policy DuplicateRowPolicy) ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.duplicateRows = policy
+	}
+}
+
+// ProblemOption configures the creation of a problem by NewExactCoverProblem
+// or Builder.Build
+type ProblemOption func(*exactCoverProblem)
+
+// WithEmptyRows sets the policy for rows which cover no columns
+func WithEmptyRows(policy EmptyRowPolicy) ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.emptyRows = policy
+	}
 }
 
 // NewExactCoverProblem creates a new exact cover problem. m is a matrix of
 // bools which specifies the problem to be solved. n is the names of the rows
-// in the problem and are used to identify the solutions that are found.
-func NewExactCoverProblem(m [][]bool, n []string) (*exactCoverProblem, error) {
+// in the problem and are used to identify the solutions that are found. If the
+// inputs are invalid, e.g. there are fewer names than rows, the error is an
+// *InputError listing every problem with them.
+func NewExactCoverProblem(m [][]bool, n []string, opts ...ProblemOption) (*exactCoverProblem, error) {
 	// Perform sanity checks on the inputs
 
 	ret := &exactCoverProblem{}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	span := ret.startBuild()
 
 	err := ret.checkInputs(m, n)
 	if err != nil {
+		ret.endBuild(span, err)
 		return nil, err
 	}
 
 	// Initialize problem fields
-	ret.numRows = len(m)
 	ret.numCols = len(m[0]) // Safe after verification
-	ret.rowsByName = make(map[string]*rowHeader)
+	ret.numPrimary = ret.numCols
+	ret.rowsByName = make(map[string]*rowHeader, len(n))
 
 	// Create root, ensure the column index is invalid
 	ret.root = &node{colIndex: -1}
@@ -95,42 +250,145 @@ func NewExactCoverProblem(m [][]bool, n []string) (*exactCoverProblem, error) {
 
 	ret.allocateColHeaders()
 	ret.initializeColHeaders()
-	err = ret.createNodes(m, n)
+	// Now create the nodes
+	err = ret.createNodes(m, n, nil)
 	if err != nil {
+		ret.endBuild(span, err)
 		return nil, err
 	}
-	// Now create the nodes
+	ret.numRows = len(ret.rowHeaders)
+	ret.endBuild(span, nil)
+	ret.setState(Ready)
 	return ret, nil
 }
 
-// checkInputs makes sure the inputs given are sane
+// InputProblem describes one problem with the inputs of NewExactCoverProblem
+type InputProblem struct {
+	// Row is the index of the row or name concerned, or -1 if the problem is
+	// not with a particular row
+	Row int
+	Msg string
+}
+
+func (p InputProblem) String() string {
+	return p.Msg
+}
+
+// InputError is returned by NewExactCoverProblem for invalid inputs, listing
+// every problem found rather than only the first
+type InputError struct {
+	Problems []InputProblem
+}
+
+func (e *InputError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+	return "Invalid inputs: " + strings.Join(msgs, "; ")
+}
+
+// checkInputs makes sure the inputs given are sane, returning an *InputError
+// listing each problem found
 func (p *exactCoverProblem) checkInputs(m [][]bool, n []string) error {
+	problems := p.checkMatrix(m, len(n), "names")
+	add := func(row int, format string, args ...interface{}) {
+		problems = append(problems, InputProblem{Row: row, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]int, len(n))
+	for i, name := range n {
+		if name == "" {
+			add(i, "names[%d] is empty", i)
+			continue
+		}
+		if j, ok := seen[name]; ok && p.duplicateRows == RejectDuplicateRows {
+			add(i, "Duplicate row name present: names[%d] = names[%d] = %s", j, i, name)
+			continue
+		}
+		seen[name] = i
+	}
+
+	if problems != nil {
+		return &InputError{Problems: problems}
+	}
+	return nil
+}
+
+// checkMatrix returns the problems with the matrix given to a constructor.
+// numLabels is the number of row names or identifiers given with it, and
+// labels what the messages call them, e.g. "names".
+func (p *exactCoverProblem) checkMatrix(m [][]bool, numLabels int, labels string) []InputProblem {
+	var problems []InputProblem
+	add := func(row int, format string, args ...interface{}) {
+		problems = append(problems, InputProblem{Row: row, Msg: fmt.Sprintf(format, args...)})
+	}
+
 	if len(m) <= 1 {
-		return fmt.Errorf("Number of columns must exceed 1")
+		add(-1, "Number of rows must exceed 1: %d", len(m))
+	}
+	if numLabels != len(m) {
+		add(-1, "Number of %s must equal number of rows: %s=%d, rows=%d", labels, labels, numLabels, len(m))
 	}
 
-	rowLen := len(m[0])
+	// first is the index of the first row which is not nil, rowLen its length
+	first, rowLen := 0, -1
 	for i, row := range m {
-		if len(row) != rowLen {
-			return fmt.Errorf("All rows must be same length: rows[0]=%d, rows[%d] = %d", rowLen, i, len(row))
+		switch {
+		case row == nil:
+			add(i, "rows[%d] is nil", i)
+		case rowLen < 0:
+			first, rowLen = i, len(row)
+			if rowLen == 0 {
+				add(i, "rows[%d] has no columns", i)
+			}
+		case len(row) != rowLen:
+			add(i, "All rows must be same length: rows[%d]=%d, rows[%d] = %d", first, rowLen, i, len(row))
+			continue
+		}
+		if p.emptyRows == RejectEmptyRows && row != nil && len(row) > 0 && isEmpty(row) {
+			add(i, "rows[%d] has no true cells", i)
 		}
 	}
-	return nil
+	return problems
+}
+
+// isEmpty reports whether a row of a matrix has no true cells
+func isEmpty(row []bool) bool {
+	for _, elem := range row {
+		if elem {
+			return false
+		}
+	}
+	return true
 }
 
 // allocateColHeaders creates the column headers and adds them to the problem's
 // slice so they can be iterated over before creating the necessary links
 func (p *exactCoverProblem) allocateColHeaders() {
-	for i := 0; i < p.numCols; i++ {
-		p.colHeaders = append(p.colHeaders, &node{colIndex: i})
+	headers := make([]node, p.numCols)
+	p.colHeaders = make([]*node, p.numCols)
+	for i := range headers {
+		headers[i].colIndex = i
+		p.colHeaders[i] = &headers[i]
 	}
+	// A solution has at most a row for each primary column, besides any
+	// given which cover only secondary columns
+	p.solutionRows = make([]*rowHeader, 0, p.numPrimary)
 }
 
-// initializeColHeaders inserts the column headers into the problem
+// initializeColHeaders inserts the column headers into the problem. Only the
+// primary columns are linked to the root, secondary column headers are linked
+// to themselves so that they are never chosen by nextCol.
 func (p *exactCoverProblem) initializeColHeaders() {
-	for _, n := range p.colHeaders {
+	for i, n := range p.colHeaders {
 		n.up = n
 		n.down = n
+		if i >= p.numPrimary {
+			n.left = n
+			n.right = n
+			continue
+		}
 		n.right = p.root
 		n.left = p.root.left
 		p.root.left.right = n
@@ -138,116 +396,309 @@ func (p *exactCoverProblem) initializeColHeaders() {
 	}
 }
 
-// createNodes adds the problem's nodes into the linked list matrix
-func (p *exactCoverProblem) createNodes(m [][]bool, n []string) error {
+// createNodes adds the problem's nodes into the linked list matrix. The rows
+// are given the names n and the identifiers ids, either of which may be nil.
+func (p *exactCoverProblem) createNodes(m [][]bool, n []string, ids []int64) error {
+	rows := make([]sparseRow, len(m))
 	for rowIndex := range m {
-		// Create the row header
-		rowHead := &rowHeader{index: rowIndex, name: n[rowIndex]}
-		// Check for duplicate names
-		if _, ok := p.rowsByName[rowHead.name]; ok {
-			return fmt.Errorf("Duplicate row name present: %s", rowHead.name)
-		} else {
-			p.rowsByName[rowHead.name] = rowHead
+		if n != nil {
+			rows[rowIndex].name = n[rowIndex]
 		}
-		p.rowHeaders = append(p.rowHeaders, rowHead)
-		var firstNode *node = nil
-
+		if ids != nil {
+			rows[rowIndex].id = ids[rowIndex]
+		}
+		count := 0
+		for _, elem := range m[rowIndex] {
+			if elem {
+				count++
+			}
+		}
+		rows[rowIndex].cols = make([]int, 0, count)
 		for colIndex, elem := range m[rowIndex] {
-			colHead := p.colHeaders[colIndex]
 			if elem {
-				nd := &node{
-					rowHead:  rowHead,
-					colHead:  colHead,
-					colIndex: colIndex,
-				}
+				rows[rowIndex].cols = append(rows[rowIndex].cols, colIndex)
+			}
+		}
+	}
+	return p.addRows(rows)
+}
 
-				// Add node to row at the right, if this is the first node
-				// in the row, ensure the pointer in the row header will be set
-				if firstNode == nil {
-					firstNode = nd
-					firstNode.left = nd
-					firstNode.right = nd
-				} else {
-					nd.right = firstNode
-					nd.left = firstNode.left
-					firstNode.left.right = nd
-					firstNode.left = nd
+// addRow creates a row header for the row given and links a node into each
+// of its columns. A row without columns is handled according to the problem's
+// EmptyRowPolicy.
+func (p *exactCoverProblem) addRow(row sparseRow) error {
+	// Create the row header
+	rowHead := p.newRowHeader()
+	*rowHead = rowHeader{index: len(p.rowHeaders), name: row.name, id: row.id}
+	if len(row.cols) == 0 {
+		switch p.emptyRows {
+		case RejectEmptyRows:
+			return fmt.Errorf("Row %s covers no columns", rowHead.label())
+		case DropEmptyRows:
+			p.droppedRows = append(p.droppedRows, rowHead.label())
+			p.warn("Row %s covers no columns, so is dropped", rowHead.label())
+			return nil
+		}
+	}
+	// Check for duplicate names, or identifiers
+	if p.rowsByID != nil {
+		if _, ok := p.rowsByID[rowHead.id]; ok {
+			return fmt.Errorf("Duplicate row ID present: %d", rowHead.id)
+		}
+		p.rowsByID[rowHead.id] = rowHead
+	}
+	if rowHead.name != "" {
+		if other, ok := p.rowsByName[rowHead.name]; ok {
+			switch p.duplicateRows {
+			case SuffixDuplicateRows:
+				name := rowHead.name
+				rowHead.name = p.suffixedName(name)
+				p.warn("Row %s is named like an earlier row, so is renamed %s", name, rowHead.name)
+			case AliasDuplicateRows:
+				if other.sparse().key() != row.key() {
+					return fmt.Errorf("Rows named %s cover different columns", rowHead.name)
 				}
+				return nil
+			default:
+				return fmt.Errorf("Duplicate row name present: %s", rowHead.name)
+			}
+		}
+		p.rowsByName[rowHead.name] = rowHead
+	}
+	p.rowHeaders = append(p.rowHeaders, rowHead)
+	p.noteRowWidth(row)
+	var firstNode *node = nil
 
-				if rowHead.first == nil {
-					rowHead.first = nd
-				}
+	nodes := p.newNodes(len(row.cols))
+	for i, colIndex := range row.cols {
+		colHead := p.colHeaders[colIndex]
+		nd := &nodes[i]
+		*nd = node{
+			rowHead:  rowHead,
+			colHead:  colHead,
+			colIndex: colIndex,
+		}
+		if row.colors != nil {
+			nd.color = row.colors[i]
+		}
 
-				// Add node to column at the bottom
-				nd.down = colHead
-				nd.up = colHead.up
-				colHead.up.down = nd
-				colHead.up = nd
+		// Add node to row at the right, if this is the first node
+		// in the row, ensure the pointer in the row header will be set
+		if firstNode == nil {
+			firstNode = nd
+			firstNode.left = nd
+			firstNode.right = nd
+		} else {
+			nd.right = firstNode
+			nd.left = firstNode.left
+			firstNode.left.right = nd
+			firstNode.left = nd
+		}
 
-				// Increment the column count
-				colHead.colCount += 1
-			}
+		if rowHead.first == nil {
+			rowHead.first = nd
 		}
+
+		// Add node to column at the bottom
+		nd.down = colHead
+		nd.up = colHead.up
+		colHead.up.down = nd
+		colHead.up = nd
+
+		// Increment the column count
+		colHead.colCount += 1
 	}
 
 	return nil
 }
 
+// suffixedName returns the name given to a row named like an earlier row by
+// SuffixDuplicateRows
+func (p *exactCoverProblem) suffixedName(name string) string {
+	for i := 2; ; i++ {
+		suffixed := fmt.Sprintf("%s#%d", name, i)
+		if _, ok := p.rowsByName[suffixed]; !ok {
+			return suffixed
+		}
+	}
+}
+
+// sparse returns the columns of the row and their colours, e.g. to compare
+// its key with that of another row
+func (r *rowHeader) sparse() sparseRow {
+	var row sparseRow
+	for n := r.first; n != nil; {
+		row.cols = append(row.cols, n.colIndex)
+		row.colors = append(row.colors, n.color)
+		if n = n.right; n == r.first {
+			n = nil
+		}
+	}
+	return row
+}
+
 // search embodies the main structure of the algorithm. This is a recursive,
 // depth-first search of the problem domain that sysematically tries rows to
 // find the solutions, backtracking when the constraints of the problem can no
-// longer be satisfied.
-func (p *exactCoverProblem) search() {
-	// Check to see if the matrix is empty, this occurs when there are no
-	// more column headers
-	if p.root == p.root.right {
-		// Solution found, copy the current solution's row names to the slice
-		// of solutions.
-		soln := make([]string, len(p.solutionRows))
-		for i, r := range p.solutionRows {
-			soln[i] = r.name
-		}
-		p.solutions = append(p.solutions, soln)
-		return
+// longer be satisfied. search returns true if the search was stopped before
+// every solution was found, the matrix is restored either way.
+func (p *exactCoverProblem) search(c *config) bool {
+	if c.interrupted() {
+		return true
 	}
 
-	// Retrieve the next column to satisfy, if there are no rows in any of the
-	// columns, the problem is not solvable, so backtrack
-	colHead := p.nextCol()
-	if colHead.colCount == 0 {
-		return
+	// Give the rows which are forced before making a real decision, then
+	// take them back once every branch has been searched
+	forced := len(c.forced)
+	colHead, stopped := p.propagate(c)
+	if colHead != nil {
+		stopped = p.branchOn(c, colHead)
 	}
+	p.unpropagate(c, forced)
+	return stopped
+}
 
-	p.cover(colHead)
-
-	// Attempt to add each row in turn to the solution
-	for rowNode := colHead.down; rowNode != colHead; rowNode = rowNode.down {
-		// Add to partial solution
-		p.pushRowToSolution(rowNode.rowHead)
+// propagate gives the rows forced at this point of the search, each the only
+// row left in the column chosen, one after another without recursing, as unit
+// propagation does in a SAT solver. Each row forced is still a node of the
+// search tree, counted and traced as if it had been branched on. propagate
+// returns the column to branch on next, or nil if a solution or dead end was
+// reached first, along with true if the search was stopped. The nodes of the
+// rows given are pushed onto c.forced, for unpropagate to take back.
+func (p *exactCoverProblem) propagate(c *config) (*node, bool) {
+	for {
+		// Check to see if the matrix is empty, this occurs when there are
+		// no more column headers
+		if p.root == p.root.right {
+			// Solution found, pass the current solution's rows on to be
+			// recorded
+			c.solutions++
+			if c.participation != nil {
+				for _, r := range p.solutionRows {
+					c.participation.Counts[r.index]++
+				}
+			}
+			p.emit(c, FoundSolution, nil, nil)
+			return nil, !c.found(p.solutionRows)
+		}
 
-		// For each node in the row, remove the all nodes in the column as
-		// the constraint has been satisfied
-		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
-			p.cover(rightNode.colHead)
+		// Retrieve the next column to satisfy, if there are no rows in any
+		// of the columns, the problem is not solvable, so backtrack
+		colHead := p.nextCol(c)
+		p.chose(c, colHead)
+		switch colHead.colCount {
+		case 0:
+			p.emit(c, DeadEnd, colHead, nil)
+			c.conflict(colHead)
+			return nil, false
+		case 1:
+		default:
+			return colHead, false
 		}
 
-		// search again on the reduced matrix
-		p.search()
+		// The column has a single row, which must be part of any solution
+		// below this point
+		rowNode := colHead.down
+		p.cover(colHead)
+		p.applyRow(c, rowNode)
+		c.forced = append(c.forced, rowNode)
+		if c.interrupted() {
+			return nil, true
+		}
+	}
+}
 
-		// remove the row from the solution as either a solution has been found
-		// and copied to the solutions, or the attempt was incorrect
-		p.popRowFromSolution()
+// unpropagate takes back the rows forced since c.forced held n nodes, the
+// last first, restoring the matrix
+func (p *exactCoverProblem) unpropagate(c *config, n int) {
+	for i := len(c.forced) - 1; i >= n; i-- {
+		rowNode := c.forced[i]
+		p.undoRow(c, rowNode)
+		p.uncover(rowNode.colHead)
+	}
+	c.forced = c.forced[:n]
+}
 
-		// uncover the columns that were covered when the row was added to the
-		// solution
-		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
-			p.uncover(leftNode.colHead)
+// branchOn tries each row of colHead in turn, returning true if the search was
+// stopped. The matrix is restored either way.
+func (p *exactCoverProblem) branchOn(c *config, colHead *node) bool {
+	// Skip partial solutions known to lead nowhere, see WithNogoods
+	var key []byte
+	if c.nogoods != nil {
+		key = p.stateKey()
+		if _, ok := c.nogoods.get(key); ok {
+			c.pruned++
+			p.emit(c, DeadEnd, colHead, nil)
+			return false
 		}
+	}
+	solutions := c.solutions
+
+	p.cover(colHead)
 
+	// Attempt to add each row in turn to the solution
+	stopped := false
+	for rowNode := colHead.down; rowNode != colHead && !stopped; rowNode = rowNode.down {
+		stopped = p.tryRow(c, rowNode)
 	}
 
 	// add back the column to the matrix
 	p.uncover(colHead)
+	if c.nogoods != nil && !stopped && c.solutions == solutions {
+		c.nogoods.put(string(key), 0)
+	}
+	return stopped
+}
+
+// tryRow adds the row of rowNode to the solution, its column having been
+// covered, and searches the reduced matrix, returning true if the search was
+// stopped. The matrix is restored either way.
+func (p *exactCoverProblem) tryRow(c *config, rowNode *node) bool {
+	p.applyRow(c, rowNode)
+
+	// search again on the reduced matrix
+	stopped := p.search(c)
+
+	p.undoRow(c, rowNode)
+	return stopped
+}
+
+// applyRow adds the row of rowNode to the solution, its column having been
+// covered, removing the other columns it covers from the matrix
+func (p *exactCoverProblem) applyRow(c *config, rowNode *node) {
+	// Add to partial solution
+	p.pushRowToSolution(rowNode.rowHead)
+	if c.paging {
+		c.path = append(c.path, rowNode)
+	}
+
+	// For each node in the row, remove the all nodes in the column as
+	// the constraint has been satisfied
+	for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+		p.commit(rightNode)
+	}
+	p.emit(c, TryRow, nil, rowNode.rowHead)
+	if c.tieBreak == RecentTie {
+		p.stampNeighbours(c, rowNode)
+	}
+}
+
+// undoRow takes the row of rowNode back out of the solution, undoing applyRow
+// but leaving its column covered
+func (p *exactCoverProblem) undoRow(c *config, rowNode *node) {
+	// remove the row from the solution as either a solution has been found
+	// and copied to the solutions, or the attempt was incorrect
+	p.popRowFromSolution()
+	if c.paging {
+		c.path = c.path[:len(c.path)-1]
+	}
+
+	// uncover the columns that were covered when the row was added to the
+	// solution
+	for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+		p.uncommit(leftNode)
+	}
+	p.emit(c, UndoRow, nil, rowNode.rowHead)
 }
 
 // cover removes a column from a solution. It removes the rows from the matrix
@@ -256,19 +707,19 @@ func (p *exactCoverProblem) cover(head *node) {
 	// remove the column header
 	head.right.left = head.left
 	head.left.right = head.right
+	if p.buckets != nil && p.buckets.in[head.colIndex] {
+		p.buckets.remove(head.colIndex, head.colCount)
+	}
 
 	// for each node in each row that is in the column, remove it from the
 	// matrix
 	for rowNode := head.down; rowNode != head; rowNode = rowNode.down {
-		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
-			rightNode.up.down = rightNode.down
-			rightNode.down.up = rightNode.up
-
-			// Update count of nodes in the column header to reflect the removal
-			// of the node
-			rightNode.colHead.colCount -= 1
-		}
+		p.hide(rowNode)
+	}
+	if p.coverEvents != nil {
+		p.emit(p.coverEvents, Cover, head, nil)
 	}
+	p.mustCheckInvariants("cover", head)
 }
 
 // uncover is the reverse of cover. It adds back the removed nodes from the
@@ -276,25 +727,171 @@ func (p *exactCoverProblem) cover(head *node) {
 func (p *exactCoverProblem) uncover(head *node) {
 	// add in all the rows that were removed for the covered column
 	for rowNode := head.up; rowNode != head; rowNode = rowNode.up {
-		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
-			leftNode.up.down = leftNode
-			leftNode.down.up = leftNode
-
-			// Update column node count
-			leftNode.colHead.colCount += 1
-		}
+		p.unhide(rowNode)
 	}
 
 	// add back in the column header
 	head.right.left = head
 	head.left.right = head
+	if p.buckets != nil && head.colIndex < p.numPrimary && head.left != head {
+		p.buckets.insert(head.colIndex, head.colCount)
+	}
+	if p.coverEvents != nil {
+		p.emit(p.coverEvents, Uncover, head, nil)
+	}
+	p.mustCheckInvariants("uncover", head)
+}
+
+// hide removes every other node in the row of n from its column. Nodes whose
+// colour has already been satisfied are left in place, as their column can no
+// longer be chosen.
+func (p *exactCoverProblem) hide(n *node) {
+	if p.buckets == nil {
+		switch p.rowWidth {
+		case 2:
+			p.hideNode(n.right)
+			return
+		case 3:
+			p.hideNode(n.right)
+			p.hideNode(n.right.right)
+			return
+		case 4:
+			p.hideNode(n.right)
+			p.hideNode(n.right.right)
+			p.hideNode(n.left)
+			return
+		}
+	}
+	for rightNode := n.right; rightNode != n; rightNode = rightNode.right {
+		if rightNode.color < 0 {
+			continue
+		}
+		rightNode.up.down = rightNode.down
+		rightNode.down.up = rightNode.up
+		p.updates++
+
+		// Update count of nodes in the column header to reflect the removal
+		// of the node
+		rightNode.colHead.colCount -= 1
+		if p.buckets != nil {
+			p.buckets.moved(rightNode.colIndex, rightNode.colHead.colCount+1, rightNode.colHead.colCount)
+		}
+	}
+}
+
+// unhide is the reverse of hide
+func (p *exactCoverProblem) unhide(n *node) {
+	if p.buckets == nil {
+		switch p.rowWidth {
+		case 2:
+			p.unhideNode(n.left)
+			return
+		case 3:
+			p.unhideNode(n.left)
+			p.unhideNode(n.left.left)
+			return
+		case 4:
+			p.unhideNode(n.left)
+			p.unhideNode(n.left.left)
+			p.unhideNode(n.right)
+			return
+		}
+	}
+	for leftNode := n.left; leftNode != n; leftNode = leftNode.left {
+		if leftNode.color < 0 {
+			continue
+		}
+		leftNode.up.down = leftNode
+		leftNode.down.up = leftNode
+
+		// Update column node count
+		leftNode.colHead.colCount += 1
+		if p.buckets != nil {
+			p.buckets.moved(leftNode.colIndex, leftNode.colHead.colCount-1, leftNode.colHead.colCount)
+		}
+	}
+}
+
+// commit satisfies the column of n once the row of n has been added to the
+// solution. Uncoloured columns are covered, while secondary columns with a
+// colour are purified so that only rows agreeing on the colour remain.
+func (p *exactCoverProblem) commit(n *node) {
+	if n.color == 0 {
+		p.cover(n.colHead)
+	} else if n.color > 0 {
+		p.purify(n)
+	}
+}
+
+// uncommit is the reverse of commit
+func (p *exactCoverProblem) uncommit(n *node) {
+	if n.color == 0 {
+		p.uncover(n.colHead)
+	} else if n.color > 0 {
+		p.unpurify(n)
+	}
+}
+
+// purify removes the rows from the column of n which have a different colour
+// to n. The other nodes which have the same colour are marked as satisfied, so
+// that choosing one of their rows later leaves the column alone. n keeps its
+// colour so that uncommit knows to unpurify the column.
+func (p *exactCoverProblem) purify(n *node) {
+	head := n.colHead
+	for rowNode := head.down; rowNode != head; rowNode = rowNode.down {
+		if rowNode == n {
+			continue
+		}
+		if rowNode.color == n.color {
+			rowNode.color = -rowNode.color
+		} else {
+			p.hide(rowNode)
+		}
+	}
+}
+
+// unpurify is the reverse of purify
+func (p *exactCoverProblem) unpurify(n *node) {
+	head := n.colHead
+	for rowNode := head.up; rowNode != head; rowNode = rowNode.up {
+		if rowNode == n {
+			continue
+		}
+		if rowNode.color < 0 {
+			rowNode.color = -rowNode.color
+		} else {
+			p.unhide(rowNode)
+		}
+	}
 }
 
 // nextCol picks the next column which has the least number of nodes present.
 // if there are more than one node with the same number of nodes, nextCol choses
-// the first it encounters when moving right from the node
-func (p *exactCoverProblem) nextCol() *node {
+// the one with the greatest weight, then the first it encounters when moving
+// right from the node, unless another tie-break was given, see tiebreak.go.
+// With the FirstColumn heuristic the first column is always chosen, and
+// ConflictWeighted is described in conflict.go, and BucketedMinRemaining in
+// bucket.go. Only the columns of the highest priority class left are
+// considered, see priority.go.
+func (p *exactCoverProblem) nextCol(c *config) *node {
 	ret := p.root.right
+	top := 0
+	if p.colPriorities != nil && ret != p.root {
+		ret, top = p.topClass()
+	}
+	switch c.heuristic {
+	case FirstColumn:
+		return ret
+	case ConflictWeighted:
+		return p.nextConflictCol(c, ret, top)
+	case BucketedMinRemaining:
+		if p.buckets != nil && ret != p.root {
+			return p.colHeaders[p.buckets.first()]
+		}
+	}
+	if p.colWeights != nil || p.colPriorities != nil || c.tieBreak != LeftmostTie {
+		return p.nextTiedCol(c, ret, top)
+	}
 	for n := ret; n != p.root; n = n.right {
 		if n.colCount < ret.colCount {
 			ret = n
@@ -316,21 +913,134 @@ func (p *exactCoverProblem) popRowFromSolution() (ret *rowHeader) {
 
 // Solve starts the computation of the exact cover problem, it returns an slice
 // of the solutions. The solutions are a slice of row names that were given when
-// the exact cover problem was created.
-func (p *exactCoverProblem) Solve() [][]string {
-	p.search()
+// the exact cover problem was created. Solve cannot return an error, so if
+// the problem may not be solved, or the search panics, it returns nil and
+// keeps the error for Err: a *ConcurrentUseError, *StateError or
+// *InternalError. Use SolveContext to have the error returned.
+func (p *exactCoverProblem) Solve() (ret [][]string) {
+	if err := p.startSolve("Solve"); err != nil {
+		p.solveErr.Store(solveError{err})
+		return nil
+	}
+	var err error
+	defer func() {
+		p.solveErr.Store(solveError{err})
+		if err != nil {
+			ret = nil
+		}
+	}()
+	defer p.finishSolve(&err)
+	c := &config{}
+	c.found = func(rows []*rowHeader) bool {
+		p.solutions = append(p.solutions, rowNames(rows))
+		return true
+	}
+	p.run(c)
 	return p.solutions
 }
 
+// solveError holds the error of a call to Solve, as an atomic.Value cannot
+// hold nil
+type solveError struct {
+	err error
+}
+
+// Err returns the error which stopped the last call to Solve, or nil if it
+// succeeded. It may be called from another goroutine.
+func (p *exactCoverProblem) Err() error {
+	v, _ := p.solveErr.Load().(solveError)
+	return v.err
+}
+
+// PartialSolver is implemented by the problems of gox, which report the
+// partial solution being searched from. It is kept out of ExactCoverSolver
+// so that interface stays as it is, and is checked for with a type assertion
+// like Inspector.
+type PartialSolver interface {
+	CurrentSolution() []string
+}
+
+// CurrentSolution returns the names of the rows of the partial solution, in
+// the order they were added: the rows given with RowIsSolution or forced by
+// ForceRows, then, when called from a function passed to WithTrace or
+// WithSolutionFunc, the rows chosen so far by the search. It does not mark
+// the problem as in use, so that it may be called from those functions, but
+// must not be called from another goroutine while the problem is being
+// solved. The workers of SolveParallel search copies of the problem, whose
+// choices are not seen.
+func (p *exactCoverProblem) CurrentSolution() []string {
+	return rowNames(p.solutionRows)
+}
+
 // Rows returns the names of all the rows associated with the problem
 func (p *exactCoverProblem) Rows() []string {
 	var ret []string
 	for _, r := range p.rowHeaders {
-		ret = append(ret, r.name)
+		ret = append(ret, r.label())
 	}
 	return ret
 }
 
+// Inspector is implemented by the problems of gox, which report the columns
+// of each row and the rows of each column. It is kept out of ExactCoverSolver
+// so that interface stays as it is, and is checked for with a type assertion
+// like PrefixSolver.
+type Inspector interface {
+	RowColumns(string) ([]string, error)
+	ColumnRows(string) ([]string, error)
+}
+
+// RowColumns returns the items of the row with the given name, as given to
+// Builder.AddRow: the names of the columns it covers, followed by a colon and
+// a colour for the columns it gives a colour. The names of the columns of a
+// problem created by NewExactCoverProblem are their indexes.
+func (p *exactCoverProblem) RowColumns(name string) ([]string, error) {
+	header := p.row(name)
+	if header == nil {
+		return nil, fmt.Errorf("No row found with name %s", name)
+	}
+	return p.rowItems(header), nil
+}
+
+// ColumnRows returns the names of the rows covering the named column, which
+// are the rows that can satisfy its constraint. Rows ruled out by the rows
+// given with RowIsSolution are included.
+func (p *exactCoverProblem) ColumnRows(col string) ([]string, error) {
+	index := p.columnIndex(col)
+	if index < 0 {
+		return nil, fmt.Errorf("No column found with name %s", col)
+	}
+	var ret []string
+	for _, r := range p.rowHeaders {
+		for n := r.first; n != nil; {
+			if n.colIndex == index {
+				ret = append(ret, r.label())
+				break
+			}
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+	}
+	return ret, nil
+}
+
+// columnIndex returns the index of the named column, or -1 if there is none
+func (p *exactCoverProblem) columnIndex(name string) int {
+	for i, h := range p.colHeaders {
+		if p.colName(h) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// DroppedRows returns the names of the rows left out of the problem because
+// they cover no columns, see DropEmptyRows
+func (p *exactCoverProblem) DroppedRows() []string {
+	return p.droppedRows
+}
+
 // RowIsSolution allows the caller to specify rows as solutions to the problem
 // before the computation of the solutions. This can be useful for problems
 // which have a common matrix (e.g. sudoku), but with pre-selected rows given (
@@ -338,25 +1048,42 @@ func (p *exactCoverProblem) Rows() []string {
 // Strictly, it should be the responsibility of the caller to provide the
 // correct starting matrix, but there would be a lot of duplicated functionality
 // for covering the correct rows of a puzzle
-func (p *exactCoverProblem) RowIsSolution(name string) error {
+func (p *exactCoverProblem) RowIsSolution(name string) (err error) {
+	if err := p.acquire("RowIsSolution"); err != nil {
+		return err
+	}
+	defer p.finish(&err)
+
 	// find the row header
-	header := p.rowsByName[name]
+	header := p.row(name)
 	if header == nil {
-		return fmt.Errorf("No row found with name ", name)
+		return fmt.Errorf("No row found with name %s", name)
+	}
+	if header.first == nil {
+		return fmt.Errorf("Row %s covers no columns, so cannot be part of a solution", name)
+	}
+	if header.forbidden {
+		return fmt.Errorf("Row %s is forbidden, so cannot be part of a solution", name)
 	}
 
+	p.give(header)
+	header.given = true
+	return nil
+}
+
+// give covers the columns of a row and adds it to the working solution
+func (p *exactCoverProblem) give(header *rowHeader) {
 	// cover the columns which correspond to satisfied constraints for the row
 	// given
 	for rightNode := header.first.right; rightNode != header.first; rightNode = rightNode.right {
-		p.cover(rightNode.colHead)
+		p.commit(rightNode)
 	}
 
 	// Ensure that the first one is done, too
-	p.cover(header.first.colHead)
+	p.commit(header.first)
 
 	// Add the solution to the working solution.
 	p.pushRowToSolution(header)
-	return nil
 }
 
 type ExactCoverSolver interface {
@@ -364,3 +1091,20 @@ type ExactCoverSolver interface {
 	Rows() []string
 	Solve() [][]string
 }
+
+// Solver is implemented by the problems of gox, and is returned by the
+// packages which build problems on it. It adds the methods gox has gained to
+// ExactCoverSolver, which is kept as it is so that other implementations of it
+// still satisfy it.
+type Solver interface {
+	ExactCoverSolver
+	SolveContext(context.Context, ...Option) ([][]string, error)
+	Verify([]string) error
+	Solution([]string) *Solution
+	SolveIndices(context.Context, ...Option) ([][]int, error)
+	SolveMinRows(context.Context, ...Option) ([]string, error)
+	CountSolutions(context.Context, ...Option) (uint64, error)
+	CountSolutionsBig(context.Context) (*big.Int, error)
+	ApplyGivens([]string) error
+	Err() error
+}
@@ -1,6 +1,9 @@
 package gox
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"sort"
 	"testing"
 )
@@ -67,3 +70,248 @@ func TestExactCoverSuccess(t *testing.T) {
 		}
 	}
 }
+
+func TestNewExactCoverProblemInvalid(t *testing.T) {
+	for _, c := range []struct {
+		desc  string
+		mat   [][]bool
+		names []string
+		// rows are the positions of the problems expected
+		rows []int
+	}{
+		{"too few rows", [][]bool{{true}}, []string{"A"}, []int{-1}},
+		{"short names", [][]bool{{true}, {true}, {true}}, []string{"A", "B"}, []int{-1}},
+		{"long names", [][]bool{{true}, {true}}, []string{"A", "B", "C"}, []int{-1}},
+		{"empty names", [][]bool{{true}, {true}, {true}}, []string{"A", "", ""}, []int{1, 2}},
+		{"duplicate names", [][]bool{{true}, {true}}, []string{"A", "A"}, []int{1}},
+		{"nil row", [][]bool{{true}, nil, {true}}, []string{"A", "B", "C"}, []int{1}},
+		{"no columns", [][]bool{{}, {}}, []string{"A", "B"}, []int{0}},
+		{"ragged rows", [][]bool{nil, {true}, {true, false}}, []string{"A", "B", "C"}, []int{0, 2}},
+		{"several", [][]bool{{true}, {true, true}, nil}, []string{"", "B"}, []int{-1, 1, 2, 0}},
+	} {
+		_, err := NewExactCoverProblem(c.mat, c.names)
+		ierr, ok := err.(*InputError)
+		if !ok {
+			t.Fatalf("%s: expected *InputError, got %v", c.desc, err)
+		}
+		var rows []int
+		for _, p := range ierr.Problems {
+			rows = append(rows, p.Row)
+		}
+		if !reflect.DeepEqual(rows, c.rows) {
+			t.Fatalf("%s: expected problems at %v, got %v: %v", c.desc, c.rows, rows, err)
+		}
+	}
+}
+
+func TestEmptyRows(t *testing.T) {
+	mat := [][]bool{
+		{true, false},
+		{false, false},
+		{false, true},
+	}
+	names := []string{"A", "B", "C"}
+
+	prob, err := NewExactCoverProblem(mat, names)
+	if err != nil {
+		t.Fatalf("Error creating problem keeping empty rows: %v", err)
+	}
+	assertStringSliceEqual(t, prob.Rows(), []string{"A", "B", "C"})
+	if err := prob.RowIsSolution("B"); err == nil {
+		t.Fatal("Expected error giving an empty row")
+	}
+	if solns := prob.Solve(); len(solns) != 1 {
+		t.Fatalf("Expected 1 solution, got %v", solns)
+	}
+
+	_, err = NewExactCoverProblem(mat, names, WithEmptyRows(RejectEmptyRows))
+	if ierr, ok := err.(*InputError); !ok || len(ierr.Problems) != 1 || ierr.Problems[0].Row != 1 {
+		t.Fatalf("Expected empty row 1 to be rejected, got %v", err)
+	}
+
+	prob, err = NewExactCoverProblem(mat, names, WithEmptyRows(DropEmptyRows))
+	if err != nil {
+		t.Fatalf("Error creating problem dropping empty rows: %v", err)
+	}
+	assertStringSliceEqual(t, prob.Rows(), []string{"A", "C"})
+	assertStringSliceEqual(t, prob.DroppedRows(), []string{"B"})
+
+	b := NewBuilder()
+	b.AddColumns("a")
+	b.AddRow("A", "a")
+	b.AddRow("B")
+	if _, err := b.Build(WithEmptyRows(RejectEmptyRows)); err == nil {
+		t.Fatal("Expected builder to reject empty row")
+	}
+	prob, err = b.Build(WithEmptyRows(DropEmptyRows))
+	if err != nil {
+		t.Fatalf("Error building problem dropping empty rows: %v", err)
+	}
+	assertStringSliceEqual(t, prob.DroppedRows(), []string{"B"})
+}
+
+func TestDuplicateRows(t *testing.T) {
+	mat := [][]bool{
+		{true, false, false},
+		{true, false, false},
+		{false, true, true},
+		{false, true, false},
+	}
+	names := []string{"A", "A", "B", "A#2"}
+
+	prob, err := NewExactCoverProblem(mat, names, WithDuplicateRows(SuffixDuplicateRows))
+	if err != nil {
+		t.Fatalf("Error creating problem suffixing duplicates: %v", err)
+	}
+	if rows := prob.Rows(); !reflect.DeepEqual(rows, []string{"A", "A#2", "B", "A#2#2"}) {
+		t.Fatalf("Expected suffixed rows, got %v", rows)
+	}
+	if solns := prob.Solve(); len(solns) != 2 {
+		t.Fatalf("Expected 2 solutions, got %v", solns)
+	}
+
+	// Rows of the same name must be identical to be aliases
+	if _, err := NewExactCoverProblem(mat, []string{"A", "A", "B", "A"}, WithDuplicateRows(AliasDuplicateRows)); err == nil {
+		t.Fatal("Expected error for rows of the same name covering different columns")
+	}
+	prob, err = NewExactCoverProblem(mat[:3], names[:3], WithDuplicateRows(AliasDuplicateRows))
+	if err != nil {
+		t.Fatalf("Error creating problem aliasing duplicates: %v", err)
+	}
+	if rows := prob.Rows(); !reflect.DeepEqual(rows, []string{"A", "B"}) {
+		t.Fatalf("Expected the first row of each name, got %v", rows)
+	}
+	if solns := prob.Solve(); !reflect.DeepEqual(solns, [][]string{{"A", "B"}}) {
+		t.Fatalf("Expected a single solution, got %v", solns)
+	}
+
+	// The builder checks names as rows are added, so takes the policy when
+	// it is created
+	b := NewBuilder(WithDuplicateRows(AliasDuplicateRows))
+	b.AddColumns("a", "b")
+	b.AddSecondaryColumns("s")
+	b.AddRow("P", "a", "s:X")
+	b.AddRow("P", "s:X", "a")
+	b.AddRow("Q", "b")
+	prob, err = b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem aliasing duplicates: %v", err)
+	}
+	if rows := prob.Rows(); !reflect.DeepEqual(rows, []string{"P", "Q"}) {
+		t.Fatalf("Expected rows P and Q, got %v", rows)
+	}
+	b.AddRow("P", "a", "s:Y")
+	if _, err := b.Build(); err == nil {
+		t.Fatal("Expected error for rows of the same name giving different colours")
+	}
+}
+
+// minimalSolver implements only the methods ExactCoverSolver has always had
+type minimalSolver struct{}
+
+func (minimalSolver) RowIsSolution(string) error { return nil }
+func (minimalSolver) Rows() []string             { return nil }
+func (minimalSolver) Solve() [][]string          { return nil }
+
+func TestExactCoverSolver(t *testing.T) {
+	// Implementations outside gox still satisfy ExactCoverSolver, and the
+	// problems of gox satisfy Solver
+	var solver ExactCoverSolver = minimalSolver{}
+	if _, ok := solver.(Solver); ok {
+		t.Fatal("Expected a minimal implementation not to be a Solver")
+	}
+	solver = dominoProblem(t, 2)
+	if _, ok := solver.(Solver); !ok {
+		t.Fatal("Expected the problem to be a Solver")
+	}
+}
+
+func TestRowColumns(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("p", "q")
+	b.AddSecondaryColumns("x")
+	b.AddRow("A", "p", "x:red")
+	b.AddRow("B", "q", "p")
+	b.AddRow("C", "q", "x")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var solver ExactCoverSolver = prob
+	if _, ok := solver.(Inspector); !ok {
+		t.Fatal("Expected the problem to be an Inspector")
+	}
+	if cols, err := prob.RowColumns("A"); err != nil || !reflect.DeepEqual(cols, []string{"p", "x:red"}) {
+		t.Fatalf("Expected columns of A, got %v, %v", cols, err)
+	}
+	if rows, err := prob.ColumnRows("q"); err != nil || !reflect.DeepEqual(rows, []string{"B", "C"}) {
+		t.Fatalf("Expected rows of q, got %v, %v", rows, err)
+	}
+	if _, err := prob.RowColumns("Z"); err == nil {
+		t.Fatal("Expected error for unknown row")
+	}
+	if _, err := prob.ColumnRows("z"); err == nil {
+		t.Fatal("Expected error for unknown column")
+	}
+
+	prob, err = NewExactCoverProblem(successfulCoverTests[1].mat, successfulCoverTests[1].names)
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	if rows, err := prob.ColumnRows("3"); err != nil || !reflect.DeepEqual(rows, []string{"C", "D", "E"}) {
+		t.Fatalf("Expected rows of column 3, got %v, %v", rows, err)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	// x is forced at the start, and z after y is tried, so of the five
+	// partial solutions only one is branched on
+	b := NewBuilder()
+	b.AddColumns("A", "B", "C")
+	b.AddRow("x", "A")
+	b.AddRow("y", "B")
+	b.AddRow("z", "C")
+	b.AddRow("w", "B", "C")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+
+	var stats Stats
+	var tried []string
+	solns, err := prob.SolveContext(context.Background(), WithStats(&stats), WithTrace(func(e Event) {
+		if e.Kind == TryRow {
+			tried = append(tried, fmt.Sprintf("%s@%d", e.Row, e.Depth))
+		}
+	}))
+	if err != nil || !reflect.DeepEqual(solns, [][]string{{"x", "y", "z"}, {"x", "w"}}) {
+		t.Fatalf("Unexpected solutions %v: %v", solns, err)
+	}
+	if stats.Nodes != 5 {
+		t.Fatalf("Expected 5 nodes, got %+v", stats)
+	}
+	if !reflect.DeepEqual(tried, []string{"x@1", "y@2", "z@3", "w@2"}) {
+		t.Fatalf("Unexpected rows tried: %v", tried)
+	}
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Matrix not restored after search: %v", err)
+	}
+
+	// Paging resumes after a forced row as after any other
+	var paged [][]string
+	var cursor Cursor
+	for {
+		page, next, err := prob.SolvePage(context.Background(), cursor, 1)
+		if err != nil {
+			t.Fatalf("Error paging: %v", err)
+		}
+		paged = append(paged, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !reflect.DeepEqual(paged, solns) {
+		t.Fatalf("Expected paged solutions %v, got %v", solns, paged)
+	}
+}
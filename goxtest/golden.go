@@ -0,0 +1,70 @@
+package goxtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ifross89/gox"
+)
+
+// UpdateEnv names the environment variable which, set to 1, makes GoldenTrace
+// rewrite the golden files rather than compare with them:
+//
+//	GOXTEST_UPDATE=1 go test ./...
+const UpdateEnv = "GOXTEST_UPDATE"
+
+// GoldenTrace solves a problem and checks that the search explored exactly
+// the tree recorded in the golden file at path, failing the test at the first
+// step which differs. The trace is the stream of WithEventStream, so every
+// column covered and uncovered must match as well as the columns chosen and
+// the rows tried, catching changes to the search which leave its solutions
+// alone. The problem is solved with the MinRemaining heuristic, which is
+// deterministic, followed by opts, e.g. WithLimit to keep the trace of a
+// larger instance short.
+//
+// The golden file is written, along with its directory, when UpdateEnv is
+// set; the new file should be checked in after reviewing the change to the
+// search which required it.
+func GoldenTrace(t testing.TB, prob gox.Solver, path string, opts ...gox.Option) {
+	t.Helper()
+	var trace bytes.Buffer
+	opts = append([]gox.Option{gox.WithHeuristic(gox.MinRemaining), gox.WithEventStream(&trace)}, opts...)
+	if _, err := prob.SolveContext(context.Background(), opts...); err != nil {
+		t.Fatalf("Error solving problem for %s: %v", path, err)
+	}
+
+	golden, err := os.ReadFile(path)
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Error creating directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, trace.Bytes(), 0644); err != nil {
+			t.Fatalf("Error writing golden trace: %v", err)
+		}
+		t.Logf("Wrote golden trace %s", path)
+		return
+	} else if os.IsNotExist(err) {
+		t.Fatalf("No golden trace %s, run with %s=1 to record it", path, UpdateEnv)
+	} else if err != nil {
+		t.Fatalf("Error reading golden trace: %v", err)
+	}
+
+	want, got := bufio.NewScanner(bytes.NewReader(golden)), bufio.NewScanner(&trace)
+	for step := 1; ; step++ {
+		wantOK, gotOK := want.Scan(), got.Scan()
+		switch {
+		case !wantOK && !gotOK:
+			return
+		case !gotOK:
+			t.Fatalf("Search of %s ended at step %d, expected %s", path, step, want.Text())
+		case !wantOK:
+			t.Fatalf("Search of %s continued past the %d steps expected with %s", path, step-1, got.Text())
+		case want.Text() != got.Text():
+			t.Fatalf("Search of %s differs at step %d:\nexpected %s\ngot      %s\nrun with %s=1 to accept the new trace", path, step, want.Text(), got.Text(), UpdateEnv)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package goxtest
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/testgen"
+)
+
+// goldenFixture is an instance whose search is checked against a golden trace
+type goldenFixture struct {
+	name string
+	prob func() (gox.Solver, error)
+	opts []gox.Option
+}
+
+// goldenFixtures are small instances exercising colours, secondary columns
+// and dead ends, whose traces are in testdata
+var goldenFixtures = []goldenFixture{
+	{name: "knuth", prob: func() (gox.Solver, error) {
+		// The example of colours in The Art of Computer Programming,
+		// Volume 4B
+		b := gox.NewBuilder()
+		b.AddColumns("p", "q", "r")
+		b.AddSecondaryColumns("x", "y")
+		b.AddRow("p q x y:A", "p", "q", "x", "y:A")
+		b.AddRow("p r x:A y", "p", "r", "x:A", "y")
+		b.AddRow("p x:B", "p", "x:B")
+		b.AddRow("q x:A", "q", "x:A")
+		b.AddRow("r y:B", "r", "y:B")
+		prob, err := b.Build()
+		if err != nil {
+			return nil, err
+		}
+		return prob, nil
+	}},
+	{name: "uniform", prob: generated(1, testgen.Config{Rows: 30, Columns: 10, Secondary: 2, Colors: 2, Density: 0.3, Planted: true})},
+	{name: "intervals", prob: generated(2, testgen.Config{Rows: 25, Columns: 12, Density: 0.3, Planted: true, Pattern: testgen.Intervals}),
+		opts: []gox.Option{gox.WithLimit(3)}},
+}
+
+// generated returns a function creating the instance generated by testgen
+// from a seed
+func generated(seed int64, c testgen.Config) func() (gox.Solver, error) {
+	return func() (gox.Solver, error) {
+		inst, err := testgen.Generate(rand.New(rand.NewSource(seed)), c)
+		if err != nil {
+			return nil, err
+		}
+		return inst.Problem()
+	}
+}
+
+func TestGoldenTrace(t *testing.T) {
+	for _, f := range goldenFixtures {
+		t.Run(f.name, func(t *testing.T) {
+			prob, err := f.prob()
+			if err != nil {
+				t.Fatalf("Error creating problem: %v", err)
+			}
+			GoldenTrace(t, prob, filepath.Join("testdata", f.name+".jsonl"), f.opts...)
+		})
+	}
+}
+
+// fatalRecorder records the failure of a test helper, stopping its goroutine
+// as testing.T does
+type fatalRecorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *fatalRecorder) Helper()                                 {}
+func (r *fatalRecorder) Logf(format string, args ...interface{}) {}
+func (r *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	r.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func TestGoldenTraceDiffers(t *testing.T) {
+	prob, err := goldenFixtures[0].prob()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	golden, err := os.ReadFile(filepath.Join("testdata", "knuth.jsonl"))
+	if err != nil {
+		t.Fatalf("Error reading golden trace: %v", err)
+	}
+	lines := strings.SplitAfter(string(golden), "\n")
+	dir := t.TempDir()
+	for _, c := range []struct {
+		name, golden, failure string
+	}{
+		{"same", string(golden), ""},
+		{"changed", strings.Join(lines[:2], "") + `{"kind":"choose","depth":0,"column":"r","size":2,"remaining":3}` + "\n" + strings.Join(lines[3:], ""), "differs at step 3"},
+		{"shorter", strings.Join(lines[:5], ""), "continued past the 5 steps"},
+		{"longer", string(golden) + lines[0], "ended at step"},
+		{"missing", "", "No golden trace"},
+	} {
+		path := filepath.Join(dir, c.name+".jsonl")
+		if c.name != "missing" {
+			if err := os.WriteFile(path, []byte(c.golden), 0644); err != nil {
+				t.Fatalf("Error writing golden trace: %v", err)
+			}
+		}
+		r := &fatalRecorder{TB: t}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			GoldenTrace(r, prob, path)
+		}()
+		<-done
+		if (c.failure == "") != (r.failure == "") || !strings.Contains(r.failure, c.failure) {
+			t.Fatalf("%s: expected failure containing %q, got %q", c.name, c.failure, r.failure)
+		}
+	}
+}
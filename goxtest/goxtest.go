@@ -0,0 +1,218 @@
+// Package goxtest provides random exact cover instances for property tests
+// with testing/quick, such as checking that a puzzle's encoding as an exact
+// cover problem is solvable exactly when the puzzle is. Instance and
+// Solvable implement quick.Generator, so they may be the arguments of the
+// properties passed to quick.Check, and Check shrinks a failing instance to a
+// smaller one which still fails, which is usually far easier to debug.
+//
+// The instances are generated by the testgen package. GoldenTrace checks
+// that the search of an instance explores the same tree as when its golden
+// trace was recorded, to catch changes to the search itself.
+package goxtest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing/quick"
+
+	"github.com/ifross89/gox/testgen"
+)
+
+// maxColumns bounds the primary columns of a generated instance, so that
+// even the largest instances are solved quickly
+const maxColumns = 12
+
+// Instance is a random exact cover problem, which may or may not have a
+// solution. Its Problem and Matrix methods build it for gox.
+type Instance struct {
+	testgen.Instance
+	// Config is the configuration the instance was generated with
+	Config testgen.Config
+}
+
+// Generate generates a random instance for testing/quick, with a random
+// pattern and a number of columns growing with size
+func (Instance) Generate(rng *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(generate(rng, size, rng.Intn(2) == 0))
+}
+
+// Shrink returns the instances smaller than this one by a single row or
+// column. Columns are removed from the rows covering them, and rows left
+// covering nothing are removed. The rows of a planted solution are kept, so
+// that it remains a solution, and so is the last primary column.
+func (inst Instance) Shrink() []Instance {
+	var ret []Instance
+	for i, r := range inst.Rows {
+		if !inst.planted(r.Name) {
+			ret = append(ret, inst.withoutRow(i))
+		}
+	}
+	if len(inst.Primary) > 1 {
+		for _, col := range inst.Primary {
+			ret = append(ret, inst.withoutColumn(col))
+		}
+	}
+	for _, col := range inst.Secondary {
+		ret = append(ret, inst.withoutColumn(col))
+	}
+	return ret
+}
+
+// String returns the instance in the format of Knuth's dlx programs, as read
+// by format.ReadDLX, each row preceded by a comment naming it
+func (inst Instance) String() string {
+	var b strings.Builder
+	b.WriteString(strings.Join(inst.Primary, " "))
+	if len(inst.Secondary) > 0 {
+		fmt.Fprintf(&b, " | %s", strings.Join(inst.Secondary, " "))
+	}
+	for _, r := range inst.Rows {
+		fmt.Fprintf(&b, "\n| %s\n%s", r.Name, strings.Join(r.Items, " "))
+	}
+	if inst.Planted != nil {
+		fmt.Fprintf(&b, "\n| planted: %s", strings.Join(inst.Planted, " "))
+	}
+	return b.String()
+}
+
+// Solvable is a random exact cover problem with at least one solution, whose
+// rows are listed in Planted
+type Solvable struct {
+	Instance
+}
+
+// Generate generates a random solvable instance for testing/quick, with a
+// random pattern and a number of columns growing with size
+func (Solvable) Generate(rng *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Solvable{generate(rng, size, true)})
+}
+
+// Shrink returns the solvable instances smaller than this one, as for
+// Instance.Shrink
+func (s Solvable) Shrink() []Solvable {
+	shrunk := s.Instance.Shrink()
+	ret := make([]Solvable, len(shrunk))
+	for i, inst := range shrunk {
+		ret[i] = Solvable{inst}
+	}
+	return ret
+}
+
+// generate generates an instance with a random configuration for the size
+// given by testing/quick
+func generate(rng *rand.Rand, size int, planted bool) Instance {
+	cols := 1 + size/4
+	if cols > maxColumns {
+		cols = maxColumns
+	}
+	c := testgen.Config{
+		Columns: 1 + rng.Intn(cols),
+		Density: 0.1 + 0.4*rng.Float64(),
+		Planted: planted,
+		Pattern: testgen.Patterns[rng.Intn(len(testgen.Patterns))],
+	}
+	c.Rows = rng.Intn(2*c.Columns + 1)
+	if rng.Intn(2) == 0 {
+		c.Secondary = rng.Intn(3)
+		c.Colors = rng.Intn(3)
+	}
+	inst, err := testgen.Generate(rng, c)
+	if err != nil {
+		// The configuration is always valid
+		panic(err)
+	}
+	return Instance{Instance: *inst, Config: c}
+}
+
+// planted reports whether a row is part of the planted solution
+func (inst Instance) planted(name string) bool {
+	for _, p := range inst.Planted {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutRow returns a copy of the instance without its ith row
+func (inst Instance) withoutRow(i int) Instance {
+	ret := inst
+	ret.Rows = append(append([]testgen.Row(nil), inst.Rows[:i]...), inst.Rows[i+1:]...)
+	return ret
+}
+
+// withoutColumn returns a copy of the instance without a column, removing it
+// from the rows covering it, and removing those rows if they are left
+// covering nothing
+func (inst Instance) withoutColumn(col string) Instance {
+	ret := inst
+	ret.Primary = without(inst.Primary, col)
+	ret.Secondary = without(inst.Secondary, col)
+	ret.Rows = nil
+	ret.Planted = nil
+	for _, r := range inst.Rows {
+		var items []string
+		for _, item := range r.Items {
+			if name := strings.SplitN(item, ":", 2)[0]; name != col {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 && len(r.Items) > 0 {
+			continue
+		}
+		ret.Rows = append(ret.Rows, testgen.Row{Name: r.Name, Items: items})
+		if inst.planted(r.Name) {
+			ret.Planted = append(ret.Planted, r.Name)
+		}
+	}
+	if inst.Planted != nil && ret.Planted == nil {
+		ret.Planted = []string{}
+	}
+	return ret
+}
+
+// without returns a copy of names without name
+func without(names []string, name string) []string {
+	var ret []string
+	for _, n := range names {
+		if n != name {
+			ret = append(ret, n)
+		}
+	}
+	return ret
+}
+
+// Check calls quick.Check with f, a function taking an Instance or Solvable
+// and returning a bool. If f fails, the instance it failed for is shrunk
+// while f still fails for it, and the *quick.CheckError returned holds the
+// smallest instance found instead of the one generated.
+func Check(f interface{}, c *quick.Config) error {
+	err := quick.Check(f, c)
+	failed, ok := err.(*quick.CheckError)
+	if !ok || len(failed.In) != 1 {
+		return err
+	}
+	fn := reflect.ValueOf(f)
+	in := reflect.ValueOf(failed.In[0])
+	shrink := in.MethodByName("Shrink")
+	if !shrink.IsValid() {
+		return err
+	}
+	for {
+		candidates := shrink.Call(nil)[0]
+		next := -1
+		for i := 0; i < candidates.Len() && next < 0; i++ {
+			if !fn.Call([]reflect.Value{candidates.Index(i)})[0].Bool() {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		in = candidates.Index(next)
+		shrink = in.MethodByName("Shrink")
+	}
+	return &quick.CheckError{Count: failed.Count, In: []interface{}{in.Interface()}}
+}
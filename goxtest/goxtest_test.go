@@ -0,0 +1,57 @@
+package goxtest
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+func TestSolvable(t *testing.T) {
+	f := func(s Solvable) bool {
+		prob, err := s.Problem()
+		if err != nil {
+			t.Logf("Error creating problem: %v", err)
+			return false
+		}
+		if err := prob.Verify(s.Planted); err != nil {
+			t.Logf("Planted solution is invalid: %v", err)
+			return false
+		}
+		for _, shrunk := range s.Shrink() {
+			prob, err := shrunk.Problem()
+			if err != nil {
+				t.Logf("Error creating shrunk problem: %v", err)
+				return false
+			}
+			if err := prob.Verify(shrunk.Planted); err != nil {
+				t.Logf("Planted solution of shrunk instance is invalid: %v\n%v", err, shrunk)
+				return false
+			}
+		}
+		return true
+	}
+	if err := Check(f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckShrinks(t *testing.T) {
+	// Fails for any instance with a column c3, which shrinks away every
+	// other column and every row not planted
+	f := func(inst Instance) bool {
+		for _, col := range inst.Primary {
+			if col == "c3" {
+				return false
+			}
+		}
+		return true
+	}
+	err := Check(f, &quick.Config{MaxCount: 1000})
+	failed, ok := err.(*quick.CheckError)
+	if !ok {
+		t.Fatalf("Expected check to fail, got %v", err)
+	}
+	inst := failed.In[0].(Instance)
+	if len(inst.Primary) != 1 || len(inst.Secondary) != 0 || len(inst.Rows) > 1 {
+		t.Errorf("Expected instance to shrink to column c3 and at most one row, got\n%v", inst)
+	}
+}
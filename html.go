@@ -0,0 +1,108 @@
+package gox
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// HTMLReport holds the results of solving a problem, to be written as a
+// single HTML page which can be opened in any browser and shared without
+// other files. The solutions are shown in a table which can be filtered by
+// row name.
+type HTMLReport struct {
+	// Title is shown at the top of the page, e.g. the name of the problem
+	Title string
+	// Instance describes the problem, see Report
+	Instance *InstanceReport
+	// Stats, if not nil, are the statistics of the search, see WithStats
+	Stats *Stats
+	// Elapsed is the time taken by the search, left out if zero
+	Elapsed time.Duration
+	// Complete is false if the search was stopped before every solution was
+	// found
+	Complete  bool
+	Solutions [][]string
+}
+
+// Write writes the report as an HTML page
+func (r *HTMLReport) Write(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"inc":  func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.2em; margin-top: 1.5em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.75em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+#filter { margin-bottom: 0.5em; padding: 0.25em; width: 20em; }
+.incomplete { color: #a60; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Instance}}
+<h2>Instance</h2>
+<table>
+<tr><th>Rows</th><td>{{.Rows}}</td></tr>
+<tr><th>Primary columns</th><td>{{.PrimaryColumns}}</td></tr>
+<tr><th>Secondary columns</th><td>{{.SecondaryColumns}}</td></tr>
+<tr><th>Nodes</th><td>{{.Nodes}}</td></tr>
+<tr><th>Density</th><td>{{printf "%.4f" .Density}}</td></tr>
+<tr><th>Rows per column</th><td>min {{.MinColumn}}, max {{.MaxColumn}}, mean {{printf "%.2f" .MeanColumn}}</td></tr>
+<tr><th>Duplicate rows</th><td>{{.DuplicateRows}}</td></tr>
+<tr><th>Isolated columns</th><td>{{if .IsolatedColumns}}{{join .IsolatedColumns ", "}}{{else}}none{{end}}</td></tr>
+</table>
+{{end}}
+<h2>Search</h2>
+<table>
+<tr><th>Solutions</th><td>{{len .Solutions}}{{if not .Complete}} <span class="incomplete">(stopped before every solution was found)</span>{{end}}</td></tr>
+{{with .Stats}}<tr><th>Nodes visited</th><td>{{.Nodes}}</td></tr>
+<tr><th>Updates</th><td>{{.Updates}}</td></tr>
+{{end}}{{if .Elapsed}}<tr><th>Time</th><td>{{.Elapsed}}</td></tr>
+{{end}}</table>
+<h2>Solutions</h2>
+{{if .Solutions}}
+<input id="filter" type="search" placeholder="Filter by row name" oninput="filterSolutions(this.value)">
+<p id="shown">Showing {{len .Solutions}} of {{len .Solutions}}</p>
+<table id="solutions">
+<thead><tr><th>#</th><th>Rows</th></tr></thead>
+<tbody>
+{{range $i, $soln := .Solutions}}<tr><td>{{inc $i}}</td><td>{{join $soln " "}}</td></tr>
+{{end}}</tbody>
+</table>
+<script>
+function filterSolutions(text) {
+	var terms = text.toLowerCase().split(/\s+/).filter(function(t) { return t; });
+	var rows = document.querySelectorAll("#solutions tbody tr");
+	var shown = 0;
+	rows.forEach(function(row) {
+		var names = row.cells[1].textContent.toLowerCase().split(" ");
+		var match = terms.every(function(t) {
+			return names.some(function(n) { return n.indexOf(t) >= 0; });
+		});
+		row.style.display = match ? "" : "none";
+		if (match) {
+			shown++;
+		}
+	});
+	document.getElementById("shown").textContent = "Showing " + shown + " of " + rows.length;
+}
+</script>
+{{else}}
+<p>No solutions were found.</p>
+{{end}}
+</body>
+</html>
+`))
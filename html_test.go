@@ -0,0 +1,69 @@
+package gox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTMLReport(t *testing.T) {
+	b := NewBuilder()
+	if err := b.AddColumns("a", "b"); err != nil {
+		t.Fatalf("Error adding columns: %v", err)
+	}
+	for _, row := range [][]string{{"<x>", "a"}, {"y", "b"}, {"z", "a", "b"}} {
+		if err := b.AddRow(row[0], row[1:]...); err != nil {
+			t.Fatalf("Error adding row: %v", err)
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var stats Stats
+	solns, err := prob.SolveContext(context.Background(), WithStats(&stats))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+
+	r := &HTMLReport{
+		Title:     "a & b",
+		Instance:  prob.Report(),
+		Stats:     &stats,
+		Elapsed:   time.Second,
+		Complete:  true,
+		Solutions: solns,
+	}
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>a &amp; b</title>",
+		"<tr><th>Rows</th><td>3</td></tr>",
+		"<tr><th>Solutions</th><td>2</td></tr>",
+		"<tr><th>Time</th><td>1s</td></tr>",
+		"<td>&lt;x&gt; y</td>",
+		"<td>z</td>",
+		"Showing 2 of 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Expected report to contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "stopped before") {
+		t.Fatalf("Expected a complete search:\n%s", out)
+	}
+
+	buf.Reset()
+	r = &HTMLReport{Title: "none"}
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No solutions were found") || strings.Contains(buf.String(), "Instance") {
+		t.Fatalf("Unexpected report without solutions or instance:\n%s", buf.String())
+	}
+}
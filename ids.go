@@ -0,0 +1,143 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// NewExactCoverProblemIDs creates a new exact cover problem like
+// NewExactCoverProblem, but identifies the rows by the integers ids rather
+// than by names. No names are stored or compared, which saves memory and time
+// for large, generated problems; use SolveIDs and RowIsSolutionID to work
+// with the identifiers. The methods taking and returning names still work,
+// the name of a row being its identifier in decimal.
+//
+// Preprocessing with WithMergeDuplicates or WithDominatedRows reports the rows
+// it removes by name, so with either of them the names are stored after all.
+func NewExactCoverProblemIDs(m [][]bool, ids []int64, opts ...ProblemOption) (*exactCoverProblem, error) {
+	ret := &exactCoverProblem{}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	span := ret.startBuild()
+
+	if err := ret.checkIDs(m, ids); err != nil {
+		ret.endBuild(span, err)
+		return nil, err
+	}
+
+	ret.numCols = len(m[0])
+	ret.numPrimary = ret.numCols
+	ret.rowsByName = make(map[string]*rowHeader)
+	ret.rowsByID = make(map[int64]*rowHeader, len(ids))
+
+	ret.root = &node{colIndex: -1}
+	ret.root.right = ret.root
+	ret.root.left = ret.root
+
+	ret.allocateColHeaders()
+	ret.initializeColHeaders()
+	var names []string
+	if ret.mergeDuplicates || ret.removeDominated {
+		names = make([]string, len(ids))
+		for i, id := range ids {
+			names[i] = strconv.FormatInt(id, 10)
+		}
+	}
+	if err := ret.createNodes(m, names, ids); err != nil {
+		ret.endBuild(span, err)
+		return nil, err
+	}
+	ret.numRows = len(ret.rowHeaders)
+	ret.endBuild(span, nil)
+	ret.setState(Ready)
+	return ret, nil
+}
+
+// checkIDs makes sure the inputs of NewExactCoverProblemIDs are sane,
+// returning an *InputError listing each problem found
+func (p *exactCoverProblem) checkIDs(m [][]bool, ids []int64) error {
+	problems := p.checkMatrix(m, len(ids), "IDs")
+	seen := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		if j, ok := seen[id]; ok {
+			problems = append(problems, InputProblem{
+				Row: i,
+				Msg: fmt.Sprintf("Duplicate row ID present: ids[%d] = ids[%d] = %d", j, i, id),
+			})
+			continue
+		}
+		seen[id] = i
+	}
+	if problems != nil {
+		return &InputError{Problems: problems}
+	}
+	return nil
+}
+
+// SolveIDs finds the solutions to a problem created by
+// NewExactCoverProblemIDs like SolveContext, but returns each solution as the
+// identifiers of its rows. The names of the rows are only formatted if
+// WithSolutionFunc is given.
+func (p *exactCoverProblem) SolveIDs(ctx context.Context, opts ...Option) ([][]int64, error) {
+	if p.rowsByID == nil {
+		return nil, fmt.Errorf("Problem has no row IDs, it was not created by NewExactCoverProblemIDs")
+	}
+	var ret [][]int64
+	err := p.solve(ctx, "SolveIDs", opts, func(c *config, rows []*rowHeader) {
+		if !c.discard {
+			soln := make([]int64, len(rows))
+			for i, r := range rows {
+				soln[i] = r.id
+			}
+			ret = append(ret, soln)
+		}
+		if c.onSolution != nil {
+			c.onSolution(rowNames(rows))
+		}
+	})
+	return ret, err
+}
+
+// RowIsSolutionID gives the row with the identifier id as part of the
+// solution, like RowIsSolution
+func (p *exactCoverProblem) RowIsSolutionID(id int64) (err error) {
+	if err := p.acquire("RowIsSolutionID"); err != nil {
+		return err
+	}
+	defer p.finish(&err)
+	header := p.rowsByID[id]
+	if header == nil {
+		return fmt.Errorf("No row found with ID %d", id)
+	}
+	if header.first == nil {
+		return fmt.Errorf("Row %d covers no columns, so cannot be part of a solution", id)
+	}
+	p.give(header)
+	header.given = true
+	return nil
+}
+
+// row returns the row header with the given name, or nil if there is none.
+// The rows of a problem created by NewExactCoverProblemIDs are found by
+// parsing the name as an identifier.
+func (p *exactCoverProblem) row(name string) *rowHeader {
+	if header := p.rowsByName[name]; header != nil || p.rowsByID == nil {
+		return header
+	}
+	id, err := strconv.ParseInt(name, 10, 64)
+	if err != nil || strconv.FormatInt(id, 10) != name {
+		return nil
+	}
+	return p.rowsByID[id]
+}
+
+// label returns the name of a row, which for a row identified by an integer
+// is the identifier in decimal
+func (r *rowHeader) label() string {
+	if r.name == "" {
+		return strconv.FormatInt(r.id, 10)
+	}
+	return r.name
+}
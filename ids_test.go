@@ -0,0 +1,91 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExactCoverProblemIDs(t *testing.T) {
+	mat := successfulCoverTests[0].mat
+	ids := []int64{10, 20, 30, 40, 50, -60}
+	prob, err := NewExactCoverProblemIDs(mat, ids)
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	if len(prob.rowsByName) != 0 {
+		t.Fatalf("Expected no row names to be stored, got %d", len(prob.rowsByName))
+	}
+	solns, err := prob.SolveIDs(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if want := [][]int64{{20, 40, -60}}; !reflect.DeepEqual(solns, want) {
+		t.Fatalf("Expected solutions %v, got %v", want, solns)
+	}
+
+	// The names of the rows are their identifiers
+	assertStringSliceEqual(t, prob.Rows(), []string{"10", "20", "30", "40", "50", "-60"})
+	if err := prob.Verify([]string{"20", "40", "-60"}); err != nil {
+		t.Fatalf("Expected solution to be valid: %v", err)
+	}
+	if err := prob.Verify([]string{"020", "40", "-60"}); err == nil {
+		t.Fatalf("Expected row 020 to be unknown")
+	}
+
+	if err := prob.RowIsSolutionID(70); err == nil {
+		t.Fatalf("Expected error giving unknown row")
+	}
+	if err := prob.RowIsSolutionID(40); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	solns, err = prob.SolveIDs(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if want := [][]int64{{40, -60, 20}}; !reflect.DeepEqual(solns, want) {
+		t.Fatalf("Expected solutions %v with row 40 given, got %v", want, solns)
+	}
+}
+
+func TestNewExactCoverProblemIDsInvalid(t *testing.T) {
+	mat := successfulCoverTests[1].mat
+	_, err := NewExactCoverProblemIDs(mat, []int64{1, 2, 1, 3, 2})
+	ierr, ok := err.(*InputError)
+	if !ok {
+		t.Fatalf("Expected *InputError, got %v", err)
+	}
+	if len(ierr.Problems) != 2 || ierr.Problems[0].Row != 2 || ierr.Problems[1].Row != 4 {
+		t.Fatalf("Expected duplicate IDs at rows 2 and 4, got %v", ierr)
+	}
+
+	named, err := NewExactCoverProblem(mat, successfulCoverTests[1].names)
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	if _, err := named.SolveIDs(context.Background()); err == nil {
+		t.Fatalf("Expected error solving a problem without IDs")
+	}
+}
+
+func TestExactCoverProblemIDsPreprocessed(t *testing.T) {
+	mat := [][]bool{
+		{true, false},
+		{true, false},
+		{false, true},
+	}
+	prob, err := NewExactCoverProblemIDs(mat, []int64{1, 2, 3}, WithMergeDuplicates())
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	solns, err := prob.SolveIDs(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if want := [][]int64{{1, 3}}; !reflect.DeepEqual(solns, want) {
+		t.Fatalf("Expected solutions %v, got %v", want, solns)
+	}
+	if dups := prob.Preprocessed().DuplicateRows["1"]; !reflect.DeepEqual(dups, []string{"2"}) {
+		t.Fatalf("Expected row 2 to be merged into row 1, got %v", dups)
+	}
+}
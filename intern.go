@@ -0,0 +1,80 @@
+package gox
+
+import "strings"
+
+// internChunk is the size of the buffers row names are copied into when they
+// are not known in advance, as for NewExactCoverProblemReader
+const internChunk = 64 << 10
+
+// nameTable interns the names of the rows of a problem. Rather than each name
+// being allocated by itself, the names are copied one after another into a
+// few large buffers, and each row's name is a slice of one. Problems with
+// hundreds of thousands of rows named after a pattern such as "R5C7#3" then
+// take far less memory, as each name has no allocation overhead, and the
+// names looked up by rowsByName are close together.
+type nameTable struct {
+	// buf is the buffer being filled. A strings.Builder never moves what it
+	// has written while it has the capacity, so the names sliced from it stay
+	// valid, and a new builder is started once it is full.
+	buf *strings.Builder
+}
+
+// grow makes room for n more bytes, starting a new buffer of at least size
+// bytes if the current one is full
+func (t *nameTable) grow(n, size int) {
+	if t.buf != nil && t.buf.Cap()-t.buf.Len() >= n {
+		return
+	}
+	if size < n {
+		size = n
+	}
+	t.buf = &strings.Builder{}
+	t.buf.Grow(size)
+}
+
+// intern returns a copy of name held by the table
+func (t *nameTable) intern(name string) string {
+	if name == "" {
+		return ""
+	}
+	t.grow(len(name), internChunk)
+	start := t.buf.Len()
+	t.buf.WriteString(name)
+	return t.buf.String()[start:]
+}
+
+// join returns the fields joined by single spaces, held by the table, without
+// allocating the name by itself first
+func (t *nameTable) join(fields []string) string {
+	n := len(fields) - 1
+	for _, f := range fields {
+		n += len(f)
+	}
+	if n <= 0 {
+		return ""
+	}
+	t.grow(n, internChunk)
+	start := t.buf.Len()
+	for i, f := range fields {
+		if i > 0 {
+			t.buf.WriteByte(' ')
+		}
+		t.buf.WriteString(f)
+	}
+	return t.buf.String()[start:]
+}
+
+// internRows interns the names of rows, in a single buffer holding them all
+func (t *nameTable) internRows(rows []sparseRow) {
+	n := 0
+	for _, r := range rows {
+		n += len(r.name)
+	}
+	if n == 0 {
+		return
+	}
+	t.grow(n, n)
+	for i := range rows {
+		rows[i].name = t.intern(rows[i].name)
+	}
+}
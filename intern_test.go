@@ -0,0 +1,42 @@
+package gox
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNameTable(t *testing.T) {
+	// Enough names to fill several buffers, each of which must be left
+	// unchanged by those interned after it
+	var table nameTable
+	var want, got []string
+	for i := 0; i < 3*internChunk/8; i++ {
+		name := fmt.Sprintf("R%dC%d#%d", i%9, i/9%9, i)
+		want = append(want, name)
+		if i%2 == 0 {
+			got = append(got, table.intern(name))
+		} else {
+			got = append(got, table.join(strings.SplitAfterN(name, "#", 2)))
+		}
+	}
+	for i := range want {
+		if i%2 == 1 {
+			want[i] = strings.Replace(want[i], "#", "# ", 1)
+		}
+		if got[i] != want[i] {
+			t.Fatalf("Expected name %d to be %s, got %s", i, want[i], got[i])
+		}
+	}
+	if table.intern("") != "" || table.join(nil) != "" {
+		t.Fatal("Expected empty names")
+	}
+
+	// The names of rows given in advance share a single buffer
+	rows := []sparseRow{{name: "a"}, {name: ""}, {name: "bc"}}
+	table = nameTable{}
+	table.internRows(rows)
+	if table.buf.String() != "abc" || rows[0].name != "a" || rows[1].name != "" || rows[2].name != "bc" {
+		t.Fatalf("Unexpected names interned: %q %+v", table.buf.String(), rows)
+	}
+}
@@ -0,0 +1,238 @@
+// Package jobs provides the store of problems being solved in the background
+// which is shared by the services built on gox, such as the HTTP server and
+// the gRPC server, so that each only has to translate its requests and
+// responses.
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ifross89/gox"
+)
+
+// State is the state of the search of a job
+type State int
+
+// States of a job
+const (
+	Running State = iota
+	Done
+	Cancelled
+	TimedOut
+)
+
+// Snapshot describes a job at a moment of its search
+type Snapshot struct {
+	State State
+	// Solutions is the number of solutions found so far
+	Solutions int
+	// Complete is true once every solution has been found
+	Complete bool
+	// Stats holds the statistics of the search once it has finished
+	Stats gox.Stats
+	// Seconds is the time spent searching so far
+	Seconds float64
+}
+
+// Job is a problem being solved, or which has been solved
+type Job struct {
+	id     string
+	limit  int
+	start  time.Time
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	solutions [][]string
+	state     State
+	stats     gox.Stats
+	end       time.Time
+	// changed is closed and replaced whenever a solution is found or the
+	// state changes, to wake up the streams of solutions
+	changed chan struct{}
+}
+
+// ID returns the id the job was given by its store
+func (j *Job) ID() string {
+	return j.id
+}
+
+// notify wakes up anything waiting for the job to change. j.mu must be held.
+func (j *Job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// Snapshot returns the state and progress of the job
+func (j *Job) Snapshot() Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	end := j.end
+	if j.state == Running {
+		end = time.Now()
+	}
+	return Snapshot{
+		State:     j.state,
+		Solutions: len(j.solutions),
+		Complete:  j.state == Done && (j.limit <= 0 || len(j.solutions) < j.limit),
+		Stats:     j.stats,
+		Seconds:   end.Sub(j.start).Seconds(),
+	}
+}
+
+// Cancel stops the search, returning once it has stopped
+func (j *Job) Cancel() {
+	j.cancel()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for j.state == Running {
+		ch := j.changed
+		j.mu.Unlock()
+		<-ch
+		j.mu.Lock()
+	}
+}
+
+// Stream passes the solutions to send as they are found, a batch at a time,
+// returning once the search has finished and every solution has been sent,
+// or with the error of send or ctx
+func (j *Job) Stream(ctx context.Context, send func(solns [][]string) error) error {
+	sent := 0
+	for {
+		j.mu.Lock()
+		solns := j.solutions[sent:]
+		running := j.state == Running
+		changed := j.changed
+		j.mu.Unlock()
+
+		if len(solns) > 0 {
+			if err := send(solns); err != nil {
+				return err
+			}
+		}
+		sent += len(solns)
+		if !running {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// solve searches for the solutions to the job's problem, recording them as
+// they are found
+func (j *Job) solve(ctx context.Context, prob gox.Solver, h gox.Heuristic) {
+	defer j.cancel()
+	var stats gox.Stats
+	_, err := prob.SolveContext(ctx, gox.WithLimit(j.limit), gox.WithHeuristic(h), gox.WithStats(&stats), gox.WithSolutionFunc(func(soln []string) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.solutions = append(j.solutions, soln)
+		j.notify()
+	}))
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := err.(*gox.UncoverableError); ok {
+		// The problem was found to have no solutions without searching
+		err = nil
+	}
+	switch err {
+	case nil:
+		j.state = Done
+	case context.DeadlineExceeded:
+		j.state = TimedOut
+	default:
+		j.state = Cancelled
+	}
+	j.stats = stats
+	j.end = time.Now()
+	j.notify()
+}
+
+// Store holds the jobs of a server, forgetting the oldest finished job once
+// it is full
+type Store struct {
+	max int
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+	// order holds the ids of the jobs from oldest to newest
+	order []string
+	next  int
+}
+
+// NewStore creates a store which keeps at most max jobs
+func NewStore(max int) *Store {
+	return &Store{max: max, jobs: make(map[string]*Job)}
+}
+
+// Submit starts searching for at most limit solutions to prob in the
+// background, for at most timeout, each zero for no limit. It returns false
+// if the store is full and every job in it is still running.
+func (s *Store) Submit(prob gox.Solver, limit int, timeout time.Duration, h gox.Heuristic) (*Job, bool) {
+	// The search belongs to the store rather than the request, so that it
+	// carries on once the response has been written
+	ctx, cancel := context.Background(), context.CancelFunc(nil)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	j := &Job{limit: limit, start: time.Now(), cancel: cancel, state: Running, changed: make(chan struct{})}
+	if !s.add(j) {
+		cancel()
+		return nil, false
+	}
+	go j.solve(ctx, prob, h)
+	return j, true
+}
+
+// add adds a job to the store, forgetting the oldest finished job if the
+// store is full. It returns false if every job is still running.
+func (s *Store) add(j *Job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) >= s.max {
+		evicted := false
+		for i, id := range s.order {
+			if s.jobs[id].Snapshot().State != Running {
+				delete(s.jobs, id)
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				evicted = true
+				break
+			}
+		}
+		if !evicted {
+			return false
+		}
+	}
+	s.next++
+	j.id = strconv.Itoa(s.next)
+	s.jobs[j.id] = j
+	s.order = append(s.order, j.id)
+	return true
+}
+
+// Get returns the job with the given id, or false if there is none
+func (s *Store) Get(id string) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+// Close cancels every job which is still running
+func (s *Store) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		j.cancel()
+	}
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ifross89/gox"
+)
+
+// dominoes returns the problem of tiling a 2xn board with dominoes
+func dominoes(t *testing.T, n int) gox.Solver {
+	b := gox.NewBuilder()
+	cell := func(r, c int) string { return fmt.Sprintf("%d,%d", r, c) }
+	for c := 0; c < n; c++ {
+		b.AddColumns(cell(0, c), cell(1, c))
+	}
+	for c := 0; c < n; c++ {
+		b.AddRow("v"+cell(0, c), cell(0, c), cell(1, c))
+		if c > 0 {
+			for r := 0; r < 2; r++ {
+				b.AddRow("h"+cell(r, c), cell(r, c-1), cell(r, c))
+			}
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	return prob
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore(1)
+	defer s.Close()
+
+	j, ok := s.Submit(dominoes(t, 6), 0, 0, gox.MinRemaining)
+	if !ok {
+		t.Fatalf("Expected the job to be accepted")
+	}
+	var solns [][]string
+	if err := j.Stream(context.Background(), func(batch [][]string) error {
+		solns = append(solns, batch...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Error streaming solutions: %v", err)
+	}
+	snap := j.Snapshot()
+	if len(solns) != 13 || snap.State != Done || snap.Solutions != 13 || !snap.Complete || snap.Stats.Nodes == 0 {
+		t.Fatalf("Expected 13 solutions, got %d and %+v", len(solns), snap)
+	}
+
+	// The finished job is forgotten to make room for another, which is
+	// refused another while running
+	big, ok := s.Submit(dominoes(t, 60), 0, 0, gox.MinRemaining)
+	if !ok {
+		t.Fatalf("Expected the job to be accepted")
+	}
+	if _, ok := s.Get(j.ID()); ok {
+		t.Fatalf("Expected the finished job to be forgotten")
+	}
+	if _, ok := s.Submit(dominoes(t, 2), 0, 0, gox.MinRemaining); ok {
+		t.Fatalf("Expected the job to be refused while the store is full")
+	}
+	big.Cancel()
+	if snap := big.Snapshot(); snap.State != Cancelled || snap.Complete {
+		t.Fatalf("Expected the job to be cancelled, got %+v", snap)
+	}
+}
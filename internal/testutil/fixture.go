@@ -0,0 +1,76 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Row is a row of a Fixture, a secondary item taking a colour after a colon
+type Row struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+// Fixture is a problem shared by the tests of the packages which read
+// problems in their own formats
+type Fixture struct {
+	Primary   []string `json:"primary"`
+	Secondary []string `json:"secondary,omitempty"`
+	Rows      []Row    `json:"rows"`
+}
+
+// Knuth is the example of colours given by Knuth in The Art of Computer
+// Programming, Volume 4B, which has a single solution, the rows B and D
+var Knuth = Fixture{
+	Primary:   []string{"p", "q", "r"},
+	Secondary: []string{"x", "y"},
+	Rows: []Row{
+		{Name: "A", Items: []string{"p", "q", "x", "y:A"}},
+		{Name: "B", Items: []string{"p", "r", "x:A", "y"}},
+		{Name: "C", Items: []string{"p", "x:B"}},
+		{Name: "D", Items: []string{"q", "x:A"}},
+		{Name: "E", Items: []string{"r", "y:B"}},
+	},
+}
+
+// Dominoes is the problem of tiling a 2xn board with dominoes, which has
+// Fibonacci(n+1) solutions, so too many to find them all during a test once n
+// is 40 or so. The cells are named "row,column", the vertical dominoes "v"
+// and the horizontal ones "h" followed by the cell at their right.
+func Dominoes(n int) Fixture {
+	var f Fixture
+	cell := func(r, c int) string { return fmt.Sprintf("%d,%d", r, c) }
+	for c := 0; c < n; c++ {
+		f.Primary = append(f.Primary, cell(0, c), cell(1, c))
+		f.Rows = append(f.Rows, Row{Name: "v" + cell(0, c), Items: []string{cell(0, c), cell(1, c)}})
+		if c > 0 {
+			for r := 0; r < 2; r++ {
+				f.Rows = append(f.Rows, Row{Name: "h" + cell(r, c), Items: []string{cell(r, c-1), cell(r, c)}})
+			}
+		}
+	}
+	return f
+}
+
+// JSON returns the problem in the JSON format read by the servers
+func (f Fixture) JSON() string {
+	data, err := json.Marshal(f)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// DLX returns the problem in the DLX format, which has no names for the rows
+func (f Fixture) DLX() string {
+	cols := strings.Join(f.Primary, " ")
+	if len(f.Secondary) > 0 {
+		cols += " | " + strings.Join(f.Secondary, " ")
+	}
+	lines := []string{cols}
+	for _, r := range f.Rows {
+		lines = append(lines, strings.Join(r.Items, " "))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,136 @@
+// Package testutil provides a brute force exact cover solver to check the
+// dancing links solver against. The brute force solver tries every subset of
+// the rows, so it is far too slow for real problems, but it is simple enough
+// to be obviously correct, which makes it a reference for refactoring the
+// link surgery of cover and uncover. It also holds the problems shared by the
+// tests of the packages which read problems in their own formats, see Fixture.
+package testutil
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// MaxRows is the largest number of rows BruteForce accepts, as it tries
+// 2^rows subsets
+const MaxRows = 20
+
+// BruteForce returns the solutions of the exact cover problem given by a
+// matrix and the names of its rows, in the form taken by
+// gox.NewExactCoverProblem, by trying every subset of the rows. Rows with no
+// true elements are never part of a solution, as in the dancing links solver.
+// The solutions are in canonical form, see Canonical.
+func BruteForce(m [][]bool, names []string) ([]string, error) {
+	if len(m) > MaxRows {
+		return nil, fmt.Errorf("Too many rows to solve by brute force: %d > %d", len(m), MaxRows)
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+	cols := len(m[0])
+	// empty has the bits set of the rows with no true elements
+	empty := 0
+	for i, row := range m {
+		if !anyTrue(row) {
+			empty |= 1 << i
+		}
+	}
+
+	var solns [][]string
+	for set := 0; set < 1<<len(m); set++ {
+		if set&empty != 0 {
+			continue
+		}
+		counts := make([]int, cols)
+		for i, row := range m {
+			if set&(1<<i) == 0 {
+				continue
+			}
+			for j, elem := range row {
+				if elem {
+					counts[j]++
+				}
+			}
+		}
+		exact := true
+		for _, c := range counts {
+			exact = exact && c == 1
+		}
+		if !exact {
+			continue
+		}
+		var soln []string
+		for i := range m {
+			if set&(1<<i) != 0 {
+				soln = append(soln, names[i])
+			}
+		}
+		solns = append(solns, soln)
+	}
+	return Canonical(solns), nil
+}
+
+// anyTrue reports whether any element of a row is true
+func anyTrue(row []bool) bool {
+	for _, elem := range row {
+		if elem {
+			return true
+		}
+	}
+	return false
+}
+
+// Canonical sorts the rows of each solution and then the solutions, so that
+// solutions found in different orders can be compared. Each solution is
+// returned as its rows separated by spaces.
+func Canonical(solns [][]string) []string {
+	var ret []string
+	for _, soln := range solns {
+		rows := append([]string(nil), soln...)
+		sort.Strings(rows)
+		ret = append(ret, strings.Join(rows, " "))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Random returns a random matrix of the size given, each element of which is
+// true with probability density, along with names for its rows
+func Random(rng *rand.Rand, rows, cols int, density float64) ([][]bool, []string) {
+	m := make([][]bool, rows)
+	names := make([]string, rows)
+	for i := range m {
+		m[i] = make([]bool, cols)
+		for j := range m[i] {
+			m[i][j] = rng.Float64() < density
+		}
+		names[i] = fmt.Sprintf("r%d", i)
+	}
+	return m, names
+}
+
+// Compare solves random matrices of up to maxRows rows and maxCols columns
+// with solve and with BruteForce, failing the test if the solutions differ.
+// Each matrix has at least two rows and one column, so that it is accepted by
+// gox.NewExactCoverProblem.
+func Compare(t testing.TB, rng *rand.Rand, trials, maxRows, maxCols int, solve func(m [][]bool, names []string) ([][]string, error)) {
+	t.Helper()
+	for trial := 0; trial < trials; trial++ {
+		rows, cols := 2+rng.Intn(maxRows-1), 1+rng.Intn(maxCols)
+		m, names := Random(rng, rows, cols, 0.1+0.4*rng.Float64())
+		expected, err := BruteForce(m, names)
+		if err != nil {
+			t.Fatalf("Error solving by brute force: %v", err)
+		}
+		solns, err := solve(m, names)
+		if err != nil {
+			t.Fatalf("Error solving %v: %v", m, err)
+		}
+		if got := Canonical(solns); strings.Join(got, ", ") != strings.Join(expected, ", ") {
+			t.Fatalf("Solutions to %v differ: expected %q, got %q", m, expected, got)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package testutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBruteForce(t *testing.T) {
+	m := [][]bool{
+		{true, false, false, true},
+		{true, true, true, false},
+		{false, true, false, true},
+		{false, false, true, true},
+		{false, false, false, true},
+		{false, true, true, false},
+	}
+	names := []string{"A", "B", "C", "D", "E", "F"}
+	solns, err := BruteForce(m, names)
+	if err != nil {
+		t.Fatalf("Error solving: %v", err)
+	}
+	if expected := []string{"A F", "B E"}; !reflect.DeepEqual(solns, expected) {
+		t.Fatalf("Expected %q, got %q", expected, solns)
+	}
+	if _, err := BruteForce(make([][]bool, MaxRows+1), nil); err == nil {
+		t.Fatal("Expected error for too many rows")
+	}
+}
@@ -0,0 +1,100 @@
+package gox
+
+import "fmt"
+
+// WithDebug checks the invariants of the matrix, see CheckInvariants, after
+// every cover and uncover, panicking as soon as one is broken. This makes the
+// search very slow, but finds mistakes in the link surgery at the step which
+// makes them, rather than when they later cause wrong answers.
+func WithDebug() ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.debug = true
+	}
+}
+
+// CheckInvariants walks the matrix checking that it is consistent, returning
+// an error describing the first problem found. It checks that the links of
+// the active columns, of the nodes in each column and of the nodes in each
+// row are symmetric, that each column's count matches the nodes which can be
+// reached from it, and that every node in a column can be reached from the
+// header of its row, and during a search with BucketedMinRemaining, that the
+// index of the active columns by count matches them. It may be called at any time, including from the
+// functions passed to WithSolutionFunc or WithTrace during a search.
+func (p *exactCoverProblem) CheckInvariants() error {
+	// inRow records the nodes which can be reached from the row headers
+	inRow := make(map[*node]bool)
+	for _, r := range p.rowHeaders {
+		if r.first == nil {
+			continue
+		}
+		n, steps := r.first, 0
+		for {
+			if n.rowHead != r {
+				return fmt.Errorf("Node of column %s in row %s points to row %s", p.colName(n.colHead), r.label(), n.rowHead.label())
+			}
+			if n.right.left != n || n.left.right != n {
+				return fmt.Errorf("Horizontal links of node of column %s in row %s are not symmetric", p.colName(n.colHead), r.label())
+			}
+			inRow[n] = true
+			if n = n.right; n == r.first {
+				break
+			}
+			if steps++; steps > p.numCols {
+				return fmt.Errorf("Row %s does not link back to its first node", r.label())
+			}
+		}
+	}
+
+	for _, h := range p.colHeaders {
+		if h.up.down != h || h.down.up != h {
+			return fmt.Errorf("Vertical links of header of column %s are not symmetric", p.colName(h))
+		}
+		count := 0
+		for n := h.down; n != h; n = n.down {
+			if n.colHead != h {
+				return fmt.Errorf("Node of row %s in column %s points to column %s", n.rowHead.label(), p.colName(h), p.colName(n.colHead))
+			}
+			if n.up.down != n || n.down.up != n {
+				return fmt.Errorf("Vertical links of node of row %s in column %s are not symmetric", n.rowHead.label(), p.colName(h))
+			}
+			if !inRow[n] {
+				return fmt.Errorf("Node of row %s in column %s cannot be reached from its row", n.rowHead.label(), p.colName(h))
+			}
+			if count++; count > len(p.rowHeaders) {
+				return fmt.Errorf("Column %s does not link back to its header", p.colName(h))
+			}
+		}
+		if count != h.colCount {
+			return fmt.Errorf("Column %s has %d nodes but a count of %d", p.colName(h), count, h.colCount)
+		}
+	}
+
+	steps := 0
+	for h := p.root.right; h != p.root; h = h.right {
+		if h.right.left != h || h.left.right != h {
+			return fmt.Errorf("Horizontal links of header of column %s are not symmetric", p.colName(h))
+		}
+		if h.colIndex < 0 || h.colIndex >= p.numPrimary || p.colHeaders[h.colIndex] != h {
+			return fmt.Errorf("Active column %d is not a primary column header", h.colIndex)
+		}
+		if steps++; steps > p.numPrimary {
+			return fmt.Errorf("Active columns do not link back to the root")
+		}
+	}
+	if p.buckets != nil {
+		return p.buckets.check(p, steps)
+	}
+	return nil
+}
+
+// mustCheckInvariants panics if the invariants of the matrix are broken and
+// the problem was created with WithDebug. op names the operation which has
+// just been performed.
+func (p *exactCoverProblem) mustCheckInvariants(op string, head *node) {
+	if !p.debug {
+		return
+	}
+	if err := p.CheckInvariants(); err != nil {
+		panic(fmt.Sprintf("gox: invariant broken after %s of column %s: %v", op, p.colName(head), err))
+	}
+}
@@ -0,0 +1,42 @@
+package gox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("p", "q", "r")
+	b.AddSecondaryColumns("x", "y")
+	b.AddRow("A", "p", "q", "x", "y:A")
+	b.AddRow("B", "p", "r", "x:A", "y")
+	b.AddRow("C", "p", "x:B")
+	b.AddRow("D", "q", "x:A")
+	b.AddRow("E", "r", "y:B")
+	prob, err := b.Build(WithDebug())
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if solns := prob.Solve(); len(solns) != 1 {
+		t.Fatalf("Expected 1 solution, got %v", solns)
+	}
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Unexpected error after solving: %v", err)
+	}
+
+	// Break the count of a column
+	prob.colHeaders[1].colCount++
+	if err := prob.CheckInvariants(); err == nil || !strings.Contains(err.Error(), "Column q") {
+		t.Fatalf("Expected error for the count of column q, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic covering a column of a broken matrix")
+		}
+	}()
+	prob.cover(prob.colHeaders[0])
+}
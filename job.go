@@ -0,0 +1,200 @@
+package gox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// JobVersion is the version of the encoding of jobs and their results written
+// by EncodeJob and EncodeJobResult. Decoding refuses any other version, so
+// that a worker never misreads a job written by a different release.
+const JobVersion = 2
+
+// Job is a shard of a problem packaged to be solved elsewhere, such as on
+// another machine: it names the problem by its fingerprint, and carries the
+// rows given and forbidden before the search, the rows fixed by the shard and
+// the limit on the solutions to find. See Jobs and SolveJob.
+type Job struct {
+	Version int `json:"v"`
+	// Problem is the Fingerprint of the problem the job was made from
+	Problem string `json:"problem"`
+	Shard
+	// Limit is the most solutions to find, or 0 to find them all
+	Limit int `json:"limit,omitempty"`
+}
+
+// JobResult holds the solutions found for a job, see SolveJob
+type JobResult struct {
+	Version int    `json:"v"`
+	Problem string `json:"problem"`
+	// Index and Count are those of the shard of the job
+	Index int `json:"index"`
+	Count int `json:"count"`
+	// Complete is set if every solution of the job was found, and is unset
+	// if the job's limit was reached
+	Complete  bool       `json:"complete"`
+	Solutions [][]string `json:"solutions"`
+}
+
+// Fingerprint returns a hash of the columns and rows of the problem, in
+// hexadecimal, identifying it independently of the rows given with
+// RowIsSolution or forbidden by Forbid, which jobs carry themselves. Problems
+// built in the same way have the same fingerprint.
+func (p *exactCoverProblem) Fingerprint() string {
+	h := sha256.New()
+	var buf [binary.MaxVarintLen64]byte
+	writeInt := func(v int) {
+		h.Write(buf[:binary.PutVarint(buf[:], int64(v))])
+	}
+	writeString := func(s string) {
+		writeInt(len(s))
+		h.Write([]byte(s))
+	}
+
+	writeInt(p.numPrimary)
+	writeInt(len(p.colHeaders))
+	for _, c := range p.colHeaders {
+		writeString(p.colName(c))
+	}
+	writeInt(len(p.rowHeaders))
+	for _, r := range p.rowHeaders {
+		writeString(r.label())
+		for n := r.first; n != nil; {
+			writeInt(n.colIndex)
+			color := n.color
+			if color < 0 {
+				color = -color
+			}
+			if color == 0 {
+				writeString("")
+			} else {
+				writeString(p.colorName(color))
+			}
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+		writeInt(-1)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Jobs splits the problem into jobs by Shard, each finding at most limit
+// solutions, or all of them if limit is 0
+func (p *exactCoverProblem) Jobs(limit int, opts ...Option) ([]Job, error) {
+	shards, err := p.Shard(opts...)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := p.Fingerprint()
+	ret := make([]Job, len(shards))
+	for i, s := range shards {
+		ret[i] = Job{Version: JobVersion, Problem: fingerprint, Shard: s, Limit: limit}
+	}
+	return ret, nil
+}
+
+// SolveJob solves a job made by Jobs, which must have been made from a
+// problem with the same fingerprint as this one and the same rows given and
+// forbidden, as the fingerprint leaves them out; a job which does not fit is
+// refused by SolveShard. The options are passed to SolveShard, the job's
+// limit taking precedence over WithLimit.
+func (p *exactCoverProblem) SolveJob(ctx context.Context, j *Job, opts ...Option) (*JobResult, error) {
+	if j.Version != JobVersion {
+		return nil, fmt.Errorf("Job has version %d, expected %d", j.Version, JobVersion)
+	}
+	if fingerprint := p.Fingerprint(); j.Problem != fingerprint {
+		return nil, fmt.Errorf("Job is for problem %s, not %s", j.Problem, fingerprint)
+	}
+	opts = append(opts, WithLimit(j.Limit))
+	solns, err := p.SolveShard(ctx, j.Shard, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &JobResult{
+		Version:   JobVersion,
+		Problem:   j.Problem,
+		Index:     j.Index,
+		Count:     j.Count,
+		Complete:  j.Limit <= 0 || len(solns) < j.Limit,
+		Solutions: solns,
+	}, nil
+}
+
+// MergeJobResults merges the results of the jobs of a problem into its
+// solutions, in the order of the shards, which is the order SolveContext
+// would find them in with the heuristics described by Shard. An error is
+// returned unless there is exactly one result for each job of the same
+// problem. complete is unset if any job stopped at its limit.
+func MergeJobResults(results []*JobResult) (solns [][]string, complete bool, err error) {
+	if len(results) == 0 {
+		return nil, false, fmt.Errorf("No job results to merge")
+	}
+	sorted := append([]*JobResult(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	first := sorted[0]
+	if len(sorted) != first.Count {
+		return nil, false, fmt.Errorf("Expected results for %d jobs, got %d", first.Count, len(sorted))
+	}
+	complete = true
+	for i, r := range sorted {
+		switch {
+		case r.Version != JobVersion:
+			return nil, false, fmt.Errorf("Job result has version %d, expected %d", r.Version, JobVersion)
+		case r.Problem != first.Problem || r.Count != first.Count:
+			return nil, false, fmt.Errorf("Job result %d is for problem %s with %d jobs, expected %s with %d",
+				r.Index, r.Problem, r.Count, first.Problem, first.Count)
+		case r.Index != i:
+			return nil, false, fmt.Errorf("Expected one result for job %d, got result for job %d", i, r.Index)
+		}
+		solns = append(solns, r.Solutions...)
+		complete = complete && r.Complete
+	}
+	return solns, complete, nil
+}
+
+// EncodeJob encodes a job as compact JSON, on a single line
+func EncodeJob(j *Job) ([]byte, error) {
+	return json.Marshal(j)
+}
+
+// DecodeJob decodes a job encoded by EncodeJob
+func DecodeJob(data []byte) (*Job, error) {
+	j := &Job{}
+	if err := decodeVersioned(data, j, &j.Version); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// EncodeJobResult encodes the result of a job as compact JSON, on a single
+// line
+func EncodeJobResult(r *JobResult) ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// DecodeJobResult decodes the result of a job encoded by EncodeJobResult
+func DecodeJobResult(data []byte) (*JobResult, error) {
+	r := &JobResult{}
+	if err := decodeVersioned(data, r, &r.Version); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// decodeVersioned decodes JSON into v, checking the version it holds, which
+// is decoded into version
+func decodeVersioned(data []byte, v interface{}, version *int) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if *version != JobVersion {
+		return fmt.Errorf("Encoded with version %d, expected %d", *version, JobVersion)
+	}
+	return nil
+}
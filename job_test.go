@@ -0,0 +1,135 @@
+package gox_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox"
+)
+
+func TestJobs(t *testing.T) {
+	prob, err := dominoBuilder(8).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	want, err := prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	for _, limit := range []int{0, 3} {
+		jobs, err := prob.Jobs(limit)
+		if err != nil {
+			t.Fatalf("Error making jobs: %v", err)
+		}
+
+		// The jobs are solved on another copy of the problem, in reverse
+		worker, err := dominoBuilder(8).Build()
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		var results []*gox.JobResult
+		for i := len(jobs) - 1; i >= 0; i-- {
+			data, err := gox.EncodeJob(&jobs[i])
+			if err != nil {
+				t.Fatalf("Error encoding job: %v", err)
+			}
+			if strings.Contains(string(data), "\n") {
+				t.Fatalf("Expected job on one line, got %s", data)
+			}
+			j, err := gox.DecodeJob(data)
+			if err != nil {
+				t.Fatalf("Error decoding job: %v", err)
+			}
+			res, err := worker.SolveJob(context.Background(), j)
+			if err != nil {
+				t.Fatalf("Error solving job %s: %v", data, err)
+			}
+			data, err = gox.EncodeJobResult(res)
+			if err != nil {
+				t.Fatalf("Error encoding result: %v", err)
+			}
+			if res, err = gox.DecodeJobResult(data); err != nil {
+				t.Fatalf("Error decoding result: %v", err)
+			}
+			results = append(results, res)
+		}
+
+		got, complete, err := gox.MergeJobResults(results)
+		if err != nil {
+			t.Fatalf("Error merging results: %v", err)
+		}
+		if limit == 0 {
+			if !complete || !reflect.DeepEqual(got, want) {
+				t.Fatalf("Expected %v, complete, got %v, %t", want, got, complete)
+			}
+			continue
+		}
+		if complete || len(got) != limit*len(jobs) {
+			t.Fatalf("Expected %d solutions, incomplete, got %d, %t", limit*len(jobs), len(got), complete)
+		}
+		if _, _, err := gox.MergeJobResults(results[1:]); err == nil {
+			t.Fatalf("Expected an error merging with a result missing")
+		}
+		if _, _, err := gox.MergeJobResults(append(results[1:], results[1])); err == nil {
+			t.Fatalf("Expected an error merging with a result repeated")
+		}
+	}
+}
+
+func TestJobMismatch(t *testing.T) {
+	prob, err := dominoBuilder(4).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	other, err := dominoBuilder(6).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if prob.Fingerprint() == other.Fingerprint() {
+		t.Fatalf("Expected different problems to have different fingerprints")
+	}
+	jobs, err := prob.Jobs(0)
+	if err != nil {
+		t.Fatalf("Error making jobs: %v", err)
+	}
+	if _, err := other.SolveJob(context.Background(), &jobs[0]); err == nil {
+		t.Fatalf("Expected an error solving a job for another problem")
+	}
+	if _, err := gox.DecodeJob([]byte(`{"v":1,"problem":"x"}`)); err == nil {
+		t.Fatalf("Expected an error decoding a job of another version")
+	}
+
+	// Giving a row does not change the fingerprint
+	fingerprint := prob.Fingerprint()
+	if err := prob.RowIsSolution(jobs[0].Row); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if got := prob.Fingerprint(); got != fingerprint {
+		t.Fatalf("Expected fingerprint %s after giving a row, got %s", fingerprint, got)
+	}
+
+	// but a job is only solved with the same rows given and forbidden
+	if _, err := prob.SolveJob(context.Background(), &jobs[1]); err == nil {
+		t.Fatalf("Expected an error solving a job made before a row was given")
+	}
+	prob, _ = dominoBuilder(4).Build()
+	if err := prob.Forbid(jobs[1].Row); err != nil {
+		t.Fatalf("Error forbidding row: %v", err)
+	}
+	if _, err := prob.SolveJob(context.Background(), &jobs[0]); err == nil {
+		t.Fatalf("Expected an error solving a job made before a row was forbidden")
+	}
+	forbidden, err := prob.Jobs(0)
+	if err != nil {
+		t.Fatalf("Error making jobs: %v", err)
+	}
+	other, _ = dominoBuilder(4).Build()
+	if _, err := other.SolveJob(context.Background(), &forbidden[0]); err == nil {
+		t.Fatalf("Expected an error solving a job made with a row forbidden")
+	}
+	if _, err := prob.SolveJob(context.Background(), &forbidden[0]); err != nil {
+		t.Fatalf("Error solving job: %v", err)
+	}
+}
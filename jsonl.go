@@ -0,0 +1,75 @@
+package gox
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// SolutionRecord is the JSON object written by a SolutionWriter for each
+// solution
+type SolutionRecord struct {
+	// Index counts the solutions written, from 1
+	Index int      `json:"index"`
+	Rows  []string `json:"rows"`
+	// Given names the rows given with RowIsSolution, see Solution.Given
+	Given []string `json:"given,omitempty"`
+	// Coverage gives the rows covering each column, see Solution.Coverage
+	Coverage map[string][]string `json:"coverage"`
+	// Seconds is the time since the writer was created
+	Seconds float64 `json:"seconds"`
+}
+
+// SolutionWriter writes solutions as JSON lines, one SolutionRecord per line,
+// flushing each as it is written so that enormous enumerations can be piped
+// to disk or another process as they run:
+//
+//	sw := gox.NewSolutionWriter(os.Stdout, prob)
+//	_, err := prob.SolveContext(ctx, gox.WithSolutionFunc(sw.Write), gox.WithoutSolutions())
+//	...
+//	err = sw.Err()
+type SolutionWriter struct {
+	p     Solver
+	w     *bufio.Writer
+	enc   *json.Encoder
+	start time.Time
+	count int
+	err   error
+}
+
+// NewSolutionWriter creates a writer of the solutions to p which writes to w
+func NewSolutionWriter(w io.Writer, p Solver) *SolutionWriter {
+	bw := bufio.NewWriter(w)
+	return &SolutionWriter{p: p, w: bw, enc: json.NewEncoder(bw), start: time.Now()}
+}
+
+// Write writes a solution, it may be passed to WithSolutionFunc. Once writing
+// fails further solutions are ignored, and the error is returned by Err.
+func (s *SolutionWriter) Write(solution []string) {
+	if s.err != nil {
+		return
+	}
+	s.count++
+	soln := s.p.Solution(solution)
+	rec := SolutionRecord{
+		Index:    s.count,
+		Rows:     solution,
+		Given:    soln.Givens(),
+		Coverage: soln.Coverage(),
+		Seconds:  time.Since(s.start).Seconds(),
+	}
+	if s.err = s.enc.Encode(rec); s.err == nil {
+		s.err = s.w.Flush()
+	}
+}
+
+// Count returns the number of solutions written
+func (s *SolutionWriter) Count() int {
+	return s.count
+}
+
+// Err returns the first error writing a solution
+func (s *SolutionWriter) Err() error {
+	return s.err
+}
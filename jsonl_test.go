@@ -0,0 +1,65 @@
+package gox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSolutionWriter(t *testing.T) {
+	prob := dominoProblem(t, 3)
+	var buf bytes.Buffer
+	sw := NewSolutionWriter(&buf, prob)
+	solns, err := prob.SolveContext(context.Background(), WithSolutionFunc(sw.Write), WithoutSolutions(), WithLimit(2))
+	if err != nil || solns != nil {
+		t.Fatalf("Expected no solutions to be kept, got %v, %v", solns, err)
+	}
+	if sw.Err() != nil || sw.Count() != 2 {
+		t.Fatalf("Expected 2 solutions written, got %d: %v", sw.Count(), sw.Err())
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	index := 0
+	for scanner.Scan() {
+		var rec SolutionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Invalid line %q: %v", scanner.Text(), err)
+		}
+		index++
+		if rec.Index != index {
+			t.Fatalf("Expected index %d, got %d", index, rec.Index)
+		}
+		if len(rec.Coverage) != 6 {
+			t.Fatalf("Expected all 6 columns covered, got %v", rec.Coverage)
+		}
+		for _, rows := range rec.Coverage {
+			if len(rows) != 1 {
+				t.Fatalf("Expected each column covered once, got %v", rec.Coverage)
+			}
+		}
+	}
+	if index != 2 {
+		t.Fatalf("Expected 2 lines, got %d", index)
+	}
+
+	sw = NewSolutionWriter(failWriter{}, prob)
+	sw.Write([]string{"v0", "v1", "v2"})
+	sw.Write([]string{"v0", "v1", "v2"})
+	if sw.Err() == nil || sw.Count() != 1 {
+		t.Fatalf("Expected an error after the first solution, got %d: %v", sw.Count(), sw.Err())
+	}
+	if cov := prob.Solution([]string{"v0"}).Coverage(); !reflect.DeepEqual(cov, map[string][]string{"0,0": {"v0"}, "1,0": {"v0"}}) {
+		t.Fatalf("Unexpected coverage %v", cov)
+	}
+}
+
+// failWriter fails every write
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
@@ -0,0 +1,448 @@
+// Package jsonrpc drives gox with JSON-RPC 2.0 messages over a pair of
+// streams, normally the standard input and output of a subprocess, so that
+// editors, notebooks and programs in other languages can use the solver
+// without HTTP or gRPC. Each message is a JSON object on its own line. gox
+// serve -stdio runs a Server on its standard streams.
+//
+// The methods are:
+//
+//	load      {"problem": {...}} or {"format": "dlx", "data": "..."}
+//	          loads a problem in the JSON format of the format package, or
+//	          in any readable format, returning {"problem": id, ...}
+//	addGiven  {"problem": id, "rows": [...]}
+//	          gives rows which must be part of every solution
+//	solve     {"problem": id, "limit": n, "timeout": "5s", "heuristic": "mrv"}
+//	          returns {"solutions": [...], "count": n, "complete": bool},
+//	          with "timedOut" set if the timeout was reached
+//	stream    as solve, but sends each solution as a "solution" notification
+//	          {"request": id, "rows": [...]} as it is found, and leaves
+//	          them out of the result
+//	cancel    {"request": id}
+//	          stops the solve or stream call with the id given, which then
+//	          fails with the code RequestCancelled
+//
+// Calls to solve and stream run in the background, so that other calls,
+// such as cancel, are answered while they search. A problem can only be
+// searched by one call at a time.
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+// Error codes of the responses, those below -32000 being defined by JSON-RPC
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	// SolverError is returned when the solver fails, e.g. when a problem is
+	// searched by two calls at once
+	SolverError = -32000
+	// RequestCancelled is returned by a call stopped by cancel, or by the
+	// server shutting down
+	RequestCancelled = -32800
+)
+
+// Error is the error of a response
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// errorf creates an error with the given code
+func errorf(code int, format string, a ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// Config holds the limits of a Server on each call of solve or stream, which
+// are applied whatever limit the call asks for
+type Config struct {
+	// MaxSolutions is the most solutions found by a call, zero for no limit
+	MaxSolutions int
+	// MaxTimeout is the longest time spent searching by a call, zero for no
+	// limit
+	MaxTimeout time.Duration
+}
+
+// request is a request or notification read from the input, notifications
+// having no id
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is the response to a request
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// notification is a message sent without being asked for
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type loadParams struct {
+	Problem json.RawMessage `json:"problem"`
+	Format  string          `json:"format"`
+	Data    string          `json:"data"`
+}
+
+type loadResult struct {
+	Problem string `json:"problem"`
+	Rows    int    `json:"rows"`
+	Columns int    `json:"columns"`
+}
+
+type givenParams struct {
+	Problem string   `json:"problem"`
+	Rows    []string `json:"rows"`
+}
+
+type solveParams struct {
+	Problem   string `json:"problem"`
+	Limit     int    `json:"limit"`
+	Timeout   string `json:"timeout"`
+	Heuristic string `json:"heuristic"`
+}
+
+type solveResult struct {
+	Solutions [][]string `json:"solutions,omitempty"`
+	Count     int        `json:"count"`
+	// Complete is set if every solution was found, and TimedOut if the
+	// search ran out of time, returning the solutions found so far
+	Complete bool  `json:"complete"`
+	TimedOut bool  `json:"timedOut,omitempty"`
+	Nodes    int64 `json:"nodes"`
+}
+
+type solutionParams struct {
+	Request json.RawMessage `json:"request"`
+	Rows    []string        `json:"rows"`
+}
+
+type cancelParams struct {
+	Request json.RawMessage `json:"request"`
+}
+
+// Server answers the requests read from a stream
+type Server struct {
+	c Config
+
+	mu       sync.Mutex
+	problems map[string]gox.Solver
+	next     int
+	// calls holds the cancel functions of the calls to solve and stream
+	// which are running, by id
+	calls map[string]context.CancelFunc
+
+	// wmu serialises the messages written
+	wmu sync.Mutex
+	enc *json.Encoder
+}
+
+// NewServer creates a server with the limits given
+func NewServer(c Config) *Server {
+	return &Server{c: c, problems: make(map[string]gox.Solver), calls: make(map[string]context.CancelFunc)}
+}
+
+// Serve reads requests from r until it ends or ctx is cancelled, writing
+// responses and notifications to w. Calls to solve and stream still running
+// when the input ends are answered before Serve returns, so that requests may
+// be piped in; those running when ctx is cancelled fail with
+// RequestCancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.enc = json.NewEncoder(w)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<26)
+		for scanner.Scan() {
+			select {
+			case lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-ctx.Done():
+				return
+			}
+		}
+		errc <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var req request
+			if err := json.Unmarshal(line, &req); err != nil {
+				s.respond(nil, nil, errorf(ParseError, "Invalid JSON: %v", err))
+				continue
+			}
+			if req.JSONRPC != "2.0" || req.Method == "" {
+				s.respond(req.ID, nil, errorf(InvalidRequest, "Expected a JSON-RPC 2.0 request with a method"))
+				continue
+			}
+			s.handle(ctx, &wg, &req)
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// handle answers a request, starting the calls to solve and stream in the
+// background
+func (s *Server) handle(ctx context.Context, wg *sync.WaitGroup, req *request) {
+	var result interface{}
+	var err *Error
+	switch req.Method {
+	case "load":
+		result, err = s.load(req.Params)
+	case "addGiven":
+		result, err = s.addGiven(req.Params)
+	case "cancel":
+		result, err = s.cancel(req.Params)
+	case "solve", "stream":
+		var p solveParams
+		if err := unmarshal(req.Params, &p); err != nil {
+			s.respond(req.ID, nil, err)
+			return
+		}
+		id := string(req.ID)
+		s.mu.Lock()
+		_, running := s.calls[id]
+		s.mu.Unlock()
+		if req.ID == nil || running {
+			s.respond(req.ID, nil, errorf(InvalidRequest, "Calls to %s need an id not used by a running call", req.Method))
+			return
+		}
+		callCtx, cancel := context.WithCancel(ctx)
+		s.mu.Lock()
+		s.calls[id] = cancel
+		s.mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.solve(callCtx, req.ID, &p, req.Method == "stream")
+			s.mu.Lock()
+			delete(s.calls, id)
+			s.mu.Unlock()
+			cancel()
+			s.respond(req.ID, result, err)
+		}()
+		return
+	default:
+		err = errorf(MethodNotFound, "Unknown method: %s", req.Method)
+	}
+	s.respond(req.ID, result, err)
+}
+
+// respond writes the response to a request, unless it is a notification
+func (s *Server) respond(id json.RawMessage, result interface{}, err *Error) {
+	if id == nil && (err == nil || err.Code != ParseError && err.Code != InvalidRequest) {
+		return
+	}
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	resp := &response{JSONRPC: "2.0", ID: id, Result: result, Error: err}
+	if err == nil && result == nil {
+		resp.Result = struct{}{}
+	}
+	s.write(resp)
+}
+
+// write writes a message on its own line
+func (s *Server) write(v interface{}) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.enc.Encode(v)
+}
+
+// unmarshal decodes the parameters of a request
+func unmarshal(params json.RawMessage, v interface{}) *Error {
+	if params == nil {
+		return errorf(InvalidParams, "Missing parameters")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return errorf(InvalidParams, "Invalid parameters: %v", err)
+	}
+	return nil
+}
+
+// problem returns the problem with the given id
+func (s *Server) problem(id string) (gox.Solver, *Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prob, ok := s.problems[id]
+	if !ok {
+		return nil, errorf(InvalidParams, "Unknown problem: %s", id)
+	}
+	return prob, nil
+}
+
+// load creates a problem, returning its id
+func (s *Server) load(params json.RawMessage) (interface{}, *Error) {
+	var p loadParams
+	if err := unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	var in *format.Instance
+	var err error
+	switch {
+	case p.Problem != nil:
+		in, err = format.ReadJSON(bytes.NewReader(p.Problem))
+	case p.Format != "":
+		var f format.Format
+		if f, err = format.Parse(p.Format); err == nil {
+			in, err = format.Read(strings.NewReader(p.Data), f)
+		}
+	default:
+		return nil, errorf(InvalidParams, "Expected a problem, or a format and data")
+	}
+	if err != nil {
+		return nil, errorf(InvalidParams, "Invalid problem: %v", err)
+	}
+	prob, err := in.Problem()
+	if err != nil {
+		return nil, errorf(InvalidParams, "Invalid problem: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	id := strconv.Itoa(s.next)
+	s.problems[id] = prob
+	return &loadResult{Problem: id, Rows: len(in.Rows), Columns: len(in.Primary) + len(in.Secondary)}, nil
+}
+
+// addGiven gives rows of a problem
+func (s *Server) addGiven(params json.RawMessage) (interface{}, *Error) {
+	var p givenParams
+	if err := unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	prob, rerr := s.problem(p.Problem)
+	if rerr != nil {
+		return nil, rerr
+	}
+	for _, row := range p.Rows {
+		if err := prob.RowIsSolution(row); err != nil {
+			return nil, errorf(SolverError, "%v", err)
+		}
+	}
+	return nil, nil
+}
+
+// cancel stops a call to solve or stream
+func (s *Server) cancel(params json.RawMessage) (interface{}, *Error) {
+	var p cancelParams
+	if err := unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	cancel, ok := s.calls[string(p.Request)]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return map[string]bool{"cancelled": ok}, nil
+}
+
+// solve searches for the solutions to a problem, sending each as a
+// notification if stream is set
+func (s *Server) solve(ctx context.Context, id json.RawMessage, p *solveParams, stream bool) (interface{}, *Error) {
+	prob, rerr := s.problem(p.Problem)
+	if rerr != nil {
+		return nil, rerr
+	}
+	limit := s.c.MaxSolutions
+	if p.Limit < 0 {
+		return nil, errorf(InvalidParams, "Invalid limit: %d", p.Limit)
+	}
+	if p.Limit > 0 && (limit <= 0 || p.Limit < limit) {
+		limit = p.Limit
+	}
+	timeout := s.c.MaxTimeout
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil || d < 0 {
+			return nil, errorf(InvalidParams, "Invalid timeout: %s", p.Timeout)
+		}
+		if d > 0 && (timeout <= 0 || d < timeout) {
+			timeout = d
+		}
+	}
+	h := gox.MinRemaining
+	if p.Heuristic != "" {
+		var err error
+		if h, err = gox.ParseHeuristic(p.Heuristic); err != nil {
+			return nil, errorf(InvalidParams, "%v", err)
+		}
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res := &solveResult{}
+	var stats gox.Stats
+	opts := []gox.Option{gox.WithLimit(limit), gox.WithHeuristic(h), gox.WithStats(&stats)}
+	if stream {
+		opts = append(opts, gox.WithoutSolutions(), gox.WithSolutionFunc(func(soln []string) {
+			res.Count++
+			s.write(&notification{JSONRPC: "2.0", Method: "solution", Params: &solutionParams{Request: id, Rows: soln}})
+		}))
+	}
+	solns, err := prob.SolveContext(ctx, opts...)
+	if _, ok := err.(*gox.UncoverableError); ok {
+		// The problem was found to have no solutions without searching
+		err = nil
+	}
+	switch err {
+	case nil, context.DeadlineExceeded:
+	case context.Canceled:
+		return nil, errorf(RequestCancelled, "Cancelled")
+	default:
+		return nil, errorf(SolverError, "%v", err)
+	}
+	if !stream {
+		res.Solutions = solns
+		res.Count = len(solns)
+	}
+	res.Complete = err == nil && (limit <= 0 || res.Count < limit)
+	res.TimedOut = err != nil
+	res.Nodes = stats.Nodes
+	return res, nil
+}
@@ -0,0 +1,185 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+// message is a response or notification written by the server
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Params json.RawMessage `json:"params"`
+	Error  *Error          `json:"error"`
+}
+
+// client talks to a server running in the background
+type client struct {
+	t    *testing.T
+	in   *io.PipeWriter
+	out  *bufio.Scanner
+	done chan error
+}
+
+func newClient(t *testing.T, c Config) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	cl := &client{t: t, in: inW, out: bufio.NewScanner(outR), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(c).Serve(context.Background(), inR, outW)
+		outW.Close()
+		cl.done <- err
+	}()
+	return cl
+}
+
+// send writes a request
+func (c *client) send(id int, method string, params interface{}) {
+	b, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		c.t.Fatalf("Error encoding request: %v", err)
+	}
+	if _, err := c.in.Write(append(b, '\n')); err != nil {
+		c.t.Fatalf("Error writing request: %v", err)
+	}
+}
+
+// read reads the next message
+func (c *client) read() *message {
+	if !c.out.Scan() {
+		c.t.Fatalf("Expected a message, got %v", c.out.Err())
+	}
+	m := &message{}
+	if err := json.Unmarshal(c.out.Bytes(), m); err != nil {
+		c.t.Fatalf("Error decoding %s: %v", c.out.Text(), err)
+	}
+	return m
+}
+
+// readResponse reads the next message which is not a notification
+func (c *client) readResponse() *message {
+	for {
+		if m := c.read(); m.Method == "" {
+			return m
+		}
+	}
+}
+
+// call sends a request and decodes the result of its response into v
+func (c *client) call(id int, method string, params, v interface{}) {
+	c.send(id, method, params)
+	m := c.read()
+	if m.Error != nil || string(m.ID) != fmt.Sprint(id) {
+		c.t.Fatalf("Expected result for %d, got %s: %v", id, m.ID, m.Error)
+	}
+	if err := json.Unmarshal(m.Result, v); err != nil {
+		c.t.Fatalf("Error decoding result %s: %v", m.Result, err)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	c := newClient(t, Config{})
+	var loaded loadResult
+	c.call(1, "load", map[string]json.RawMessage{"problem": json.RawMessage(testutil.Knuth.JSON())}, &loaded)
+	if loaded.Problem == "" || loaded.Rows != 5 || loaded.Columns != 5 {
+		t.Fatalf("Unexpected load result %+v", loaded)
+	}
+
+	var res solveResult
+	c.call(2, "solve", map[string]interface{}{"problem": loaded.Problem}, &res)
+	if res.Count != 1 || !res.Complete || len(res.Solutions[0]) != 2 {
+		t.Fatalf("Unexpected solve result %+v", res)
+	}
+
+	// Giving a row not in the solution leaves none
+	c.call(3, "addGiven", map[string]interface{}{"problem": loaded.Problem, "rows": []string{"E"}}, &struct{}{})
+	res = solveResult{}
+	c.call(4, "solve", map[string]interface{}{"problem": loaded.Problem}, &res)
+	if res.Count != 0 || !res.Complete {
+		t.Fatalf("Unexpected solve result %+v", res)
+	}
+
+	c.send(5, "solve", map[string]interface{}{"problem": "missing"})
+	if m := c.read(); m.Error == nil || m.Error.Code != InvalidParams {
+		t.Fatalf("Expected invalid params, got %+v", m)
+	}
+	c.send(6, "frobnicate", map[string]interface{}{})
+	if m := c.read(); m.Error == nil || m.Error.Code != MethodNotFound {
+		t.Fatalf("Expected method not found, got %+v", m)
+	}
+	c.in.Write([]byte("{\n"))
+	if m := c.read(); m.Error == nil || m.Error.Code != ParseError || string(m.ID) != "null" {
+		t.Fatalf("Expected parse error, got %+v", m)
+	}
+
+	c.in.Close()
+	if err := <-c.done; err != nil {
+		t.Fatalf("Error serving: %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := newClient(t, Config{MaxSolutions: 3})
+	var loaded loadResult
+	c.call(1, "load", map[string]string{"format": "dlx", "data": testutil.Dominoes(4).DLX()}, &loaded)
+
+	// A 2x4 board has 5 tilings, but the server stops at 3
+	c.send(2, "stream", map[string]interface{}{"problem": loaded.Problem, "limit": 10})
+	for i := 0; i < 3; i++ {
+		m := c.read()
+		var p solutionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil || m.Method != "solution" || string(p.Request) != "2" || len(p.Rows) == 0 {
+			t.Fatalf("Expected solution notification, got %+v", m)
+		}
+	}
+	m := c.read()
+	var res solveResult
+	if err := json.Unmarshal(m.Result, &res); err != nil || res.Count != 3 || res.Complete || res.Solutions != nil {
+		t.Fatalf("Unexpected stream result %s", m.Result)
+	}
+	c.in.Close()
+	<-c.done
+}
+
+func TestCancel(t *testing.T) {
+	c := newClient(t, Config{})
+	var loaded loadResult
+	c.call(1, "load", map[string]string{"format": "dlx", "data": testutil.Dominoes(60).DLX()}, &loaded)
+	c.send(2, "stream", map[string]interface{}{"problem": loaded.Problem})
+	if m := c.read(); m.Method != "solution" {
+		t.Fatalf("Expected solution notification, got %+v", m)
+	}
+
+	// The problem may only be searched by one call at a time. The responses
+	// may come in any order among the solutions still being sent.
+	c.send(3, "solve", map[string]interface{}{"problem": loaded.Problem})
+	m := c.readResponse()
+	if m.Error == nil || m.Error.Code != SolverError || string(m.ID) != "3" {
+		t.Fatalf("Expected solver error, got %+v", m)
+	}
+	c.send(4, "cancel", map[string]interface{}{"request": 2})
+	for i := 0; i < 2; i++ {
+		m := c.readResponse()
+		switch string(m.ID) {
+		case "4":
+			if string(m.Result) != `{"cancelled":true}` {
+				t.Fatalf("Expected cancel to find the call, got %s", m.Result)
+			}
+		case "2":
+			if m.Error == nil || m.Error.Code != RequestCancelled {
+				t.Fatalf("Expected call to be cancelled, got %+v", m)
+			}
+		default:
+			t.Fatalf("Unexpected message %+v", m)
+		}
+	}
+	c.in.Close()
+	<-c.done
+}
@@ -0,0 +1,229 @@
+package gox
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KnuthWriter writes solutions in the layout of Knuth's dlx1 and xcc programs
+// run with the option m1, so that the solutions found by gox can be diffed
+// against those of the reference implementations. Each solution is written
+// as its number followed by a colon, then a line for each row chosen, in the
+// order chosen: its items starting from the column it was chosen for, each
+// preceded by a space, then "(k of n)" when it was the kth of the n rows left
+// in that column. For example
+//
+//	1:
+//	 a d (2 of 2)
+//	 e f c (1 of 1)
+//	 b g (1 of 1)
+//
+// The rows are only known from the steps of the search, so the writer is
+// passed to WithTrace, which slows the search down:
+//
+//	kw := gox.NewKnuthWriter(os.Stdout, prob)
+//	_, err := prob.SolveContext(ctx, gox.WithTrace(kw.Trace), gox.WithoutSolutions())
+//
+// Both programs choose the first column with fewest rows, as MinRemaining
+// does, so for the same input the solutions are found in the same order.
+// Rows given with RowIsSolution are not written, as the programs have no
+// such rows.
+type KnuthWriter struct {
+	p   Inspector
+	w   *bufio.Writer
+	err error
+	// base is the depth at which the search starts, the number of rows
+	// given, or -1 before the first event
+	base   int
+	levels []knuthLevel
+	count  int64
+}
+
+// knuthLevel is a level of the search followed by a KnuthWriter
+type knuthLevel struct {
+	col  string
+	size int
+	// k counts the rows of the column tried so far, and row is the last
+	k   int
+	row string
+}
+
+// NewKnuthWriter creates a writer of the solutions to p which writes to w. The
+// items of the rows are found by p's RowColumns, so writing fails unless p is
+// an Inspector, as the problems of gox are.
+func NewKnuthWriter(w io.Writer, p ExactCoverSolver) *KnuthWriter {
+	insp, _ := p.(Inspector)
+	return &KnuthWriter{p: insp, w: bufio.NewWriter(w), base: -1}
+}
+
+// Trace follows an event of the search, writing the solution found for
+// FoundSolution. It must be passed to WithTrace. Once writing fails further
+// solutions are ignored, and the error is returned by Err.
+func (k *KnuthWriter) Trace(e Event) {
+	if k.base < 0 {
+		k.base = e.Depth
+	}
+	switch e.Kind {
+	case ChooseColumn:
+		k.levels = append(k.levels[:e.Depth-k.base], knuthLevel{col: e.Column, size: e.Size})
+	case TryRow:
+		l := &k.levels[e.Depth-1-k.base]
+		l.k++
+		l.row = e.Row
+	case FoundSolution:
+		k.count++
+		if k.err == nil {
+			k.err = k.write(k.levels[:e.Depth-k.base])
+		}
+	}
+}
+
+// write writes a solution
+func (k *KnuthWriter) write(levels []knuthLevel) error {
+	if k.p == nil {
+		return fmt.Errorf("Problem is not an Inspector, so its rows cannot be written")
+	}
+	fmt.Fprintf(k.w, "%d:\n", k.count)
+	for _, l := range levels {
+		items, err := k.p.RowColumns(l.row)
+		if err != nil {
+			return err
+		}
+		// Start from the item of the column the row was chosen for
+		for i, item := range items {
+			if strings.SplitN(item, ":", 2)[0] == l.col {
+				items = append(append([]string{}, items[i:]...), items[:i]...)
+				break
+			}
+		}
+		fmt.Fprintf(k.w, " %s (%d of %d)\n", strings.Join(items, " "), l.k, l.size)
+	}
+	return k.w.Flush()
+}
+
+// Count returns the number of solutions found
+func (k *KnuthWriter) Count() int64 {
+	return k.count
+}
+
+// Err returns the first error writing a solution
+func (k *KnuthWriter) Err() error {
+	return k.err
+}
+
+// Summary returns the line the programs write to stderr once the search has
+// finished, such as "Altogether 3 solutions, 1234 updates, 56 nodes.",
+// leaving out the mems and bytes, which gox does not count. stats is that
+// given to WithStats, or nil to leave out the updates and nodes, which are
+// counted differently by gox.
+func (k *KnuthWriter) Summary(stats *Stats) string {
+	plural := "s"
+	if k.count == 1 {
+		plural = ""
+	}
+	if stats == nil {
+		return fmt.Sprintf("Altogether %d solution%s.", k.count, plural)
+	}
+	return fmt.Sprintf("Altogether %d solution%s, %d updates, %d nodes.", k.count, plural, stats.Updates, stats.Nodes)
+}
+
+// KnuthDigest summarises the solutions written in the layout of KnuthWriter,
+// by gox or by Knuth's programs, so that enumerations too large to hold in
+// memory can be compared, see CompareKnuth
+type KnuthDigest struct {
+	// Count is the number of solutions written, and Reported the number
+	// given by an "Altogether" line, or -1 if there was none
+	Count, Reported int64
+	// Sum adds up a hash of each solution, which is independent of the
+	// order of the solutions, of the rows of each and of the items of each
+	// row, so that solutions found in different orders can be compared
+	Sum uint64
+}
+
+var (
+	knuthSolution   = regexp.MustCompile(`^\d+:$`)
+	knuthRow        = regexp.MustCompile(`^ (.*?)( \(\d+ of \d+\)| \(\?\))?$`)
+	knuthAltogether = regexp.MustCompile(`^Altogether (\d+) solutions?\b`)
+)
+
+// ReadKnuth reads the solutions written in the layout of KnuthWriter. Lines
+// which are not part of a solution or the "Altogether" line, such as the
+// other lines the programs write to stderr, are ignored.
+func ReadKnuth(r io.Reader) (*KnuthDigest, error) {
+	d := &KnuthDigest{Reported: -1}
+	var rows []string
+	finish := func() {
+		if rows == nil {
+			return
+		}
+		sort.Strings(rows)
+		h := fnv.New64a()
+		io.WriteString(h, strings.Join(rows, "\n"))
+		d.Sum += h.Sum64()
+		rows = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case knuthSolution.MatchString(line):
+			finish()
+			d.Count++
+			rows = []string{}
+		case rows != nil && strings.HasPrefix(line, " "):
+			items := strings.Fields(knuthRow.FindStringSubmatch(line)[1])
+			sort.Strings(items)
+			rows = append(rows, strings.Join(items, " "))
+		default:
+			finish()
+			if m := knuthAltogether.FindStringSubmatch(line); m != nil {
+				n, err := strconv.ParseInt(m[1], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				d.Reported = n
+			}
+		}
+	}
+	finish()
+	return d, scanner.Err()
+}
+
+// CompareKnuth compares the solutions written in the layout of KnuthWriter to
+// want and got, e.g. by Knuth's dlx1 and by gox, returning an error
+// describing how they differ, or nil if they hold the same solutions. The
+// order of the solutions does not matter. Where only every mth solution was
+// written, only the counts given by the "Altogether" lines are compared.
+func CompareKnuth(want, got io.Reader) error {
+	w, err := ReadKnuth(want)
+	if err != nil {
+		return err
+	}
+	g, err := ReadKnuth(got)
+	if err != nil {
+		return err
+	}
+	if w.Reported >= 0 && g.Reported >= 0 {
+		if w.Reported != g.Reported {
+			return fmt.Errorf("Expected %d solutions altogether, got %d", w.Reported, g.Reported)
+		}
+		if w.Count != w.Reported || g.Count != g.Reported {
+			return nil
+		}
+	}
+	switch {
+	case w.Count != g.Count:
+		return fmt.Errorf("Expected %d solutions to be written, got %d", w.Count, g.Count)
+	case w.Sum != g.Sum:
+		return fmt.Errorf("Both have %d solutions, but they differ", w.Count)
+	}
+	return nil
+}
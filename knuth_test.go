@@ -0,0 +1,89 @@
+package gox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// knuthOutput solves a problem writing its solutions with a KnuthWriter
+func knuthOutput(t *testing.T, prob *exactCoverProblem) (string, *KnuthWriter) {
+	var buf bytes.Buffer
+	kw := NewKnuthWriter(&buf, prob)
+	if _, err := prob.SolveContext(context.Background(), WithTrace(kw.Trace), WithoutSolutions()); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if err := kw.Err(); err != nil {
+		t.Fatalf("Error writing solutions: %v", err)
+	}
+	return buf.String(), kw
+}
+
+func TestKnuthWriter(t *testing.T) {
+	// The example of Knuth's paper, as written by dlx1 with m1
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c", "d", "e", "f", "g")
+	for i, row := range [][]string{{"c", "e", "f"}, {"a", "d", "g"}, {"b", "c", "f"}, {"a", "d"}, {"b", "g"}, {"d", "e", "g"}} {
+		b.AddRow(string(rune('A'+i)), row...)
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	out, kw := knuthOutput(t, prob)
+	if want := "1:\n a d (2 of 2)\n e f c (1 of 1)\n b g (1 of 1)\n"; out != want {
+		t.Fatalf("Expected output\n%s\ngot\n%s", want, out)
+	}
+	if s := kw.Summary(nil); s != "Altogether 1 solution." {
+		t.Fatalf("Unexpected summary %q", s)
+	}
+
+	// Knuth's example of colours, as written by xcc with m1
+	b = NewBuilder()
+	b.AddColumns("p", "q", "r")
+	b.AddSecondaryColumns("x", "y")
+	for i, row := range [][]string{{"p", "q", "x", "y:A"}, {"p", "r", "x:A", "y"}, {"p", "x:B"}, {"q", "x:A"}, {"r", "y:B"}} {
+		b.AddRow(string(rune('A'+i)), row...)
+	}
+	if prob, err = b.Build(); err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	// Given rows are not written
+	prob.RowIsSolution("D")
+	if out, _ := knuthOutput(t, prob); out != "1:\n p r x:A y (1 of 1)\n" {
+		t.Fatalf("Unexpected output\n%s", out)
+	}
+}
+
+func TestCompareKnuth(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	out, kw := knuthOutput(t, prob)
+	if kw.Count() != 5 {
+		t.Fatalf("Expected 5 solutions, got %d", kw.Count())
+	}
+
+	// The same solutions in another order, with the rows and their items in
+	// another order, among the other lines written by dlx1
+	blocks := strings.SplitAfter(out, "\n5:")
+	reordered := "5:" + blocks[1] + strings.Replace(blocks[0], "\n5:", "\n", 1)
+	reordered = strings.Replace(reordered, " 0,0 1,0 (1 of 2)", " 1,0 0,0 (1 of 2)", 1)
+	reference := "Reading the input...\n" + reordered + "Altogether 5 solutions, 1234+5678 mems, 42 updates, 9999 bytes, 17 nodes.\n"
+	if err := CompareKnuth(strings.NewReader(reference), strings.NewReader(out)); err != nil {
+		t.Fatalf("Expected the same solutions: %v", err)
+	}
+
+	if err := CompareKnuth(strings.NewReader(reference), strings.NewReader(strings.SplitAfter(out, "\n5:")[0])); err == nil {
+		t.Fatal("Expected different counts")
+	}
+	changed := strings.Replace(out, "0,3 1,3", "0,3 0,2", 1)
+	if err := CompareKnuth(strings.NewReader(out), strings.NewReader(changed)); err == nil || !strings.Contains(err.Error(), "differ") {
+		t.Fatalf("Expected solutions to differ, got %v", err)
+	}
+
+	// Only every second solution written, so only the counts are compared
+	sparse := "2:\n 0,0 1,0 (1 of 2)\nAltogether 5 solutions.\n"
+	if err := CompareKnuth(strings.NewReader(sparse), strings.NewReader(out+kw.Summary(nil)+"\n")); err != nil {
+		t.Fatalf("Expected counts to match: %v", err)
+	}
+}
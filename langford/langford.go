@@ -0,0 +1,89 @@
+// Package langford finds Langford pairings using the exact cover solver in
+// gox.
+//
+// A Langford pairing of order n arranges two copies of each number from 1 to
+// n in a sequence of length 2n so that the two copies of k have k numbers
+// between them, e.g. 2 3 1 2 1 3. Pairings only exist when n is 0 or 3 modulo
+// 4. Each number and each position of the sequence is a primary column, and
+// there is a row for each way of placing the two copies of each number.
+//
+// Every pairing is found twice, as its reverse is also a pairing.
+package langford
+
+import (
+	"fmt"
+
+	"github.com/ifross89/gox"
+)
+
+// RowName is the name of the row placing the first copy of k at position i of
+// the sequence and the second at position i+k+1, e.g. "3@1"
+func RowName(k, i int) string {
+	return fmt.Sprintf("%d@%d", k, i)
+}
+
+// Problem creates the exact cover problem for Langford pairings of order n
+func Problem(n int) (gox.Solver, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("Order must be positive: n=%d", n)
+	}
+
+	b := gox.NewBuilder()
+	for k := 1; k <= n; k++ {
+		if err := b.AddColumns(fmt.Sprint(k)); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < 2*n; i++ {
+		if err := b.AddColumns(fmt.Sprintf("s%d", i)); err != nil {
+			return nil, err
+		}
+	}
+	for k := 1; k <= n; k++ {
+		for i := 0; i+k+1 < 2*n; i++ {
+			if err := b.AddRow(RowName(k, i), fmt.Sprint(k), fmt.Sprintf("s%d", i), fmt.Sprintf("s%d", i+k+1)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode returns the sequence of numbers given by a solution to the problem of
+// order n
+func Decode(n int, solution []string) ([]int, error) {
+	ret := make([]int, 2*n)
+	for _, name := range solution {
+		var k, i int
+		if _, err := fmt.Sscanf(name, "%d@%d", &k, &i); err != nil {
+			return nil, fmt.Errorf("Invalid row name %s: %v", name, err)
+		}
+		if k < 1 || k > n || i < 0 || i+k+1 >= 2*n {
+			return nil, fmt.Errorf("Invalid row name %s", name)
+		}
+		ret[i], ret[i+k+1] = k, k
+	}
+	return ret, nil
+}
+
+// Solve finds every Langford pairing of order n
+func Solve(n int) ([][]int, error) {
+	prob, err := Problem(n)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret [][]int
+	for _, soln := range prob.Solve() {
+		seq, err := Decode(n, soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, seq)
+	}
+	return ret, nil
+}
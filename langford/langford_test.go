@@ -0,0 +1,41 @@
+package langford
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	// The number of pairings, counting a pairing and its reverse separately
+	for n, expected := range map[int]int{1: 0, 2: 0, 3: 2, 4: 2, 5: 0, 6: 0, 7: 52, 8: 300} {
+		solns, err := Solve(n)
+		if err != nil {
+			t.Fatalf("Error solving order %d: %v", n, err)
+		}
+		if len(solns) != expected {
+			t.Fatalf("Expected %d pairings of order %d, got %d", expected, n, len(solns))
+		}
+		for _, s := range solns {
+			first := make(map[int]int)
+			for i, k := range s {
+				if j, ok := first[k]; ok && i-j != k+1 {
+					t.Fatalf("Copies of %d are not %d apart in %v", k, k+1, s)
+				}
+				first[k] = i
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	seq, err := Decode(3, []string{"2@0", "3@1", "1@2"})
+	if err != nil {
+		t.Fatalf("Error decoding: %v", err)
+	}
+	if s := fmt.Sprint(seq); s != "[2 3 1 2 1 3]" {
+		t.Fatalf("Unexpected sequence %s", s)
+	}
+	if _, err := Decode(3, []string{"3@4"}); err == nil {
+		t.Fatal("Expected error for placement off the end of the sequence")
+	}
+}
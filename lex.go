@@ -0,0 +1,121 @@
+package gox
+
+import "sort"
+
+// RowOrder orders the rows of a problem for WithLexicographic
+type RowOrder int
+
+const (
+	// ByIndex orders the rows by their index in Rows, i.e. the order in
+	// which they were given to the problem
+	ByIndex RowOrder = iota
+	// ByName orders the rows by name
+	ByName
+)
+
+// WithLexicographic finds only the lexicographically smallest solution: the
+// solution which comes first when the rows of each solution are sorted by
+// order and the sorted solutions compared row by row. The search branches on
+// the smallest row left, trying the solutions with it before those without,
+// so the first solution found is the smallest and the others need not be
+// enumerated. This gives a canonical solution, e.g. for golden tests, which
+// does not depend on the heuristic. The rows of the solution are returned in
+// ascending order, after any rows given with RowIsSolution. WithLimit and
+// WithHeuristic have no effect.
+func WithLexicographic(order RowOrder) Option {
+	return func(c *config) {
+		c.lex = true
+		c.lexOrder = order
+	}
+}
+
+// rowRanks returns the position of each row of the problem in order
+func (p *exactCoverProblem) rowRanks(order RowOrder) []int {
+	ranks := make([]int, len(p.rowHeaders))
+	if order == ByIndex {
+		for i := range ranks {
+			ranks[i] = i
+		}
+		return ranks
+	}
+	sorted := make([]*rowHeader, len(p.rowHeaders))
+	copy(sorted, p.rowHeaders)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].label() < sorted[j].label()
+	})
+	for rank, r := range sorted {
+		ranks[r.index] = rank
+	}
+	return ranks
+}
+
+// searchLex finds the lexicographically smallest solution, see
+// WithLexicographic. Rather than choosing a column and trying each of its
+// rows, it takes the smallest row left and tries first to add it to the
+// solution, then to solve the problem without it. search only chooses rows
+// covering a primary column, so only those are considered. searchLex returns
+// true once a solution has been found or the search was interrupted, the
+// matrix is restored either way.
+func (p *exactCoverProblem) searchLex(c *config, ranks []int) bool {
+	if c.interrupted() {
+		return true
+	}
+	if p.root == p.root.right {
+		c.solutions++
+		if c.participation != nil {
+			for _, r := range p.solutionRows {
+				c.participation.Counts[r.index]++
+			}
+		}
+		p.emit(c, FoundSolution, nil, nil)
+		c.found(p.solutionRows)
+		return true
+	}
+
+	// Find the smallest row left, and give up if any column has no rows
+	var first *node
+	for col := p.root.right; col != p.root; col = col.right {
+		if col.colCount == 0 {
+			p.emit(c, DeadEnd, col, nil)
+			return false
+		}
+		for n := col.down; n != col; n = n.down {
+			if first == nil || ranks[n.rowHead.index] < ranks[first.rowHead.index] {
+				first = n
+			}
+		}
+	}
+	colHead := first.colHead
+	p.chose(c, colHead)
+
+	// Try the solutions with the row, as in search
+	p.cover(colHead)
+	p.pushRowToSolution(first.rowHead)
+	for rightNode := first.right; rightNode != first; rightNode = rightNode.right {
+		p.commit(rightNode)
+	}
+	p.emit(c, TryRow, nil, first.rowHead)
+	stopped := p.searchLex(c, ranks)
+	p.popRowFromSolution()
+	for leftNode := first.left; leftNode != first; leftNode = leftNode.left {
+		p.uncommit(leftNode)
+	}
+	p.emit(c, UndoRow, nil, first.rowHead)
+	p.uncover(colHead)
+	if stopped {
+		return true
+	}
+
+	// Then the solutions without it, removing it from the matrix
+	first.up.down = first.down
+	first.down.up = first.up
+	colHead.colCount--
+	p.updates++
+	p.hide(first)
+	stopped = p.searchLex(c, ranks)
+	p.unhide(first)
+	first.up.down = first
+	first.down.up = first
+	colHead.colCount++
+	return stopped
+}
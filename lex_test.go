@@ -0,0 +1,81 @@
+package gox
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+// lexLess reports whether a comes before b, both being sorted
+func lexLess(a, b []string, less func(x, y string) bool) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return less(a[i], b[i])
+		}
+	}
+	return len(a) < len(b)
+}
+
+func TestLexicographic(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 300; trial++ {
+		m, names := testutil.Random(rng, 2+rng.Intn(14), 1+rng.Intn(7), 0.3)
+		index := make(map[string]int, len(names))
+		for i, name := range names {
+			index[name] = i
+		}
+		orders := map[RowOrder]func(x, y string) bool{
+			ByIndex: func(x, y string) bool { return index[x] < index[y] },
+			ByName:  func(x, y string) bool { return x < y },
+		}
+		for order, less := range orders {
+			prob, err := NewExactCoverProblem(m, names, WithDebug())
+			if err != nil {
+				t.Fatalf("Error creating problem: %v", err)
+			}
+			all, err := prob.SolveContext(context.Background())
+			if _, ok := err.(*UncoverableError); ok {
+				continue
+			} else if err != nil {
+				t.Fatalf("Error solving problem: %v", err)
+			}
+			var want [][]string
+			for _, soln := range all {
+				sort.Slice(soln, func(i, j int) bool { return less(soln[i], soln[j]) })
+				if want == nil || lexLess(soln, want[0], less) {
+					want = [][]string{soln}
+				}
+			}
+
+			var stats Stats
+			got, err := prob.SolveContext(context.Background(), WithLexicographic(order), WithStats(&stats))
+			if err != nil {
+				t.Fatalf("Error solving problem: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Trial %d, order %d: expected %v, got %v from %v", trial, order, want, got, all)
+			}
+			if stats.Solutions != int64(len(got)) {
+				t.Fatalf("Expected stats of %d solutions, got %d", len(got), stats.Solutions)
+			}
+		}
+	}
+}
+
+func TestLexicographicGiven(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	if err := prob.RowIsSolution("v3"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	solns, err := prob.SolveContext(context.Background(), WithLexicographic(ByName))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if want := [][]string{{"v3", "h0,0", "h1,0", "v2"}}; !reflect.DeepEqual(solns, want) {
+		t.Fatalf("Expected %v, got %v", want, solns)
+	}
+}
@@ -0,0 +1,155 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+)
+
+// SolveMinRows finds a solution with the fewest rows, searching by branch and
+// bound: once a solution has been found, partial solutions which cannot be
+// completed with fewer rows are abandoned. A partial solution needs at least
+// the number of primary columns left divided by the most primary columns any
+// row covers. Unlike SolveByCost each row counts the same, which finds the
+// simplest explanation rather than the cheapest.
+//
+// The rows given with RowIsSolution are part of the solution returned. If the
+// search is interrupted the smallest solution found so far is returned along
+// with the context's error. WithSolutionFunc is called with each solution
+// smaller than those before it. WithLimit has no effect, and WithParticipation
+// counts no rows. nil is returned if the problem has no solutions.
+//
+// A search can be warm started with WithIncumbent or WithUpperBound, so that
+// it only looks for solutions smaller than one already known. The solution
+// returned can be passed to WithIncumbent when solving the problem again,
+// e.g. after editing it, so that successive searches keep improving on it,
+// and its number of rows is reported as the Bound of WithStats for
+// WithUpperBound. An incumbent which is not a solution is refused before
+// searching.
+func (p *exactCoverProblem) SolveMinRows(ctx context.Context, opts ...Option) ([]string, error) {
+	var ret []string
+	opts = append(opts, func(c *config) {
+		c.minRows = true
+		c.prepare = func(c *config) error {
+			if c.incumbent == nil {
+				return nil
+			}
+			if err := p.Verify(c.incumbent); err != nil {
+				return fmt.Errorf("Invalid incumbent: %v", err)
+			}
+			ret = append([]string(nil), c.incumbent...)
+			return nil
+		}
+	})
+	err := p.solve(ctx, "SolveMinRows", opts, func(c *config, rows []*rowHeader) {
+		ret = rowNames(rows)
+		if c.onSolution != nil {
+			c.onSolution(ret)
+		}
+	})
+	return ret, err
+}
+
+// WithIncumbent gives SolveMinRows a known solution, so that it only looks for
+// solutions with fewer rows. If it finds none the incumbent is returned. Given
+// to SolveByCost, only the solutions costing less than the incumbent are
+// found, and none are returned if there are none. The incumbent must be a
+// solution, including any rows given with RowIsSolution, or an error is
+// returned.
+func WithIncumbent(rows []string) Option {
+	return func(c *config) {
+		c.incumbent = rows
+		c.bound = len(rows)
+	}
+}
+
+// WithUpperBound makes SolveMinRows look only for solutions with fewer than n
+// rows, counting the rows given with RowIsSolution, returning nil if there
+// are none. A bound of zero or less means there is no bound.
+func WithUpperBound(n int) Option {
+	return func(c *config) {
+		c.incumbent = nil
+		c.bound = n
+	}
+}
+
+// maxPrimary returns the most primary columns covered by any row
+func (p *exactCoverProblem) maxPrimary() int {
+	ret := 0
+	for _, r := range p.rowHeaders {
+		count := 0
+		for n := r.first; n != nil; {
+			if n.colIndex < p.numPrimary {
+				count++
+			}
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+		if count > ret {
+			ret = count
+		}
+	}
+	return ret
+}
+
+// searchMin is search for SolveMinRows. best is the number of rows in the
+// smallest solution found so far, or the bound given to SolveMinRows, or zero
+// if there is neither, and
+// maxPrimary the most primary columns covered by a row. Rows are tried in the
+// column with the fewest rows whatever the heuristic, as that leaves the
+// fewest branches for the bound to cut. searchMin returns true if the search
+// was interrupted, the matrix is restored either way.
+func (p *exactCoverProblem) searchMin(c *config, best *int, maxPrimary int) bool {
+	if c.interrupted() {
+		return true
+	}
+	if p.root == p.root.right {
+		// Only the rows given can reach here without checking the bound
+		if *best > 0 && len(p.solutionRows) >= *best {
+			return false
+		}
+		c.solutions++
+		*best = len(p.solutionRows)
+		p.emit(c, FoundSolution, nil, nil)
+		c.found(p.solutionRows)
+		return false
+	}
+
+	colHead := p.root.right
+	remaining := 0
+	for n := colHead; n != p.root; n = n.right {
+		remaining++
+		if n.colCount < colHead.colCount {
+			colHead = n
+		}
+	}
+	p.chose(c, colHead)
+	if colHead.colCount == 0 {
+		p.emit(c, DeadEnd, colHead, nil)
+		return false
+	}
+	// At least this many more rows are needed to cover the columns left
+	needed := (remaining + maxPrimary - 1) / maxPrimary
+	if *best > 0 && len(p.solutionRows)+needed >= *best {
+		p.emit(c, DeadEnd, colHead, nil)
+		return false
+	}
+
+	p.cover(colHead)
+	stopped := false
+	for rowNode := colHead.down; rowNode != colHead && !stopped; rowNode = rowNode.down {
+		p.pushRowToSolution(rowNode.rowHead)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		p.emit(c, TryRow, nil, rowNode.rowHead)
+		stopped = p.searchMin(c, best, maxPrimary)
+		p.popRowFromSolution()
+		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+			p.uncommit(leftNode)
+		}
+		p.emit(c, UndoRow, nil, rowNode.rowHead)
+	}
+	p.uncover(colHead)
+	return stopped
+}
@@ -0,0 +1,138 @@
+package gox
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+func TestSolveMinRows(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 300; trial++ {
+		m, names := testutil.Random(rng, 2+rng.Intn(14), 1+rng.Intn(8), 0.3)
+		prob, err := NewExactCoverProblem(m, names, WithDebug())
+		if err != nil {
+			t.Fatalf("Error creating problem: %v", err)
+		}
+		all, err := prob.SolveContext(context.Background())
+		if _, ok := err.(*UncoverableError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		want := -1
+		for _, soln := range all {
+			if want < 0 || len(soln) < want {
+				want = len(soln)
+			}
+		}
+
+		got, err := prob.SolveMinRows(context.Background())
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		if want < 0 {
+			if got != nil {
+				t.Fatalf("Trial %d: expected no solution, got %v", trial, got)
+			}
+			continue
+		}
+		if len(got) != want {
+			t.Fatalf("Trial %d: expected a solution of %d rows, got %v from %v", trial, want, got, all)
+		}
+		if err := prob.Verify(got); err != nil {
+			t.Fatalf("Trial %d: invalid solution %v: %v", trial, got, err)
+		}
+	}
+}
+
+func TestSolveMinRowsImproves(t *testing.T) {
+	// The search tries row a of column a first, which leads to a solution of
+	// three rows before the solution of one is found
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c", "d")
+	b.AddRow("a", "a")
+	b.AddRow("b", "b")
+	b.AddRow("cd", "c", "d")
+	b.AddRow("abcd", "a", "b", "c", "d")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var sizes []int
+	got, err := prob.SolveMinRows(context.Background(), WithSolutionFunc(func(soln []string) {
+		sizes = append(sizes, len(soln))
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"abcd"}) {
+		t.Fatalf("Expected solution [abcd], got %v", got)
+	}
+	if !reflect.DeepEqual(sizes, []int{3, 1}) {
+		t.Fatalf("Expected solutions of 3 then 1 rows to be passed on, got %v", sizes)
+	}
+}
+
+func TestSolveMinRowsWarmStart(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c", "d")
+	b.AddRow("a", "a")
+	b.AddRow("b", "b")
+	b.AddRow("cd", "c", "d")
+	b.AddRow("ab", "a", "b")
+	b.AddRow("abcd", "a", "b", "c", "d")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+
+	// Only solutions smaller than the incumbent are passed on
+	var found [][]string
+	onSolution := WithSolutionFunc(func(soln []string) {
+		found = append(found, soln)
+	})
+	got, err := prob.SolveMinRows(context.Background(), WithIncumbent([]string{"ab", "cd"}), onSolution)
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"abcd"}) || !reflect.DeepEqual(found, [][]string{{"abcd"}}) {
+		t.Fatalf("Expected only solution [abcd] to be found, got %v from %v", got, found)
+	}
+
+	// The incumbent is returned if nothing is smaller
+	got, err = prob.SolveMinRows(context.Background(), WithIncumbent([]string{"abcd"}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"abcd"}) {
+		t.Fatalf("Expected the incumbent [abcd], got %v", got)
+	}
+
+	got, err = prob.SolveMinRows(context.Background(), WithUpperBound(1))
+	if err != nil || got != nil {
+		t.Fatalf("Expected no solution of fewer than 1 row, got %v, %v", got, err)
+	}
+	got, err = prob.SolveMinRows(context.Background(), WithUpperBound(3))
+	if err != nil || !reflect.DeepEqual(got, []string{"abcd"}) {
+		t.Fatalf("Expected solution [abcd] within the bound, got %v, %v", got, err)
+	}
+
+	// The final bound is that of the smallest solution known
+	var stats Stats
+	if _, err := prob.SolveMinRows(context.Background(), WithIncumbent([]string{"ab", "cd"}), WithStats(&stats)); err != nil || stats.Bound != 1 {
+		t.Fatalf("Expected the bound to be 1, got %v: %v", stats.Bound, err)
+	}
+	if _, err := prob.SolveMinRows(context.Background(), WithUpperBound(1), WithStats(&stats)); err != nil || stats.Bound != 1 {
+		t.Fatalf("Expected the bound given to be kept, got %v: %v", stats.Bound, err)
+	}
+
+	got, err = prob.SolveMinRows(context.Background(), WithIncumbent([]string{"ab"}))
+	if err == nil || got != nil || !strings.HasPrefix(err.Error(), "Invalid incumbent") {
+		t.Fatalf("Expected error for an invalid incumbent, got %v, %v", got, err)
+	}
+}
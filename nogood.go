@@ -0,0 +1,95 @@
+package gox
+
+import (
+	"sort"
+	"strconv"
+)
+
+// WithNogoods records the partial solutions which could not be completed, so
+// that the search can skip them when it reaches them again. The rows left in
+// the matrix depend only on the columns covered by the rows chosen, and the
+// colours they give, so the nogood recorded is those columns and colours: a
+// different choice of rows covering the same columns the same way leads to
+// the same dead end. On structured problems, such as schedules, where many
+// choices lead to the same state, this prunes much of the search.
+//
+// At most capacity nogoods are kept, the oldest being forgotten once it is
+// reached. The number of partial solutions skipped is reported by WithStats.
+func WithNogoods(capacity int) Option {
+	return func(c *config) {
+		c.nogoods = newStateTable(capacity)
+	}
+}
+
+// stateTable maps the keys of partial solutions, see stateKey, to a value
+// such as their number of solutions, forgetting the oldest once it holds
+// capacity of them. It is used for nogoods, and by WithTranspositions.
+type stateTable struct {
+	values   map[string]uint64
+	order    []string
+	next     int
+	capacity int
+}
+
+// newStateTable creates a table holding up to capacity keys, or returns nil
+// if capacity is not positive
+func newStateTable(capacity int) *stateTable {
+	if capacity <= 0 {
+		return nil
+	}
+	return &stateTable{values: make(map[string]uint64), capacity: capacity}
+}
+
+// get returns the value of a key, and whether it is in the table
+func (s *stateTable) get(key []byte) (uint64, bool) {
+	v, ok := s.values[string(key)]
+	return v, ok
+}
+
+// put sets the value of a key
+func (s *stateTable) put(key string, value uint64) {
+	if _, ok := s.values[key]; !ok {
+		if len(s.order) < s.capacity {
+			s.order = append(s.order, key)
+		} else {
+			delete(s.values, s.order[s.next])
+			s.order[s.next] = key
+			s.next = (s.next + 1) % s.capacity
+		}
+	}
+	s.values[key] = value
+}
+
+// stateKey returns a key which is the same for partial solutions which cover
+// the same columns with the same colours
+func (p *exactCoverProblem) stateKey() []byte {
+	type item struct{ col, color int }
+	var items []item
+	for _, r := range p.solutionRows {
+		for n := r.first; n != nil; {
+			color := n.color
+			if color < 0 {
+				color = -color
+			}
+			items = append(items, item{n.colIndex, color})
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].col < items[j].col
+	})
+	var key []byte
+	for i, it := range items {
+		// Rows agreeing on the colour of a secondary column all cover it
+		if i > 0 && items[i-1].col == it.col {
+			continue
+		}
+		key = strconv.AppendInt(key, int64(it.col), 36)
+		key = append(key, ':')
+		key = strconv.AppendInt(key, int64(it.color), 36)
+		key = append(key, ' ')
+	}
+	return key
+}
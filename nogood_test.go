@@ -0,0 +1,95 @@
+package gox_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/internal/testutil"
+	"github.com/ifross89/gox/testgen"
+)
+
+func TestWithNogoods(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      rng.Intn(30),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		want, err := solveGenerated(inst)
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		// A small store forgets nogoods, which must not lose solutions
+		for _, capacity := range []int{1, 4, 1 << 20} {
+			got, err := solveGenerated(inst, gox.WithNogoods(capacity))
+			if err != nil {
+				t.Fatalf("Error solving problem: %v", err)
+			}
+			if !reflect.DeepEqual(testutil.Canonical(got), testutil.Canonical(want)) {
+				t.Fatalf("Trial %d, capacity %d: expected %v, got %v", trial, capacity, want, got)
+			}
+		}
+	}
+}
+
+func TestWithNogoodsPrunes(t *testing.T) {
+	// A 2x20 board with one cell sticking out cannot be tiled with dominoes,
+	// but searching from the left the many tilings of each part of the board
+	// lead to the same dead ends
+	const n = 20
+	b := gox.NewBuilder()
+	for c := 0; c < n; c++ {
+		b.AddColumns(fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+	}
+	b.AddColumns(fmt.Sprintf("0,%d", n))
+	for c := 0; c < n; c++ {
+		b.AddRow(fmt.Sprintf("v%d", c), fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+		for r := 0; r < 2 && (c < n-1 || r == 0); r++ {
+			b.AddRow(fmt.Sprintf("h%d,%d", r, c), fmt.Sprintf("%d,%d", r, c), fmt.Sprintf("%d,%d", r, c+1))
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+
+	var without, with gox.Stats
+	if _, err := prob.SolveContext(context.Background(), gox.WithHeuristic(gox.FirstColumn), gox.WithStats(&without)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	solns, err := prob.SolveContext(context.Background(), gox.WithHeuristic(gox.FirstColumn), gox.WithNogoods(100), gox.WithStats(&with))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(solns) != 0 {
+		t.Fatalf("Expected no solutions, got %v", solns)
+	}
+	if with.Pruned == 0 || with.Nodes*10 > without.Nodes {
+		t.Fatalf("Expected nogoods to prune the search, visited %d nodes with %d pruned, %d without", with.Nodes, with.Pruned, without.Nodes)
+	}
+}
+
+// solveGenerated builds and solves a generated instance with the options
+// given, checking the invariants of the matrix as it goes
+func solveGenerated(inst *testgen.Instance, opts ...gox.Option) ([][]string, error) {
+	prob, err := inst.Problem(gox.WithDebug())
+	if err != nil {
+		return nil, err
+	}
+	solns, err := prob.SolveContext(context.Background(), opts...)
+	if _, ok := err.(*gox.UncoverableError); ok {
+		return nil, nil
+	}
+	return solns, err
+}
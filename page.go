@@ -0,0 +1,180 @@
+package gox
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Cursor marks a position in the solutions of a problem, just after the last
+// solution returned by SolvePage. It is opaque, but is a plain string so that
+// it can be handed to a browser and back in a later request. The zero Cursor
+// marks the start of the solutions.
+type Cursor string
+
+// cursorVersion is the version of the encoding of cursors. Cursors of any
+// other version are refused.
+const cursorVersion = 1
+
+// cursorState is the content of a Cursor: the rows given when it was made,
+// and the columns branched on to reach the last solution returned along with
+// the row chosen to cover each
+type cursorState struct {
+	Version int      `json:"v"`
+	Given   []string `json:"given,omitempty"`
+	Columns []string `json:"columns"`
+	Rows    []string `json:"rows"`
+}
+
+// SolvePage finds up to n of the solutions to the problem which follow the
+// position marked by cursor, like SolveContext, returning them with a cursor
+// marking the position after the last, so that a huge set of solutions can be
+// paged through across requests without finding the earlier solutions again.
+// The cursor returned is empty once every solution has been returned; a page
+// ending with the last solution may be followed by an empty page.
+//
+// The cursor holds the choices leading to the last solution returned, so it
+// is only valid for this problem, or one created in the same way, with the
+// same rows given. The heuristic may change between pages, but a change
+// reorders the solutions still to come. Each page starts the search afresh
+// from the cursor, so with ConflictWeighted, BucketedMinRemaining, RandomTie
+// or RecentTie, which choose from the search so far, every solution still
+// comes once, but not in the order SolveContext finds them. Options choosing
+// another kind of search, such as WithLexicographic, are not supported. If
+// the search is interrupted the solutions found so far are returned with a
+// cursor after the last of them, along with the error.
+func (p *exactCoverProblem) SolvePage(ctx context.Context, cursor Cursor, n int, opts ...Option) ([][]string, Cursor, error) {
+	if n <= 0 {
+		return nil, cursor, fmt.Errorf("Page size must be positive, got %d", n)
+	}
+	var ret [][]string
+	next := cursor
+	opts = append(opts, WithLimit(n), func(c *config) {
+		c.paging = true
+		c.sorted = false
+		c.prepare = func(c *config) error {
+			if cursor == "" {
+				return nil
+			}
+			var err error
+			c.resume, err = p.decodeCursor(cursor)
+			return err
+		}
+	})
+	err := p.solve(ctx, "SolvePage", opts, func(c *config, rows []*rowHeader) {
+		soln := rowNames(rows)
+		ret = append(ret, soln)
+		if c.onSolution != nil {
+			c.onSolution(soln)
+		}
+		next = p.encodeCursor(c.path)
+	})
+	if err == nil && len(ret) < n {
+		next = ""
+	}
+	return ret, next, err
+}
+
+// encodeCursor returns the cursor for the position reached by choosing the
+// rows of path
+func (p *exactCoverProblem) encodeCursor(path []*node) Cursor {
+	s := cursorState{Version: cursorVersion, Given: rowNames(p.solutionRows[:len(p.solutionRows)-len(path)])}
+	for _, n := range path {
+		s.Columns = append(s.Columns, p.colName(n.colHead))
+		s.Rows = append(s.Rows, n.rowHead.label())
+	}
+	b, _ := json.Marshal(s)
+	return Cursor(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// decodeCursor returns the nodes of the rows chosen to reach the position
+// marked by a cursor, checking that it fits the problem, which must have been
+// acquired
+func (p *exactCoverProblem) decodeCursor(cursor Cursor) ([]*node, error) {
+	b, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	var s cursorState
+	if err == nil {
+		err = json.Unmarshal(b, &s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid cursor: %v", err)
+	}
+	if s.Version != cursorVersion {
+		return nil, fmt.Errorf("Cursor has version %d, expected %d", s.Version, cursorVersion)
+	}
+	given := rowNames(p.solutionRows)
+	if strings.Join(given, "\x00") != strings.Join(s.Given, "\x00") {
+		return nil, fmt.Errorf("Cursor was made with rows [%s] given, but the problem has [%s] given",
+			strings.Join(s.Given, " "), strings.Join(given, " "))
+	}
+	if len(s.Columns) != len(s.Rows) {
+		return nil, fmt.Errorf("Invalid cursor: expected a row for each column")
+	}
+	path := make([]*node, len(s.Columns))
+	for i, colName := range s.Columns {
+		col := p.columnIndex(colName)
+		if col < 0 || col >= p.numPrimary {
+			return nil, fmt.Errorf("No primary column found with name %s", colName)
+		}
+		header := p.row(s.Rows[i])
+		if header == nil {
+			return nil, fmt.Errorf("No row found with name %s", s.Rows[i])
+		}
+		for n := header.first; n != nil; {
+			if n.colIndex == col {
+				path[i] = n
+				break
+			}
+			if n = n.right; n == header.first {
+				n = nil
+			}
+		}
+		if path[i] == nil {
+			return nil, fmt.Errorf("Row %s does not cover column %s", s.Rows[i], colName)
+		}
+	}
+	return path, nil
+}
+
+// searchResume searches the part of the problem after the position reached
+// by choosing the rows of path, the first of which covers the column the
+// search branches on next. The subtree of the row of path[0] is only searched
+// after the rest of path, as the solutions before it have been found, then
+// the rows below it in its column are tried in turn. An empty path is the
+// position after the solution made up of the rows given alone, which has
+// nothing after it.
+func (p *exactCoverProblem) searchResume(c *config, path []*node) bool {
+	if len(path) == 0 {
+		return false
+	}
+	rowNode := path[0]
+	colHead := rowNode.colHead
+	// A cursor made for another problem may name a column already covered,
+	// or a row already hidden
+	if colHead.right.left != colHead || rowNode.up.down != rowNode {
+		c.err = fmt.Errorf("Cursor does not fit the problem: row %s cannot cover column %s", rowNode.rowHead.label(), p.colName(colHead))
+		return true
+	}
+	p.cover(colHead)
+	stopped := false
+	if len(path) > 1 {
+		p.pushRowToSolution(rowNode.rowHead)
+		c.path = append(c.path, rowNode)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		stopped = p.searchResume(c, path[1:])
+		p.popRowFromSolution()
+		c.path = c.path[:len(c.path)-1]
+		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+			p.uncommit(leftNode)
+		}
+	}
+	for rowNode = rowNode.down; rowNode != colHead && !stopped; rowNode = rowNode.down {
+		stopped = p.tryRow(c, rowNode)
+	}
+	p.uncover(colHead)
+	return stopped
+}
@@ -0,0 +1,113 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSolvePage(t *testing.T) {
+	prob := dominoProblem(t, 8)
+	all, err := prob.SolveContext(context.Background())
+	if err != nil || len(all) != 34 {
+		t.Fatalf("Expected 34 solutions, got %d: %v", len(all), err)
+	}
+
+	// Paging gives the solutions in the same order, whatever the page size
+	for _, n := range []int{1, 5, 34, 100} {
+		var got [][]string
+		var cursor Cursor
+		for pages := 0; ; pages++ {
+			page, next, err := prob.SolvePage(context.Background(), cursor, n)
+			if err != nil {
+				t.Fatalf("Error solving page: %v", err)
+			}
+			if len(page) > n || pages > 40 {
+				t.Fatalf("Expected at most %d solutions a page, got %d on page %d", n, len(page), pages)
+			}
+			got = append(got, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if !reflect.DeepEqual(got, all) {
+			t.Fatalf("Pages of %d: expected %v, got %v", n, all, got)
+		}
+	}
+
+	// A cursor can be used again, and only fits the problem with the same
+	// rows given
+	first, cursor, _ := prob.SolvePage(context.Background(), "", 3)
+	second, _, _ := prob.SolvePage(context.Background(), cursor, 3)
+	again, _, _ := prob.SolvePage(context.Background(), cursor, 3)
+	if !reflect.DeepEqual(second, again) || !reflect.DeepEqual(append(first, second...), all[:6]) {
+		t.Fatalf("Expected the second page again, got %v and %v", second, again)
+	}
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if _, _, err := prob.SolvePage(context.Background(), cursor, 3); err == nil {
+		t.Fatal("Expected error for cursor made with other rows given")
+	}
+	if _, _, err := prob.SolvePage(context.Background(), "bogus", 3); err == nil {
+		t.Fatal("Expected error for invalid cursor")
+	}
+	if _, _, err := prob.SolvePage(context.Background(), "", 0); err == nil {
+		t.Fatal("Expected error for empty page")
+	}
+}
+
+// pagedSolutions returns the solutions of the pages of n solutions found by
+// SolvePage with opts, failing if there are more than limit pages
+func pagedSolutions(t *testing.T, prob *exactCoverProblem, n, limit int, opts ...Option) [][]string {
+	var ret [][]string
+	var cursor Cursor
+	for pages := 0; pages < limit; pages++ {
+		page, next, err := prob.SolvePage(context.Background(), cursor, n, opts...)
+		if err != nil {
+			t.Fatalf("Error solving page: %v", err)
+		}
+		ret = append(ret, page...)
+		if next == "" {
+			return ret
+		}
+		cursor = next
+	}
+	t.Fatalf("Expected at most %d pages", limit)
+	return nil
+}
+
+func TestSolvePageHeuristics(t *testing.T) {
+	prob := dominoProblem(t, 8)
+	for h := range heuristicNames {
+		want, err := prob.SolveContext(context.Background(), WithHeuristic(h))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		got := pagedSolutions(t, prob, 5, len(want), WithHeuristic(h))
+		// Only the heuristics which choose from the problem as it stands
+		// keep the order of SolveContext across pages
+		if h == MinRemaining || h == FirstColumn {
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%v: expected %v, got %v", h, want, got)
+			}
+		} else if !reflect.DeepEqual(canonicalSolutions(got), canonicalSolutions(want)) {
+			t.Fatalf("%v: expected the solutions %v in any order, got %v", h, want, got)
+		}
+	}
+}
+
+func TestSolvePageGiven(t *testing.T) {
+	// The rows given are the only solution
+	prob := dominoProblem(t, 2)
+	prob.RowIsSolution("v0")
+	prob.RowIsSolution("v1")
+	page, cursor, err := prob.SolvePage(context.Background(), "", 1)
+	if err != nil || len(page) != 1 || cursor == "" {
+		t.Fatalf("Expected the solution and a cursor, got %v %q: %v", page, cursor, err)
+	}
+	if page, cursor, err = prob.SolvePage(context.Background(), cursor, 1); err != nil || len(page) != 0 || cursor != "" {
+		t.Fatalf("Expected an empty last page, got %v %q: %v", page, cursor, err)
+	}
+}
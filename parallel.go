@@ -0,0 +1,496 @@
+package gox
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+// parallelBuffer is the number of solutions each branch of SolveParallel may
+// find ahead of the solutions being passed on, bounding the memory used by
+// branches which finish before those before them. The solutions are sent in
+// batches of parallelBatch, so that a worker finding many solutions takes the
+// lock of its channel once for each batch rather than for each solution.
+const (
+	parallelBuffer = 1024
+	parallelBatch  = 64
+)
+
+// cacheLine is the size of the cache lines of the processors gox runs on, as
+// far as padding is concerned
+const cacheLine = 64
+
+// parallelWorker holds the statistics of a worker of SolveParallel. The
+// workers update them as they search, so each is padded to a cache line of its
+// own: workers sharing a line would contend for it on every update.
+type parallelWorker struct {
+	stats Stats
+	_     [cacheLine - unsafe.Sizeof(Stats{})%cacheLine]byte
+}
+
+// ParallelConfig tunes the search of SolveParallelConfig
+type ParallelConfig struct {
+	// Workers is the number of goroutines searching, GOMAXPROCS if not
+	// positive
+	Workers int
+	// NodeBudget is the most nodes of the search tree each worker may visit,
+	// or 0 for no limit. Once a worker has spent its budget the search stops,
+	// returning the solutions found in order up to the point where that
+	// worker stopped, with ErrNodeBudget.
+	NodeBudget int64
+	// SplitDepth is the most rows chosen to reach a branch, 1 splitting the
+	// tree only at the root, or 0 for no limit. See planBranches.
+	SplitDepth int
+	// WorkStealing has each worker take the next branch not yet started
+	// when it finishes one. Otherwise the branches are dealt out to the
+	// workers in turn before the search starts, which keeps the work done
+	// by each worker reproducible but leaves workers idle when their
+	// branches are small.
+	WorkStealing bool
+}
+
+// DefaultParallelConfig returns the configuration used by SolveParallel: a
+// worker for each of GOMAXPROCS, with work stealing and no limits
+func DefaultParallelConfig() ParallelConfig {
+	return ParallelConfig{Workers: runtime.GOMAXPROCS(0), WorkStealing: true}
+}
+
+// ErrNodeBudget is returned by SolveParallelConfig when a worker has visited
+// the number of nodes allowed by ParallelConfig.NodeBudget
+var ErrNodeBudget = errors.New("Node budget of worker exhausted")
+
+// SolveParallel finds the solutions to the problem like SolveContext, using
+// up to workers goroutines. The search tree is split into branches, see
+// planBranches, each searched on a copy of the problem, and the solutions of
+// the branches are merged in the order of the tree. With MinRemaining or
+// FirstColumn, and the LeftmostTie, RightmostTie or DegreeTie tie-breaks,
+// which choose each column from the problem as it stands, the solutions are
+// in the same order as SolveContext finds them, whatever the number of
+// workers and however long each branch takes. This makes the results
+// reproducible, e.g. for golden tests, while still using every core.
+// ConflictWeighted, BucketedMinRemaining, RandomTie and RecentTie choose from
+// the search so far, which each branch starts afresh, so with them the same
+// solutions are found in an order which depends on the number of workers;
+// WithSortedOutput gives an order which does not. Each branch collects
+// its solutions in batches of its own, which are merged as they fill, so the
+// workers seldom wait on one another to pass their solutions on, and may find
+// parallelBuffer solutions ahead of those being passed on before it waits.
+//
+// WithLimit, WithHeuristic, WithSolutionFunc, WithoutSolutions, WithStore,
+// WithNogoods and WithStats are supported, the function given to
+// WithSolutionFunc and the store being called from the calling goroutine.
+// Each worker searches its own copy of the problem and counts its own
+// statistics, which are only added together once the workers have finished,
+// so the workers share no memory while they search. The other options have
+// no effect.
+func (p *exactCoverProblem) SolveParallel(ctx context.Context, workers int, opts ...Option) ([][]string, error) {
+	if workers <= 1 {
+		return p.SolveContext(ctx, opts...)
+	}
+	cfg := DefaultParallelConfig()
+	cfg.Workers = workers
+	return p.solveParallel(ctx, "SolveParallel", cfg, opts)
+}
+
+// SolveParallelConfig finds the solutions to the problem like SolveParallel,
+// with the search tuned by cfg
+func (p *exactCoverProblem) SolveParallelConfig(ctx context.Context, cfg ParallelConfig, opts ...Option) ([][]string, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.GOMAXPROCS(0)
+	}
+	return p.solveParallel(ctx, "SolveParallelConfig", cfg, opts)
+}
+
+// solveParallel implements SolveParallel and SolveParallelConfig, op being
+// the call made
+func (p *exactCoverProblem) solveParallel(ctx context.Context, op string, cfg ParallelConfig, opts []Option) (_ [][]string, err error) {
+	if err := p.startSolve(op); err != nil {
+		return nil, err
+	}
+	defer p.finishSolve(&err)
+	c := newConfig(ctx, opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cols := p.uncoverable(); cols != nil {
+		return nil, &UncoverableError{Columns: cols}
+	}
+	if p.root == p.root.right {
+		// The rows given are the only solution
+		soln := rowNames(c.output(p.solutionRows))
+		if c.sorted {
+			sort.Strings(soln)
+		}
+		if c.onSolution != nil {
+			c.onSolution(soln)
+		}
+		if c.discard {
+			return nil, nil
+		}
+		return [][]string{soln}, nil
+	}
+
+	workers := cfg.Workers
+	branches, splits := p.planBranches(c, workers, cfg.SplitDepth)
+	// errs holds the error which stopped each branch, set before its
+	// channel is closed
+	errs := make([]error, len(branches))
+	results := make([]chan [][]string, len(branches))
+	for i := range results {
+		results[i] = make(chan [][]string, parallelBuffer/parallelBatch)
+	}
+
+	// The workers share the time of the last solution, see WithIdleTimeout
+	branchOpts := append(opts[:len(opts):len(opts)], func(bc *config) { bc.lastFound = c.lastFound })
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	if workers > len(branches) {
+		workers = len(branches)
+	}
+	state := make([]parallelWorker, workers)
+	for w := range state {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			search := func(i int) {
+				errs[i] = p.solveBranch(ctx, branchOpts, branches[i], results[i], &state[w].stats, cfg.NodeBudget)
+				close(results[i])
+			}
+			if cfg.WorkStealing {
+				for i := range jobs {
+					search(i)
+				}
+				return
+			}
+			for i := w; i < len(branches) && ctx.Err() == nil; i += workers {
+				search(i)
+			}
+		}(w)
+	}
+	if cfg.WorkStealing {
+		go func() {
+			// The branches are started in order, so the branch being merged
+			// has always been started and the merge cannot wait on a branch
+			// which is itself waiting for a worker
+			defer close(jobs)
+			for i := range branches {
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	var ret [][]string
+	var branchErr error
+	count := 0
+	limited := false
+merge:
+	for i, ch := range results {
+		for batch := range ch {
+			for _, soln := range batch {
+				count++
+				if !c.discard || c.sorted {
+					ret = append(ret, soln)
+				}
+				if c.onSolution != nil && !c.sorted {
+					c.onSolution(soln)
+				}
+				if c.err != nil {
+					// Adding the solution to the store given by WithStore failed
+					break merge
+				}
+				if !c.sorted && c.limit > 0 && count >= c.limit {
+					limited = true
+					break merge
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if errs[i] != nil {
+			branchErr = errs[i]
+			break
+		}
+	}
+	cancel()
+	wg.Wait()
+	if c.sorted {
+		sortSolutions(ret)
+		if c.limit > 0 && len(ret) > c.limit {
+			ret = ret[:c.limit]
+		}
+		if c.onSolution != nil {
+			for _, soln := range ret {
+				c.onSolution(soln)
+			}
+		}
+		if c.discard {
+			ret = nil
+		}
+	}
+	if c.stats != nil {
+		// The nodes split by planBranches are nodes of the search tree
+		*c.stats = Stats{Nodes: splits, Solutions: int64(count)}
+		for _, w := range state {
+			c.stats.Nodes += w.stats.Nodes
+			c.stats.Updates += w.stats.Updates
+			c.stats.Pruned += w.stats.Pruned
+		}
+	}
+	if c.err != nil {
+		return ret, c.err
+	}
+	if limited {
+		return ret, nil
+	}
+	if branchErr != nil {
+		return ret, branchErr
+	}
+	return ret, c.ctx.Err()
+}
+
+// solveBranch searches a branch of SolveParallel, given by the indexes of the
+// rows chosen to reach it, on a copy of the problem, sending the solutions
+// found to out in batches until ctx is cancelled and adding the statistics of
+// the search to stats. ErrNodeBudget is returned if the worker's statistics
+// reach budget, if it is positive, and ErrIdleTimeout once WithIdleTimeout's
+// time has passed without a solution.
+func (p *exactCoverProblem) solveBranch(ctx context.Context, opts []Option, rows []int, out chan<- [][]string, stats *Stats, budget int64) (err error) {
+	if budget > 0 && stats.Nodes >= budget {
+		return ErrNodeBudget
+	}
+	c := newConfig(ctx, opts)
+	// A branch may be too small for the search to check the time itself
+	if c.idle > 0 && c.idleExpired() {
+		return ErrIdleTimeout
+	}
+	q, err := p.clone()
+	if err != nil {
+		return err
+	}
+	defer q.recoverSearch("SolveParallel", &err)
+	// Only the options affecting the search itself apply to a branch
+	c.limit, c.onSolution, c.participation, c.trace, c.events, c.stats, c.rng = 0, nil, nil, nil, nil, nil, nil
+	if budget > 0 {
+		c.budget = budget - stats.Nodes
+	}
+	// The solutions are sent on in batches, the last when the search ends
+	var batch [][]string
+	send := func() bool {
+		select {
+		case out <- batch:
+			batch = nil
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	c.found = func(rows []*rowHeader) bool {
+		if batch == nil {
+			batch = make([][]string, 0, parallelBatch)
+		}
+		batch = append(batch, rowNames(c.output(rows)))
+		return len(batch) < parallelBatch || send()
+	}
+
+	updates := q.updates
+	for _, row := range rows {
+		q.take(q.rowHeaders[row])
+	}
+	q.search(c)
+	if batch != nil {
+		send()
+	}
+	stats.Nodes += c.steps
+	stats.Updates += q.updates - updates
+	stats.Pruned += c.pruned
+	if c.err == ErrNodeBudget {
+		return c.err
+	}
+	return nil
+}
+
+// parallelBranches is the number of branches per worker SolveParallel aims to
+// split the search into, and parallelProbes the number of probes estimating
+// the size of each
+const (
+	parallelBranches = 8
+	parallelProbes   = 16
+)
+
+// plannedBranch is a branch of the search tree planned by planBranches: the
+// indexes of the rows chosen to reach it, and the estimated number of nodes
+// below it
+type plannedBranch struct {
+	rows []int
+	size float64
+}
+
+// planBranches splits the search tree into branches for SolveParallel to
+// search, in the order search visits them. Splitting only at the root leaves
+// one worker with most of the work when one row leads to most of the tree,
+// so the size of each branch is estimated by Knuth's method, see
+// EstimateSolutions, and the largest branch is split into the branches below
+// it until no branch is more than its share of the tree or there are
+// parallelBranches branches per worker. Branches reached by choosing maxDepth
+// rows are not split, unless maxDepth is 0. The number of nodes split is
+// returned with the branches. The matrix is restored.
+func (p *exactCoverProblem) planBranches(c *config, workers, maxDepth int) ([][]int, int64) {
+	// The estimates only decide where to split, so a fixed seed does no
+	// harm and keeps the split, and so the work done, reproducible
+	rng := rand.New(rand.NewSource(1))
+	planned, _ := p.splitBranch(c, nil, rng)
+	splits := int64(1)
+	target := workers * parallelBranches
+	for len(planned) < target {
+		largest, total := -1, 0.0
+		for i, b := range planned {
+			total += b.size
+			if maxDepth > 0 && len(b.rows) >= maxDepth {
+				continue
+			}
+			if largest < 0 || b.size > planned[largest].size {
+				largest = i
+			}
+		}
+		if largest < 0 || planned[largest].size <= total/float64(target) {
+			break
+		}
+		children, ok := p.splitBranch(c, planned[largest].rows, rng)
+		if !ok {
+			// A solution cannot be split
+			planned[largest].size = 0
+			continue
+		}
+		splits++
+		planned = append(planned[:largest], append(children, planned[largest+1:]...)...)
+	}
+
+	ret := make([][]int, len(planned))
+	for i, b := range planned {
+		ret[i] = b.rows
+	}
+	return ret, splits
+}
+
+// splitBranch returns the branches below the one reached by choosing rows,
+// one for each row of the column search would choose next, with their
+// estimated sizes. ok is unset if the branch is a solution, so has no
+// branches below it. The matrix is restored.
+func (p *exactCoverProblem) splitBranch(c *config, rows []int, rng *rand.Rand) (children []plannedBranch, ok bool) {
+	taken := make([]*rowHeader, len(rows))
+	for i, row := range rows {
+		taken[i] = p.rowHeaders[row]
+		p.take(taken[i])
+	}
+	defer p.untakeAll(taken)
+	if p.root == p.root.right {
+		return nil, false
+	}
+
+	colHead := p.nextCol(c)
+	for n := colHead.down; n != colHead; n = n.down {
+		p.take(n.rowHead)
+		size := 0.0
+		for i := 0; i < parallelProbes; i++ {
+			_, nodes := p.probe(c, rng)
+			size += nodes / parallelProbes
+		}
+		p.untake(n.rowHead)
+		children = append(children, plannedBranch{
+			rows: append(append([]int(nil), rows...), n.rowHead.index),
+			size: size,
+		})
+	}
+	return children, true
+}
+
+// clone returns a copy of the problem as it stands, with the same rows given
+func (p *exactCoverProblem) clone() (*exactCoverProblem, error) {
+	q := &exactCoverProblem{
+		numCols:       p.numCols,
+		numPrimary:    p.numPrimary,
+		rowsByName:    make(map[string]*rowHeader, len(p.rowsByName)),
+		colNames:      p.colNames,
+		colorNames:    p.colorNames,
+		removedCols:   p.removedCols,
+		colWeights:    p.colWeights,
+		colPriorities: p.colPriorities,
+		rowCosts:      p.rowCosts,
+		debug:         p.debug,
+	}
+	if p.rowsByID != nil {
+		q.rowsByID = make(map[int64]*rowHeader, len(p.rowsByID))
+	}
+	q.root = &node{colIndex: -1}
+	q.root.right = q.root
+	q.root.left = q.root
+	q.allocateColHeaders()
+	q.initializeColHeaders()
+	for col, h := range q.colHeaders {
+		if p.removedCols != nil && p.removedCols[col] && col < p.numPrimary {
+			h.left.right = h.right
+			h.right.left = h.left
+			h.left, h.right = h, h
+		}
+	}
+
+	// The rows are added in order, so that each column holds its rows in the
+	// same order as in p
+	rows := make([]sparseRow, len(p.rowHeaders))
+	for i, r := range p.rowHeaders {
+		row := sparseRow{name: r.name, id: r.id}
+		colored := false
+		for n := r.first; n != nil; {
+			color := n.color
+			if color < 0 {
+				color = -color
+			}
+			row.cols = append(row.cols, n.colIndex)
+			row.colors = append(row.colors, color)
+			colored = colored || color != 0
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+		// A row without colours is added as it was, so that the copy keeps
+		// the width of the rows, see noteRowWidth
+		if !colored {
+			row.colors = nil
+		}
+		rows[i] = row
+	}
+	q.reserve(len(rows), countCells(rows))
+	for _, row := range rows {
+		if err := q.addRow(row); err != nil {
+			return nil, err
+		}
+	}
+	q.dropReserved()
+	q.numRows = len(q.rowHeaders)
+	for i, r := range p.rowHeaders {
+		if r.forbidden {
+			if q.rowHeaders[i].first != nil {
+				q.unlink(q.rowHeaders[i])
+			}
+			q.rowHeaders[i].forbidden = true
+		}
+	}
+	for _, r := range p.solutionRows {
+		q.give(q.rowHeaders[r.index])
+		q.rowHeaders[r.index].given = r.given
+	}
+	q.setState(Ready)
+	return q, nil
+}
@@ -0,0 +1,240 @@
+package gox_test
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/testgen"
+)
+
+// parallelProblem is implemented by the problems created by gox
+type parallelProblem interface {
+	SolveParallel(context.Context, int, ...gox.Option) ([][]string, error)
+}
+
+func TestSolveParallel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      rng.Intn(40),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		opts := []gox.ProblemOption{gox.WithDebug()}
+		if trial%2 == 1 {
+			opts = append(opts, gox.WithMergeDuplicates(), gox.WithForcedRows())
+		}
+		prob, err := inst.Problem(opts...)
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		// Rows already forced cannot be given again
+		if len(inst.Planted) > 0 && trial%4 == 0 {
+			if err := prob.RowIsSolution(inst.Planted[0]); err != nil {
+				t.Fatalf("Error giving row: %v", err)
+			}
+		}
+		want, err := prob.SolveContext(context.Background())
+		if _, ok := err.(*gox.UncoverableError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		// The solutions are in the same order as SolveContext finds them
+		got, err := prob.(parallelProblem).SolveParallel(context.Background(), 4)
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Trial %d: expected %v, got %v", trial, want, got)
+		}
+	}
+}
+
+// splitOrders are the heuristics and tie-breaks, and whether searching the tree in parts,
+// as SolveParallel does, finds the solutions in the order SolveContext does
+// with each
+var splitOrders = []struct {
+	name    string
+	opts    []gox.Option
+	ordered bool
+}{
+	{"mrv", nil, true},
+	{"first", []gox.Option{gox.WithHeuristic(gox.FirstColumn)}, true},
+	{"wdeg", []gox.Option{gox.WithHeuristic(gox.ConflictWeighted)}, false},
+	{"bucket", []gox.Option{gox.WithHeuristic(gox.BucketedMinRemaining)}, false},
+	{"right", []gox.Option{gox.WithTieBreak(gox.RightmostTie)}, true},
+	{"degree", []gox.Option{gox.WithTieBreak(gox.DegreeTie)}, true},
+	{"random", []gox.Option{gox.WithTieBreak(gox.RandomTie)}, false},
+	{"recent", []gox.Option{gox.WithTieBreak(gox.RecentTie)}, false},
+}
+
+// sameSolutions reports whether a and b hold the same solutions, in any order
+func sameSolutions(a, b [][]string) bool {
+	key := func(solns [][]string) []string {
+		var ret []string
+		for _, soln := range solns {
+			soln = append([]string(nil), soln...)
+			sort.Strings(soln)
+			ret = append(ret, strings.Join(soln, " "))
+		}
+		sort.Strings(ret)
+		return ret
+	}
+	return reflect.DeepEqual(key(a), key(b))
+}
+
+func TestSolveParallelHeuristics(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for trial := 0; trial < 100; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      10 + rng.Intn(40),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		prob, err := inst.Problem(gox.WithDebug())
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		for _, o := range splitOrders {
+			want, err := prob.SolveContext(context.Background(), o.opts...)
+			if _, ok := err.(*gox.UncoverableError); ok {
+				break
+			} else if err != nil {
+				t.Fatalf("Error solving problem: %v", err)
+			}
+			got, err := prob.(parallelProblem).SolveParallel(context.Background(), 4, o.opts...)
+			if err != nil {
+				t.Fatalf("Error solving problem: %v", err)
+			}
+			if o.ordered && !reflect.DeepEqual(got, want) {
+				t.Fatalf("Trial %d, %s: expected %v, got %v", trial, o.name, want, got)
+			}
+			if !o.ordered && !sameSolutions(got, want) {
+				t.Fatalf("Trial %d, %s: expected the solutions %v in any order, got %v", trial, o.name, want, got)
+			}
+		}
+	}
+}
+
+func TestSolveParallelLimit(t *testing.T) {
+	prob, err := dominoBuilder(16).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	want, err := prob.SolveContext(context.Background(), gox.WithLimit(100))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	var streamed [][]string
+	got, err := prob.SolveParallel(context.Background(), 8, gox.WithLimit(100), gox.WithoutSolutions(), gox.WithSolutionFunc(func(soln []string) {
+		streamed = append(streamed, soln)
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("Expected no solutions to be kept, got %d", len(got))
+	}
+	if !reflect.DeepEqual(streamed, want) {
+		t.Fatalf("Expected the first 100 solutions in order, got %v", streamed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prob.SolveParallel(ctx, 8); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSolveParallelBatches(t *testing.T) {
+	// The branches find more solutions than fit in the buffers of their
+	// batches, which are merged in order all the same
+	prob, err := dominoBuilder(16).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	want, err := prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	for _, workers := range []int{2, 8} {
+		got, err := prob.SolveParallel(context.Background(), workers)
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%d workers: expected %d solutions in order, got %d", workers, len(want), len(got))
+		}
+	}
+}
+
+func TestSolveParallelStats(t *testing.T) {
+	prob, err := dominoBuilder(10).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var want, got gox.Stats
+	if _, err := prob.SolveContext(context.Background(), gox.WithStats(&want)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if _, err := prob.SolveParallel(context.Background(), 4, gox.WithStats(&got)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	// Each branch covers the first column itself, so only the updates differ
+	if got.Nodes != want.Nodes || got.Solutions != want.Solutions || got.Updates < want.Updates {
+		t.Fatalf("Expected stats like %+v, got %+v", want, got)
+	}
+}
+
+func TestSolveParallelConfig(t *testing.T) {
+	prob, err := dominoBuilder(14).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	want, err := prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	for _, cfg := range []gox.ParallelConfig{
+		gox.DefaultParallelConfig(),
+		{Workers: 3, SplitDepth: 1},
+		{Workers: 3, SplitDepth: 3, WorkStealing: true},
+		{Workers: 5},
+	} {
+		got, err := prob.SolveParallelConfig(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("Error solving problem with %+v: %v", cfg, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Expected %d solutions in order with %+v, got %v", len(want), cfg, got)
+		}
+	}
+
+	// Running out of budget stops the search, with the solutions found up to
+	// the point where the worker ran out
+	got, err := prob.SolveParallelConfig(context.Background(), gox.ParallelConfig{Workers: 2, NodeBudget: 100, WorkStealing: true})
+	if err != gox.ErrNodeBudget {
+		t.Fatalf("Expected ErrNodeBudget, got %v", err)
+	}
+	if len(got) >= len(want) || !reflect.DeepEqual(got, want[:len(got)]) {
+		t.Fatalf("Expected a prefix of the solutions, got %d of them", len(got))
+	}
+}
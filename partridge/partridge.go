@@ -0,0 +1,82 @@
+// Package partridge encodes the partridge puzzle, and other packings of squares
+// into rectangles, as exact cover problems using the polyomino package.
+//
+// The partridge puzzle of order n is to pack 1 square of side 1, 2 squares of
+// side 2, and so on up to n squares of side n, into a square board of side
+// n(n+1)/2. Since 1^3 + 2^3 + ... + n^3 is (n(n+1)/2)^2, the squares exactly
+// fill the board. There are no packings for n < 8.
+package partridge
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ifross89/gox/polyomino"
+)
+
+// Size returns the side of the board for the partridge puzzle of order n
+func Size(n int) int {
+	return n * (n + 1) / 2
+}
+
+// symbol returns the symbol used to draw a square of side k
+func symbol(k int) byte {
+	if k < 10 {
+		return byte('0' + k)
+	}
+	return byte('A' + k - 10)
+}
+
+// Square returns a piece for a square of side k which must be used count
+// times. The piece is drawn with the digit k, or a letter from A for sides of
+// 10 and above.
+func Square(k, count int) polyomino.Piece {
+	return polyomino.Piece{
+		Name:   fmt.Sprintf("%dx%d", k, k),
+		Symbol: symbol(k),
+		Shape:  polyomino.Rectangle(k, k),
+		Count:  count,
+	}
+}
+
+// Pieces returns the squares of the partridge puzzle of order n, k squares of
+// side k for k from 1 to n
+func Pieces(n int) []polyomino.Piece {
+	ret := make([]polyomino.Piece, n)
+	for k := 1; k <= n; k++ {
+		ret[k-1] = Square(k, k)
+	}
+	return ret
+}
+
+// New creates the packing for the partridge puzzle of order n
+func New(n int) (*polyomino.Packing, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("Order of partridge puzzle must be positive: %d", n)
+	}
+	return polyomino.New(polyomino.Rectangle(Size(n), Size(n)), Pieces(n))
+}
+
+// Squares creates a packing of a board with w columns and h rows using counts[k]
+// squares of side k. The squares must exactly fill the board.
+func Squares(w, h int, counts map[int]int) (*polyomino.Packing, error) {
+	var sides []int
+	area := 0
+	for k, count := range counts {
+		if k < 1 || count < 1 {
+			return nil, fmt.Errorf("Invalid count of squares: %d of side %d", count, k)
+		}
+		sides = append(sides, k)
+		area += k * k * count
+	}
+	if area != w*h {
+		return nil, fmt.Errorf("Squares must fill the board: area of squares=%d, area of board=%d", area, w*h)
+	}
+
+	sort.Ints(sides)
+	pieces := make([]polyomino.Piece, len(sides))
+	for i, k := range sides {
+		pieces[i] = Square(k, counts[k])
+	}
+	return polyomino.New(polyomino.Rectangle(w, h), pieces)
+}
@@ -0,0 +1,84 @@
+package partridge
+
+import (
+	"testing"
+)
+
+func TestPieces(t *testing.T) {
+	for n := 1; n <= 10; n++ {
+		area := 0
+		for _, p := range Pieces(n) {
+			area += len(p.Shape) * p.Count
+		}
+		if area != Size(n)*Size(n) {
+			t.Fatalf("Pieces of order %d have area %d, expected %d", n, area, Size(n)*Size(n))
+		}
+	}
+	if s := Square(12, 1); s.Symbol != 'C' || s.Name != "12x12" {
+		t.Fatalf("Unexpected square %+v", s)
+	}
+}
+
+func TestSmallOrders(t *testing.T) {
+	for n := 1; n <= 3; n++ {
+		p, err := New(n)
+		if err != nil {
+			t.Fatalf("Error creating puzzle of order %d: %v", n, err)
+		}
+		solns, err := p.Solve()
+		if err != nil {
+			t.Fatalf("Error solving puzzle of order %d: %v", n, err)
+		}
+		// Only the trivial puzzle of order 1 can be solved
+		if expected := map[bool]int{true: 1, false: 0}[n == 1]; len(solns) != expected {
+			t.Fatalf("Expected %d packings for order %d, got %q", expected, n, solns)
+		}
+	}
+}
+
+func TestSquares(t *testing.T) {
+	p, err := Squares(5, 5, map[int]int{1: 4, 2: 3, 3: 1})
+	if err != nil {
+		t.Fatalf("Error creating packing: %v", err)
+	}
+	solns, err := p.Solve()
+	if err != nil {
+		t.Fatalf("Error solving packing: %v", err)
+	}
+	if len(solns) == 0 {
+		t.Fatal("Expected packings of 5x5 square")
+	}
+	// Each packing is only found once, regardless of the order of the
+	// copies of each square
+	seen := make(map[string]bool)
+	for _, s := range solns {
+		if seen[s] {
+			t.Fatalf("Packing found more than once:\n%s", s)
+		}
+		seen[s] = true
+	}
+
+	if _, err := Squares(5, 5, map[int]int{2: 6}); err == nil {
+		t.Fatal("Expected error for squares not filling board")
+	}
+}
+
+func TestLargeBoard(t *testing.T) {
+	p, err := New(8)
+	if err != nil {
+		t.Fatalf("Error creating puzzle: %v", err)
+	}
+	prob, err := p.Problem()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	// A square of side k can be placed in (36-k+1)^2 positions by each of
+	// its k copies
+	expected := 0
+	for k := 1; k <= 8; k++ {
+		expected += k * (37 - k) * (37 - k)
+	}
+	if n := len(prob.Rows()); n != expected {
+		t.Fatalf("Expected %d rows, got %d", expected, n)
+	}
+}
@@ -0,0 +1,31 @@
+package gox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanBranches(t *testing.T) {
+	prob := dominoProblem(t, 16)
+	c := newConfig(nil, nil)
+	branches, splits := prob.planBranches(c, 4, 0)
+	// Splitting only the root gives two branches, one per domino covering
+	// the corner
+	if len(branches) < 8 || splits < 2 {
+		t.Fatalf("Expected the tree to be split further than the root, got %d branches from %d splits", len(branches), splits)
+	}
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Matrix not restored: %v", err)
+	}
+	// The plan is reproducible
+	again, _ := prob.planBranches(c, 4, 0)
+	if !reflect.DeepEqual(again, branches) {
+		t.Fatalf("Expected the same branches, got %v and %v", branches, again)
+	}
+
+	// Limiting the depth to the root leaves a branch per domino covering the
+	// corner
+	if shallow, splits := prob.planBranches(c, 4, 1); len(shallow) != 2 || splits != 1 {
+		t.Fatalf("Expected 2 branches from 1 split, got %v from %d", shallow, splits)
+	}
+}
@@ -0,0 +1,478 @@
+// Package polyiamond tiles boards made of equilateral triangles with
+// polyiamonds, shapes made by joining triangles edge to edge, using the exact
+// cover solver in gox.
+//
+// The board is made up of horizontal rows of triangles which alternately point
+// up and down. A tiling is found by giving each cell of the board a primary
+// column, along with each piece, and adding a row for every placement of every
+// orientation of every piece which fits on the board.
+package polyiamond
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+// Cell is a triangle in the grid. Row increases down the grid and Col
+// increases to the right along a row, with neighbouring cells in a row sharing
+// a sloping edge. The cell points up if Row+Col is even and down otherwise.
+type Cell struct {
+	Row, Col int
+}
+
+// Up reports whether the cell points up, i.e. its horizontal edge is below it
+func (c Cell) Up() bool {
+	return (c.Row+c.Col)%2 == 0
+}
+
+// Neighbours returns the three cells which share an edge with the cell
+func (c Cell) Neighbours() []Cell {
+	if c.Up() {
+		return []Cell{{c.Row, c.Col - 1}, {c.Row, c.Col + 1}, {c.Row + 1, c.Col}}
+	}
+	return []Cell{{c.Row, c.Col - 1}, {c.Row, c.Col + 1}, {c.Row - 1, c.Col}}
+}
+
+// The grid's symmetries are computed using three coordinates, one for each
+// direction of the lines in the grid. Each coordinate counts the number of
+// lines in its direction between the cell and the origin, measured against a
+// normal to the lines. The three normals are at 120 degrees to each other, so
+// the coordinates of a cell always sum to -1 (pointing up) or -2 (pointing
+// down). Rotating by 120 degrees cycles the coordinates, rotating by 180
+// degrees negates them and reflecting in a vertical line swaps two of them.
+type triangle struct {
+	a, b, c int
+}
+
+// toTriangle converts a cell to its triangle coordinates
+func toTriangle(c Cell) triangle {
+	sum := c.Row - 2
+	if c.Up() {
+		sum = c.Row - 1
+	}
+	diff := c.Col - 1
+	return triangle{a: -c.Row, b: (sum + diff) / 2, c: (sum - diff) / 2}
+}
+
+// toCell is the reverse of toTriangle
+func (t triangle) toCell() Cell {
+	return Cell{Row: -t.a, Col: t.b - t.c + 1}
+}
+
+// Shape is a set of cells, such as a piece or a board
+type Shape []Cell
+
+// ParseShape reads a shape from a string with one line per row of the grid. A
+// '.' or a space is not part of the shape, any other character is. Note that
+// whether a cell points up or down depends on its position, so the first
+// character of the first line is a cell pointing up.
+func ParseShape(s string) (Shape, error) {
+	var ret Shape
+	for r, line := range strings.Split(strings.Trim(s, "\n"), "\n") {
+		for c, ch := range []byte(strings.TrimRight(line, " \t")) {
+			if ch != '.' && ch != ' ' {
+				ret = append(ret, Cell{Row: r, Col: c})
+			}
+		}
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("Shape must contain at least one cell")
+	}
+	return ret, nil
+}
+
+// String returns the shape in the format read by ParseShape, using '^' for
+// cells pointing up and 'v' for cells pointing down. Shapes with cells in
+// negative rows or columns are normalized first.
+func (s Shape) String() string {
+	return s.render(func(c Cell) byte {
+		if c.Up() {
+			return '^'
+		}
+		return 'v'
+	})
+}
+
+// render draws the shape with the symbols given for each cell
+func (s Shape) render(symbol func(Cell) byte) string {
+	if len(s) == 0 {
+		return ""
+	}
+	minRow, minCol := s.min()
+	if minRow < 0 || minCol < 0 {
+		s = s.Normalize()
+	}
+
+	maxRow, maxCol := 0, 0
+	for _, c := range s {
+		if c.Row > maxRow {
+			maxRow = c.Row
+		}
+		if c.Col > maxCol {
+			maxCol = c.Col
+		}
+	}
+	lines := make([][]byte, maxRow+1)
+	for r := range lines {
+		lines[r] = []byte(strings.Repeat(".", maxCol+1))
+	}
+	for _, c := range s {
+		lines[c.Row][c.Col] = symbol(c)
+	}
+
+	ret := make([]string, len(lines))
+	for i, l := range lines {
+		ret[i] = string(l)
+	}
+	return strings.Join(ret, "\n")
+}
+
+// min returns the smallest row and column of any cell in the shape
+func (s Shape) min() (row, col int) {
+	row, col = s[0].Row, s[0].Col
+	for _, c := range s[1:] {
+		if c.Row < row {
+			row = c.Row
+		}
+		if c.Col < col {
+			col = c.Col
+		}
+	}
+	return row, col
+}
+
+// Translate moves the shape down by dr rows and right by dc columns. Since the
+// direction of a cell depends on its position, dr+dc must be even.
+func (s Shape) Translate(dr, dc int) Shape {
+	ret := make(Shape, len(s))
+	for i, c := range s {
+		ret[i] = Cell{Row: c.Row + dr, Col: c.Col + dc}
+	}
+	return ret
+}
+
+// Normalize translates the shape so that its smallest row is zero and its
+// smallest column is zero or one, and sorts the cells by row then column. Two
+// shapes which are translations of each other have the same normalized form.
+func (s Shape) Normalize() Shape {
+	minRow, minCol := s.min()
+	dr, dc := -minRow, -minCol
+	if (dr+dc)%2 != 0 {
+		dc++
+	}
+	ret := s.Translate(dr, dc)
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Row != ret[j].Row {
+			return ret[i].Row < ret[j].Row
+		}
+		return ret[i].Col < ret[j].Col
+	})
+	return ret
+}
+
+// transform applies f to the triangle coordinates of every cell in the shape
+func (s Shape) transform(f func(triangle) triangle) Shape {
+	ret := make(Shape, len(s))
+	for i, c := range s {
+		ret[i] = f(toTriangle(c)).toCell()
+	}
+	return ret.Normalize()
+}
+
+// Rotate turns the shape by 60 degrees, returning it normalized
+func (s Shape) Rotate() Shape {
+	// A rotation by 180 degrees combined with one by 120 degrees
+	return s.transform(func(t triangle) triangle {
+		return triangle{a: -t.b - 1, b: -t.c - 1, c: -t.a - 1}
+	})
+}
+
+// Reflect flips the shape from left to right, returning it normalized
+func (s Shape) Reflect() Shape {
+	return s.transform(func(t triangle) triangle {
+		return triangle{a: t.a, b: t.c, c: t.b}
+	})
+}
+
+// Orientations returns the distinct normalized shapes which can be made by
+// rotating and reflecting the shape. There are at most 12 of them.
+func (s Shape) Orientations() []Shape {
+	var ret []Shape
+	seen := make(map[string]bool)
+	for _, start := range []Shape{s.Normalize(), s.Reflect()} {
+		o := start
+		for i := 0; i < 6; i++ {
+			if key := o.String(); !seen[key] {
+				seen[key] = true
+				ret = append(ret, o)
+			}
+			o = o.Rotate()
+		}
+	}
+	return ret
+}
+
+// Connected reports whether every cell in the shape can be reached from every
+// other by moving between neighbouring cells of the shape
+func (s Shape) Connected() bool {
+	if len(s) == 0 {
+		return false
+	}
+	inShape := make(map[Cell]bool, len(s))
+	for _, c := range s {
+		inShape[c] = true
+	}
+	seen := map[Cell]bool{s[0]: true}
+	stack := []Cell{s[0]}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range c.Neighbours() {
+			if inShape[n] && !seen[n] {
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return len(seen) == len(inShape)
+}
+
+// Parallelogram returns a board of h rows of 2*w cells. Each row starts one
+// column to the left of the row above, so that the left and right edges of the
+// board are straight. A parallelogram with w == h is a rhombus.
+func Parallelogram(w, h int) Shape {
+	var ret Shape
+	start := firstColumn(h)
+	for r := 0; r < h; r++ {
+		for c := 0; c < 2*w; c++ {
+			ret = append(ret, Cell{Row: r, Col: start - r + c})
+		}
+	}
+	return ret
+}
+
+// Triangle returns a board in the shape of a triangle, pointing up, with sides
+// of length n
+func Triangle(n int) Shape {
+	var ret Shape
+	start := firstColumn(n)
+	for r := 0; r < n; r++ {
+		for c := 0; c < 2*r+1; c++ {
+			ret = append(ret, Cell{Row: r, Col: start - r + c})
+		}
+	}
+	return ret
+}
+
+// Hexagon returns a board in the shape of a regular hexagon with sides of
+// length n
+func Hexagon(n int) Shape {
+	var ret Shape
+	start := firstColumn(n)
+	for r := 0; r < 2*n; r++ {
+		// The rows widen by one cell either side until the middle of the
+		// hexagon, then narrow again
+		first, count := start-r, 2*n+1+2*r
+		if r >= n {
+			first, count = start-2*n+1+r, 6*n-1-2*r
+		}
+		for c := 0; c < count; c++ {
+			ret = append(ret, Cell{Row: r, Col: first + c})
+		}
+	}
+	return ret
+}
+
+// firstColumn returns the column of the first cell in the top row of a board
+// whose rows each start one column further left, over h rows. This is the
+// smallest column which keeps every column non-negative and makes the top row
+// start with a cell pointing up.
+func firstColumn(h int) int {
+	return h - 1 + (h-1)%2
+}
+
+// Piece is a polyiamond used to tile a board
+type Piece struct {
+	Name string
+	// Symbol is used to draw the piece in a tiling
+	Symbol byte
+	Shape  Shape
+}
+
+// mustParseShape parses shapes which are known to be valid
+func mustParseShape(s string) Shape {
+	ret, err := ParseShape(s)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// Hexiamonds returns the 12 hexiamonds, the polyiamonds made from 6 triangles,
+// with the names given to them by T. H. O'Beirne
+func Hexiamonds() []Piece {
+	return []Piece{
+		{"Bar", 'I', mustParseShape("^v^v^v")},
+		{"Yacht", 'Y', mustParseShape("^v^v^\nv")},
+		{"Crown", 'C', mustParseShape("^v^v^\n..v")},
+		{"Chevron", 'V', mustParseShape("..^v^v\n.^v")},
+		{"Crook", 'J', mustParseShape("^v^v\nv^")},
+		{"Lobster", 'L', mustParseShape("^v^v\nv.v")},
+		{"Signpost", 'P', mustParseShape("^v^v\n.^v")},
+		{"Hook", 'H', mustParseShape("^v^v\n..v^")},
+		{"Snake", 'S', mustParseShape("..^v^\nv^v")},
+		{"Hexagon", 'O', mustParseShape("^v^\nv^v")},
+		{"Sphinx", 'X', mustParseShape("..^v\nv^v\n.v")},
+		{"Butterfly", 'B', mustParseShape("..^\nv^v^\n.v")},
+	}
+}
+
+// placement is a piece placed on the board, represented by a row of the
+// problem
+type placement struct {
+	piece int
+	cells Shape
+}
+
+// Tiling is the problem of covering every cell of a board with the pieces
+// given, using each piece exactly once
+type Tiling struct {
+	board  Shape
+	pieces []Piece
+	// placements maps the names of the rows in the problem to the placements
+	// they represent
+	placements map[string]placement
+	builder    *gox.Builder
+}
+
+// cellName is the name of the column for a cell of the board
+func cellName(c Cell) string {
+	return fmt.Sprintf("%d,%d", c.Row, c.Col)
+}
+
+// New creates a tiling of the board with the pieces given. Pieces may be
+// rotated and reflected, and must have distinct names.
+func New(board Shape, pieces []Piece) (*Tiling, error) {
+	t := &Tiling{
+		board:      board,
+		pieces:     pieces,
+		placements: make(map[string]placement),
+		builder:    gox.NewBuilder(),
+	}
+
+	onBoard := make(map[Cell]bool, len(board))
+	for _, c := range board {
+		if onBoard[c] {
+			return nil, fmt.Errorf("Board contains cell %d,%d more than once", c.Row, c.Col)
+		}
+		onBoard[c] = true
+		if err := t.builder.AddColumns(cellName(c)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range pieces {
+		if !p.Shape.Connected() {
+			return nil, fmt.Errorf("Piece %s is not connected", p.Name)
+		}
+		if err := t.builder.AddColumns(p.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, p := range pieces {
+		for o, shape := range p.Shape.Orientations() {
+			// Try each cell of the board as the position of the shape's
+			// first cell, the translation must keep the cells' directions
+			for _, anchor := range board {
+				dr, dc := anchor.Row-shape[0].Row, anchor.Col-shape[0].Col
+				if (dr+dc)%2 != 0 {
+					continue
+				}
+				cells := shape.Translate(dr, dc)
+				items := []string{p.Name}
+				for _, c := range cells {
+					if !onBoard[c] {
+						items = nil
+						break
+					}
+					items = append(items, cellName(c))
+				}
+				if items == nil {
+					continue
+				}
+
+				name := fmt.Sprintf("%s/%d@%d,%d", p.Name, o, anchor.Row, anchor.Col)
+				if err := t.builder.AddRow(name, items...); err != nil {
+					return nil, err
+				}
+				t.placements[name] = placement{piece: i, cells: cells}
+			}
+		}
+	}
+	return t, nil
+}
+
+// Problem creates the exact cover problem for the tiling. The rows of the
+// problem are named after the piece, the index of its orientation and the
+// position of its first cell, e.g. "Sphinx/3@2,5".
+func (t *Tiling) Problem() (gox.Solver, error) {
+	prob, err := t.builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode returns the piece covering each cell of the board in a solution to
+// the tiling's problem
+func (t *Tiling) Decode(solution []string) (map[Cell]Piece, error) {
+	ret := make(map[Cell]Piece, len(t.board))
+	for _, name := range solution {
+		pl, ok := t.placements[name]
+		if !ok {
+			return nil, fmt.Errorf("No placement found with name %s", name)
+		}
+		for _, c := range pl.cells {
+			ret[c] = t.pieces[pl.piece]
+		}
+	}
+	return ret, nil
+}
+
+// Render draws a solution to the tiling's problem in the format read by
+// ParseShape, with each cell showing the symbol of the piece covering it
+func (t *Tiling) Render(solution []string) (string, error) {
+	cells, err := t.Decode(solution)
+	if err != nil {
+		return "", err
+	}
+	return t.board.render(func(c Cell) byte {
+		if p, ok := cells[c]; ok {
+			return p.Symbol
+		}
+		return '?'
+	}), nil
+}
+
+// Solve finds every tiling of the board, returning each as it is drawn by
+// Render
+func (t *Tiling) Solve() ([]string, error) {
+	prob, err := t.Problem()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, soln := range prob.Solve() {
+		s, err := t.Render(soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
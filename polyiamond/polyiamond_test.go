@@ -0,0 +1,147 @@
+package polyiamond
+
+import (
+	"testing"
+)
+
+func TestTriangleCoordinates(t *testing.T) {
+	for r := -3; r <= 3; r++ {
+		for c := -3; c <= 3; c++ {
+			cell := Cell{Row: r, Col: c}
+			tri := toTriangle(cell)
+			if back := tri.toCell(); back != cell {
+				t.Fatalf("Cell %v converted to %v and back to %v", cell, tri, back)
+			}
+			sum := tri.a + tri.b + tri.c
+			if (cell.Up() && sum != -1) || (!cell.Up() && sum != -2) {
+				t.Fatalf("Cell %v has coordinates %v", cell, tri)
+			}
+		}
+	}
+}
+
+func TestRotateReflect(t *testing.T) {
+	for _, p := range Hexiamonds() {
+		s := p.Shape.Normalize()
+		r := s
+		for i := 0; i < 6; i++ {
+			r = r.Rotate()
+			if !r.Connected() || len(r) != len(s) {
+				t.Fatalf("Rotating %s gave an invalid shape:\n%v", p.Name, r)
+			}
+		}
+		if r.String() != s.String() {
+			t.Fatalf("Rotating %s six times gave\n%v\nexpected\n%v", p.Name, r, s)
+		}
+		if f := s.Reflect().Reflect(); f.String() != s.String() {
+			t.Fatalf("Reflecting %s twice gave\n%v\nexpected\n%v", p.Name, f, s)
+		}
+	}
+}
+
+func TestHexiamonds(t *testing.T) {
+	// There are 94 hexiamonds when rotations and reflections are counted as
+	// distinct
+	total := 0
+	seen := make(map[string]string)
+	for _, p := range Hexiamonds() {
+		if len(p.Shape) != 6 || !p.Shape.Connected() {
+			t.Fatalf("%s is not a hexiamond:\n%v", p.Name, p.Shape)
+		}
+		for _, o := range p.Shape.Orientations() {
+			if other, ok := seen[o.String()]; ok {
+				t.Fatalf("%s and %s are the same shape", p.Name, other)
+			}
+			seen[o.String()] = p.Name
+			total++
+		}
+	}
+	if total != 94 {
+		t.Fatalf("Expected 94 fixed hexiamonds, got %d", total)
+	}
+}
+
+func TestBoards(t *testing.T) {
+	boards := []struct {
+		name  string
+		board Shape
+		size  int
+	}{
+		{"Parallelogram(3, 2)", Parallelogram(3, 2), 12},
+		{"Parallelogram(2, 3)", Parallelogram(2, 3), 12},
+		{"Triangle(3)", Triangle(3), 9},
+		{"Triangle(4)", Triangle(4), 16},
+		{"Hexagon(1)", Hexagon(1), 6},
+		{"Hexagon(2)", Hexagon(2), 24},
+	}
+	for _, b := range boards {
+		if len(b.board) != b.size || !b.board.Connected() {
+			t.Fatalf("%s is invalid:\n%v", b.name, b.board)
+		}
+		// The top row of each board starts with a cell pointing up
+		if first := b.board.Normalize()[0]; !first.Up() {
+			t.Fatalf("%s starts with a cell pointing down:\n%v", b.name, b.board)
+		}
+	}
+
+	if s := Hexagon(1).String(); s != "^v^\nv^v" {
+		t.Fatalf("Unexpected hexagon:\n%s", s)
+	}
+	if s := Triangle(2).String(); s != "..^.\n.^v^" {
+		t.Fatalf("Unexpected triangle:\n%s", s)
+	}
+}
+
+func TestTilingSolve(t *testing.T) {
+	triamond := mustParseShape("^v^")
+	pieces := []Piece{{"A", 'A', triamond}, {"B", 'B', triamond}}
+	tiling, err := New(Hexagon(1), pieces)
+	if err != nil {
+		t.Fatalf("Error creating tiling: %v", err)
+	}
+	solns, err := tiling.Solve()
+	if err != nil {
+		t.Fatalf("Error solving tiling: %v", err)
+	}
+	// The hexagon can be cut in half in three ways, and each piece can
+	// take either half
+	if len(solns) != 6 {
+		t.Fatalf("Expected 6 tilings, got %d: %q", len(solns), solns)
+	}
+	for _, s := range solns {
+		if s == "AAA\nBBB" {
+			return
+		}
+	}
+	t.Fatalf("Expected tiling with pieces in rows, got %q", solns)
+}
+
+func TestHexiamondRhombus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping hexiamond enumeration in short mode")
+	}
+	tiling, err := New(Parallelogram(6, 6), Hexiamonds())
+	if err != nil {
+		t.Fatalf("Error creating tiling: %v", err)
+	}
+	prob, err := tiling.Problem()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	// There are 156 tilings of the 6x6 rhombus, each of which appears four
+	// times as the rhombus has four symmetries
+	if n := len(prob.Solve()); n != 4*156 {
+		t.Fatalf("Expected %d tilings, got %d", 4*156, n)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	disconnected := mustParseShape("^.^")
+	if _, err := New(Hexagon(1), []Piece{{"X", 'X', disconnected}}); err == nil {
+		t.Fatal("Expected error for disconnected piece")
+	}
+	triamond := mustParseShape("^v^")
+	if _, err := New(Hexagon(1), []Piece{{"A", 'A', triamond}, {"A", 'A', triamond}}); err == nil {
+		t.Fatal("Expected error for duplicate piece names")
+	}
+}
@@ -0,0 +1,571 @@
+// Package polyomino packs boards made of squares with polyominoes, shapes made
+// by joining squares edge to edge, using the exact cover solver in gox.
+//
+// Each cell of the board is a primary column. A piece which must be used a
+// fixed number of times has a primary column for each copy, and a row for
+// every placement of every orientation of every copy which fits on the board.
+// Copies of the same piece are interchangeable, so to avoid finding the same
+// packing once for every permutation of the copies, each copy must be placed
+// at a later position than the copy before it. The positions are split into
+// blocks of about the square root of their number, and each pair of
+// consecutive copies has a secondary column for each block, and for each
+// position within each block. The row placing a copy covers, for the pair it
+// makes with the next copy, the columns of the blocks before its own and of
+// the positions in its block up to its own. For the pair with the previous
+// copy, it covers the columns of the blocks from its own onwards and of the
+// positions in its block from its own onwards. Two consecutive copies
+// therefore conflict unless the second comes later, while each row only covers
+// a number of ordering columns proportional to the size of a block.
+//
+// A piece which may be used any number of times has no column of its own, so
+// the board's cells alone determine which placements are chosen.
+package polyomino
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+// Cell is a square in the grid, Row increases down the grid and Col increases
+// to the right
+type Cell struct {
+	Row, Col int
+}
+
+// Shape is a set of cells, such as a piece or a board
+type Shape []Cell
+
+// ParseShape reads a shape from a string with one line per row of the grid. A
+// '.' or a space is not part of the shape, any other character is.
+func ParseShape(s string) (Shape, error) {
+	var ret Shape
+	for r, line := range strings.Split(strings.Trim(s, "\n"), "\n") {
+		for c, ch := range []byte(strings.TrimRight(line, " \t")) {
+			if ch != '.' && ch != ' ' {
+				ret = append(ret, Cell{Row: r, Col: c})
+			}
+		}
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("Shape must contain at least one cell")
+	}
+	return ret, nil
+}
+
+// mustParseShape parses shapes which are known to be valid
+func mustParseShape(s string) Shape {
+	ret, err := ParseShape(s)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// String returns the shape in the format read by ParseShape, using '#' for
+// the cells of the shape. Shapes with cells in negative rows or columns are
+// normalized first.
+func (s Shape) String() string {
+	return s.render(func(Cell) byte { return '#' })
+}
+
+// render draws the shape with the symbols given for each cell
+func (s Shape) render(symbol func(Cell) byte) string {
+	if len(s) == 0 {
+		return ""
+	}
+	minRow, minCol := s.min()
+	if minRow < 0 || minCol < 0 {
+		s = s.Normalize()
+	}
+
+	maxRow, maxCol := 0, 0
+	for _, c := range s {
+		if c.Row > maxRow {
+			maxRow = c.Row
+		}
+		if c.Col > maxCol {
+			maxCol = c.Col
+		}
+	}
+	lines := make([][]byte, maxRow+1)
+	for r := range lines {
+		lines[r] = []byte(strings.Repeat(".", maxCol+1))
+	}
+	for _, c := range s {
+		lines[c.Row][c.Col] = symbol(c)
+	}
+
+	ret := make([]string, len(lines))
+	for i, l := range lines {
+		ret[i] = string(l)
+	}
+	return strings.Join(ret, "\n")
+}
+
+// min returns the smallest row and column of any cell in the shape
+func (s Shape) min() (row, col int) {
+	row, col = s[0].Row, s[0].Col
+	for _, c := range s[1:] {
+		if c.Row < row {
+			row = c.Row
+		}
+		if c.Col < col {
+			col = c.Col
+		}
+	}
+	return row, col
+}
+
+// Translate moves the shape down by dr rows and right by dc columns
+func (s Shape) Translate(dr, dc int) Shape {
+	ret := make(Shape, len(s))
+	for i, c := range s {
+		ret[i] = Cell{Row: c.Row + dr, Col: c.Col + dc}
+	}
+	return ret
+}
+
+// Normalize translates the shape so that its smallest row and column are zero,
+// and sorts the cells by row then column. Two shapes which are translations of
+// each other have the same normalized form.
+func (s Shape) Normalize() Shape {
+	minRow, minCol := s.min()
+	ret := s.Translate(-minRow, -minCol)
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Row != ret[j].Row {
+			return ret[i].Row < ret[j].Row
+		}
+		return ret[i].Col < ret[j].Col
+	})
+	return ret
+}
+
+// Rotate turns the shape clockwise by 90 degrees, returning it normalized
+func (s Shape) Rotate() Shape {
+	ret := make(Shape, len(s))
+	for i, c := range s {
+		ret[i] = Cell{Row: c.Col, Col: -c.Row}
+	}
+	return ret.Normalize()
+}
+
+// Reflect flips the shape from left to right, returning it normalized
+func (s Shape) Reflect() Shape {
+	ret := make(Shape, len(s))
+	for i, c := range s {
+		ret[i] = Cell{Row: c.Row, Col: -c.Col}
+	}
+	return ret.Normalize()
+}
+
+// Orientations returns the distinct normalized shapes which can be made by
+// rotating the shape and, if reflect is true, reflecting it. There are at most
+// 8 of them.
+func (s Shape) Orientations(reflect bool) []Shape {
+	starts := []Shape{s.Normalize()}
+	if reflect {
+		starts = append(starts, s.Reflect())
+	}
+
+	var ret []Shape
+	seen := make(map[string]bool)
+	for _, o := range starts {
+		for i := 0; i < 4; i++ {
+			if key := o.String(); !seen[key] {
+				seen[key] = true
+				ret = append(ret, o)
+			}
+			o = o.Rotate()
+		}
+	}
+	return ret
+}
+
+// Connected reports whether every cell in the shape can be reached from every
+// other by moving between neighbouring cells of the shape
+func (s Shape) Connected() bool {
+	if len(s) == 0 {
+		return false
+	}
+	inShape := make(map[Cell]bool, len(s))
+	for _, c := range s {
+		inShape[c] = true
+	}
+	seen := map[Cell]bool{s[0]: true}
+	stack := []Cell{s[0]}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range []Cell{{c.Row - 1, c.Col}, {c.Row + 1, c.Col}, {c.Row, c.Col - 1}, {c.Row, c.Col + 1}} {
+			if inShape[n] && !seen[n] {
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return len(seen) == len(inShape)
+}
+
+// Rectangle returns a board with h rows of w cells
+func Rectangle(w, h int) Shape {
+	ret := make(Shape, 0, w*h)
+	for r := 0; r < h; r++ {
+		for c := 0; c < w; c++ {
+			ret = append(ret, Cell{Row: r, Col: c})
+		}
+	}
+	return ret
+}
+
+// Without returns a copy of the shape with the cells given removed, e.g. to
+// make holes in a board
+func (s Shape) Without(cells ...Cell) Shape {
+	remove := make(map[Cell]bool, len(cells))
+	for _, c := range cells {
+		remove[c] = true
+	}
+	var ret Shape
+	for _, c := range s {
+		if !remove[c] {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Any is the Count of a piece which may be used any number of times
+const Any = -1
+
+// Piece is a polyomino used to pack a board
+type Piece struct {
+	Name string
+	// Symbol is used to draw the piece in a packing
+	Symbol byte
+	Shape  Shape
+	// Count is the number of copies of the piece which must be used. A count
+	// of zero is treated as one, and a count of Any allows the piece to be
+	// used any number of times, including not at all.
+	Count int
+	// OneSided pieces may be rotated but not reflected
+	OneSided bool
+}
+
+// copies returns the number of copies of the piece which must be used, or Any
+func (p Piece) copies() int {
+	if p.Count == 0 {
+		return 1
+	}
+	return p.Count
+}
+
+// Tetrominoes returns the 5 free tetrominoes, the polyominoes made from 4
+// squares, which may be rotated and reflected
+func Tetrominoes() []Piece {
+	return []Piece{
+		{Name: "I", Symbol: 'I', Shape: mustParseShape("####")},
+		{Name: "O", Symbol: 'O', Shape: mustParseShape("##\n##")},
+		{Name: "T", Symbol: 'T', Shape: mustParseShape("###\n.#")},
+		{Name: "S", Symbol: 'S', Shape: mustParseShape(".##\n##")},
+		{Name: "L", Symbol: 'L', Shape: mustParseShape("###\n#")},
+	}
+}
+
+// OneSidedTetrominoes returns the 7 tetrominoes used in Tetris, which may be
+// rotated but not reflected
+func OneSidedTetrominoes() []Piece {
+	return []Piece{
+		{Name: "I", Symbol: 'I', Shape: mustParseShape("####"), OneSided: true},
+		{Name: "O", Symbol: 'O', Shape: mustParseShape("##\n##"), OneSided: true},
+		{Name: "T", Symbol: 'T', Shape: mustParseShape("###\n.#"), OneSided: true},
+		{Name: "S", Symbol: 'S', Shape: mustParseShape(".##\n##"), OneSided: true},
+		{Name: "Z", Symbol: 'Z', Shape: mustParseShape("##\n.##"), OneSided: true},
+		{Name: "J", Symbol: 'J', Shape: mustParseShape("#\n###"), OneSided: true},
+		{Name: "L", Symbol: 'L', Shape: mustParseShape("..#\n###"), OneSided: true},
+	}
+}
+
+// Pentominoes returns the 12 free pentominoes, the polyominoes made from 5
+// squares, which may be rotated and reflected. They are named with Conway's
+// letters.
+func Pentominoes() []Piece {
+	return []Piece{
+		{Name: "F", Symbol: 'F', Shape: mustParseShape(".##\n##\n.#")},
+		{Name: "I", Symbol: 'I', Shape: mustParseShape("#####")},
+		{Name: "L", Symbol: 'L', Shape: mustParseShape("####\n#")},
+		{Name: "N", Symbol: 'N', Shape: mustParseShape("##\n.###")},
+		{Name: "P", Symbol: 'P', Shape: mustParseShape("##\n##\n#")},
+		{Name: "T", Symbol: 'T', Shape: mustParseShape("###\n.#\n.#")},
+		{Name: "U", Symbol: 'U', Shape: mustParseShape("#.#\n###")},
+		{Name: "V", Symbol: 'V', Shape: mustParseShape("#\n#\n###")},
+		{Name: "W", Symbol: 'W', Shape: mustParseShape("#\n##\n.##")},
+		{Name: "X", Symbol: 'X', Shape: mustParseShape(".#\n###\n.#")},
+		{Name: "Y", Symbol: 'Y', Shape: mustParseShape("####\n.#")},
+		{Name: "Z", Symbol: 'Z', Shape: mustParseShape("##\n.#\n.##")},
+	}
+}
+
+// WithCount returns copies of the pieces which must each be used n times
+func WithCount(pieces []Piece, n int) []Piece {
+	ret := make([]Piece, len(pieces))
+	for i, p := range pieces {
+		p.Count = n
+		ret[i] = p
+	}
+	return ret
+}
+
+// placement is a piece placed on the board, represented by a row of the
+// problem
+type placement struct {
+	piece int
+	cells Shape
+}
+
+// Packing is the problem of covering every cell of a board with the pieces
+// given
+type Packing struct {
+	board  Shape
+	pieces []Piece
+	// placements maps the names of the rows in the problem to the placements
+	// they represent
+	placements map[string]placement
+	builder    *gox.Builder
+}
+
+// cellName is the name of the column for a cell of the board
+func cellName(c Cell) string {
+	return fmt.Sprintf("%d,%d", c.Row, c.Col)
+}
+
+// copyName is the name of the column for a copy of a piece
+func copyName(p Piece, k int) string {
+	return fmt.Sprintf("%s#%d", p.Name, k)
+}
+
+// blockName is the name of the secondary column ordering a copy of a piece and
+// the next copy in a block of positions
+func blockName(p Piece, k, block int) string {
+	return fmt.Sprintf("%s#%d<%d", p.Name, k, block)
+}
+
+// orderName is the name of the secondary column ordering a copy of a piece and
+// the next copy at a position within a block
+func orderName(p Piece, k, block, position int) string {
+	return fmt.Sprintf("%s#%d<%d.%d", p.Name, k, block, position)
+}
+
+// New creates a packing of the board with the pieces given, which must have
+// distinct names
+func New(board Shape, pieces []Piece) (*Packing, error) {
+	p := &Packing{
+		board:      board,
+		pieces:     pieces,
+		placements: make(map[string]placement),
+		builder:    gox.NewBuilder(),
+	}
+
+	onBoard := make(map[Cell]bool, len(board))
+	for _, c := range board {
+		if onBoard[c] {
+			return nil, fmt.Errorf("Board contains cell %d,%d more than once", c.Row, c.Col)
+		}
+		onBoard[c] = true
+		if err := p.builder.AddColumns(cellName(c)); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]bool, len(pieces))
+	for i, piece := range pieces {
+		if names[piece.Name] {
+			return nil, fmt.Errorf("Duplicate piece name present: %s", piece.Name)
+		}
+		names[piece.Name] = true
+		if !piece.Shape.Connected() {
+			return nil, fmt.Errorf("Piece %s is not connected", piece.Name)
+		}
+		if piece.Count < 0 && piece.Count != Any {
+			return nil, fmt.Errorf("Piece %s has invalid count %d", piece.Name, piece.Count)
+		}
+		if err := p.addPiece(i, onBoard); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// addPiece adds the columns and rows for a piece
+func (p *Packing) addPiece(index int, onBoard map[Cell]bool) error {
+	piece := p.pieces[index]
+
+	// Find every position the piece can be placed in
+	var positions []Shape
+	for _, shape := range piece.Shape.Orientations(!piece.OneSided) {
+		for _, anchor := range p.board {
+			cells := shape.Translate(anchor.Row-shape[0].Row, anchor.Col-shape[0].Col)
+			fits := true
+			for _, c := range cells {
+				if !onBoard[c] {
+					fits = false
+					break
+				}
+			}
+			if fits {
+				positions = append(positions, cells)
+			}
+		}
+	}
+
+	copies := piece.copies()
+	if copies == Any {
+		for i, cells := range positions {
+			if err := p.addPlacement(piece.Name, index, i, cells, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Split the positions into blocks for ordering the copies
+	size := int(math.Ceil(math.Sqrt(float64(len(positions)))))
+	blocks := 0
+	if size > 0 {
+		blocks = (len(positions) + size - 1) / size
+	}
+
+	for k := 0; k < copies; k++ {
+		name := piece.Name
+		if copies > 1 {
+			name = copyName(piece, k)
+		}
+		if err := p.builder.AddColumns(name); err != nil {
+			return err
+		}
+		if k == copies-1 {
+			continue
+		}
+		for b := 0; b < blocks; b++ {
+			if err := p.builder.AddSecondaryColumns(blockName(piece, k, b)); err != nil {
+				return err
+			}
+			for i := 0; i < size; i++ {
+				if err := p.builder.AddSecondaryColumns(orderName(piece, k, b, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for k := 0; k < copies; k++ {
+		for i, cells := range positions {
+			name := piece.Name
+			var items []string
+			if copies > 1 {
+				name = copyName(piece, k)
+				block, offset := i/size, i%size
+				// This copy comes after the previous one...
+				if k > 0 {
+					for b := block; b < blocks; b++ {
+						items = append(items, blockName(piece, k-1, b))
+					}
+					for j := offset; j < size; j++ {
+						items = append(items, orderName(piece, k-1, block, j))
+					}
+				}
+				// ...and before the next one
+				if k < copies-1 {
+					for b := 0; b < block; b++ {
+						items = append(items, blockName(piece, k, b))
+					}
+					for j := 0; j <= offset; j++ {
+						items = append(items, orderName(piece, k, block, j))
+					}
+				}
+			}
+			items = append(items, name)
+			if err := p.addPlacement(name, index, i, cells, items); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addPlacement adds the row placing a piece on the cells given, along with any
+// other items the row covers
+func (p *Packing) addPlacement(name string, piece, position int, cells Shape, items []string) error {
+	for _, c := range cells {
+		items = append(items, cellName(c))
+	}
+	name = fmt.Sprintf("%s@%d", name, position)
+	if err := p.builder.AddRow(name, items...); err != nil {
+		return err
+	}
+	p.placements[name] = placement{piece: piece, cells: cells}
+	return nil
+}
+
+// Problem creates the exact cover problem for the packing. The rows of the
+// problem are named after the piece, the copy of the piece if more than one
+// must be used, and the index of the position it is placed in, e.g. "T#1@12".
+func (p *Packing) Problem() (gox.Solver, error) {
+	prob, err := p.builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode returns the piece covering each cell of the board in a solution to
+// the packing's problem
+func (p *Packing) Decode(solution []string) (map[Cell]Piece, error) {
+	ret := make(map[Cell]Piece, len(p.board))
+	for _, name := range solution {
+		pl, ok := p.placements[name]
+		if !ok {
+			return nil, fmt.Errorf("No placement found with name %s", name)
+		}
+		for _, c := range pl.cells {
+			ret[c] = p.pieces[pl.piece]
+		}
+	}
+	return ret, nil
+}
+
+// Render draws a solution to the packing's problem in the format read by
+// ParseShape, with each cell showing the symbol of the piece covering it
+func (p *Packing) Render(solution []string) (string, error) {
+	cells, err := p.Decode(solution)
+	if err != nil {
+		return "", err
+	}
+	return p.board.render(func(c Cell) byte {
+		if piece, ok := cells[c]; ok {
+			return piece.Symbol
+		}
+		return '?'
+	}), nil
+}
+
+// Solve finds every packing of the board, returning each as it is drawn by
+// Render
+func (p *Packing) Solve() ([]string, error) {
+	prob, err := p.Problem()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, soln := range prob.Solve() {
+		s, err := p.Render(soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
@@ -0,0 +1,194 @@
+package polyomino
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox"
+)
+
+func TestTetrominoOrientations(t *testing.T) {
+	// There are 19 tetrominoes when rotations and reflections are counted as
+	// distinct, and 7 when only reflections are
+	for _, test := range []struct {
+		pieces   []Piece
+		expected int
+	}{
+		{Tetrominoes(), 19},
+		{OneSidedTetrominoes(), 19},
+	} {
+		total := 0
+		seen := make(map[string]string)
+		for _, p := range test.pieces {
+			if len(p.Shape) != 4 || !p.Shape.Connected() {
+				t.Fatalf("%s is not a tetromino:\n%v", p.Name, p.Shape)
+			}
+			for _, o := range p.Shape.Orientations(!p.OneSided) {
+				if other, ok := seen[o.String()]; ok {
+					t.Fatalf("%s and %s are the same shape", p.Name, other)
+				}
+				seen[o.String()] = p.Name
+				total++
+			}
+		}
+		if total != test.expected {
+			t.Fatalf("Expected %d fixed tetrominoes, got %d", test.expected, total)
+		}
+	}
+}
+
+func TestPentominoes(t *testing.T) {
+	// There are 63 fixed pentominoes
+	total := 0
+	seen := make(map[string]string)
+	for _, p := range Pentominoes() {
+		if len(p.Shape) != 5 || !p.Shape.Connected() {
+			t.Fatalf("%s is not a pentomino:\n%v", p.Name, p.Shape)
+		}
+		for _, o := range p.Shape.Orientations(true) {
+			if other, ok := seen[o.String()]; ok {
+				t.Fatalf("%s and %s are the same shape", p.Name, other)
+			}
+			seen[o.String()] = p.Name
+			total++
+		}
+	}
+	if total != 63 {
+		t.Fatalf("Expected 63 fixed pentominoes, got %d", total)
+	}
+
+	// The 3x20 rectangle has 2 packings, each found in its 4 symmetric
+	// positions
+	if solns := solve(t, Rectangle(20, 3), Pentominoes()); len(solns) != 8 {
+		t.Fatalf("Expected 8 packings of 3x20 rectangle, got %d", len(solns))
+	}
+}
+
+func TestRotateReflect(t *testing.T) {
+	s := mustParseShape("###\n#")
+	if r := s.Rotate(); r.String() != "##\n.#\n.#" {
+		t.Fatalf("Unexpected rotation:\n%v", r)
+	}
+	if r := s.Reflect(); r.String() != "###\n..#" {
+		t.Fatalf("Unexpected reflection:\n%v", r)
+	}
+	if r := s.Rotate().Rotate().Rotate().Rotate(); r.String() != s.String() {
+		t.Fatalf("Rotating four times gave\n%v", r)
+	}
+}
+
+func solve(t *testing.T, board Shape, pieces []Piece) []string {
+	p, err := New(board, pieces)
+	if err != nil {
+		t.Fatalf("Error creating packing: %v", err)
+	}
+	solns, err := p.Solve()
+	if err != nil {
+		t.Fatalf("Error solving packing: %v", err)
+	}
+	sort.Strings(solns)
+	return solns
+}
+
+func TestCopiesFoundOnce(t *testing.T) {
+	square := mustParseShape("##\n##")
+	solns := solve(t, Rectangle(4, 4), []Piece{{Name: "O", Symbol: 'O', Shape: square, Count: 4}})
+	if len(solns) != 1 || solns[0] != "OOOO\nOOOO\nOOOO\nOOOO" {
+		t.Fatalf("Expected a single packing, got %q", solns)
+	}
+}
+
+func TestAnyCount(t *testing.T) {
+	pieces := []Piece{
+		{Name: "I", Symbol: 'I', Shape: mustParseShape("####"), Count: Any},
+		{Name: "O", Symbol: 'O', Shape: mustParseShape("##\n##"), Count: Any},
+	}
+	solns := solve(t, Rectangle(4, 2), pieces)
+	expected := []string{"IIII\nIIII", "OOOO\nOOOO"}
+	if len(solns) != 2 || solns[0] != expected[0] || solns[1] != expected[1] {
+		t.Fatalf("Expected %q, got %q", expected, solns)
+	}
+}
+
+func TestHoles(t *testing.T) {
+	// The ring around the centre of a 3x3 board can be packed with two L
+	// tetrominoes in four ways
+	board := Rectangle(3, 3).Without(Cell{1, 1})
+	l := Piece{Name: "L", Symbol: 'L', Shape: mustParseShape("###\n#"), Count: 2}
+	if solns := solve(t, board, []Piece{l}); len(solns) != 4 {
+		t.Fatalf("Expected 4 packings, got %q", solns)
+	}
+
+	// One-sided pieces cannot be reflected, so only two of the packings use
+	// two of the same piece
+	l.OneSided = true
+	if solns := solve(t, board, []Piece{l}); len(solns) != 2 {
+		t.Fatalf("Expected 2 packings, got %q", solns)
+	}
+}
+
+func TestCheckerboardParity(t *testing.T) {
+	// Coloured like a checkerboard, the T tetromino covers three squares of
+	// one colour while the others cover two of each, so a single T cannot
+	// be part of a packing of a rectangle
+	if solns := solve(t, Rectangle(7, 4), OneSidedTetrominoes()); len(solns) != 0 {
+		t.Fatalf("Expected no packings, got %d", len(solns))
+	}
+	if solns := solve(t, Rectangle(5, 4), Tetrominoes()); len(solns) != 0 {
+		t.Fatalf("Expected no packings, got %d", len(solns))
+	}
+
+	// With four Ts the colours balance
+	solns := solve(t, Rectangle(4, 4), WithCount(Tetrominoes()[2:3], 4))
+	if len(solns) == 0 {
+		t.Fatal("Expected packings of 4x4 square")
+	}
+	for _, s := range solns {
+		if s != "TTTT\nTTTT\nTTTT\nTTTT" {
+			t.Fatalf("Unexpected pieces in packing:\n%s", s)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	square := mustParseShape("##\n##")
+	if _, err := New(Rectangle(2, 2), []Piece{{Name: "O", Shape: square}, {Name: "O", Shape: square}}); err == nil {
+		t.Fatal("Expected error for duplicate piece names")
+	}
+	if _, err := New(Rectangle(2, 2), []Piece{{Name: "X", Shape: mustParseShape("#.#")}}); err == nil {
+		t.Fatal("Expected error for disconnected piece")
+	}
+	if _, err := New(Rectangle(2, 2), []Piece{{Name: "O", Shape: square, Count: -2}}); err == nil {
+		t.Fatal("Expected error for invalid count")
+	}
+}
+
+func TestSVGCell(t *testing.T) {
+	pieces := []Piece{{Name: "I", Symbol: 'I', Shape: mustParseShape("##")}, {Name: "O", Symbol: 'O', Shape: mustParseShape("#")}}
+	p, err := New(Rectangle(3, 1), pieces)
+	if err != nil {
+		t.Fatalf("Error creating packing: %v", err)
+	}
+	prob, err := p.Problem()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	solns, err := prob.SolveContext(context.Background())
+	if err != nil || len(solns) != 2 {
+		t.Fatalf("Expected 2 packings, got %v: %v", solns, err)
+	}
+	var buf bytes.Buffer
+	if err := prob.Solution(solns[0]).WriteSVG(&buf, &gox.SVGOptions{Cell: p.SVGCell}); err != nil {
+		t.Fatalf("Error writing figure: %v", err)
+	}
+	// Each piece has its own colour, the rows chosen drawn solid
+	svg := buf.String()
+	for _, expected := range []string{`fill="#e6194b" fill-opacity="1"`, `fill="#3cb44b" fill-opacity="1"`, `fill-opacity="0.3"`} {
+		if !strings.Contains(svg, expected) {
+			t.Fatalf("Expected figure to contain %s:\n%s", expected, svg)
+		}
+	}
+}
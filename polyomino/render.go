@@ -0,0 +1,185 @@
+package polyomino
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/ifross89/gox"
+)
+
+// palette holds the colours of the pieces in the figures drawn of a packing,
+// used in turn for the pieces in the order given to New
+var palette = []color.RGBA{
+	{0xe6, 0x19, 0x4b, 0xff}, {0x3c, 0xb4, 0x4b, 0xff}, {0x43, 0x63, 0xd8, 0xff},
+	{0xf5, 0x82, 0x31, 0xff}, {0x91, 0x1e, 0xb4, 0xff}, {0x42, 0xd4, 0xf4, 0xff},
+	{0xf0, 0x32, 0xe6, 0xff}, {0xbf, 0xef, 0x45, 0xff}, {0x46, 0x99, 0x90, 0xff},
+	{0x9a, 0x63, 0x24, 0xff}, {0x80, 0x00, 0x00, 0xff}, {0x00, 0x00, 0x75, 0xff},
+}
+
+// pieceColor returns the colour of the piece with the given index
+func pieceColor(piece int) color.RGBA {
+	return palette[piece%len(palette)]
+}
+
+// hex returns a colour as used in SVG, e.g. "#e6194b"
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVGCell draws a cell of the matrix of the packing's problem in the colour of
+// the piece placed by its row, faded unless the row is in the solution, so
+// that the rows of each piece stand out. It is passed to gox as
+// SVGOptions.Cell:
+//
+//	err := prob.Solution(soln).WriteSVG(w, &gox.SVGOptions{Cell: packing.SVGCell})
+func (p *Packing) SVGCell(w io.Writer, c gox.SVGCell) error {
+	fill := "#999"
+	if pl, ok := p.placements[c.Row]; ok {
+		fill = hex(pieceColor(pl.piece))
+	}
+	opacity := 0.3
+	if c.Chosen {
+		opacity = 1
+	}
+	_, err := fmt.Fprintf(w, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s" fill-opacity="%g"/>`+"\n", c.X, c.Y, c.Size, c.Size, fill, opacity)
+	return err
+}
+
+// layout is a solution to a packing laid out for drawing
+type layout struct {
+	// placed holds the index in the solution of the placement covering each
+	// cell of the board, and piece the index of the piece of each placement
+	placed map[Cell]int
+	piece  []int
+	// board holds the cells of the board
+	board map[Cell]bool
+	// minRow and minCol are the first row and column of the board, which
+	// has rows rows and cols columns
+	minRow, minCol, rows, cols int
+}
+
+// layout lays out a solution to the packing's problem
+func (p *Packing) layout(solution []string) (*layout, error) {
+	l := &layout{placed: make(map[Cell]int, len(p.board)), board: make(map[Cell]bool, len(p.board))}
+	for i, name := range solution {
+		pl, ok := p.placements[name]
+		if !ok {
+			return nil, fmt.Errorf("No placement found with name %s", name)
+		}
+		l.piece = append(l.piece, pl.piece)
+		for _, c := range pl.cells {
+			l.placed[c] = i
+		}
+	}
+	l.minRow, l.minCol = p.board.min()
+	for _, c := range p.board {
+		l.board[c] = true
+		if c.Row-l.minRow >= l.rows {
+			l.rows = c.Row - l.minRow + 1
+		}
+		if c.Col-l.minCol >= l.cols {
+			l.cols = c.Col - l.minCol + 1
+		}
+	}
+	return l, nil
+}
+
+// border reports whether an outline runs between a cell of the board and its
+// neighbour, which it does unless the neighbour is on the board and covered by
+// the same placement, or neither is covered
+func (l *layout) border(c, neighbour Cell) bool {
+	a, aok := l.placed[c]
+	b, bok := l.placed[neighbour]
+	return !l.board[neighbour] || aok != bok || a != b
+}
+
+// WriteSVG draws a solution to the packing's problem as an SVG figure of the
+// board, with cells of cell units, each piece filled in its own colour and
+// outlined. Cells of the board left uncovered are white.
+func (p *Packing) WriteSVG(w io.Writer, solution []string, cell float64) error {
+	l, err := p.layout(solution)
+	if err != nil {
+		return err
+	}
+	width, height := float64(l.cols)*cell, float64(l.rows)*cell
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`+"\n", width+2, height+2, width+2, height+2)
+	fmt.Fprintln(bw, `<g transform="translate(1 1)" stroke-linecap="square">`)
+	for _, c := range p.board {
+		x, y := float64(c.Col-l.minCol)*cell, float64(c.Row-l.minRow)*cell
+		fill := "white"
+		if i, ok := l.placed[c]; ok {
+			fill = hex(pieceColor(l.piece[i]))
+		}
+		fmt.Fprintf(bw, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`+"\n", x, y, cell, cell, fill)
+	}
+	// Each edge of the outlines is drawn from the cell above it or to its
+	// left, the edges at the bottom and right of the board from the cell
+	// inside it
+	line := func(x1, y1, x2, y2 float64) {
+		fmt.Fprintf(bw, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="black" stroke-width="2"/>`+"\n", x1, y1, x2, y2)
+	}
+	for _, c := range p.board {
+		x, y := float64(c.Col-l.minCol)*cell, float64(c.Row-l.minRow)*cell
+		if l.border(c, Cell{c.Row - 1, c.Col}) {
+			line(x, y, x+cell, y)
+		}
+		if l.border(c, Cell{c.Row, c.Col - 1}) {
+			line(x, y, x, y+cell)
+		}
+		if !l.board[Cell{c.Row + 1, c.Col}] {
+			line(x, y+cell, x+cell, y+cell)
+		}
+		if !l.board[Cell{c.Row, c.Col + 1}] {
+			line(x+cell, y, x+cell, y+cell)
+		}
+	}
+	fmt.Fprintln(bw, "</g>\n</svg>")
+	return bw.Flush()
+}
+
+// WritePNG draws a solution to the packing's problem as a PNG image of the
+// board, like WriteSVG, with cells of cell pixels
+func (p *Packing) WritePNG(w io.Writer, solution []string, cell int) error {
+	l, err := p.layout(solution)
+	if err != nil {
+		return err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, l.cols*cell+1, l.rows*cell+1))
+	black := color.RGBA{0, 0, 0, 0xff}
+	fill := func(x0, y0, x1, y1 int, c color.RGBA) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	for _, c := range p.board {
+		x, y := (c.Col-l.minCol)*cell, (c.Row-l.minRow)*cell
+		col := color.RGBA{0xff, 0xff, 0xff, 0xff}
+		if i, ok := l.placed[c]; ok {
+			col = pieceColor(l.piece[i])
+		}
+		fill(x, y, x+cell+1, y+cell+1, col)
+	}
+	for _, c := range p.board {
+		x, y := (c.Col-l.minCol)*cell, (c.Row-l.minRow)*cell
+		if l.border(c, Cell{c.Row - 1, c.Col}) {
+			fill(x, y, x+cell+1, y+1, black)
+		}
+		if l.border(c, Cell{c.Row, c.Col - 1}) {
+			fill(x, y, x+1, y+cell+1, black)
+		}
+		if !l.board[Cell{c.Row + 1, c.Col}] {
+			fill(x, y+cell, x+cell+1, y+cell+1, black)
+		}
+		if !l.board[Cell{c.Row, c.Col + 1}] {
+			fill(x+cell, y, x+cell+1, y+cell+1, black)
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,88 @@
+package polyomino
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// dominoPacking returns the packing of a 3 by 1 board with a domino and a
+// monomino, and its first solution
+func dominoPacking(t *testing.T) (*Packing, []string) {
+	pieces := []Piece{{Name: "I", Symbol: 'I', Shape: mustParseShape("##")}, {Name: "O", Symbol: 'O', Shape: mustParseShape("#")}}
+	p, err := New(Rectangle(3, 1), pieces)
+	if err != nil {
+		t.Fatalf("Error creating packing: %v", err)
+	}
+	prob, err := p.Problem()
+	if err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+	solns := prob.Solve()
+	if len(solns) != 2 {
+		t.Fatalf("Expected 2 packings, got %v", solns)
+	}
+	return p, solns[0]
+}
+
+func TestPackingWriteSVG(t *testing.T) {
+	p, soln := dominoPacking(t)
+	var buf bytes.Buffer
+	if err := p.WriteSVG(&buf, soln, 10); err != nil {
+		t.Fatalf("Error writing figure: %v", err)
+	}
+	// A cell for each square of the board, with the outline of the board
+	// and the edge between the pieces
+	elems := make(map[string]int)
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Figure is not well formed: %v", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			elems[start.Name.Local]++
+		}
+	}
+	if elems["rect"] != 3 || elems["line"] != 9 {
+		t.Fatalf("Unexpected elements %v", elems)
+	}
+
+	if err := p.WriteSVG(&buf, []string{"Z@1"}, 10); err == nil {
+		t.Fatal("Expected error for an unknown placement")
+	}
+}
+
+func TestPackingWritePNG(t *testing.T) {
+	p, soln := dominoPacking(t)
+	var buf bytes.Buffer
+	if err := p.WritePNG(&buf, soln, 10); err != nil {
+		t.Fatalf("Error writing image: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Error decoding image: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 31 || b.Dy() != 11 {
+		t.Fatalf("Expected a 31 by 11 image, got %v", b)
+	}
+	cells, err := p.Decode(soln)
+	if err != nil {
+		t.Fatalf("Error decoding packing: %v", err)
+	}
+	// The middle of each cell is the colour of its piece
+	for col := 0; col < 3; col++ {
+		piece := 0
+		if cells[Cell{0, col}].Name == "O" {
+			piece = 1
+		}
+		if got := color.RGBAModel.Convert(img.At(col*10+5, 5)); got != pieceColor(piece) {
+			t.Fatalf("Expected cell %d in %v, got %v", col, pieceColor(piece), got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package gox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RowSeparator separates the segments of a hierarchical row name, such as
+// "piece:F/pos:3,4/rot:2", see RowName and RowsWithPrefix
+const RowSeparator = "/"
+
+// RowName joins segments into a hierarchical row name, so that the rows an
+// encoder makes for a piece, or a piece in a position, can be picked out
+// together by RowsWithPrefix, ApplyGivensByPrefix and ForbidByPrefix
+func RowName(segments ...string) string {
+	return strings.Join(segments, RowSeparator)
+}
+
+// PrefixSolver is implemented by the problems of gox, which pick out rows by
+// the prefixes of their hierarchical names. It is kept out of
+// ExactCoverSolver so that interface stays as it is; a problem returned as an
+// ExactCoverSolver is checked for it with a type assertion:
+//
+//	if ps, ok := prob.(gox.PrefixSolver); ok {
+//		err = ps.ApplyGivensByPrefix("piece:F")
+//	}
+type PrefixSolver interface {
+	RowsWithPrefix(string) []string
+	ApplyGivensByPrefix(string) error
+}
+
+// Forbidder is implemented by the problems of gox, which can leave rows out of
+// every solution, and is checked for with a type assertion like PrefixSolver
+type Forbidder interface {
+	Forbid(string) error
+	ForbidByPrefix(string) error
+}
+
+// hasPrefix reports whether the row name is prefix, or begins with the
+// segments of prefix. The empty prefix matches every row.
+func hasPrefix(name, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, RowSeparator)
+	if prefix == "" || name == prefix {
+		return true
+	}
+	return strings.HasPrefix(name, prefix) && strings.HasPrefix(name[len(prefix):], RowSeparator)
+}
+
+// rowsWithPrefix returns the rows whose names match prefix, in the order
+// they were added
+func (p *exactCoverProblem) rowsWithPrefix(prefix string) []*rowHeader {
+	var ret []*rowHeader
+	for _, r := range p.rowHeaders {
+		if hasPrefix(r.label(), prefix) {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+// RowsWithPrefix returns the names of the rows whose names begin with the
+// segments of prefix, in the order they were added. Segments are matched
+// whole: "piece:F" matches "piece:F" and "piece:F/pos:3,4" but not
+// "piece:FF/pos:3,4". The empty prefix matches every row.
+func (p *exactCoverProblem) RowsWithPrefix(prefix string) []string {
+	return rowNames(p.rowsWithPrefix(prefix))
+}
+
+// ApplyGivensByPrefix gives the rows named by RowsWithPrefix, as ApplyGivens
+// does, each InputProblem's Row being the index in the names returned by
+// RowsWithPrefix. It is an error if no row matches prefix.
+func (p *exactCoverProblem) ApplyGivensByPrefix(prefix string) error {
+	names := p.RowsWithPrefix(prefix)
+	if len(names) == 0 {
+		return fmt.Errorf("No rows found with prefix %s", prefix)
+	}
+	return p.ApplyGivens(names)
+}
+
+// Forbid leaves the named row out of every solution found from now on. Like
+// a given row it cannot be taken back. It is an error to forbid a row which
+// has been given, and to give a row which has been forbidden. Forbidding a
+// row twice, or a row ruled out by the rows given already, is allowed.
+// Forbidden rows are still listed by Rows and ColumnRows, and are recorded
+// by Shard, so that a shard is only solved with the same rows forbidden.
+func (p *exactCoverProblem) Forbid(name string) error {
+	header := p.row(name)
+	if header == nil {
+		return fmt.Errorf("No row found with name %s", name)
+	}
+	return p.forbidRows("Forbid", []*rowHeader{header})
+}
+
+// ForbidByPrefix forbids the rows named by RowsWithPrefix, as Forbid does. If
+// any of them has been given none are forbidden. It is an error if no row
+// matches prefix.
+func (p *exactCoverProblem) ForbidByPrefix(prefix string) error {
+	rows := p.rowsWithPrefix(prefix)
+	if rows == nil {
+		return fmt.Errorf("No rows found with prefix %s", prefix)
+	}
+	return p.forbidRows("ForbidByPrefix", rows)
+}
+
+// forbidRows forbids rows for the method op, once it has checked that none of
+// them has been given
+func (p *exactCoverProblem) forbidRows(op string, rows []*rowHeader) (err error) {
+	if err := p.acquire(op); err != nil {
+		return err
+	}
+	defer p.finish(&err)
+
+	for _, r := range rows {
+		if r.given {
+			return fmt.Errorf("Row %s is given, so cannot be forbidden", r.label())
+		}
+	}
+	for _, r := range rows {
+		if r.forbidden {
+			continue
+		}
+		// A row conflicting with a given row has been hidden for good already
+		if r.first != nil && !p.conflictsWithGivens(r) {
+			p.unlink(r)
+		}
+		r.forbidden = true
+	}
+	return nil
+}
+
+// conflictsWithGivens reports whether r covers a column covered by a row in
+// the working solution, or gives a secondary column another colour
+func (p *exactCoverProblem) conflictsWithGivens(r *rowHeader) bool {
+	rows := append(append([]*rowHeader(nil), p.solutionRows...), r)
+	claims := make([]int32, p.numCols)
+	for i := range p.solutionRows {
+		p.stake(claims, rows, int32(i))
+	}
+	return p.stake(claims, rows, int32(len(rows)-1)) != ""
+}
+
+// unlink removes the nodes of a row from their columns for good, so that no
+// search can choose it
+func (p *exactCoverProblem) unlink(r *rowHeader) {
+	for n := r.first; ; {
+		n.up.down = n.down
+		n.down.up = n.up
+		n.colHead.colCount--
+		if n = n.right; n == r.first {
+			break
+		}
+	}
+}
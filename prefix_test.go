@@ -0,0 +1,114 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// namedDominoProblem is dominoProblem with hierarchical row names, "v/c" for
+// the vertical domino in column c and "h/r,c" for the horizontal one at r,c
+func namedDominoProblem(t *testing.T, n int) *exactCoverProblem {
+	b := NewBuilder()
+	for c := 0; c < n; c++ {
+		b.AddColumns(fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+	}
+	for c := 0; c < n; c++ {
+		b.AddRow(RowName("v", fmt.Sprint(c)), fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+		if c < n-1 {
+			for r := 0; r < 2; r++ {
+				b.AddRow(RowName("h", fmt.Sprintf("%d,%d", r, c)), fmt.Sprintf("%d,%d", r, c), fmt.Sprintf("%d,%d", r, c+1))
+			}
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	return prob
+}
+
+func TestRowsWithPrefix(t *testing.T) {
+	prob := namedDominoProblem(t, 3)
+	for _, tc := range []struct {
+		prefix string
+		want   []string
+	}{
+		{"v", []string{"v/0", "v/1", "v/2"}},
+		{"v/", []string{"v/0", "v/1", "v/2"}},
+		{"h/1,0", []string{"h/1,0"}},
+		{"", prob.Rows()},
+		{"h/1", nil},
+		{"x", nil},
+	} {
+		got := prob.RowsWithPrefix(tc.prefix)
+		if len(got) != len(tc.want) || (len(got) > 0 && !reflect.DeepEqual(got, tc.want)) {
+			t.Fatalf("Expected rows %v with prefix %q, got %v", tc.want, tc.prefix, got)
+		}
+	}
+	if err := prob.ApplyGivensByPrefix("x"); err == nil {
+		t.Fatalf("Expected an error giving rows with an unknown prefix")
+	}
+	if err := prob.ForbidByPrefix("x"); err == nil {
+		t.Fatalf("Expected an error forbidding rows with an unknown prefix")
+	}
+
+	// The problem behind an ExactCoverSolver implements the optional
+	// interfaces
+	var solver ExactCoverSolver = prob
+	if _, ok := solver.(PrefixSolver); !ok {
+		t.Fatalf("Expected the problem to be a PrefixSolver")
+	}
+	if _, ok := solver.(Forbidder); !ok {
+		t.Fatalf("Expected the problem to be a Forbidder")
+	}
+}
+
+func TestForbid(t *testing.T) {
+	ctx := context.Background()
+	prob := namedDominoProblem(t, 3)
+	if err := prob.ForbidByPrefix("h"); err != nil {
+		t.Fatalf("Error forbidding rows: %v", err)
+	}
+	if err := prob.CheckInvariants(); err != nil {
+		t.Fatalf("Matrix inconsistent after forbidding rows: %v", err)
+	}
+	want := [][]string{{"v/0", "v/1", "v/2"}}
+	if got, _ := prob.SolveContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	// The workers of a parallel search leave the rows out too
+	if got, _ := prob.SolveParallel(ctx, 2); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v solving in parallel, got %v", want, got)
+	}
+	if err := prob.RowIsSolution("h/0,0"); err == nil {
+		t.Fatalf("Expected an error giving a forbidden row")
+	}
+	if _, ok := prob.ApplyGivens([]string{"v/0", "h/0,1"}).(*InputError); !ok {
+		t.Fatalf("Expected an *InputError giving a forbidden row")
+	}
+
+	// Rows ruled out by the givens may be forbidden, but not the givens
+	prob = namedDominoProblem(t, 3)
+	if err := prob.ApplyGivensByPrefix("v/0"); err != nil {
+		t.Fatalf("Error giving rows: %v", err)
+	}
+	if err := prob.Forbid("v/0"); err == nil {
+		t.Fatalf("Expected an error forbidding a given row")
+	}
+	if err := prob.ForbidByPrefix("v"); err == nil {
+		t.Fatalf("Expected an error forbidding rows including a given row")
+	}
+	for _, name := range []string{"h/0,0", "h/0,1", "h/0,1"} {
+		if err := prob.Forbid(name); err != nil {
+			t.Fatalf("Error forbidding %s: %v", name, err)
+		}
+		if err := prob.CheckInvariants(); err != nil {
+			t.Fatalf("Matrix inconsistent after forbidding %s: %v", name, err)
+		}
+	}
+	if got, _ := prob.SolveContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,382 @@
+package gox
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sparseRow is a row of a problem before it is linked into the matrix, see
+// addRows. colors, if not nil, holds the colour given to each of cols. id is
+// the identifier of the row in a problem created by NewExactCoverProblemIDs.
+type sparseRow struct {
+	name   string
+	id     int64
+	cols   []int
+	colors []int
+}
+
+// key returns a string which is the same for rows covering the same columns
+// with the same colours, whatever the order of the columns
+func (r sparseRow) key() string {
+	items := make([]string, len(r.cols))
+	for i, col := range r.cols {
+		color := 0
+		if r.colors != nil {
+			color = r.colors[i]
+		}
+		items[i] = strconv.Itoa(col) + ":" + strconv.Itoa(color)
+	}
+	sort.Strings(items)
+	return strings.Join(items, " ")
+}
+
+// PreprocessReport describes the changes made to a problem before searching,
+// by the options which ask for them such as WithMergeDuplicates
+type PreprocessReport struct {
+	// DuplicateRows maps the name of a row kept in the problem to the names
+	// of the rows covering the same columns which were merged into it
+	DuplicateRows map[string][]string
+	// DuplicateColumns maps the name of a column kept in the problem to the
+	// names of the columns covered by the same rows which were removed
+	DuplicateColumns map[string][]string
+	// Forced lists the rows given because they were the only candidate left
+	// for a primary column, in the order they were given, see ForceRows
+	Forced []string
+	// Dominated maps the name of each row removed by WithDominatedRows to
+	// the primary column whose every candidate it conflicts with
+	Dominated map[string]string
+}
+
+// Multiplicity returns the number of solutions to the original problem which
+// a solution to the preprocessed problem stands for, since each row of the
+// solution may be replaced by any of its duplicates
+func (r *PreprocessReport) Multiplicity(solution []string) int {
+	ret := 1
+	for _, name := range solution {
+		ret *= 1 + len(r.DuplicateRows[name])
+	}
+	return ret
+}
+
+// Originals returns the rows of the original problem which a row of the
+// preprocessed problem stands for: the row itself followed by the rows merged
+// into it, see WithMergeDuplicates
+func (r *PreprocessReport) Originals(row string) []string {
+	return append([]string{row}, r.DuplicateRows[row]...)
+}
+
+// Expand calls f with each solution to the original problem which a solution
+// to the preprocessed problem stands for, Multiplicity of them, replacing each
+// row by each of its Originals in turn, until f returns false. The first is
+// the solution itself.
+func (r *PreprocessReport) Expand(solution []string, f func([]string) bool) {
+	originals := make([][]string, len(solution))
+	for i, name := range solution {
+		originals[i] = r.Originals(name)
+	}
+	choice := make([]int, len(solution))
+	for {
+		expanded := make([]string, len(solution))
+		for i, c := range choice {
+			expanded[i] = originals[i][c]
+		}
+		if !f(expanded) {
+			return
+		}
+		// Count through the choices, the last row changing fastest
+		i := len(choice) - 1
+		for ; i >= 0; i-- {
+			if choice[i]++; choice[i] < len(originals[i]) {
+				break
+			}
+			choice[i] = 0
+		}
+		if i < 0 {
+			return
+		}
+	}
+}
+
+// WithMergeDuplicates removes rows which cover the same columns, with the
+// same colours, as an earlier row, and columns which are covered by the same
+// rows as an earlier column. Such rows and columns are common in machine
+// generated problems, and removing them can shrink the search substantially.
+//
+// The solutions found use the names of the rows kept, so that equivalent
+// solutions are found once, and Preprocessed reports which rows they stand
+// for, see PreprocessReport.Expand. The names of the rows removed may still
+// be given to RowIsSolution and Verify, which treat them as the row kept.
+func WithMergeDuplicates() ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.mergeDuplicates = true
+	}
+}
+
+// WithDominatedRows removes the rows which can never be part of a solution
+// because they conflict with every row covering some primary column which
+// they do not cover themselves: choosing such a row would leave the column
+// impossible to cover. This is the case for a row which is a strict superset
+// of another only when the extra columns block a column in this way, as in
+// general a larger row is still useful under exact cover. Rows are removed
+// until no more can be, as each removal can leave other rows dominated, and
+// Preprocessed().Dominated reports the column which ruled out each.
+func WithDominatedRows() ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.removeDominated = true
+	}
+}
+
+// WithForcedRows gives the rows which are forced, see ForceRows, once the
+// problem has been created
+func WithForcedRows() ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.forceRows = true
+	}
+}
+
+// ForceRows repeatedly gives the row which is the only candidate left for a
+// primary column, as if by RowIsSolution, until every column has no
+// candidates or more than one. Such rows are part of every solution, so giving
+// them shrinks the search, and they show which parts of a model are trivially
+// decided. It may be called after giving rows with RowIsSolution, which can
+// leave further rows forced. The names of the rows given are returned and
+// added to Preprocessed().Forced.
+func (p *exactCoverProblem) ForceRows() (forced []string, err error) {
+	if err := p.acquire("ForceRows"); err != nil {
+		return nil, err
+	}
+	defer p.finish(&err)
+	return p.force(), nil
+}
+
+// force performs ForceRows, which addRows calls while the problem is still
+// being built
+func (p *exactCoverProblem) force() []string {
+	if p.report == nil {
+		p.report = &PreprocessReport{}
+	}
+	var ret []string
+	for forced := true; forced; {
+		forced = false
+		for col := p.root.right; col != p.root; col = col.right {
+			if col.colCount == 1 {
+				header := col.down.rowHead
+				p.give(header)
+				ret = append(ret, header.label())
+				forced = true
+				break
+			}
+		}
+	}
+	p.report.Forced = append(p.report.Forced, ret...)
+	return ret
+}
+
+// Preprocessed returns the changes made to the problem before searching, or
+// nil if no preprocessing was asked for
+func (p *exactCoverProblem) Preprocessed() *PreprocessReport {
+	return p.report
+}
+
+// addRows preprocesses the rows of a problem as asked for by its options,
+// then links them into the matrix
+func (p *exactCoverProblem) addRows(rows []sparseRow) error {
+	var aliases map[string]string
+	if p.mergeDuplicates || p.forceRows || p.removeDominated {
+		p.report = &PreprocessReport{
+			DuplicateRows:    make(map[string][]string),
+			DuplicateColumns: make(map[string][]string),
+			Dominated:        make(map[string]string),
+		}
+		span := startSpan(p.traceCtx, p.tracer, "gox.preprocess", Attribute{"gox.rows", len(rows)})
+		defer func() { p.endPreprocess(span) }()
+	}
+	if p.mergeDuplicates {
+		rows, aliases = p.mergeDuplicateRows(rows)
+		rows = p.mergeDuplicateColumns(rows)
+	}
+	if p.removeDominated {
+		rows = p.removeDominatedRows(rows)
+	}
+
+	p.names.internRows(rows)
+	p.reserve(len(rows), countCells(rows))
+	defer p.dropReserved()
+	for _, r := range rows {
+		if err := p.addRow(r); err != nil {
+			return err
+		}
+	}
+	for name, kept := range aliases {
+		// The row kept may since have been removed as dominated
+		if header, ok := p.rowsByName[kept]; ok {
+			p.rowsByName[p.names.intern(name)] = header
+		}
+	}
+	if err := p.checkStructure(); err != nil {
+		return err
+	}
+	if p.forceRows {
+		p.force()
+	}
+	return nil
+}
+
+// mergeDuplicateRows removes the rows which cover the same columns as an
+// earlier row, recording them in the report. It returns the rows kept, and a
+// map from the name of each row removed to the name of the row kept.
+func (p *exactCoverProblem) mergeDuplicateRows(rows []sparseRow) ([]sparseRow, map[string]string) {
+	var ret []sparseRow
+	aliases := make(map[string]string)
+	kept := make(map[string]string, len(rows))
+	for _, r := range rows {
+		// Rows covering no columns are left to the EmptyRowPolicy
+		if len(r.cols) == 0 {
+			ret = append(ret, r)
+			continue
+		}
+		key := r.key()
+		if name, ok := kept[key]; ok {
+			p.report.DuplicateRows[name] = append(p.report.DuplicateRows[name], r.name)
+			aliases[r.name] = name
+			continue
+		}
+		kept[key] = r.name
+		ret = append(ret, r)
+	}
+	return ret, aliases
+}
+
+// mergeDuplicateColumns removes the columns which are covered by the same rows,
+// with the same colours, as an earlier column, recording them in the report.
+// As the primary columns come first a primary column is always kept in favour
+// of a secondary one, which is implied by it. The headers of the columns
+// removed are left out of the matrix.
+func (p *exactCoverProblem) mergeDuplicateColumns(rows []sparseRow) []sparseRow {
+	keys := make([]strings.Builder, p.numCols)
+	covered := make([]bool, p.numCols)
+	for i, r := range rows {
+		for j, col := range r.cols {
+			color := 0
+			if r.colors != nil {
+				color = r.colors[j]
+			}
+			keys[col].WriteString(strconv.Itoa(i) + ":" + strconv.Itoa(color) + " ")
+			covered[col] = true
+		}
+	}
+
+	p.removedCols = make([]bool, p.numCols)
+	kept := make(map[string]int)
+	removed := false
+	for col := range keys {
+		// Columns without rows are left to be reported by SolveContext
+		if !covered[col] {
+			continue
+		}
+		key := keys[col].String()
+		if k, ok := kept[key]; ok {
+			name := p.colName(p.colHeaders[k])
+			p.report.DuplicateColumns[name] = append(p.report.DuplicateColumns[name], p.colName(p.colHeaders[col]))
+			p.removedCols[col] = true
+			removed = true
+			continue
+		}
+		kept[key] = col
+	}
+	if !removed {
+		return rows
+	}
+
+	for col, h := range p.colHeaders {
+		if p.removedCols[col] && col < p.numPrimary {
+			h.left.right = h.right
+			h.right.left = h.left
+			h.left, h.right = h, h
+		}
+	}
+	ret := make([]sparseRow, len(rows))
+	for i, r := range rows {
+		ret[i].name, ret[i].id = r.name, r.id
+		for j, col := range r.cols {
+			if p.removedCols[col] {
+				continue
+			}
+			ret[i].cols = append(ret[i].cols, col)
+			if r.colors != nil {
+				ret[i].colors = append(ret[i].colors, r.colors[j])
+			}
+		}
+	}
+	return ret
+}
+
+// conflicts reports whether two rows cannot both be part of a solution,
+// because they share a column without agreeing on its colour. colors maps
+// each column of a to its colour.
+func conflicts(colors map[int]int, b sparseRow) bool {
+	for i, col := range b.cols {
+		color := 0
+		if b.colors != nil {
+			color = b.colors[i]
+		}
+		if other, ok := colors[col]; ok && (color == 0 || color != other) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeDominatedRows removes the rows which conflict with every candidate for
+// a primary column they do not cover, recording them in the report
+func (p *exactCoverProblem) removeDominatedRows(rows []sparseRow) []sparseRow {
+	for removed := true; removed; {
+		removed = false
+		// candidates holds the rows covering each primary column
+		candidates := make([][]int, p.numPrimary)
+		for i, r := range rows {
+			for _, col := range r.cols {
+				if col < p.numPrimary {
+					candidates[col] = append(candidates[col], i)
+				}
+			}
+		}
+
+		var ret []sparseRow
+		for _, r := range rows {
+			colors := make(map[int]int, len(r.cols))
+			for i, col := range r.cols {
+				colors[col] = 0
+				if r.colors != nil {
+					colors[col] = r.colors[i]
+				}
+			}
+			blocked := -1
+			for col, rs := range candidates {
+				if _, ok := colors[col]; ok || len(rs) == 0 {
+					continue
+				}
+				all := true
+				for _, i := range rs {
+					if !conflicts(colors, rows[i]) {
+						all = false
+						break
+					}
+				}
+				if all {
+					blocked = col
+					break
+				}
+			}
+			if blocked >= 0 {
+				p.report.Dominated[r.name] = p.colName(p.colHeaders[blocked])
+				removed = true
+				continue
+			}
+			ret = append(ret, r)
+		}
+		rows = ret
+	}
+	return rows
+}
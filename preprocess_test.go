@@ -0,0 +1,170 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// duplicatesBuilder returns a builder for a problem in which rows B and D
+// duplicate A and C, column d duplicates b and secondary column s duplicates a
+func duplicatesBuilder() *Builder {
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c", "d")
+	b.AddSecondaryColumns("s")
+	b.AddRow("A", "a", "s")
+	b.AddRow("B", "s", "a")
+	b.AddRow("C", "b", "c", "d")
+	b.AddRow("D", "d", "c", "b")
+	b.AddRow("E", "c")
+	return b
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	plain, err := duplicatesBuilder().Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	prob, err := duplicatesBuilder().Build(WithMergeDuplicates())
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if plain.Preprocessed() != nil {
+		t.Fatal("Expected no report without preprocessing")
+	}
+
+	report := prob.Preprocessed()
+	if expected := map[string][]string{"A": {"B"}, "C": {"D"}}; !reflect.DeepEqual(report.DuplicateRows, expected) {
+		t.Fatalf("Expected duplicate rows %v, got %v", expected, report.DuplicateRows)
+	}
+	if expected := map[string][]string{"a": {"s"}, "b": {"d"}}; !reflect.DeepEqual(report.DuplicateColumns, expected) {
+		t.Fatalf("Expected duplicate columns %v, got %v", expected, report.DuplicateColumns)
+	}
+	assertStringSliceEqual(t, prob.Rows(), []string{"A", "C", "E"})
+
+	solns, err := prob.SolveContext(context.Background())
+	if err != nil || len(solns) != 1 {
+		t.Fatalf("Expected 1 solution, got %v, %v", solns, err)
+	}
+	assertStringSliceEqual(t, solns[0], []string{"A", "C"})
+	if n, expected := report.Multiplicity(solns[0]), len(plain.Solve()); n != expected {
+		t.Fatalf("Expected multiplicity %d, got %d", expected, n)
+	}
+
+	if err := prob.Verify([]string{"B", "D"}); err != nil {
+		t.Fatalf("Expected duplicates to verify: %v", err)
+	}
+	if err := prob.Verify([]string{"A", "B", "C"}); err == nil {
+		t.Fatal("Expected a row and its duplicate to overcover")
+	}
+	if err := prob.RowIsSolution("D"); err != nil {
+		t.Fatalf("Error giving duplicate row: %v", err)
+	}
+	if solns := prob.Solve(); len(solns) != 1 {
+		t.Fatalf("Expected 1 solution with a given duplicate, got %v", solns)
+	}
+}
+
+func TestExpandDuplicates(t *testing.T) {
+	prob, err := duplicatesBuilder().Build(WithMergeDuplicates())
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	report := prob.Preprocessed()
+	if originals := report.Originals("C"); !reflect.DeepEqual(originals, []string{"C", "D"}) {
+		t.Fatalf("Expected C to stand for C and D, got %v", originals)
+	}
+	if originals := report.Originals("E"); !reflect.DeepEqual(originals, []string{"E"}) {
+		t.Fatalf("Expected E to stand for itself, got %v", originals)
+	}
+
+	// The solutions expanded are those of the original problem
+	var expanded [][]string
+	report.Expand([]string{"A", "C"}, func(soln []string) bool {
+		expanded = append(expanded, soln)
+		return true
+	})
+	expected := [][]string{{"A", "C"}, {"A", "D"}, {"B", "C"}, {"B", "D"}}
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Fatalf("Expected %v, got %v", expected, expanded)
+	}
+	plain, err := duplicatesBuilder().Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	for _, soln := range expanded {
+		if err := plain.Verify(soln); err != nil {
+			t.Fatalf("Expanded solution %v is invalid: %v", soln, err)
+		}
+	}
+
+	count := 0
+	report.Expand([]string{"A", "C"}, func([]string) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Fatalf("Expected expansion to stop after 2 solutions, got %d", count)
+	}
+}
+
+func TestForcedRows(t *testing.T) {
+	// a is only covered by A, which is forced. Giving it rules out B, but
+	// leaves both C and E for c.
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c", "d")
+	b.AddRow("A", "a", "b")
+	b.AddRow("B", "b", "c")
+	b.AddRow("C", "c")
+	b.AddRow("D", "d")
+	b.AddRow("E", "c", "d")
+	prob, err := b.Build(WithForcedRows())
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if forced := prob.Preprocessed().Forced; !reflect.DeepEqual(forced, []string{"A"}) {
+		t.Fatalf("Expected A to be forced, got %v", forced)
+	}
+	solns := prob.Solve()
+	if len(solns) != 2 {
+		t.Fatalf("Expected 2 solutions, got %v", solns)
+	}
+
+	prob, err = b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if err := prob.RowIsSolution("E"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if forced, err := prob.ForceRows(); err != nil || !reflect.DeepEqual(forced, []string{"A"}) {
+		t.Fatalf("Expected A to be forced after giving E, got %v, %v", forced, err)
+	}
+	solns = prob.Solve()
+	if len(solns) != 1 {
+		t.Fatalf("Expected 1 solution, got %v", solns)
+	}
+	assertStringSliceEqual(t, solns[0], []string{"A", "E"})
+}
+
+func TestDominatedRows(t *testing.T) {
+	// V covers both a and c, which leaves no row for b
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c")
+	b.AddRow("V", "a", "c")
+	b.AddRow("W", "a")
+	b.AddRow("X", "a", "b")
+	b.AddRow("Y", "b", "c")
+	b.AddRow("Z", "c")
+	prob, err := b.Build(WithDominatedRows())
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if dominated := prob.Preprocessed().Dominated; !reflect.DeepEqual(dominated, map[string]string{"V": "b"}) {
+		t.Fatalf("Expected V to be dominated by b, got %v", dominated)
+	}
+	assertStringSliceEqual(t, prob.Rows(), []string{"W", "X", "Y", "Z"})
+	if solns := prob.Solve(); len(solns) != 2 {
+		t.Fatalf("Expected 2 solutions, got %v", solns)
+	}
+}
@@ -0,0 +1,59 @@
+package gox
+
+import "fmt"
+
+// SetColumnPriority puts the named column in a priority class. The search
+// only branches on the columns of the highest class with columns left to
+// cover, choosing among them by the heuristic, so that a lower class is only
+// considered once every column of the higher classes has been covered. This
+// makes the "structural" constraints of a model be resolved before the
+// "labelling" ones. Columns have a priority of zero until one is set. A
+// column of a lower class left with no rows is only found to be a dead end
+// once the higher classes have been covered.
+func (p *exactCoverProblem) SetColumnPriority(col string, priority int) error {
+	if err := p.acquire("SetColumnPriority"); err != nil {
+		return err
+	}
+	defer p.release()
+	index := p.columnIndex(col)
+	if index < 0 {
+		return fmt.Errorf("No column found with name %s", col)
+	}
+	if p.colPriorities == nil {
+		p.colPriorities = make([]int, p.numCols)
+	}
+	p.colPriorities[index] = priority
+	return nil
+}
+
+// ColumnPriority returns the priority class of the named column, see
+// SetColumnPriority
+func (p *exactCoverProblem) ColumnPriority(col string) (int, error) {
+	index := p.columnIndex(col)
+	if index < 0 {
+		return 0, fmt.Errorf("No column found with name %s", col)
+	}
+	if p.colPriorities == nil {
+		return 0, nil
+	}
+	return p.colPriorities[index], nil
+}
+
+// topClass returns the first column left in the highest priority class and
+// the priority of that class. The problem must have column priorities and
+// columns left to cover.
+func (p *exactCoverProblem) topClass() (*node, int) {
+	ret := p.root.right
+	for n := ret.right; n != p.root; n = n.right {
+		if p.colPriorities[n.colIndex] > p.colPriorities[ret.colIndex] {
+			ret = n
+		}
+	}
+	return ret, p.colPriorities[ret.colIndex]
+}
+
+// inClass reports whether a column is in the priority class top, which all
+// columns are if the problem has no priorities
+func (p *exactCoverProblem) inClass(n *node, top int) bool {
+	return p.colPriorities == nil || p.colPriorities[n.colIndex] == top
+}
@@ -0,0 +1,60 @@
+package gox
+
+import (
+	"context"
+	"testing"
+)
+
+func TestColumnPriority(t *testing.T) {
+	prob := dominoProblem(t, 3)
+	// The middle columns have the most rows, but are covered first
+	for _, col := range []string{"0,1", "1,1"} {
+		if err := prob.SetColumnPriority(col, 1); err != nil {
+			t.Fatalf("Error setting priority: %v", err)
+		}
+	}
+	for _, h := range []Heuristic{MinRemaining, FirstColumn, ConflictWeighted} {
+		cols := chosenColumns(t, prob, WithHeuristic(h))
+		if cols[0] != "0,1" {
+			t.Fatalf("%s: expected to branch on 0,1 first, got %v", h, cols)
+		}
+		solns, err := prob.SolveContext(context.Background(), WithHeuristic(h))
+		if err != nil || len(solns) != 3 {
+			t.Fatalf("%s: expected 3 solutions, got %v: %v", h, solns, err)
+		}
+	}
+
+	// Choosing v1 covers 0,1 and 1,1 leaving only lower classes, while
+	// choosing h0,0 leaves 1,1 to be covered next
+	var after []string
+	_, err := prob.SolveContext(context.Background(), WithTrace(func(e Event) {
+		if e.Kind == ChooseColumn && e.Depth == 1 {
+			after = append(after, e.Column)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	for _, col := range after {
+		if col == "1,1" {
+			return
+		}
+	}
+	t.Fatalf("Expected 1,1 to be chosen once 0,1 was covered without it, got %v", after)
+}
+
+func TestColumnPriorityErrors(t *testing.T) {
+	prob := dominoProblem(t, 2)
+	if err := prob.SetColumnPriority("9,9", 1); err == nil {
+		t.Fatal("Expected error for unknown column")
+	}
+	if err := prob.SetColumnPriority("1,0", -2); err != nil {
+		t.Fatalf("Error setting priority: %v", err)
+	}
+	if p, err := prob.ColumnPriority("1,0"); err != nil || p != -2 {
+		t.Fatalf("Expected priority -2, got %d: %v", p, err)
+	}
+	if p, err := prob.ColumnPriority("0,0"); err != nil || p != 0 {
+		t.Fatalf("Expected priority 0, got %d: %v", p, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package queens solves the n queens problem, placing n queens on an n x n
+// chess board so that no two attack each other, using the exact cover solver
+// in gox.
+//
+// Each rank and file of the board is a primary column, as every rank and file
+// holds exactly one queen. The diagonals are secondary columns, as they hold at
+// most one queen. There is a row for each square of the board.
+package queens
+
+import (
+	"fmt"
+
+	"github.com/ifross89/gox"
+)
+
+// RowName is the name of the row placing a queen in a square, e.g. "0,3"
+func RowName(rank, file int) string {
+	return fmt.Sprintf("%d,%d", rank, file)
+}
+
+// Problem creates the exact cover problem for n queens
+func Problem(n int) (gox.Solver, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("Board must have at least one square: n=%d", n)
+	}
+
+	b := gox.NewBuilder()
+	for i := 0; i < n; i++ {
+		if err := b.AddColumns(fmt.Sprintf("r%d", i), fmt.Sprintf("f%d", i)); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < 2*n-1; i++ {
+		if err := b.AddSecondaryColumns(fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i)); err != nil {
+			return nil, err
+		}
+	}
+	for r := 0; r < n; r++ {
+		for f := 0; f < n; f++ {
+			if err := b.AddRow(RowName(r, f),
+				fmt.Sprintf("r%d", r),
+				fmt.Sprintf("f%d", f),
+				fmt.Sprintf("a%d", r+f),
+				fmt.Sprintf("b%d", r-f+n-1),
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode returns the file of the queen on each rank for a solution to the
+// problem for n queens
+func Decode(n int, solution []string) ([]int, error) {
+	ret := make([]int, n)
+	for _, name := range solution {
+		var r, f int
+		if _, err := fmt.Sscanf(name, "%d,%d", &r, &f); err != nil {
+			return nil, fmt.Errorf("Invalid row name %s: %v", name, err)
+		}
+		if r < 0 || r >= n || f < 0 || f >= n {
+			return nil, fmt.Errorf("Invalid row name %s", name)
+		}
+		ret[r] = f
+	}
+	return ret, nil
+}
+
+// Solve finds every way of placing n queens
+func Solve(n int) ([][]int, error) {
+	prob, err := Problem(n)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret [][]int
+	for _, soln := range prob.Solve() {
+		files, err := Decode(n, soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, files)
+	}
+	return ret, nil
+}
@@ -0,0 +1,32 @@
+package queens
+
+import (
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	for n, expected := range map[int]int{1: 1, 2: 0, 3: 0, 4: 2, 5: 10, 6: 4, 8: 92} {
+		solns, err := Solve(n)
+		if err != nil {
+			t.Fatalf("Error solving %d queens: %v", n, err)
+		}
+		if len(solns) != expected {
+			t.Fatalf("Expected %d solutions for %d queens, got %d", expected, n, len(solns))
+		}
+		for _, s := range solns {
+			for i := range s {
+				for j := 0; j < i; j++ {
+					if s[i] == s[j] || s[i]-s[j] == i-j || s[j]-s[i] == i-j {
+						t.Fatalf("Queens attack each other in %v", s)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestInvalidSize(t *testing.T) {
+	if _, err := Problem(0); err == nil {
+		t.Fatal("Expected error for empty board")
+	}
+}
@@ -0,0 +1,81 @@
+package gox
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrInternal is wrapped by every *InternalError, so that errors.Is can tell
+// them from the errors of a search which ran its course
+var ErrInternal = errors.New("Internal error")
+
+// InternalError is returned when a call panics part way through rewriting the
+// links of the matrix, whether from a bug in gox, a problem corrupted by
+// misuse, or a function passed to WithSolutionFunc or WithTrace, rather than
+// crashing the program. The problem is left Failed, see State. Solve, which
+// cannot return an error, returns nil and keeps the *InternalError for Err,
+// as it does with the other errors it would return.
+type InternalError struct {
+	// Op is the call which panicked
+	Op string
+	// Value is the value passed to panic
+	Value interface{}
+	// Depth is the number of rows in the working solution when the call
+	// panicked, Row the last of them, and Column the column last chosen to
+	// branch on, each empty if there is none
+	Depth       int
+	Row, Column string
+	// Stack is the stack of the goroutine which panicked
+	Stack []byte
+}
+
+func (e *InternalError) Error() string {
+	msg := fmt.Sprintf("Internal error in %s at depth %d", e.Op, e.Depth)
+	if e.Column != "" {
+		msg += fmt.Sprintf(", column %s", e.Column)
+	}
+	if e.Row != "" {
+		msg += fmt.Sprintf(", row %s", e.Row)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Value)
+}
+
+// Unwrap returns ErrInternal
+func (e *InternalError) Unwrap() error {
+	return ErrInternal
+}
+
+// internalError describes the panic with value v of the call op from the
+// state of the matrix
+func (p *exactCoverProblem) internalError(op string, v interface{}) *InternalError {
+	ret := &InternalError{Op: op, Value: v, Depth: len(p.solutionRows), Stack: debug.Stack()}
+	if ret.Depth > 0 {
+		ret.Row = p.solutionRows[ret.Depth-1].label()
+	}
+	if p.column != nil {
+		ret.Column = p.colName(p.column)
+	}
+	return ret
+}
+
+// finish is release for the calls other than searches which rewrite the
+// links of the matrix. Deferred, it returns a panic in *err as an
+// *InternalError, and marks the problem Failed.
+func (p *exactCoverProblem) finish(err *error) {
+	if r := recover(); r != nil {
+		op, _ := p.running.Load().(string)
+		*err = p.internalError(op, r)
+		p.setState(Failed)
+	}
+	p.release()
+}
+
+// recoverSearch is deferred by the searches of a copy of the problem, such as
+// the branches of SolveParallel, to return a panic in *err as an
+// *InternalError. The copy is dropped, so the problem is not Failed.
+func (p *exactCoverProblem) recoverSearch(op string, err *error) {
+	if r := recover(); r != nil {
+		*err = p.internalError(op, r)
+	}
+}
@@ -0,0 +1,50 @@
+package gox
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInternalError(t *testing.T) {
+	ctx := context.Background()
+	prob := dominoProblem(t, 4)
+	_, err := prob.SolveContext(ctx, WithSolutionFunc(func([]string) { panic("boom") }))
+	ierr, ok := err.(*InternalError)
+	if !ok || !errors.Is(err, ErrInternal) {
+		t.Fatalf("Expected an *InternalError, got %v", err)
+	}
+	if ierr.Op != "SolveContext" || ierr.Value != "boom" || ierr.Depth == 0 || ierr.Row == "" || ierr.Column == "" || len(ierr.Stack) == 0 {
+		t.Fatalf("Expected the state of the search in the error, got %+v", ierr)
+	}
+	if prob.State() != Failed {
+		t.Fatalf("Expected the problem to have failed, got %v", prob.State())
+	}
+
+	// Solve cannot return the error, so keeps it for Err. Breaking a link
+	// stands in for a bug in gox.
+	prob = dominoProblem(t, 4)
+	prob.colHeaders[0].down.right = nil
+	if solns := prob.Solve(); solns != nil {
+		t.Fatalf("Expected no solutions from a failed Solve, got %v", solns)
+	}
+	if _, ok := prob.Err().(*InternalError); !ok {
+		t.Fatalf("Expected Err to return an *InternalError, got %v", prob.Err())
+	}
+
+	// A panic in the search of SolveChan ends the stream with the error
+	prob = dominoProblem(t, 4)
+	s, err := prob.SolveChan(ctx, WithTrace(func(e Event) {
+		if e.Kind == FoundSolution {
+			panic("boom")
+		}
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	for range s.C {
+	}
+	if _, ok := s.Err().(*InternalError); !ok {
+		t.Fatalf("Expected an *InternalError ending the stream, got %v", s.Err())
+	}
+}
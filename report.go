@@ -0,0 +1,107 @@
+package gox
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// InstanceReport summarises the shape of a problem, to help decide how to
+// solve it
+type InstanceReport struct {
+	Rows                             int
+	PrimaryColumns, SecondaryColumns int
+	// Nodes is the number of true cells of the matrix, and Density the
+	// proportion of the cells which are true
+	Nodes   int
+	Density float64
+	// MinColumn, MaxColumn and MeanColumn describe the number of rows
+	// covering each primary column
+	MinColumn, MaxColumn int
+	MeanColumn           float64
+	// DuplicateRows is the number of rows which cover the same columns, with
+	// the same colours, as an earlier row, see WithMergeDuplicates
+	DuplicateRows int
+	// IsolatedColumns are the names of the columns which no row covers.
+	// If any are primary the problem has no solutions.
+	IsolatedColumns []string
+}
+
+// Report summarises the rows and columns of the problem as it was created,
+// whatever rows have since been given
+func (p *exactCoverProblem) Report() *InstanceReport {
+	ret := &InstanceReport{
+		Rows:             len(p.rowHeaders),
+		PrimaryColumns:   p.numPrimary,
+		SecondaryColumns: p.numCols - p.numPrimary,
+	}
+	counts := make([]int, p.numCols)
+	seen := make(map[string]bool, len(p.rowHeaders))
+	for _, r := range p.rowHeaders {
+		row := sparseRow{name: r.label()}
+		for n := r.first; n != nil; {
+			counts[n.colIndex]++
+			color := n.color
+			if color < 0 {
+				color = -color
+			}
+			row.cols = append(row.cols, n.colIndex)
+			row.colors = append(row.colors, color)
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+		ret.Nodes += len(row.cols)
+		if len(row.cols) == 0 {
+			continue
+		}
+		if key := row.key(); seen[key] {
+			ret.DuplicateRows++
+		} else {
+			seen[key] = true
+		}
+	}
+
+	if ret.Rows > 0 && p.numCols > 0 {
+		ret.Density = float64(ret.Nodes) / float64(ret.Rows*p.numCols)
+	}
+	total := 0
+	for i, count := range counts {
+		if count == 0 && (p.removedCols == nil || !p.removedCols[i]) {
+			ret.IsolatedColumns = append(ret.IsolatedColumns, p.colName(p.colHeaders[i]))
+		}
+		if i >= p.numPrimary {
+			continue
+		}
+		if i == 0 || count < ret.MinColumn {
+			ret.MinColumn = count
+		}
+		if count > ret.MaxColumn {
+			ret.MaxColumn = count
+		}
+		total += count
+	}
+	if p.numPrimary > 0 {
+		ret.MeanColumn = float64(total) / float64(p.numPrimary)
+	}
+	return ret
+}
+
+// Write writes the report as text, one figure per line
+func (r *InstanceReport) Write(w io.Writer) error {
+	isolated := strings.Join(r.IsolatedColumns, ", ")
+	if isolated == "" {
+		isolated = "none"
+	}
+	_, err := fmt.Fprintf(w, `rows              %d
+primary columns   %d
+secondary columns %d
+nodes             %d
+density           %.4f
+rows per column   min %d, max %d, mean %.2f
+duplicate rows    %d
+isolated columns  %s
+`, r.Rows, r.PrimaryColumns, r.SecondaryColumns, r.Nodes, r.Density,
+		r.MinColumn, r.MaxColumn, r.MeanColumn, r.DuplicateRows, isolated)
+	return err
+}
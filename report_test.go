@@ -0,0 +1,48 @@
+package gox
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c", "d")
+	b.AddSecondaryColumns("x", "y")
+	b.AddRow("A", "a", "b", "x:1")
+	b.AddRow("B", "b", "a", "x:1")
+	b.AddRow("C", "a", "c")
+	b.AddRow("D", "c")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	r := prob.Report()
+	expected := &InstanceReport{
+		Rows:             4,
+		PrimaryColumns:   4,
+		SecondaryColumns: 2,
+		Nodes:            9,
+		Density:          9.0 / 24,
+		MinColumn:        0,
+		MaxColumn:        3,
+		MeanColumn:       7.0 / 4,
+		DuplicateRows:    1,
+		IsolatedColumns:  []string{"d", "y"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, r)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	for _, line := range []string{"rows per column   min 0, max 3, mean 1.75\n", "isolated columns  d, y\n"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Fatalf("Expected %q in:\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// The gRPC API of gox, so that the exact cover solver can be used from other
+// languages.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: gox.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Solver_Submit_FullMethodName          = "/gox.v1.Solver/Submit"
+	Solver_StreamSolutions_FullMethodName = "/gox.v1.Solver/StreamSolutions"
+	Solver_Cancel_FullMethodName          = "/gox.v1.Solver/Cancel"
+	Solver_Stats_FullMethodName           = "/gox.v1.Solver/Stats"
+)
+
+// SolverClient is the client API for Solver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SolverClient interface {
+	// Submit starts searching for the solutions to a problem
+	Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+	// StreamSolutions sends each solution to a problem as it is found, ending
+	// when the search does
+	StreamSolutions(ctx context.Context, in *StreamSolutionsRequest, opts ...grpc.CallOption) (Solver_StreamSolutionsClient, error)
+	// Cancel stops the search for the solutions to a problem
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// Stats reports the progress of the search for the solutions to a problem
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type solverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSolverClient(cc grpc.ClientConnInterface) SolverClient {
+	return &solverClient{cc}
+}
+
+func (c *solverClient) Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	out := new(SubmitResponse)
+	err := c.cc.Invoke(ctx, Solver_Submit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *solverClient) StreamSolutions(ctx context.Context, in *StreamSolutionsRequest, opts ...grpc.CallOption) (Solver_StreamSolutionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Solver_ServiceDesc.Streams[0], Solver_StreamSolutions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &solverStreamSolutionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Solver_StreamSolutionsClient interface {
+	Recv() (*Solution, error)
+	grpc.ClientStream
+}
+
+type solverStreamSolutionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *solverStreamSolutionsClient) Recv() (*Solution, error) {
+	m := new(Solution)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *solverClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, Solver_Cancel_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *solverClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, Solver_Stats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SolverServer is the server API for Solver service.
+// All implementations must embed UnimplementedSolverServer
+// for forward compatibility
+type SolverServer interface {
+	// Submit starts searching for the solutions to a problem
+	Submit(context.Context, *SubmitRequest) (*SubmitResponse, error)
+	// StreamSolutions sends each solution to a problem as it is found, ending
+	// when the search does
+	StreamSolutions(*StreamSolutionsRequest, Solver_StreamSolutionsServer) error
+	// Cancel stops the search for the solutions to a problem
+	Cancel(context.Context, *CancelRequest) (*StatsResponse, error)
+	// Stats reports the progress of the search for the solutions to a problem
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedSolverServer()
+}
+
+// UnimplementedSolverServer must be embedded to have forward compatible implementations.
+type UnimplementedSolverServer struct {
+}
+
+func (UnimplementedSolverServer) Submit(context.Context, *SubmitRequest) (*SubmitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedSolverServer) StreamSolutions(*StreamSolutionsRequest, Solver_StreamSolutionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSolutions not implemented")
+}
+func (UnimplementedSolverServer) Cancel(context.Context, *CancelRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedSolverServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedSolverServer) mustEmbedUnimplementedSolverServer() {}
+
+// UnsafeSolverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SolverServer will
+// result in compilation errors.
+type UnsafeSolverServer interface {
+	mustEmbedUnimplementedSolverServer()
+}
+
+func RegisterSolverServer(s grpc.ServiceRegistrar, srv SolverServer) {
+	s.RegisterService(&Solver_ServiceDesc, srv)
+}
+
+func _Solver_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolverServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Solver_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolverServer).Submit(ctx, req.(*SubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Solver_StreamSolutions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSolutionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SolverServer).StreamSolutions(m, &solverStreamSolutionsServer{stream})
+}
+
+type Solver_StreamSolutionsServer interface {
+	Send(*Solution) error
+	grpc.ServerStream
+}
+
+type solverStreamSolutionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *solverStreamSolutionsServer) Send(m *Solution) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Solver_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolverServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Solver_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolverServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Solver_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolverServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Solver_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolverServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Solver_ServiceDesc is the grpc.ServiceDesc for Solver service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Solver_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gox.v1.Solver",
+	HandlerType: (*SolverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _Solver_Submit_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Solver_Cancel_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Solver_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSolutions",
+			Handler:       _Solver_StreamSolutions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gox.proto",
+}
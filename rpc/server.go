@@ -0,0 +1,185 @@
+// Package rpc provides a gRPC API for gox, defined in gox.proto, so that
+// clients in other languages can use the solver over a typed protocol. The
+// Go stubs in gox.pb.go and gox_grpc.pb.go are generated from gox.proto, and
+// Server is a reference implementation of the service:
+//
+//	s := grpc.NewServer()
+//	rpc.RegisterSolverServer(s, rpc.NewServer(rpc.Config{MaxTimeout: time.Minute}))
+//	s.Serve(lis)
+//
+// The stubs were generated by protoc-gen-go v1.31.0 and protoc-gen-go-grpc
+// v1.3.0, so the package needs google.golang.org/protobuf v1.31.0 and
+// google.golang.org/grpc v1.60.0 or later, the versions it is tested with.
+// gox has no module file, so these must be required by the module which
+// builds it; the rest of gox has no dependencies outside the standard library.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative gox.proto
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+	"github.com/ifross89/gox/internal/jobs"
+)
+
+// Config holds the limits of a Server on the problems given to Submit: how
+// many solutions are found and for how long, and how many problems are kept
+// for StreamSolutions and Stats to find. The gRPC server's own limits, such as
+// the size of a message, are set with its options.
+type Config struct {
+	// MaxSolutions is the most solutions found for a problem, zero for no
+	// limit
+	MaxSolutions int
+	// MaxTimeout is the longest time spent searching for the solutions to a
+	// problem, zero for no limit
+	MaxTimeout time.Duration
+	// MaxProblems is the number of problems kept by the server, zero for
+	// DefaultMaxProblems. When it is reached the oldest finished problem is
+	// forgotten, and submissions are refused if every problem is running.
+	MaxProblems int
+}
+
+// DefaultMaxProblems is the number of problems kept if Config.MaxProblems is
+// not set
+const DefaultMaxProblems = 100
+
+// heuristics maps the heuristics of the API to those of gox
+var heuristics = map[Heuristic]gox.Heuristic{
+	Heuristic_HEURISTIC_MIN_REMAINING:          gox.MinRemaining,
+	Heuristic_HEURISTIC_FIRST_COLUMN:           gox.FirstColumn,
+	Heuristic_HEURISTIC_CONFLICT_WEIGHTED:      gox.ConflictWeighted,
+	Heuristic_HEURISTIC_BUCKETED_MIN_REMAINING: gox.BucketedMinRemaining,
+}
+
+// states maps the states of the jobs to those of the API
+var states = map[jobs.State]State{
+	jobs.Running:   State_STATE_RUNNING,
+	jobs.Done:      State_STATE_DONE,
+	jobs.Cancelled: State_STATE_CANCELLED,
+	jobs.TimedOut:  State_STATE_TIMED_OUT,
+}
+
+// response returns the statistics of the job
+func response(j *jobs.Job) *StatsResponse {
+	snap := j.Snapshot()
+	return &StatsResponse{
+		State:     states[snap.State],
+		Solutions: int64(snap.Solutions),
+		Complete:  snap.Complete,
+		Nodes:     snap.Stats.Nodes,
+		Updates:   snap.Stats.Updates,
+		Seconds:   snap.Seconds,
+	}
+}
+
+// Server implements SolverServer, solving each problem submitted in the
+// background
+type Server struct {
+	UnimplementedSolverServer
+	c    Config
+	jobs *jobs.Store
+}
+
+// NewServer creates a server with the limits given
+func NewServer(c Config) *Server {
+	if c.MaxProblems <= 0 {
+		c.MaxProblems = DefaultMaxProblems
+	}
+	return &Server{c: c, jobs: jobs.NewStore(c.MaxProblems)}
+}
+
+// Close cancels every problem which is still being solved
+func (s *Server) Close() {
+	s.jobs.Close()
+}
+
+// job returns the job with the given id
+func (s *Server) job(id string) (*jobs.Job, error) {
+	j, ok := s.jobs.Get(id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Unknown problem: %s", id)
+	}
+	return j, nil
+}
+
+// Submit starts searching for the solutions to a problem. The limit and
+// timeout asked for are capped by the Config of the server.
+func (s *Server) Submit(ctx context.Context, req *SubmitRequest) (*SubmitResponse, error) {
+	if req.Limit < 0 || req.TimeoutMs < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Limit and timeout must not be negative: limit=%d timeout_ms=%d", req.Limit, req.TimeoutMs)
+	}
+	h, ok := heuristics[req.Heuristic]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown heuristic: %v", req.Heuristic)
+	}
+	limit := s.c.MaxSolutions
+	if n := int(req.Limit); n > 0 && (limit <= 0 || n < limit) {
+		limit = n
+	}
+	timeout := s.c.MaxTimeout
+	if d := time.Duration(req.TimeoutMs) * time.Millisecond; d > 0 && (timeout <= 0 || d < timeout) {
+		timeout = d
+	}
+
+	in := &format.Instance{Primary: req.GetProblem().GetPrimary(), Secondary: req.GetProblem().GetSecondary()}
+	for _, r := range req.GetProblem().GetRows() {
+		in.Rows = append(in.Rows, format.Row{Name: r.Name, Items: r.Items})
+	}
+	prob, err := in.Problem()
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid problem: %v", err)
+	}
+
+	j, ok := s.jobs.Submit(prob, limit, timeout, h)
+	if !ok {
+		return nil, status.Errorf(codes.ResourceExhausted, "Too many problems are being solved")
+	}
+	return &SubmitResponse{Id: j.ID()}, nil
+}
+
+// StreamSolutions sends each solution to a problem as it is found, ending
+// when the search does or the client goes away
+func (s *Server) StreamSolutions(req *StreamSolutionsRequest, stream Solver_StreamSolutionsServer) error {
+	j, err := s.job(req.Id)
+	if err != nil {
+		return err
+	}
+	err = j.Stream(stream.Context(), func(solns [][]string) error {
+		for _, soln := range solns {
+			if err := stream.Send(&Solution{Rows: soln}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err == stream.Context().Err() {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+// Cancel stops the search for the solutions to a problem, returning its
+// statistics once it has stopped
+func (s *Server) Cancel(ctx context.Context, req *CancelRequest) (*StatsResponse, error) {
+	j, err := s.job(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	j.Cancel()
+	return response(j), nil
+}
+
+// Stats reports the progress of the search for the solutions to a problem
+func (s *Server) Stats(ctx context.Context, req *StatsRequest) (*StatsResponse, error) {
+	j, err := s.job(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return response(j), nil
+}
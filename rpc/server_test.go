@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+// problem returns a fixture as a Problem of the API
+func problem(f testutil.Fixture) *Problem {
+	p := &Problem{Primary: f.Primary, Secondary: f.Secondary}
+	for _, r := range f.Rows {
+		p.Rows = append(p.Rows, &Row{Name: r.Name, Items: r.Items})
+	}
+	return p
+}
+
+// dial starts a server listening in memory, returning a client connected to
+// it
+func dial(t *testing.T, c Config) SolverClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	s := NewServer(c)
+	RegisterSolverServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(func() {
+		s.Close()
+		srv.Stop()
+	})
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Error dialing server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewSolverClient(conn)
+}
+
+func TestSolve(t *testing.T) {
+	client := dial(t, Config{})
+	ctx := context.Background()
+	resp, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Knuth)})
+	if err != nil {
+		t.Fatalf("Error submitting problem: %v", err)
+	}
+
+	stream, err := client.StreamSolutions(ctx, &StreamSolutionsRequest{Id: resp.Id})
+	if err != nil {
+		t.Fatalf("Error streaming solutions: %v", err)
+	}
+	var solns [][]string
+	for {
+		soln, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error receiving solution: %v", err)
+		}
+		solns = append(solns, soln.Rows)
+	}
+	if len(solns) != 1 || len(solns[0]) != 2 || solns[0][0] != "D" || solns[0][1] != "B" {
+		t.Fatalf("Unexpected solutions: %v", solns)
+	}
+
+	stats, err := client.Stats(ctx, &StatsRequest{Id: resp.Id})
+	if err != nil {
+		t.Fatalf("Error getting stats: %v", err)
+	}
+	if stats.State != State_STATE_DONE || stats.Solutions != 1 || !stats.Complete || stats.Nodes == 0 {
+		t.Fatalf("Unexpected stats: %v", stats)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	client := dial(t, Config{MaxSolutions: 10})
+	ctx := context.Background()
+
+	// The limit asked for is capped by the server
+	resp, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Dominoes(20)), Limit: 100, Heuristic: Heuristic_HEURISTIC_FIRST_COLUMN})
+	if err != nil {
+		t.Fatalf("Error submitting problem: %v", err)
+	}
+	stats, err := client.Cancel(ctx, &CancelRequest{Id: resp.Id})
+	if err != nil {
+		t.Fatalf("Error cancelling problem: %v", err)
+	}
+	if stats.Solutions != 10 || stats.Complete {
+		t.Fatalf("Expected 10 solutions, got %v", stats)
+	}
+
+	// As is the timeout
+	client = dial(t, Config{MaxTimeout: 10 * time.Millisecond})
+	resp, err = client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Dominoes(60)), TimeoutMs: 3600000})
+	if err != nil {
+		t.Fatalf("Error submitting problem: %v", err)
+	}
+	stats, err = client.Stats(ctx, &StatsRequest{Id: resp.Id})
+	for err == nil && stats.State == State_STATE_RUNNING {
+		time.Sleep(time.Millisecond)
+		stats, err = client.Stats(ctx, &StatsRequest{Id: resp.Id})
+	}
+	if err != nil || stats.State != State_STATE_TIMED_OUT {
+		t.Fatalf("Expected search to time out, got %v: %v", stats, err)
+	}
+
+	if _, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Knuth), Limit: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for negative limit, got %v", err)
+	}
+	invalid := &Problem{Primary: []string{"a"}, Rows: []*Row{{Name: "A", Items: []string{"b"}}}}
+	if _, err := client.Submit(ctx, &SubmitRequest{Problem: invalid}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for invalid problem, got %v", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	client := dial(t, Config{MaxProblems: 1})
+	ctx := context.Background()
+
+	resp, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Dominoes(60))})
+	if err != nil {
+		t.Fatalf("Error submitting problem: %v", err)
+	}
+	if _, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Knuth)}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted while full, got %v", err)
+	}
+
+	stats, err := client.Cancel(ctx, &CancelRequest{Id: resp.Id})
+	if err != nil || stats.State != State_STATE_CANCELLED {
+		t.Fatalf("Expected problem to be cancelled, got %v: %v", stats, err)
+	}
+
+	// The cancelled problem is forgotten to make room for another
+	if _, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Knuth)}); err != nil {
+		t.Fatalf("Error submitting problem: %v", err)
+	}
+	if _, err := client.Stats(ctx, &StatsRequest{Id: resp.Id}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound for forgotten problem, got %v", err)
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	client := dial(t, Config{})
+	ctx := context.Background()
+	for n, name := range Heuristic_name {
+		resp, err := client.Submit(ctx, &SubmitRequest{Problem: problem(testutil.Dominoes(6)), Heuristic: Heuristic(n)})
+		if err != nil {
+			t.Fatalf("Error submitting problem with %s: %v", name, err)
+		}
+		stats, err := client.Stats(ctx, &StatsRequest{Id: resp.Id})
+		for err == nil && stats.State == State_STATE_RUNNING {
+			time.Sleep(time.Millisecond)
+			stats, err = client.Stats(ctx, &StatsRequest{Id: resp.Id})
+		}
+		if err != nil || stats.Solutions != 13 || !stats.Complete {
+			t.Fatalf("Expected 13 solutions with %s, got %v: %v", name, stats, err)
+		}
+	}
+}
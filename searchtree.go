@@ -0,0 +1,168 @@
+package gox
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+)
+
+// Outcomes of the nodes of a search tree
+const (
+	// OutcomeBranch is a node whose rows were tried in turn
+	OutcomeBranch = "branch"
+	// OutcomeSolution is a node at which every primary column was covered
+	OutcomeSolution = "solution"
+	// OutcomeDeadEnd is a node whose column had no rows left, or which was
+	// known to lead nowhere, see WithNogoods
+	OutcomeDeadEnd = "deadend"
+	// OutcomeStopped is a node at which the search was stopped, e.g. by a
+	// limit, before it could be explored
+	OutcomeStopped = "stopped"
+)
+
+// TreeNode is a node of a SearchTree, the partial solution reached by adding
+// Row to the partial solution of its parent
+type TreeNode struct {
+	ID int `json:"id"`
+	// Parent is the ID of the parent of the node, or -1 for the root
+	Parent int `json:"parent"`
+	Depth  int `json:"depth"`
+	// Row is the row added by the node, empty for the root
+	Row string `json:"row,omitempty"`
+	// Column is the column the search branched on at the node, and
+	// Candidates the number of rows it had left
+	Column     string `json:"column,omitempty"`
+	Candidates int    `json:"candidates,omitempty"`
+	Outcome    string `json:"outcome"`
+	// Nodes is the number of nodes in the subtree rooted at the node,
+	// including itself and those deeper than MaxDepth, and Solutions the
+	// number of solutions in it. Nodes is the best guide to where the time
+	// of the search was spent.
+	Nodes     int `json:"nodes"`
+	Solutions int `json:"solutions"`
+}
+
+// SearchTree records the tree explored by a search from its events, for
+// analysis in tools such as Gephi, see WriteGraphML. Its Event method is
+// passed to WithTrace:
+//
+//	var tree gox.SearchTree
+//	_, err := prob.SolveContext(ctx, gox.WithTrace(tree.Event))
+//	err = tree.WriteGraphML(w)
+//
+// The zero value records the whole tree; a SearchTree records a single search.
+type SearchTree struct {
+	// MaxDepth, if positive, is the depth of the deepest nodes recorded, so
+	// that the tree of a hard instance fits in memory. The nodes below it
+	// are counted in the Nodes and Solutions of their ancestors.
+	MaxDepth int
+	Nodes    []TreeNode
+
+	// current is the index of the node of the partial solution, and hidden
+	// the number of rows added to it below MaxDepth
+	current, hidden int
+	started         bool
+}
+
+// Event adds an event of the search to the tree
+func (t *SearchTree) Event(e Event) {
+	if !t.started {
+		t.Nodes = append(t.Nodes[:0], TreeNode{Parent: -1, Nodes: 1})
+		t.current, t.hidden, t.started = 0, 0, true
+	}
+	n := &t.Nodes[t.current]
+	switch e.Kind {
+	case ChooseColumn:
+		if t.hidden == 0 {
+			n.Column, n.Candidates = e.Column, e.Size
+		}
+	case DeadEnd:
+		if t.hidden == 0 {
+			n.Outcome = OutcomeDeadEnd
+		}
+	case FoundSolution:
+		n.Solutions++
+		if t.hidden == 0 {
+			n.Outcome = OutcomeSolution
+		}
+	case TryRow:
+		if t.hidden > 0 || (t.MaxDepth > 0 && n.Depth >= t.MaxDepth) {
+			if t.hidden == 0 {
+				n.Outcome = OutcomeBranch
+			}
+			t.hidden++
+			n.Nodes++
+			break
+		}
+		n.Outcome = OutcomeBranch
+		child := TreeNode{ID: len(t.Nodes), Parent: n.ID, Depth: n.Depth + 1, Row: e.Row, Nodes: 1}
+		t.Nodes = append(t.Nodes, child)
+		t.current = child.ID
+	case UndoRow:
+		if t.hidden > 0 {
+			t.hidden--
+			break
+		}
+		if n.Parent < 0 {
+			break
+		}
+		if n.Outcome == "" {
+			n.Outcome = OutcomeStopped
+		}
+		parent := &t.Nodes[n.Parent]
+		parent.Nodes += n.Nodes
+		parent.Solutions += n.Solutions
+		t.current = parent.ID
+	}
+}
+
+// WriteJSON writes the nodes of the tree as a JSON array, in the order they
+// were explored
+func (t *SearchTree) WriteJSON(w io.Writer) error {
+	nodes := t.Nodes
+	if nodes == nil {
+		nodes = []TreeNode{}
+	}
+	return json.NewEncoder(w).Encode(nodes)
+}
+
+// graphMLKeys declares the attributes of the nodes and edges of a tree
+// written as GraphML
+const graphMLKeys = `  <key id="depth" for="node" attr.name="depth" attr.type="int"/>
+  <key id="row" for="node" attr.name="row" attr.type="string"/>
+  <key id="column" for="node" attr.name="column" attr.type="string"/>
+  <key id="candidates" for="node" attr.name="candidates" attr.type="int"/>
+  <key id="outcome" for="node" attr.name="outcome" attr.type="string"/>
+  <key id="nodes" for="node" attr.name="nodes" attr.type="int"/>
+  <key id="solutions" for="node" attr.name="solutions" attr.type="int"/>
+  <key id="label" for="edge" attr.name="label" attr.type="string"/>
+`
+
+// WriteGraphML writes the tree as a GraphML graph, with an edge from each
+// node to its children labelled with the row the child adds, and the fields of
+// each TreeNode as attributes of its node
+func (t *SearchTree) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprint(bw, graphMLKeys)
+	fmt.Fprintln(bw, `  <graph id="search" edgedefault="directed">`)
+	for _, n := range t.Nodes {
+		outcome := n.Outcome
+		if outcome == "" {
+			outcome = OutcomeStopped
+		}
+		fmt.Fprintf(bw, `    <node id="n%d"><data key="depth">%d</data><data key="row">%s</data><data key="column">%s</data>`+
+			`<data key="candidates">%d</data><data key="outcome">%s</data><data key="nodes">%d</data><data key="solutions">%d</data></node>`+"\n",
+			n.ID, n.Depth, html.EscapeString(n.Row), html.EscapeString(n.Column), n.Candidates, outcome, n.Nodes, n.Solutions)
+	}
+	for _, n := range t.Nodes {
+		if n.Parent >= 0 {
+			fmt.Fprintf(bw, `    <edge source="n%d" target="n%d"><data key="label">%s</data></edge>`+"\n", n.Parent, n.ID, html.EscapeString(n.Row))
+		}
+	}
+	fmt.Fprintln(bw, "  </graph>\n</graphml>")
+	return bw.Flush()
+}
@@ -0,0 +1,106 @@
+package gox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+// branchingProblem returns a problem whose search with FirstColumn reaches a
+// dead end before its single solution
+func branchingProblem(t *testing.T) *exactCoverProblem {
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c")
+	b.AddRow("ab", "a", "b")
+	b.AddRow("ac", "a", "c")
+	b.AddRow("b", "b")
+	b.AddRow("bc", "b", "c")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	return prob
+}
+
+func TestSearchTree(t *testing.T) {
+	prob := branchingProblem(t)
+	var tree SearchTree
+	if _, err := prob.SolveContext(context.Background(), WithHeuristic(FirstColumn), WithTrace(tree.Event)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	expected := []TreeNode{
+		{ID: 0, Parent: -1, Column: "a", Candidates: 2, Outcome: OutcomeBranch, Nodes: 4, Solutions: 1},
+		{ID: 1, Parent: 0, Depth: 1, Row: "ab", Column: "c", Outcome: OutcomeDeadEnd, Nodes: 1},
+		{ID: 2, Parent: 0, Depth: 1, Row: "ac", Column: "b", Candidates: 1, Outcome: OutcomeBranch, Nodes: 2, Solutions: 1},
+		{ID: 3, Parent: 2, Depth: 2, Row: "b", Outcome: OutcomeSolution, Nodes: 1, Solutions: 1},
+	}
+	if !reflect.DeepEqual(tree.Nodes, expected) {
+		t.Fatalf("Expected tree %+v, got %+v", expected, tree.Nodes)
+	}
+
+	var buf bytes.Buffer
+	if err := tree.WriteJSON(&buf); err != nil {
+		t.Fatalf("Error writing JSON: %v", err)
+	}
+	var decoded []TreeNode
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Expected JSON to decode to the tree, got %+v: %v", decoded, err)
+	}
+
+	// The nodes below MaxDepth are counted in their ancestors
+	shallow := SearchTree{MaxDepth: 1}
+	if _, err := prob.SolveContext(context.Background(), WithHeuristic(FirstColumn), WithTrace(shallow.Event)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if !reflect.DeepEqual(shallow.Nodes, expected[:3]) {
+		t.Fatalf("Expected tree %+v, got %+v", expected[:3], shallow.Nodes)
+	}
+}
+
+func TestSearchTreeGraphML(t *testing.T) {
+	prob := branchingProblem(t)
+	var tree SearchTree
+	if _, err := prob.SolveContext(context.Background(), WithHeuristic(FirstColumn), WithTrace(tree.Event)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tree.WriteGraphML(&buf); err != nil {
+		t.Fatalf("Error writing GraphML: %v", err)
+	}
+	var graph struct {
+		Nodes []struct {
+			ID   string `xml:"id,attr"`
+			Data []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &graph); err != nil {
+		t.Fatalf("GraphML is not well formed: %v", err)
+	}
+	if len(graph.Nodes) != 4 || len(graph.Edges) != 3 {
+		t.Fatalf("Expected 4 nodes and 3 edges, got %d and %d", len(graph.Nodes), len(graph.Edges))
+	}
+	if e := graph.Edges[2]; e.Source != "n2" || e.Target != "n3" {
+		t.Fatalf("Expected edge from n2 to n3, got %+v", e)
+	}
+	outcomes := make(map[string]string)
+	for _, n := range graph.Nodes {
+		for _, d := range n.Data {
+			if d.Key == "outcome" {
+				outcomes[n.ID] = d.Value
+			}
+		}
+	}
+	if outcomes["n1"] != OutcomeDeadEnd || outcomes["n3"] != OutcomeSolution {
+		t.Fatalf("Unexpected outcomes %v", outcomes)
+	}
+}
@@ -0,0 +1,250 @@
+// Package server provides an HTTP service which solves exact cover problems
+// using gox, so that gox can be run as a service without writing the plumbing.
+//
+// Problems are submitted in the JSON format of the format package and solved
+// in the background. The endpoints are:
+//
+//	POST   /problems                  submit a problem, returning its status
+//	GET    /problems/{id}             the status and progress of a problem
+//	GET    /problems/{id}/solutions   stream the solutions as JSON lines
+//	DELETE /problems/{id}             cancel the search for solutions
+//
+// A submission may ask for at most limit solutions, a timeout and a heuristic
+// with the query parameters "limit", "timeout" (e.g. "5s") and "heuristic".
+// The limit and timeout are capped by the Config of the server.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+	"github.com/ifross89/gox/internal/jobs"
+)
+
+// Config holds the limits of a Server on the problems submitted over HTTP:
+// how many solutions are found and for how long, how large a request body may
+// be, and how many problems are kept for their status to be polled
+type Config struct {
+	// MaxSolutions is the most solutions found for a problem, zero for no
+	// limit
+	MaxSolutions int
+	// MaxTimeout is the longest time spent searching for the solutions to a
+	// problem, zero for no limit
+	MaxTimeout time.Duration
+	// MaxBodyBytes is the size of the largest problem accepted, zero for
+	// DefaultMaxBodyBytes
+	MaxBodyBytes int64
+	// MaxProblems is the number of problems kept by the server, zero for
+	// DefaultMaxProblems. When it is reached the oldest finished problem is
+	// forgotten, and submissions are refused if every problem is running.
+	MaxProblems int
+}
+
+const (
+	// DefaultMaxBodyBytes is the size of the largest problem accepted if
+	// Config.MaxBodyBytes is not set
+	DefaultMaxBodyBytes = 10 << 20
+	// DefaultMaxProblems is the number of problems kept if
+	// Config.MaxProblems is not set
+	DefaultMaxProblems = 100
+)
+
+// States of a problem
+const (
+	Running   = "running"
+	Done      = "done"
+	Cancelled = "cancelled"
+	TimedOut  = "timeout"
+)
+
+// Status is the JSON response describing a problem
+type Status struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+	// Solutions is the number of solutions found so far
+	Solutions int `json:"solutions"`
+	// Complete is true once every solution has been found
+	Complete bool `json:"complete"`
+	// Seconds is the time spent searching so far
+	Seconds float64 `json:"seconds"`
+}
+
+// states maps the states of the jobs to those of the API
+var states = map[jobs.State]string{
+	jobs.Running:   Running,
+	jobs.Done:      Done,
+	jobs.Cancelled: Cancelled,
+	jobs.TimedOut:  TimedOut,
+}
+
+// jobStatus returns the status of the job
+func jobStatus(j *jobs.Job) Status {
+	snap := j.Snapshot()
+	return Status{
+		ID:        j.ID(),
+		State:     states[snap.State],
+		Solutions: snap.Solutions,
+		Complete:  snap.Complete,
+		Seconds:   snap.Seconds,
+	}
+}
+
+// Server is an http.Handler which solves exact cover problems
+type Server struct {
+	c    Config
+	mux  *http.ServeMux
+	jobs *jobs.Store
+}
+
+// New creates a server with the limits given
+func New(c Config) *Server {
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if c.MaxProblems <= 0 {
+		c.MaxProblems = DefaultMaxProblems
+	}
+	s := &Server{c: c, mux: http.NewServeMux(), jobs: jobs.NewStore(c.MaxProblems)}
+	s.mux.HandleFunc("/problems", s.handleProblems)
+	s.mux.HandleFunc("/problems/", s.handleProblem)
+	return s
+}
+
+// ServeHTTP handles a request to the server
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close cancels every problem which is still being solved
+func (s *Server) Close() {
+	s.jobs.Close()
+}
+
+// writeJSON writes v as the body of a response
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error as the body of a response
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// options reads the options of a submission from the query, capped by the
+// limits of the server
+func (s *Server) options(r *http.Request) (limit int, timeout time.Duration, h gox.Heuristic, err error) {
+	q := r.URL.Query()
+	limit = s.c.MaxSolutions
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, 0, fmt.Errorf("Invalid limit: %s", v)
+		}
+		if n > 0 && (limit <= 0 || n < limit) {
+			limit = n
+		}
+	}
+	timeout = s.c.MaxTimeout
+	if v := q.Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return 0, 0, 0, fmt.Errorf("Invalid timeout: %s", v)
+		}
+		if d > 0 && (timeout <= 0 || d < timeout) {
+			timeout = d
+		}
+	}
+	if v := q.Get("heuristic"); v != "" {
+		if h, err = gox.ParseHeuristic(v); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return limit, timeout, h, nil
+}
+
+// handleProblems handles submissions of problems
+func (s *Server) handleProblems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method not allowed: %s", r.Method))
+		return
+	}
+	limit, timeout, h, err := s.options(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	in, err := format.ReadJSON(http.MaxBytesReader(w, r.Body, s.c.MaxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid problem: %v", err))
+		return
+	}
+	prob, err := in.Problem()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	j, ok := s.jobs.Submit(prob, limit, timeout, h)
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("Too many problems are being solved"))
+		return
+	}
+
+	w.Header().Set("Location", "/problems/"+j.ID())
+	writeJSON(w, http.StatusCreated, jobStatus(j))
+}
+
+// handleProblem handles requests for a problem which has been submitted
+func (s *Server) handleProblem(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/problems/")
+	id, rest := path, ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		id, rest = path[:i], path[i:]
+	}
+
+	j, ok := s.jobs.Get(id)
+	if !ok || (rest != "" && rest != "/solutions") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("Not found: %s", r.URL.Path))
+		return
+	}
+
+	switch {
+	case rest == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, jobStatus(j))
+	case rest == "" && r.Method == http.MethodDelete:
+		j.Cancel()
+		writeJSON(w, http.StatusOK, jobStatus(j))
+	case rest == "/solutions" && r.Method == http.MethodGet:
+		s.stream(w, r, j)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method not allowed: %s", r.Method))
+	}
+}
+
+// stream writes each solution to the job as a line of JSON as it is found,
+// finishing when the search does or the client goes away
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, j *jobs.Job) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	j.Stream(r.Context(), func(solns [][]string) error {
+		for _, soln := range solns {
+			if err := enc.Encode(soln); err != nil {
+				return err
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
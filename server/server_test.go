@@ -0,0 +1,154 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ifross89/gox/internal/testutil"
+)
+
+// submit submits a problem to the server, returning the response's status
+// code and body
+func submit(t *testing.T, url, query, problem string) (int, Status) {
+	resp, err := http.Post(url+"/problems"+query, "application/json", strings.NewReader(problem))
+	if err != nil {
+		t.Fatalf("Error submitting problem: %v", err)
+	}
+	defer resp.Body.Close()
+	var s Status
+	json.NewDecoder(resp.Body).Decode(&s)
+	return resp.StatusCode, s
+}
+
+// status gets the status of a problem, failing the test unless it exists
+func status(t *testing.T, url, id string) Status {
+	resp, err := http.Get(url + "/problems/" + id)
+	if err != nil {
+		t.Fatalf("Error getting status: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for problem %s, got %d", id, resp.StatusCode)
+	}
+	var s Status
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		t.Fatalf("Error decoding status: %v", err)
+	}
+	return s
+}
+
+func TestSolve(t *testing.T) {
+	srv := New(Config{})
+	defer srv.Close()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	code, s := submit(t, ts.URL, "", testutil.Knuth.JSON())
+	if code != http.StatusCreated || s.ID == "" {
+		t.Fatalf("Expected problem to be created, got %d: %+v", code, s)
+	}
+
+	// Streaming the solutions waits for the search to finish
+	resp, err := http.Get(ts.URL + "/problems/" + s.ID + "/solutions")
+	if err != nil {
+		t.Fatalf("Error streaming solutions: %v", err)
+	}
+	defer resp.Body.Close()
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 1 || lines[0] != `["D","B"]` {
+		t.Fatalf("Unexpected solutions: %v", lines)
+	}
+
+	if s := status(t, ts.URL, s.ID); s.State != Done || s.Solutions != 1 || !s.Complete {
+		t.Fatalf("Unexpected status: %+v", s)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	srv := New(Config{MaxSolutions: 10, MaxTimeout: time.Second})
+	defer srv.Close()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// The limit asked for is capped by the server
+	_, s := submit(t, ts.URL, "?limit=100", testutil.Dominoes(40).JSON())
+	for s.State == Running || s.State == "" {
+		time.Sleep(time.Millisecond)
+		s = status(t, ts.URL, s.ID)
+	}
+	if s.State != Done || s.Solutions != 10 || s.Complete {
+		t.Fatalf("Expected 10 solutions, got %+v", s)
+	}
+
+	// As is the timeout
+	quick := New(Config{MaxTimeout: 10 * time.Millisecond})
+	defer quick.Close()
+	qs := httptest.NewServer(quick)
+	defer qs.Close()
+	_, s = submit(t, qs.URL, "?timeout=1h", testutil.Dominoes(40).JSON())
+	for s.State == Running {
+		time.Sleep(time.Millisecond)
+		s = status(t, qs.URL, s.ID)
+	}
+	if s.State != TimedOut || s.Solutions == 0 {
+		t.Fatalf("Expected search to time out, got %+v", s)
+	}
+
+	if code, _ := submit(t, ts.URL, "?limit=-1", testutil.Knuth.JSON()); code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for invalid limit, got %d", code)
+	}
+	if code, _ := submit(t, ts.URL, "", `{"primary": ["a"], "rows": [{"name": "A", "items": ["b"]}]}`); code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for invalid problem, got %d", code)
+	}
+
+	small := httptest.NewServer(New(Config{MaxBodyBytes: 10}))
+	defer small.Close()
+	if code, _ := submit(t, small.URL, "", testutil.Knuth.JSON()); code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for large problem, got %d", code)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	srv := New(Config{MaxProblems: 1})
+	defer srv.Close()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	_, s := submit(t, ts.URL, "", testutil.Dominoes(40).JSON())
+	if code, _ := submit(t, ts.URL, "", testutil.Knuth.JSON()); code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503 while full, got %d", code)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/problems/"+s.ID, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error cancelling problem: %v", err)
+	}
+	resp.Body.Close()
+	if s := status(t, ts.URL, s.ID); s.State != Cancelled {
+		t.Fatalf("Expected problem to be cancelled, got %+v", s)
+	}
+
+	// The cancelled problem is forgotten to make room for another
+	code, next := submit(t, ts.URL, "", testutil.Knuth.JSON())
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+	resp, err = http.Get(ts.URL + "/problems/" + s.ID)
+	if err != nil {
+		t.Fatalf("Error getting status: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || next.ID == s.ID {
+		t.Fatalf("Expected status 404 for forgotten problem, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,143 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Shard is an independent part of the search for the solutions of a problem,
+// see Shard. Shards can be encoded as JSON, so that they can be handed out to
+// other processes or machines holding the same problem.
+type Shard struct {
+	// Given are the rows given with RowIsSolution when the problem was
+	// sharded, which the problem solving the shard must also have been given,
+	// and Forbidden the rows forbidden by Forbid, which it must also have
+	// forbidden
+	Given     []string `json:"given,omitempty"`
+	Forbidden []string `json:"forbidden,omitempty"`
+	// Column is the column branched on, and Row the row chosen to cover it.
+	// Both are empty if the rows given already solve the problem.
+	Column string `json:"column,omitempty"`
+	Row    string `json:"row,omitempty"`
+	// Index is the position of the shard among the Count shards of the
+	// problem
+	Index int `json:"index"`
+	Count int `json:"count"`
+}
+
+// Shard splits the search for the solutions of the problem into independent
+// units of work, one for each row of the column the heuristic chooses first,
+// for callers distributing the search themselves, e.g. through a queue of
+// jobs. Each shard is solved by SolveShard, on this problem or on another
+// created in the same way with the same rows given and forbidden, and every
+// solution is
+// found by exactly one shard. With the heuristics for which SolveParallel
+// keeps the order of SolveContext, concatenating the solutions of the shards
+// in order gives the solutions in the order SolveContext finds them. Each
+// shard starts the search afresh, so with ConflictWeighted,
+// BucketedMinRemaining, RandomTie and RecentTie, which choose from the search
+// so far, the same solutions come in another order.
+//
+// The heuristic set by WithHeuristic must be the same as when the shards are
+// solved; other options have no effect. If the problem has no solutions
+// because a column cannot be covered, an *UncoverableError is returned.
+func (p *exactCoverProblem) Shard(opts ...Option) ([]Shard, error) {
+	if err := p.acquire("Shard"); err != nil {
+		return nil, err
+	}
+	defer p.release()
+	c := newConfig(nil, opts)
+	if cols := p.uncoverable(); cols != nil {
+		return nil, &UncoverableError{Columns: cols}
+	}
+	given, forbidden := rowNames(p.solutionRows), p.forbiddenRows()
+	if p.root == p.root.right {
+		return []Shard{{Given: given, Forbidden: forbidden, Count: 1}}, nil
+	}
+	colHead := p.nextCol(c)
+	var ret []Shard
+	for n := colHead.down; n != colHead; n = n.down {
+		ret = append(ret, Shard{
+			Given:     given,
+			Forbidden: forbidden,
+			Column:    p.colName(colHead),
+			Row:       n.rowHead.label(),
+			Index:     len(ret),
+		})
+	}
+	for i := range ret {
+		ret[i].Count = len(ret)
+	}
+	return ret, nil
+}
+
+// SolveShard finds the solutions in a shard of the problem made by Shard,
+// like SolveContext. An error is returned if the shard does not fit the
+// problem as it stands: if different rows have been given or forbidden, or its
+// row cannot be chosen to cover its column.
+func (p *exactCoverProblem) SolveShard(ctx context.Context, s Shard, opts ...Option) ([][]string, error) {
+	var ret [][]string
+	opts = append(opts, func(c *config) {
+		c.prepare = func(c *config) error {
+			given := rowNames(p.solutionRows)
+			if strings.Join(given, "\x00") != strings.Join(s.Given, "\x00") {
+				return fmt.Errorf("Shard was made with rows [%s] given, but the problem has [%s] given",
+					strings.Join(s.Given, " "), strings.Join(given, " "))
+			}
+			forbidden := p.forbiddenRows()
+			if strings.Join(forbidden, "\x00") != strings.Join(s.Forbidden, "\x00") {
+				return fmt.Errorf("Shard was made with rows [%s] forbidden, but the problem has [%s] forbidden",
+					strings.Join(s.Forbidden, " "), strings.Join(forbidden, " "))
+			}
+			if s.Row == "" && s.Column == "" {
+				return nil
+			}
+			var err error
+			c.branch, err = p.branch(s.Column, s.Row)
+			return err
+		}
+	})
+	err := p.solve(ctx, "SolveShard", opts, func(c *config, rows []*rowHeader) {
+		soln := rowNames(rows)
+		if !c.discard {
+			ret = append(ret, soln)
+		}
+		if c.onSolution != nil {
+			c.onSolution(soln)
+		}
+	})
+	return ret, err
+}
+
+// forbiddenRows returns the names of the rows forbidden by Forbid, in the
+// order of the rows
+func (p *exactCoverProblem) forbiddenRows() []string {
+	var ret []string
+	for _, r := range p.rowHeaders {
+		if r.forbidden {
+			ret = append(ret, r.label())
+		}
+	}
+	return ret
+}
+
+// branch returns the node of the named row in the named column, checking that
+// the column is still to be covered and the row can still cover it
+func (p *exactCoverProblem) branch(colName, rowName string) (*node, error) {
+	col := p.columnIndex(colName)
+	if col < 0 || col >= p.numPrimary {
+		return nil, fmt.Errorf("No primary column found with name %s", colName)
+	}
+	header := p.row(rowName)
+	if header == nil {
+		return nil, fmt.Errorf("No row found with name %s", rowName)
+	}
+	colHead := p.colHeaders[col]
+	for n := colHead.down; n != colHead; n = n.down {
+		if n.rowHead == header {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("Row %s cannot cover column %s", rowName, colName)
+}
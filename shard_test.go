@@ -0,0 +1,166 @@
+package gox_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/testgen"
+)
+
+// shardProblem is implemented by the problems created by gox
+type shardProblem interface {
+	Shard(...gox.Option) ([]gox.Shard, error)
+	SolveShard(context.Context, gox.Shard, ...gox.Option) ([][]string, error)
+}
+
+func TestShard(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      rng.Intn(40),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		prob, err := inst.Problem(gox.WithDebug())
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		if len(inst.Planted) > 0 && trial%2 == 0 {
+			if err := prob.RowIsSolution(inst.Planted[0]); err != nil {
+				t.Fatalf("Error giving row: %v", err)
+			}
+		}
+		want, err := prob.SolveContext(context.Background())
+		if _, ok := err.(*gox.UncoverableError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		shards, err := prob.(shardProblem).Shard()
+		if err != nil {
+			t.Fatalf("Error sharding problem: %v", err)
+		}
+
+		// The shards are solved on a fresh copy of the problem, as another
+		// process would, after passing through JSON
+		other, err := inst.Problem(gox.WithDebug())
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		if len(inst.Planted) > 0 && trial%2 == 0 {
+			if err := other.RowIsSolution(inst.Planted[0]); err != nil {
+				t.Fatalf("Error giving row: %v", err)
+			}
+		}
+		var got [][]string
+		for i, s := range shards {
+			if s.Index != i || s.Count != len(shards) {
+				t.Fatalf("Trial %d: shard %d is numbered %d of %d", trial, i, s.Index, s.Count)
+			}
+			b, err := json.Marshal(s)
+			if err != nil {
+				t.Fatalf("Error encoding shard: %v", err)
+			}
+			var decoded gox.Shard
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("Error decoding shard: %v", err)
+			}
+			solns, err := other.(shardProblem).SolveShard(context.Background(), decoded)
+			if err != nil {
+				t.Fatalf("Error solving shard %s: %v", b, err)
+			}
+			got = append(got, solns...)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Trial %d: expected %v, got %v", trial, want, got)
+		}
+		// Solving the shards leaves the problem as it was
+		again, err := other.SolveContext(context.Background())
+		if err != nil || !reflect.DeepEqual(again, want) {
+			t.Fatalf("Trial %d: expected %v after solving shards, got %v, %v", trial, want, again, err)
+		}
+	}
+}
+
+func TestShardHeuristics(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for trial := 0; trial < 100; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      10 + rng.Intn(40),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		prob, err := inst.Problem(gox.WithDebug())
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		for _, o := range splitOrders {
+			want, err := prob.SolveContext(context.Background(), o.opts...)
+			if _, ok := err.(*gox.UncoverableError); ok {
+				break
+			} else if err != nil {
+				t.Fatalf("Error solving problem: %v", err)
+			}
+			shards, err := prob.(shardProblem).Shard(o.opts...)
+			if err != nil {
+				t.Fatalf("Error sharding problem: %v", err)
+			}
+			var got [][]string
+			for _, s := range shards {
+				solns, err := prob.(shardProblem).SolveShard(context.Background(), s, o.opts...)
+				if err != nil {
+					t.Fatalf("Error solving shard: %v", err)
+				}
+				got = append(got, solns...)
+			}
+			if o.ordered && !reflect.DeepEqual(got, want) {
+				t.Fatalf("Trial %d, %s: expected %v, got %v", trial, o.name, want, got)
+			}
+			if !o.ordered && !sameSolutions(got, want) {
+				t.Fatalf("Trial %d, %s: expected the solutions %v in any order, got %v", trial, o.name, want, got)
+			}
+		}
+	}
+}
+
+func TestSolveShardMismatch(t *testing.T) {
+	prob, err := dominoBuilder(4).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	shards, err := prob.Shard()
+	if err != nil {
+		t.Fatalf("Error sharding problem: %v", err)
+	}
+	for _, s := range []gox.Shard{
+		{Column: "no such column", Row: shards[0].Row},
+		{Column: shards[0].Column, Row: "no such row"},
+		{Column: shards[0].Column, Row: shards[1].Row, Given: []string{shards[0].Row}},
+	} {
+		if _, err := prob.SolveShard(context.Background(), s); err == nil {
+			t.Errorf("Expected an error solving shard %+v", s)
+		}
+	}
+	if err := prob.RowIsSolution(shards[0].Row); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if _, err := prob.SolveShard(context.Background(), shards[1]); err == nil {
+		t.Errorf("Expected an error solving a shard made before a row was given")
+	}
+}
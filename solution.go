@@ -0,0 +1,229 @@
+package gox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Solution is a set of rows of a problem, such as one of the solutions found
+// by Solve, which can be checked and displayed against the problem
+type Solution struct {
+	p    *exactCoverProblem
+	Rows []string
+	// Given holds whether each row was given with RowIsSolution rather than
+	// found by the search, so that what was provided can be told apart from
+	// what was deduced
+	Given []bool
+}
+
+// Solution returns the solution of the problem made up of the rows named
+func (p *exactCoverProblem) Solution(rows []string) *Solution {
+	given := make([]bool, len(rows))
+	for i, name := range rows {
+		if r := p.row(name); r != nil {
+			given[i] = r.given
+		}
+	}
+	return &Solution{p: p, Rows: rows, Given: given}
+}
+
+// Givens returns the names of the rows of the solution which were given with
+// RowIsSolution
+func (s *Solution) Givens() []string {
+	var ret []string
+	for i, name := range s.Rows {
+		if s.Given[i] {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// Format writes the matrix of the problem with the rows of the solution
+// marked, so that a result can be checked by eye. Each row of the matrix is
+// written on its own line, with the rows of the solution marked by '>' and
+// their cells by '#', or by their colour. Beneath the matrix each column is
+// annotated with the number of rows of the solution covering it, and a '!'
+// marks the columns which break the rules of the problem, see Verify.
+func (s *Solution) Format(w io.Writer) error {
+	p := s.p
+	chosen := make(map[*rowHeader]bool, len(s.Rows))
+	var unknown []string
+	for _, name := range s.Rows {
+		if r := p.row(name); r != nil {
+			chosen[r] = true
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	// bad marks the columns reported by Verify
+	bad := make([]bool, p.numCols)
+	if verr, ok := p.Verify(s.Rows).(*VerifyError); ok {
+		for _, v := range verr.Violations {
+			if v.Kind != Uncovered && v.Kind != Overcovered {
+				continue
+			}
+			for i, h := range p.colHeaders {
+				if p.colName(h) == v.Column {
+					bad[i] = true
+				}
+			}
+		}
+	}
+
+	// cells holds the text of each cell of the matrix, and counts the number
+	// of rows of the solution covering each column
+	cells := make([][]string, len(p.rowHeaders))
+	counts := make([]int, p.numCols)
+	widths := make([]int, p.numCols)
+	for i, h := range p.colHeaders {
+		widths[i] = len(p.colName(h))
+	}
+	nameWidth := len("cover")
+	for i, r := range p.rowHeaders {
+		if width := len(r.label()); width > nameWidth {
+			nameWidth = width
+		}
+		cells[i] = make([]string, p.numCols)
+		for j := range cells[i] {
+			cells[i][j] = "."
+		}
+		for n := r.first; n != nil; {
+			cell := "1"
+			if chosen[r] {
+				cell = "#"
+				counts[n.colIndex]++
+			}
+			if color := n.color; color != 0 {
+				if color < 0 {
+					color = -color
+				}
+				cell = p.colorName(color)
+			}
+			cells[i][n.colIndex] = cell
+			if len(cell) > widths[n.colIndex] {
+				widths[n.colIndex] = len(cell)
+			}
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	line := func(mark, name string, cols func(int) string) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %-*s", mark, nameWidth, name)
+		for i := range widths {
+			fmt.Fprintf(&b, " %-*s", widths[i], cols(i))
+		}
+		fmt.Fprintln(bw, strings.TrimRight(b.String(), " "))
+	}
+	line(" ", "", func(i int) string { return p.colName(p.colHeaders[i]) })
+	for i, r := range p.rowHeaders {
+		mark := " "
+		if chosen[r] {
+			mark = ">"
+		}
+		line(mark, r.label(), func(j int) string { return cells[i][j] })
+	}
+	line(" ", "cover", func(i int) string { return strconv.Itoa(counts[i]) })
+	for _, b := range bad {
+		if b {
+			line(" ", "", func(i int) string {
+				if bad[i] {
+					return "!"
+				}
+				return ""
+			})
+			break
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Fprintf(bw, "rows not in the problem: %s\n", strings.Join(unknown, ", "))
+	}
+	return bw.Flush()
+}
+
+// colorName returns the name of a colour value, or the value itself if the
+// problem was not created by a Builder
+func (p *exactCoverProblem) colorName(color int) string {
+	if color <= len(p.colorNames) {
+		return p.colorNames[color-1]
+	}
+	return strconv.Itoa(color)
+}
+
+// Coverage returns the names of the rows of the solution covering each
+// column, by the name of the column. Columns which no row of the solution
+// covers are left out.
+func (s *Solution) Coverage() map[string][]string {
+	ret := make(map[string][]string)
+	for _, name := range s.Rows {
+		r := s.p.row(name)
+		if r == nil {
+			continue
+		}
+		for n := r.first; n != nil; {
+			col := s.p.colName(n.colHead)
+			ret[col] = append(ret[col], name)
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+	}
+	return ret
+}
+
+// SolutionDiff compares two solutions, see Diff
+type SolutionDiff struct {
+	// Shared holds the rows in both solutions, and OnlyA and OnlyB the rows
+	// in just one of them, each in the order of the solution they come from
+	Shared, OnlyA, OnlyB []string
+	// Distance is the number of rows in just one of the solutions, so that
+	// solutions differing by one row swapped for another are at distance 2
+	Distance int
+}
+
+// Jaccard returns the Jaccard distance between the solutions: the proportion
+// of the rows in either solution which are not in both. It is 0 for equal
+// solutions and 1 for solutions with no rows in common.
+func (d SolutionDiff) Jaccard() float64 {
+	union := len(d.Shared) + d.Distance
+	if union == 0 {
+		return 0
+	}
+	return float64(d.Distance) / float64(union)
+}
+
+// Diff compares the rows of two solutions, e.g. to cluster the solutions of a
+// tiling by how similar they are
+func Diff(a, b *Solution) SolutionDiff {
+	inA := make(map[string]bool, len(a.Rows))
+	for _, row := range a.Rows {
+		inA[row] = true
+	}
+	inB := make(map[string]bool, len(b.Rows))
+	for _, row := range b.Rows {
+		inB[row] = true
+	}
+
+	var ret SolutionDiff
+	for _, row := range a.Rows {
+		if inB[row] {
+			ret.Shared = append(ret.Shared, row)
+		} else {
+			ret.OnlyA = append(ret.OnlyA, row)
+		}
+	}
+	for _, row := range b.Rows {
+		if !inA[row] {
+			ret.OnlyB = append(ret.OnlyB, row)
+		}
+	}
+	ret.Distance = len(ret.OnlyA) + len(ret.OnlyB)
+	return ret
+}
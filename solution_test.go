@@ -0,0 +1,121 @@
+package gox
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSolutionFormat(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("p", "q", "r")
+	b.AddSecondaryColumns("x", "y")
+	b.AddRow("A", "p", "q", "x", "y:A")
+	b.AddRow("B", "p", "r", "x:A", "y")
+	b.AddRow("C", "p", "x:B")
+	b.AddRow("D", "q", "x:A")
+	b.AddRow("E", "r", "y:B")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := prob.Solution([]string{"B", "D"}).Format(&buf); err != nil {
+		t.Fatalf("Error formatting solution: %v", err)
+	}
+	expected := `        p q r x y
+  A     1 1 . 1 A
+> B     # . # A #
+  C     1 . . B .
+> D     . # . A .
+  E     . . 1 . B
+  cover 1 1 1 2 1
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := prob.Solution([]string{"A", "Z"}).Format(&buf); err != nil {
+		t.Fatalf("Error formatting solution: %v", err)
+	}
+	expected = `        p q r x y
+> A     # # . # A
+  B     1 . 1 A 1
+  C     1 . . B .
+  D     . 1 . A .
+  E     . . 1 . B
+  cover 1 1 0 1 1
+            !
+rows not in the problem: Z
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	a := prob.Solution([]string{"v0", "v1", "v2", "v3"})
+	b := prob.Solution([]string{"v0", "v1", "h0,2", "h1,2"})
+	d := Diff(a, b)
+	expected := SolutionDiff{
+		Shared:   []string{"v0", "v1"},
+		OnlyA:    []string{"v2", "v3"},
+		OnlyB:    []string{"h0,2", "h1,2"},
+		Distance: 4,
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, d)
+	}
+	if j := d.Jaccard(); j != 4.0/6 {
+		t.Fatalf("Expected Jaccard distance 4/6, got %v", j)
+	}
+	if d := Diff(a, a); d.Distance != 0 || d.Jaccard() != 0 {
+		t.Fatalf("Expected no difference between a solution and itself, got %+v", d)
+	}
+}
+
+func TestGivens(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	solns, err := prob.SolveContext(context.Background())
+	if err != nil || len(solns) != 3 {
+		t.Fatalf("Expected 3 solutions, got %v: %v", solns, err)
+	}
+	for _, soln := range solns {
+		s := prob.Solution(soln)
+		if !reflect.DeepEqual(s.Givens(), []string{"v0"}) || !s.Given[0] || s.Given[1] {
+			t.Fatalf("Expected v0 to be marked as given in %v, got %v", soln, s.Given)
+		}
+	}
+
+	// The givens are left out by every way of solving
+	without := func(name string, solns [][]string, err error) {
+		if err != nil || len(solns) != 3 {
+			t.Fatalf("%s: expected 3 solutions, got %v: %v", name, solns, err)
+		}
+		for _, soln := range solns {
+			if len(soln) != 3 || len(prob.Solution(soln).Givens()) != 0 {
+				t.Fatalf("%s: expected the given row to be left out of %v", name, soln)
+			}
+		}
+	}
+	solns, err = prob.SolveContext(context.Background(), WithoutGivens())
+	without("SolveContext", solns, err)
+	solns, err = prob.SolveParallel(context.Background(), 2, WithoutGivens())
+	without("SolveParallel", solns, err)
+	stream, err := prob.SolveChan(context.Background(), WithoutGivens())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	solns = nil
+	for soln := range stream.C {
+		solns = append(solns, soln)
+	}
+	without("SolveChan", solns, stream.Err())
+}
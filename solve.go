@@ -0,0 +1,597 @@
+package gox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Heuristic selects the column to branch on at each step of the search
+type Heuristic int
+
+const (
+	// MinRemaining chooses the column with the fewest rows remaining, the
+	// heuristic suggested by Knuth. Ties are broken by the weights of the
+	// columns, see SetColumnWeight, then by taking the leftmost column, or as
+	// given to WithTieBreak. This is the default.
+	MinRemaining Heuristic = iota
+	// FirstColumn chooses the leftmost column which has not been covered
+	FirstColumn
+	// ConflictWeighted learns which columns are hard to cover as it
+	// searches, in the manner of the dom/wdeg heuristic of constraint
+	// solvers. Each column has a weight, one more than the number of dead
+	// ends it has caused, and the column with the fewest rows left for its
+	// weight is chosen, so that columns which keep failing are branched on
+	// earlier. The weights start afresh with each search, and with each
+	// branch of SolveParallel, shard and page, which so find the solutions
+	// of SolveContext in another order. On structured problems such as
+	// scheduling it can do much less work than MinRemaining, at the cost of
+	// a little more work at each step.
+	ConflictWeighted
+	// BucketedMinRemaining chooses a column with the fewest rows remaining,
+	// like MinRemaining, but finds it in constant time from an index of the
+	// columns by their number of rows, kept up to date as rows are removed
+	// and restored, rather than scanning every column at each step. Keeping
+	// the index costs more at each row removed than the scan saves unless the
+	// matrix has thousands of columns, so on narrow matrices such as the
+	// classic instances it is slower than MinRemaining. Ties are broken by
+	// taking the column whose count changed last, so the solutions are found
+	// in another order, which SolveParallel, shards and pages do not keep as
+	// each starts a new index. Column weights and WithTieBreak are ignored,
+	// and with column priorities the columns are scanned as for MinRemaining.
+	BucketedMinRemaining
+)
+
+// heuristicNames are the names used by ParseHeuristic and String
+var heuristicNames = map[Heuristic]string{
+	MinRemaining:         "mrv",
+	FirstColumn:          "first",
+	ConflictWeighted:     "wdeg",
+	BucketedMinRemaining: "bucket",
+}
+
+// String returns the name of the heuristic as accepted by ParseHeuristic
+func (h Heuristic) String() string {
+	if name, ok := heuristicNames[h]; ok {
+		return name
+	}
+	return fmt.Sprintf("Heuristic(%d)", int(h))
+}
+
+// ParseHeuristic returns the heuristic with the given name, "mrv", "first",
+// "wdeg" or "bucket"
+func ParseHeuristic(name string) (Heuristic, error) {
+	for h, n := range heuristicNames {
+		if n == name {
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown heuristic: %s", name)
+}
+
+// Option configures a call to SolveContext
+type Option func(*config)
+
+// WithLimit stops the search once n solutions have been found. A limit of zero
+// or less means every solution is found. With WithSortedOutput every solution
+// is still found, and the first n in the sorted order are kept.
+func WithLimit(n int) Option {
+	return func(c *config) {
+		c.limit = n
+	}
+}
+
+// WithHeuristic sets the heuristic used to choose the column to branch on
+func WithHeuristic(h Heuristic) Option {
+	return func(c *config) {
+		c.heuristic = h
+	}
+}
+
+// WithStats records statistics about the search in s once it has finished
+func WithStats(s *Stats) Option {
+	return func(c *config) {
+		c.stats = s
+	}
+}
+
+// WithSolutionFunc calls f with each solution as soon as it is found, so
+// that the solutions can be used before the search has finished. f is called
+// from the goroutine performing the search.
+func WithSolutionFunc(f func([]string)) Option {
+	return func(c *config) {
+		c.onSolution = f
+	}
+}
+
+// WithoutSolutions stops SolveContext keeping the solutions it finds, so that
+// it returns none of them. Used with WithSolutionFunc, this allows more
+// solutions to be enumerated than can be held in memory.
+func WithoutSolutions() Option {
+	return func(c *config) {
+		c.discard = true
+	}
+}
+
+// WithoutGivens leaves the rows given with RowIsSolution out of the solutions
+// found, so that they hold only the rows chosen by the search and those
+// forced by ForceRows. By default the given rows are part of every solution,
+// see Solution.Given to tell them apart.
+func WithoutGivens() Option {
+	return func(c *config) {
+		c.withoutGivens = true
+	}
+}
+
+// ErrIdleTimeout is returned when the search is stopped by WithIdleTimeout
+var ErrIdleTimeout = errors.New("No solution found within the idle timeout")
+
+// WithIdleTimeout stops the search with ErrIdleTimeout if no solution has
+// been found for d, counting from the start of the search and then from each
+// solution found. Unlike a deadline on the context, this lets an enumeration
+// run for as long as it keeps finding solutions. The solutions found so far
+// are returned along with the error. With SolveParallel a solution found by
+// any worker counts, and with CountSolutions each solution counted, including
+// those looked up with WithTranspositions. The time is checked every few
+// thousand steps of the search, so it may run for a little longer than d.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.idle = d
+	}
+}
+
+// WithParticipation counts the solutions in which each row appears in p once
+// the search has finished. Counting as the solutions are found is much
+// cheaper than going through them afterwards, and works with
+// WithoutSolutions.
+func WithParticipation(p *Participation) Option {
+	return func(c *config) {
+		c.participation = p
+	}
+}
+
+// Participation reports the number of solutions in which each row appears,
+// see WithParticipation
+type Participation struct {
+	// Rows holds the names of the rows, as returned by Rows, and Counts the
+	// number of solutions each appears in
+	Rows   []string
+	Counts []int64
+	// Solutions is the number of solutions found
+	Solutions int64
+}
+
+// Always returns the rows which appear in every solution found, such as
+// placements which are forced by the clues of a puzzle. It returns none if no
+// solutions were found.
+func (p *Participation) Always() []string {
+	var ret []string
+	for i, n := range p.Counts {
+		if n == p.Solutions && n > 0 {
+			ret = append(ret, p.Rows[i])
+		}
+	}
+	return ret
+}
+
+// Never returns the rows which appear in none of the solutions found
+func (p *Participation) Never() []string {
+	var ret []string
+	for i, n := range p.Counts {
+		if n == 0 {
+			ret = append(ret, p.Rows[i])
+		}
+	}
+	return ret
+}
+
+// Stats reports the work done by a search
+type Stats struct {
+	// Nodes is the number of nodes of the search tree visited, each of which
+	// is a partial solution
+	Nodes int64
+	// Updates is the number of times a node was unlinked from its column,
+	// Knuth's measure of the work done by dancing links
+	Updates int64
+	// Solutions is the number of solutions found
+	Solutions int64
+	// Pruned is the number of partial solutions skipped because their
+	// outcome was already known, see WithNogoods and WithTranspositions
+	Pruned int64
+	// Columns counts the times each column was chosen to branch on and at
+	// which depths, the columns chosen most often first, showing the
+	// constraints which drive the search. Columns never chosen are left out,
+	// as are the choices of the workers of SolveParallel.
+	Columns []ColumnChoices
+	// Bound is the best bound known when SolveMinRows or SolveByCost
+	// finished: the rows, or the cost, of the best solution found, or else
+	// of the incumbent or bound given, and 0 if there is none. Passing it to
+	// WithUpperBound or WithCostBound starts a later search where this one
+	// left off, e.g. after the problem has been edited.
+	Bound float64
+}
+
+// checkInterval is the number of search steps taken between checks of the
+// context, so that checking does not dominate the search
+const checkInterval = 1024
+
+// config holds the settings and progress of a single search
+type config struct {
+	ctx       context.Context
+	limit     int
+	heuristic Heuristic
+	// tieBreak and rng are set by WithTieBreak and WithRand, and stamps
+	// records when each column was last affected for RecentTie
+	tieBreak TieBreak
+	rng      *rand.Rand
+	stamps   []int64
+	// conflicts counts the dead ends caused by each column for
+	// ConflictWeighted, see conflict.go
+	conflicts []int64
+	// idle is set by WithIdleTimeout. lastFound holds the time in
+	// nanoseconds of the last solution, shared between the workers of
+	// SolveParallel, and idleSolutions the number of solutions when it was
+	// last checked.
+	idle          time.Duration
+	lastFound     *int64
+	idleSolutions int64
+	// byCost is set by SolveByCost
+	byCost bool
+	// paging is set by SolvePage, which continues the search after the
+	// nodes of resume, and path holds the nodes of the rows chosen by the
+	// search while paging, see page.go
+	paging bool
+	resume []*node
+	path   []*node
+	// found is called with the rows of each solution, which are only valid
+	// during the call. The search stops when it returns false.
+	found func([]*rowHeader) bool
+	// onSolution is set by WithSolutionFunc
+	onSolution func([]string)
+	// discard is set by WithoutSolutions, and store by WithStore
+	discard bool
+	store   SolutionStore
+	// withoutGivens is set by WithoutGivens
+	withoutGivens bool
+	// sorted is set by WithSortedOutput
+	sorted bool
+	// participation is set by WithParticipation
+	participation *Participation
+	// trace is set by WithTrace, and events by WithEventStream
+	trace  func(Event)
+	events *eventStream
+	// lex and lexOrder are set by WithLexicographic
+	lex      bool
+	lexOrder RowOrder
+	// minRows is set by SolveMinRows, and incumbent and bound by
+	// WithIncumbent and WithUpperBound
+	minRows   bool
+	incumbent []string
+	bound     int
+	// costBound is the cost SolveByCost looks for solutions cheaper than,
+	// set by WithCostBound or from the cost of the incumbent, and cheapest
+	// the cost of the cheapest solution known, see Stats.Bound
+	costBound float64
+	cheapest  float64
+	// beamWidth and beamScore are set by WithBeam
+	beamWidth int
+	beamScore func(BeamNode) float64
+	// nogoods is set by WithNogoods, and pruned counts the partial
+	// solutions it skipped
+	nogoods *stateTable
+	pruned  int64
+	// transpositions is set by WithTranspositions, and counting by
+	// CountSolutions, which leaves the number of solutions in count
+	transpositions *stateTable
+	counting       bool
+	count          uint64
+	// budget is the most nodes a worker of SolveParallelConfig may visit
+	budget int64
+	// buffer and overflow are set by WithBuffer
+	buffer   int
+	overflow OverflowPolicy
+	// tracer is set by WithTracer
+	tracer Tracer
+	// branch is set by SolveShard to the node of the row chosen to cover
+	// its column before searching
+	branch *node
+	// prepare is set by SolveShard and SolvePage to check their input
+	// against the problem and find the nodes it names, once the problem has
+	// been acquired so that no other call can change them
+	prepare func(c *config) error
+	// forced holds the nodes of the rows given by propagate, see search
+	forced []*node
+	// steps counts the nodes of the search tree, used to decide when to
+	// check ctx
+	steps     int64
+	solutions int64
+	err       error
+	stats     *Stats
+	// choices counts the times each column was chosen at each depth, by
+	// index, when stats are recorded, see chose
+	choices [][]int64
+}
+
+// newConfig creates the configuration for a search from the options given
+func newConfig(ctx context.Context, opts []Option) *config {
+	c := &config{ctx: ctx}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.addStore()
+	if c.idle > 0 && c.lastFound == nil {
+		now := time.Now().UnixNano()
+		c.lastFound = &now
+	}
+	return c
+}
+
+// interrupted reports whether the search should stop because the context has
+// been cancelled
+func (c *config) interrupted() bool {
+	if c.err != nil {
+		return true
+	}
+	c.steps++
+	if c.budget > 0 && c.steps > c.budget {
+		c.err = ErrNodeBudget
+		return true
+	}
+	if c.steps%checkInterval != 0 {
+		return false
+	}
+	if c.idle > 0 && c.idleExpired() {
+		c.err = ErrIdleTimeout
+		return true
+	}
+	if c.ctx == nil {
+		return false
+	}
+	c.err = c.ctx.Err()
+	return c.err != nil
+}
+
+// idleExpired reports whether WithIdleTimeout's time has passed since the
+// last solution, noting the time if solutions have been found since the last
+// check
+func (c *config) idleExpired() bool {
+	now := time.Now().UnixNano()
+	if c.solutions != c.idleSolutions {
+		c.idleSolutions = c.solutions
+		atomic.StoreInt64(c.lastFound, now)
+		return false
+	}
+	return time.Duration(now-atomic.LoadInt64(c.lastFound)) > c.idle
+}
+
+// SolveContext finds the solutions to the problem, like Solve, but stops when
+// the context is cancelled or once the limit given by WithLimit is reached.
+// The solutions found so far are returned along with the context's error if
+// the search was interrupted. If a primary column has no rows left which
+// could cover it, taking into account the rows given with RowIsSolution, the
+// problem has no solutions and an *UncoverableError naming the columns is
+// returned without searching. The problem is left as it was before the call,
+// so it may be solved again.
+func (p *exactCoverProblem) SolveContext(ctx context.Context, opts ...Option) ([][]string, error) {
+	var ret [][]string
+	err := p.solve(ctx, "SolveContext", opts, func(c *config, rows []*rowHeader) {
+		soln := rowNames(rows)
+		if !c.discard {
+			ret = append(ret, soln)
+		}
+		if c.onSolution != nil {
+			c.onSolution(soln)
+		}
+	})
+	return ret, err
+}
+
+// SolveIndices finds the solutions to the problem like SolveContext, but
+// returns each solution as the indexes of its rows in Rows rather than their
+// names. This saves copying the names, and makes it simple to look up data
+// the caller holds about each row in a slice. The names of the rows are only
+// found if WithSolutionFunc is given.
+func (p *exactCoverProblem) SolveIndices(ctx context.Context, opts ...Option) ([][]int, error) {
+	var ret [][]int
+	err := p.solve(ctx, "SolveIndices", opts, func(c *config, rows []*rowHeader) {
+		if !c.discard {
+			soln := make([]int, len(rows))
+			for i, r := range rows {
+				soln[i] = r.index
+			}
+			ret = append(ret, soln)
+		}
+		if c.onSolution != nil {
+			c.onSolution(rowNames(rows))
+		}
+	})
+	return ret, err
+}
+
+// solve performs a search for the call op with the options given, calling
+// record with the rows of each solution, and returns the error which stopped
+// it, if any
+func (p *exactCoverProblem) solve(ctx context.Context, op string, opts []Option, record func(c *config, rows []*rowHeader)) (err error) {
+	if err := p.startSolve(op); err != nil {
+		return err
+	}
+	defer p.finishSolve(&err)
+	c := newConfig(ctx, opts)
+	if c.prepare != nil {
+		if err := c.prepare(c); err != nil {
+			return err
+		}
+	}
+	span := p.startSearch(ctx, c.tracer, op)
+	limited := false
+	// held keeps the solutions until they can be sorted, see WithSortedOutput
+	var held [][]*rowHeader
+	c.found = func(rows []*rowHeader) bool {
+		if c.sorted {
+			held = append(held, append([]*rowHeader(nil), c.output(rows)...))
+		} else {
+			record(c, c.output(rows))
+		}
+		span.event("gox.solution", Attribute{"gox.solutions", c.solutions}, Attribute{"gox.nodes", c.steps})
+		limited = !c.sorted && c.limit > 0 && c.solutions >= int64(c.limit)
+		return !limited
+	}
+	if err := ctx.Err(); err != nil {
+		span.end(err, Attribute{"gox.outcome", outcome(err, false)})
+		return err
+	}
+	if cols := p.uncoverable(); cols != nil {
+		err := &UncoverableError{Columns: cols}
+		span.end(err, Attribute{"gox.outcome", outcome(err, false)})
+		return err
+	}
+	updates := p.updates
+	p.run(c)
+	sortRowSolutions(held)
+	if c.limit > 0 && len(held) > c.limit {
+		held, limited = held[:c.limit], true
+	}
+	for _, rows := range held {
+		record(c, rows)
+	}
+	span.end(c.err,
+		Attribute{"gox.nodes", c.steps},
+		Attribute{"gox.updates", p.updates - updates},
+		Attribute{"gox.solutions", c.solutions},
+		Attribute{"gox.outcome", outcome(c.err, limited)},
+	)
+	return c.err
+}
+
+// output returns the rows of a solution to pass on to the caller, leaving out
+// the given rows if asked to by WithoutGivens
+func (c *config) output(rows []*rowHeader) []*rowHeader {
+	if !c.withoutGivens {
+		return rows
+	}
+	ret := make([]*rowHeader, 0, len(rows))
+	for _, r := range rows {
+		if !r.given {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+// rowNames returns the names of the rows of a solution
+func rowNames(rows []*rowHeader) []string {
+	ret := make([]string, len(rows))
+	for i, r := range rows {
+		ret[i] = r.label()
+	}
+	return ret
+}
+
+// UncoverableError is returned by SolveContext when primary columns have no
+// rows which could cover them, so that the problem has no solutions
+type UncoverableError struct {
+	// Columns are the names of the columns, or their indexes if the problem
+	// was created from a matrix
+	Columns []string
+}
+
+func (e *UncoverableError) Error() string {
+	if len(e.Columns) == 1 {
+		return fmt.Sprintf("Column %s has no candidate rows", e.Columns[0])
+	}
+	return fmt.Sprintf("Columns %s have no candidate rows", strings.Join(e.Columns, ", "))
+}
+
+// uncoverable returns the names of the primary columns remaining in the
+// problem which no row covers, or nil if there are none
+func (p *exactCoverProblem) uncoverable() []string {
+	var ret []string
+	for col := p.root.right; col != p.root; col = col.right {
+		if col.colCount == 0 {
+			ret = append(ret, p.colName(col))
+		}
+	}
+	return ret
+}
+
+// run performs a search with the configuration given, recording its
+// statistics if they were requested
+func (p *exactCoverProblem) run(c *config) {
+	updates := p.updates
+	if c.participation != nil {
+		*c.participation = Participation{Rows: p.Rows(), Counts: make([]int64, len(p.rowHeaders))}
+	}
+	if rowNode := c.branch; rowNode != nil {
+		p.cover(rowNode.colHead)
+		p.pushRowToSolution(rowNode.rowHead)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		defer func() {
+			p.popRowFromSolution()
+			for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+				p.uncommit(leftNode)
+			}
+			p.uncover(rowNode.colHead)
+		}()
+	}
+	if c.events != nil {
+		p.coverEvents = c
+		defer func() {
+			p.coverEvents = nil
+			c.events.flush(c)
+		}()
+	}
+	if c.stats != nil {
+		c.choices = make([][]int64, p.numCols)
+	}
+	if c.heuristic == BucketedMinRemaining && p.colPriorities == nil && !c.lex {
+		p.buckets = p.newBucketIndex()
+		defer func() { p.buckets = nil }()
+	}
+	switch {
+	case c.lex:
+		p.searchLex(c, p.rowRanks(c.lexOrder))
+	case c.minRows:
+		best := c.bound
+		if best < 0 {
+			best = 0
+		}
+		p.searchMin(c, &best, p.maxPrimary())
+		c.bound = best
+	case c.counting:
+		c.count, _ = p.searchCount(c)
+		c.solutions = int64(c.count)
+	case c.beamWidth > 0:
+		p.searchBeam(c)
+	case c.byCost:
+		p.searchByCost(c)
+	case c.resume != nil:
+		p.searchResume(c, c.resume)
+	default:
+		p.search(c)
+	}
+	if c.participation != nil {
+		c.participation.Solutions = c.solutions
+	}
+	if c.stats != nil {
+		*c.stats = Stats{
+			Nodes:     c.steps,
+			Updates:   p.updates - updates,
+			Solutions: c.solutions,
+			Pruned:    c.pruned,
+			Columns:   p.columnChoices(c.choices),
+		}
+		switch {
+		case c.minRows:
+			c.stats.Bound = float64(c.bound)
+		case c.byCost && !math.IsInf(c.cheapest, 1):
+			c.stats.Bound = c.cheapest
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// dominoProblem creates the problem of tiling a 2xn board with dominoes, which
+// has the n-th Fibonacci number of solutions
+func dominoProblem(t *testing.T, n int) *exactCoverProblem {
+	b := NewBuilder()
+	for c := 0; c < n; c++ {
+		b.AddColumns(fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+	}
+	for c := 0; c < n; c++ {
+		b.AddRow(fmt.Sprintf("v%d", c), fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+		if c < n-1 {
+			for r := 0; r < 2; r++ {
+				b.AddRow(fmt.Sprintf("h%d,%d", r, c), fmt.Sprintf("%d,%d", r, c), fmt.Sprintf("%d,%d", r, c+1))
+			}
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	return prob
+}
+
+func TestSolveContextLimit(t *testing.T) {
+	prob := dominoProblem(t, 10)
+	solns, err := prob.SolveContext(context.Background(), WithLimit(5))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(solns) != 5 {
+		t.Fatalf("Expected 5 solutions, got %d", len(solns))
+	}
+
+	// The problem is restored after stopping early
+	solns, err = prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(solns) != 89 {
+		t.Fatalf("Expected 89 solutions, got %d", len(solns))
+	}
+}
+
+func TestSolveContextHeuristic(t *testing.T) {
+	for _, h := range []Heuristic{MinRemaining, FirstColumn} {
+		parsed, err := ParseHeuristic(h.String())
+		if err != nil || parsed != h {
+			t.Fatalf("Error parsing heuristic %v: %v", h, err)
+		}
+		solns, err := dominoProblem(t, 6).SolveContext(context.Background(), WithHeuristic(h))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		assertStringSliceEqual(t, canonicalSolutions(dominoProblem(t, 6).Solve()), canonicalSolutions(solns))
+	}
+	if _, err := ParseHeuristic("best"); err == nil {
+		t.Fatal("Expected error parsing unknown heuristic")
+	}
+}
+
+func TestSolveContextCancelled(t *testing.T) {
+	prob := dominoProblem(t, 60)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prob.SolveContext(ctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	// A 2x60 board has too many tilings to find before the deadline
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := prob.SolveContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	solns, err := prob.SolveContext(context.Background(), WithLimit(1))
+	if err != nil || len(solns) != 1 {
+		t.Fatalf("Expected one solution after timeout, got %d: %v", len(solns), err)
+	}
+}
+
+func TestSolveContextStats(t *testing.T) {
+	var stats Stats
+	solns, err := dominoProblem(t, 4).SolveContext(context.Background(), WithStats(&stats))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if stats.Solutions != 5 || len(solns) != 5 {
+		t.Fatalf("Expected 5 solutions, got %d, stats=%+v", len(solns), stats)
+	}
+	// Every solution is a leaf of the search tree, and each placement of a
+	// domino unlinks at least one node
+	if stats.Nodes <= stats.Solutions || stats.Updates == 0 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestSolveContextSolutionFunc(t *testing.T) {
+	var seen [][]string
+	solns, err := dominoProblem(t, 4).SolveContext(context.Background(), WithLimit(3), WithSolutionFunc(func(soln []string) {
+		seen = append(seen, soln)
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(solns) != 3 {
+		t.Fatalf("Expected 3 solutions, got %d", len(solns))
+	}
+	assertStringSliceEqual(t, canonicalSolutions(solns), canonicalSolutions(seen))
+}
+
+func TestSolveContextUncoverable(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c")
+	b.AddRow("A", "a")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	_, err = prob.SolveContext(context.Background())
+	uerr, ok := err.(*UncoverableError)
+	if !ok || fmt.Sprint(uerr.Columns) != "[b c]" {
+		t.Fatalf("Expected columns b and c to be uncoverable, got %v", err)
+	}
+
+	// A column may become uncoverable when rows are given
+	b = NewBuilder()
+	b.AddColumns("a", "b", "c")
+	b.AddRow("X", "a", "b")
+	b.AddRow("Y", "b", "c")
+	b.AddRow("Z", "a")
+	prob, err = b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if solns, err := prob.SolveContext(context.Background()); err != nil || len(solns) != 1 {
+		t.Fatalf("Expected 1 solution, got %v, %v", solns, err)
+	}
+	if err := prob.RowIsSolution("X"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	_, err = prob.SolveContext(context.Background())
+	if uerr, ok := err.(*UncoverableError); !ok || fmt.Sprint(uerr.Columns) != "[c]" {
+		t.Fatalf("Expected column c to be uncoverable, got %v", err)
+	}
+	if err.Error() != "Column c has no candidate rows" {
+		t.Fatalf("Unexpected message: %v", err)
+	}
+}
+
+func TestSolveIndices(t *testing.T) {
+	prob := dominoProblem(t, 5)
+	names, err := prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	indices, err := prob.SolveIndices(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(indices) != len(names) {
+		t.Fatalf("Expected %d solutions, got %d", len(names), len(indices))
+	}
+	rows := prob.Rows()
+	for i, soln := range indices {
+		for j, index := range soln {
+			if rows[index] != names[i][j] {
+				t.Fatalf("Expected row %s at %d of solution %d, got %s", names[i][j], j, i, rows[index])
+			}
+		}
+	}
+}
+
+func TestSolveContextParticipation(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	var part Participation
+	if _, err := prob.SolveContext(context.Background(), WithParticipation(&part), WithoutSolutions()); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	// With v0 given the rest is a 2x3 board, which has 3 tilings: v1 v2 v3,
+	// v1 h0,2 h1,2 and h0,1 h1,1 v3
+	if part.Solutions != 3 {
+		t.Fatalf("Expected 3 solutions, got %d", part.Solutions)
+	}
+	counts := make(map[string]int64)
+	for i, name := range part.Rows {
+		counts[name] = part.Counts[i]
+	}
+	for name, expected := range map[string]int64{"v0": 3, "v1": 2, "v3": 2, "h0,1": 1, "h0,0": 0} {
+		if counts[name] != expected {
+			t.Fatalf("Expected %s in %d solutions, got %d", name, expected, counts[name])
+		}
+	}
+	if always := part.Always(); fmt.Sprint(always) != "[v0]" {
+		t.Fatalf("Expected only v0 in every solution, got %v", always)
+	}
+	if never := part.Never(); fmt.Sprint(never) != "[h0,0 h1,0]" {
+		t.Fatalf("Expected h0,0 and h1,0 in no solution, got %v", never)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	// Tiling the board alongside three columns which cannot be covered, so
+	// that the search finds dead ends for a long time
+	b := NewBuilder()
+	for c := 0; c < 14; c++ {
+		b.AddColumns(fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+	}
+	b.AddColumns("x", "y", "z")
+	for c := 0; c < 14; c++ {
+		b.AddRow(fmt.Sprintf("v%d", c), fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+		if c < 13 {
+			for r := 0; r < 2; r++ {
+				b.AddRow(fmt.Sprintf("h%d,%d", r, c), fmt.Sprintf("%d,%d", r, c), fmt.Sprintf("%d,%d", r, c+1))
+			}
+		}
+	}
+	b.AddRow("xy", "x", "y")
+	b.AddRow("xz", "x", "z")
+	b.AddRow("yz", "y", "z")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if _, err := prob.SolveContext(context.Background(), WithIdleTimeout(time.Nanosecond)); err != ErrIdleTimeout {
+		t.Fatalf("Expected idle timeout, got %v", err)
+	}
+	if _, err := prob.SolveParallel(context.Background(), 2, WithIdleTimeout(time.Nanosecond)); err != ErrIdleTimeout {
+		t.Fatalf("Expected idle timeout from SolveParallel, got %v", err)
+	}
+	if _, err := prob.CountSolutions(context.Background(), WithIdleTimeout(time.Nanosecond)); err != ErrIdleTimeout {
+		t.Fatalf("Expected idle timeout from CountSolutions, got %v", err)
+	}
+
+	// The timeout is reset by each solution, so a search which keeps
+	// finding them runs to the end
+	prob = dominoProblem(t, 20)
+	var stats Stats
+	if _, err := prob.SolveContext(context.Background(), WithIdleTimeout(time.Minute), WithoutSolutions(), WithStats(&stats)); err != nil || stats.Solutions != 10946 {
+		t.Fatalf("Expected 10946 solutions, got %d: %v", stats.Solutions, err)
+	}
+	// Each solution counted resets the timeout too, found on a leaf of the
+	// search or looked up in the transposition table
+	for _, opts := range [][]Option{nil, {WithTranspositions(1000)}} {
+		opts = append(opts, WithIdleTimeout(time.Nanosecond))
+		if count, err := prob.CountSolutions(context.Background(), opts...); err != nil || count != 10946 {
+			t.Fatalf("Expected to count 10946 solutions, got %d: %v", count, err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package gox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what SolveChan does with a solution when the buffer
+// of its channel is full because the solutions are not being received as
+// fast as they are found
+type OverflowPolicy int
+
+const (
+	// SpillWhenFull writes the solutions which do not fit in the buffer to a
+	// temporary file, passing them on in order once there is room, so that
+	// the search neither waits nor holds the solutions in memory. This is
+	// the default.
+	SpillWhenFull OverflowPolicy = iota
+	// BlockWhenFull makes the search wait until there is room in the buffer
+	BlockWhenFull
+	// DropWhenFull throws the solution away, counting it in Dropped
+	DropWhenFull
+)
+
+// streamBuffer is the size of the buffer of the channel of SolveChan unless
+// WithBuffer is given
+const streamBuffer = 1024
+
+// WithBuffer sets the size of the buffer of the channel of SolveChan, and
+// what is done with a solution when it is full. The default is a buffer of
+// 1024 solutions with SpillWhenFull. Other calls ignore it.
+func WithBuffer(size int, policy OverflowPolicy) Option {
+	return func(c *config) {
+		c.buffer = size
+		c.overflow = policy
+	}
+}
+
+// SolutionStream is a search running in the background, see SolveChan
+type SolutionStream struct {
+	// C receives the solutions as they are found, and is closed once the
+	// search has finished
+	C <-chan []string
+
+	err     error
+	dropped int64
+	done    chan struct{}
+}
+
+// Err returns the error which ended the search, once C has been closed, like
+// the error returned by SolveContext
+func (s *SolutionStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Dropped returns the number of solutions thrown away by DropWhenFull so far
+func (s *SolutionStream) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// SolveChan finds the solutions to the problem like SolveContext, but in the
+// background, sending them on the channel of the stream returned, which is
+// closed once the search finishes. The channel is buffered, and WithBuffer
+// sets what happens when a slow receiver lets the buffer fill up. The search
+// stops if the context is cancelled, which a receiver which stops receiving
+// early should do; with BlockWhenFull the search would otherwise wait
+// forever. The problem may not be used until the channel has been closed.
+//
+// Errors found before the search starts are returned, and any error which
+// ends the search is returned by Err.
+func (p *exactCoverProblem) SolveChan(ctx context.Context, opts ...Option) (*SolutionStream, error) {
+	if err := p.startSolve("SolveChan"); err != nil {
+		return nil, err
+	}
+	opts = append([]Option{WithBuffer(streamBuffer, SpillWhenFull)}, opts...)
+	c := newConfig(ctx, opts)
+	if err := ctx.Err(); err != nil {
+		p.finishSolve(nil)
+		return nil, err
+	}
+	if cols := p.uncoverable(); cols != nil {
+		p.finishSolve(nil)
+		return nil, &UncoverableError{Columns: cols}
+	}
+
+	ch := make(chan []string, c.buffer)
+	s := &SolutionStream{C: ch, done: make(chan struct{})}
+	var spill *spiller
+	// send passes a solution on, returning false if the search must stop
+	send := func(soln []string) bool {
+		if c.onSolution != nil {
+			c.onSolution(soln)
+		}
+		switch {
+		case c.overflow == DropWhenFull:
+			select {
+			case ch <- soln:
+			default:
+				atomic.AddInt64(&s.dropped, 1)
+			}
+		case c.overflow == SpillWhenFull:
+			if spill == nil {
+				spill = newSpiller(ctx, ch)
+			}
+			if err := spill.add(soln); err != nil {
+				c.err = err
+				return false
+			}
+		default:
+			select {
+			case ch <- soln:
+			case <-ctx.Done():
+				c.err = ctx.Err()
+				return false
+			}
+		}
+		return true
+	}
+	// held keeps the solutions until they can be sorted, see
+	// WithSortedOutput
+	var held [][]string
+	c.found = func(rows []*rowHeader) bool {
+		soln := rowNames(c.output(rows))
+		if c.sorted {
+			held = append(held, soln)
+		} else if !send(soln) {
+			return false
+		}
+		return c.sorted || c.limit <= 0 || c.solutions < int64(c.limit)
+	}
+
+	go func() {
+		// The deferred calls run in reverse order, also if the search
+		// panics: the spilled solutions are passed on, then the problem
+		// released, then the channel closed
+		defer close(s.done)
+		defer close(ch)
+		defer p.finishSolve(&s.err)
+		defer func() {
+			if spill != nil {
+				if err := spill.finish(); err != nil && s.err == nil {
+					s.err = err
+				}
+			}
+		}()
+		p.run(c)
+		sortSolutions(held)
+		if c.limit > 0 && len(held) > c.limit {
+			held = held[:c.limit]
+		}
+		for _, soln := range held {
+			if !send(soln) {
+				break
+			}
+		}
+		s.err = c.err
+	}()
+	return s, nil
+}
+
+// spiller passes solutions on to the channel of SolveChan in order, writing
+// those which do not fit to a temporary file which a goroutine reads back as
+// there is room
+type spiller struct {
+	ctx context.Context
+	ch  chan<- []string
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// pending is the number of solutions in the file which have not been
+	// passed on, and finished is set once no more will be added
+	pending  int
+	finished bool
+	err      error
+	file     *os.File
+	w        *bufio.Writer
+	// stopped is closed when the goroutine reading the file has returned
+	stopped chan struct{}
+}
+
+// newSpiller creates a spiller sending solutions to ch until ctx is cancelled
+func newSpiller(ctx context.Context, ch chan<- []string) *spiller {
+	s := &spiller{ctx: ctx, ch: ch}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// add passes a solution on, straight to the channel if there is room and no
+// solutions are waiting in the file before it, or to the file otherwise
+func (s *spiller) add(soln []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if s.pending == 0 {
+		select {
+		case s.ch <- soln:
+			return nil
+		default:
+		}
+	}
+	if s.file == nil {
+		if s.err = s.open(); s.err != nil {
+			return s.err
+		}
+	}
+	b, err := json.Marshal(soln)
+	if err == nil {
+		b = append(b, '\n')
+		if _, err = s.w.Write(b); err == nil {
+			err = s.w.Flush()
+		}
+	}
+	if err != nil {
+		s.err = err
+		return err
+	}
+	s.pending++
+	s.cond.Signal()
+	return nil
+}
+
+// open creates the file and starts the goroutine reading it back. s.mu must
+// be held.
+func (s *spiller) open() error {
+	f, err := os.CreateTemp("", "gox-spill-*.jsonl")
+	if err != nil {
+		return err
+	}
+	r, err := os.Open(f.Name())
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	s.file, s.w = f, bufio.NewWriter(f)
+	s.stopped = make(chan struct{})
+	go s.forward(r)
+	return nil
+}
+
+// forward reads the solutions back from the file and sends them to the
+// channel, until they have all been sent or the context is cancelled
+func (s *spiller) forward(f *os.File) {
+	defer close(s.stopped)
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		s.mu.Lock()
+		for s.pending == 0 && !s.finished && s.err == nil {
+			s.cond.Wait()
+		}
+		if s.pending == 0 || s.err != nil {
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+
+		// Every line counted as pending has been flushed
+		var soln []string
+		line, err := r.ReadBytes('\n')
+		if err == nil {
+			err = json.Unmarshal(line, &soln)
+		}
+		if err != nil {
+			s.fail(err)
+			return
+		}
+		select {
+		case s.ch <- soln:
+		case <-s.ctx.Done():
+			s.fail(s.ctx.Err())
+			return
+		}
+		s.mu.Lock()
+		s.pending--
+		s.mu.Unlock()
+	}
+}
+
+// fail records the error which stopped the solutions being passed on
+func (s *spiller) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+}
+
+// finish waits for the solutions in the file to be passed on, then removes
+// the file
+func (s *spiller) finish() error {
+	s.mu.Lock()
+	s.finished = true
+	s.cond.Signal()
+	file := s.file
+	s.mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	<-s.stopped
+	file.Close()
+	os.Remove(file.Name())
+	return s.err
+}
@@ -0,0 +1,92 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSolveChan(t *testing.T) {
+	// A 2x12 board has F(13) = 233 tilings by dominoes
+	prob := dominoProblem(t, 12)
+	want, err := prob.SolveContext(context.Background())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	receive := func(s *SolutionStream) [][]string {
+		var ret [][]string
+		for soln := range s.C {
+			ret = append(ret, soln)
+		}
+		return ret
+	}
+
+	// Every solution is found before any is received, so all but the first
+	// are spilled to disk
+	found := make(chan struct{})
+	count := 0
+	s, err := prob.SolveChan(context.Background(), WithBuffer(1, SpillWhenFull), WithSolutionFunc(func([]string) {
+		if count++; count == len(want) {
+			close(found)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	<-found
+	if got := receive(s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected solutions %v in order after spilling, got %v", want, got)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+
+	// The search does not wait for the receiver, so the solutions which do
+	// not fit in the buffer are dropped
+	s, err = prob.SolveChan(context.Background(), WithBuffer(5, DropWhenFull))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if got := receive(s); !reflect.DeepEqual(got, want[:5]) || s.Dropped() != int64(len(want)-5) {
+		t.Fatalf("Expected %v with %d dropped, got %v with %d", want[:5], len(want)-5, got, s.Dropped())
+	}
+
+	s, err = prob.SolveChan(context.Background(), WithBuffer(0, BlockWhenFull), WithLimit(10))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if got := receive(s); !reflect.DeepEqual(got, want[:10]) {
+		t.Fatalf("Expected %v, got %v", want[:10], got)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+}
+
+func TestSolveChanCancel(t *testing.T) {
+	prob := dominoProblem(t, 12)
+	for _, policy := range []OverflowPolicy{SpillWhenFull, BlockWhenFull} {
+		ctx, cancel := context.WithCancel(context.Background())
+		s, err := prob.SolveChan(ctx, WithBuffer(0, policy))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		<-s.C
+		// The problem cannot be used until the search has finished
+		if _, err := prob.SolveContext(context.Background()); err == nil {
+			t.Fatalf("Expected an error solving a problem being streamed")
+		}
+		cancel()
+		for range s.C {
+		}
+		if err := s.Err(); err != context.Canceled {
+			t.Fatalf("Policy %d: expected context.Canceled, got %v", policy, err)
+		}
+		if err := prob.CheckInvariants(); err != nil {
+			t.Fatalf("Matrix not restored: %v", err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package gox
+
+import "sort"
+
+// WithSortedOutput makes the solutions come out in a canonical order, whatever
+// the heuristic, tie-break or number of workers, so that the output of a
+// search can be compared with a golden file. The rows of each solution are
+// sorted by name, and the solutions are sorted by comparing their rows in
+// turn, a solution which is a prefix of another coming first. Names compare
+// byte by byte, as strings do, so that a row named "10" comes before one
+// named "9".
+//
+// The solutions can only be sorted once they have all been found, so they are
+// held until the search finishes, and only then passed to the function given
+// to WithSolutionFunc, or sent by SolveChan. With WithLimit every solution is
+// still found, so that the n kept are the first n in the sorted order rather
+// than those the search happened to find first. It has no effect on SolveByCost and
+// SolvePage, whose order is part of their result.
+func WithSortedOutput() Option {
+	return func(c *config) {
+		c.sorted = true
+	}
+}
+
+// sortSolutions sorts the rows of each solution, then the solutions, in the
+// order of WithSortedOutput
+func sortSolutions(solns [][]string) {
+	for _, soln := range solns {
+		sort.Strings(soln)
+	}
+	sort.Slice(solns, func(i, j int) bool {
+		return lessSolution(solns[i], solns[j])
+	})
+}
+
+// lessSolution reports whether the sorted solution a comes before b
+func lessSolution(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// sortRowSolutions is sortSolutions for solutions held as rows
+func sortRowSolutions(solns [][]*rowHeader) {
+	names := make([][]string, len(solns))
+	for i, rows := range solns {
+		sort.Slice(rows, func(j, k int) bool {
+			return rows[j].label() < rows[k].label()
+		})
+		names[i] = rowNames(rows)
+	}
+	sort.Sort(rowSolutions{solns, names})
+}
+
+// rowSolutions sorts solutions held as rows by the names of their rows
+type rowSolutions struct {
+	solns [][]*rowHeader
+	names [][]string
+}
+
+func (s rowSolutions) Len() int           { return len(s.solns) }
+func (s rowSolutions) Less(i, j int) bool { return lessSolution(s.names[i], s.names[j]) }
+func (s rowSolutions) Swap(i, j int) {
+	s.solns[i], s.solns[j] = s.solns[j], s.solns[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
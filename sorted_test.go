@@ -0,0 +1,82 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSortedOutput(t *testing.T) {
+	prob := dominoProblem(t, 7)
+	want, err := prob.SolveContext(context.Background(), WithSortedOutput())
+	if err != nil || len(want) != 21 {
+		t.Fatalf("Expected 21 solutions, got %d: %v", len(want), err)
+	}
+	for i, soln := range want {
+		if !sort.StringsAreSorted(soln) {
+			t.Fatalf("Expected the rows of %v to be sorted", soln)
+		}
+		if i > 0 && strings.Join(want[i-1], " ") >= strings.Join(soln, " ") {
+			t.Fatalf("Expected %v before %v", want[i-1], soln)
+		}
+	}
+
+	// The order is the same whatever the search
+	var streamed [][]string
+	got, err := prob.SolveContext(context.Background(), WithSortedOutput(), WithHeuristic(FirstColumn), WithTieBreak(RandomTie),
+		WithSolutionFunc(func(soln []string) { streamed = append(streamed, soln) }))
+	if err != nil || !reflect.DeepEqual(got, want) || !reflect.DeepEqual(streamed, want) {
+		t.Fatalf("Expected the same order with another heuristic, got %v and %v: %v", got, streamed, err)
+	}
+	streamed = nil
+	got, err = prob.SolveParallel(context.Background(), 4, WithSortedOutput(), WithoutSolutions(),
+		WithSolutionFunc(func(soln []string) { streamed = append(streamed, soln) }))
+	if err != nil || got != nil || !reflect.DeepEqual(streamed, want) {
+		t.Fatalf("Expected the same order from SolveParallel, got %v and %v: %v", got, streamed, err)
+	}
+	stream, err := prob.SolveChan(context.Background(), WithSortedOutput(), WithHeuristic(ConflictWeighted))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	got = nil
+	for soln := range stream.C {
+		got = append(got, soln)
+	}
+	if !reflect.DeepEqual(got, want) || stream.Err() != nil {
+		t.Fatalf("Expected the same order from SolveChan, got %v: %v", got, stream.Err())
+	}
+}
+
+func TestSortedOutputLimit(t *testing.T) {
+	// With WithLimit the first solutions in the sorted order are kept,
+	// whatever the search finds first
+	prob := dominoProblem(t, 7)
+	all, err := prob.SolveContext(context.Background(), WithSortedOutput())
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	want := all[:5]
+	for _, h := range []Heuristic{MinRemaining, FirstColumn, ConflictWeighted} {
+		got, err := prob.SolveContext(context.Background(), WithSortedOutput(), WithLimit(5), WithHeuristic(h), WithTieBreak(RightmostTie))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Expected %v from SolveContext with %v, got %v: %v", want, h, got, err)
+		}
+		got, err = prob.SolveParallel(context.Background(), 4, WithSortedOutput(), WithLimit(5), WithHeuristic(h))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Expected %v from SolveParallel with %v, got %v: %v", want, h, got, err)
+		}
+		stream, err := prob.SolveChan(context.Background(), WithSortedOutput(), WithLimit(5), WithHeuristic(h))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		got = nil
+		for soln := range stream.C {
+			got = append(got, soln)
+		}
+		if !reflect.DeepEqual(got, want) || stream.Err() != nil {
+			t.Fatalf("Expected %v from SolveChan with %v, got %v: %v", want, h, got, stream.Err())
+		}
+	}
+}
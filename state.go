@@ -0,0 +1,108 @@
+package gox
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// State is the phase of a problem's life, as reported by State. A problem is
+// Building until its constructor returns, then Ready. Each call which
+// searches the matrix makes it Solving until the call returns, then Solved,
+// unless the search was cut short by a panic, or by runtime.Goexit from a
+// function passed to WithSolutionFunc or WithTrace, which leaves the links of
+// the matrix as they were at that moment; a panic is returned as an
+// *InternalError. Such a problem is Failed for good, and every call which
+// would give it rows or solve it returns a *StateError, rather than searching
+// the corrupted matrix. Giving rows does not change the state.
+type State int32
+
+const (
+	Building State = iota
+	Ready
+	Solving
+	Solved
+	Failed
+)
+
+var stateNames = [...]string{"building", "ready", "solving", "solved", "failed"}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("State(%d)", int32(s))
+	}
+	return stateNames[s]
+}
+
+// StateError is returned when a problem is given rows or solved in a state
+// which does not allow it: while it is being built, or once a search has
+// failed. Calls made while another is running return a *ConcurrentUseError
+// instead.
+type StateError struct {
+	// Op is the call which was refused
+	Op    string
+	State State
+}
+
+func (e *StateError) Error() string {
+	if e.State == Failed {
+		return fmt.Sprintf("%s called on a problem whose search did not finish, leaving its matrix inconsistent", e.Op)
+	}
+	return fmt.Sprintf("%s called on a problem which is %s", e.Op, e.State)
+}
+
+// State returns the phase the problem is in. It may be called at any time,
+// including from another goroutine during a search.
+func (p *exactCoverProblem) State() State {
+	return State(atomic.LoadInt32(&p.state))
+}
+
+// setState moves the problem to state s
+func (p *exactCoverProblem) setState(s State) {
+	atomic.StoreInt32(&p.state, int32(s))
+}
+
+// checkState returns a *StateError if the problem may not be used by the
+// call op, see acquire
+func (p *exactCoverProblem) checkState(op string) error {
+	if s := p.State(); s == Building || s == Failed {
+		return &StateError{Op: op, State: s}
+	}
+	return nil
+}
+
+// startSolve is acquire for the calls which search the matrix, making the
+// problem Solving until finishSolve
+func (p *exactCoverProblem) startSolve(op string) error {
+	if err := p.acquire(op); err != nil {
+		return err
+	}
+	p.solveDepth = len(p.solutionRows)
+	p.column = nil
+	p.setState(Solving)
+	return nil
+}
+
+// finishSolve is release for startSolve. Deferred, it tells when the search
+// was cut short, by a panic or by rows left in the working solution, and
+// marks the problem Failed. A panic is returned in *err as an
+// *InternalError, or resumed with it if err is nil.
+func (p *exactCoverProblem) finishSolve(err *error) {
+	r := recover()
+	var ierr *InternalError
+	if r != nil {
+		op, _ := p.running.Load().(string)
+		ierr = p.internalError(op, r)
+	}
+	if r != nil || len(p.solutionRows) != p.solveDepth {
+		p.setState(Failed)
+	} else {
+		p.setState(Solved)
+	}
+	p.release()
+	if ierr != nil {
+		if err == nil {
+			panic(ierr)
+		}
+		*err = ierr
+	}
+}
@@ -0,0 +1,58 @@
+package gox
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	ctx := context.Background()
+	prob := dominoProblem(t, 4)
+	if s := prob.State(); s != Ready {
+		t.Fatalf("Expected a new problem to be ready, got %v", s)
+	}
+	var during State
+	if _, err := prob.SolveContext(ctx, WithSolutionFunc(func([]string) { during = prob.State() })); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if during != Solving || prob.State() != Solved {
+		t.Fatalf("Expected solving during the search and solved after it, got %v and %v", during, prob.State())
+	}
+	// Giving rows leaves the state alone
+	if err := prob.RowIsSolution("v0"); err != nil || prob.State() != Solved {
+		t.Fatalf("Expected the problem to stay solved giving a row, got %v, %v", prob.State(), err)
+	}
+
+	// A search cut short by a panic leaves the problem failed
+	if _, err := prob.SolveContext(ctx, WithSolutionFunc(func([]string) { panic("boom") })); err == nil {
+		t.Fatalf("Expected an error from a search which panicked")
+	}
+	if s := prob.State(); s != Failed {
+		t.Fatalf("Expected the problem to have failed, got %v", s)
+	}
+	if serr, ok := prob.RowIsSolution("v1").(*StateError); !ok || serr.Op != "RowIsSolution" || serr.State != Failed {
+		t.Fatalf("Expected a *StateError giving a row, got %v", serr)
+	}
+	if _, err := prob.CountSolutions(ctx); err == nil {
+		t.Fatalf("Expected an error solving a failed problem")
+	}
+	if solns := prob.Solve(); solns != nil {
+		t.Fatalf("Expected no solutions from a failed problem, got %v", solns)
+	}
+	if serr, ok := prob.Err().(*StateError); !ok || serr.Op != "Solve" {
+		t.Fatalf("Expected Err to return a *StateError from Solve on a failed problem, got %v", prob.Err())
+	}
+
+	// So does one cut short by runtime.Goexit
+	prob = dominoProblem(t, 4)
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		prob.SolveContext(ctx, WithSolutionFunc(func([]string) { runtime.Goexit() }))
+	}()
+	<-done
+	if s := prob.State(); s != Failed {
+		t.Fatalf("Expected the problem to have failed, got %v", s)
+	}
+}
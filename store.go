@@ -0,0 +1,178 @@
+package gox
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// SolutionStore collects the solutions found by a search, see WithStore. A
+// store decides what is kept of each solution, so that a search which only
+// needs the number of solutions, or which writes them out, never pays to hold
+// them in memory.
+type SolutionStore interface {
+	// Add stores a solution, the names of its rows, which the search does
+	// not use again. An error stops the search, and is returned by the call
+	// which made it.
+	Add(soln []string) error
+}
+
+// WithStore passes each solution found to s rather than returning it, as if
+// WithoutSolutions were given. Given with WithSolutionFunc, the solution is
+// passed to s first. SliceStore, CountStore, SpillStore and SolutionWriter
+// are stores, and callers may plug in their own.
+func WithStore(s SolutionStore) Option {
+	return func(c *config) {
+		c.store = s
+	}
+}
+
+// addStore passes the solutions to the store given by WithStore, before the
+// function given by WithSolutionFunc. The first error adding a solution stops
+// the search.
+func (c *config) addStore() {
+	if c.store == nil {
+		return
+	}
+	c.discard = true
+	f := c.onSolution
+	c.onSolution = func(soln []string) {
+		if c.err == nil {
+			c.err = c.store.Add(soln)
+		}
+		if f != nil {
+			f(soln)
+		}
+	}
+}
+
+// SliceStore keeps every solution in memory, as SolveContext does
+type SliceStore struct {
+	Solutions [][]string
+}
+
+// Add appends a solution to Solutions
+func (s *SliceStore) Add(soln []string) error {
+	s.Solutions = append(s.Solutions, soln)
+	return nil
+}
+
+// CountStore counts the solutions without keeping any of them
+type CountStore struct {
+	Count int64
+}
+
+// Add counts a solution
+func (s *CountStore) Add(soln []string) error {
+	s.Count++
+	return nil
+}
+
+// Add writes a solution like Write, so that a SolutionWriter may be passed to
+// WithStore, returning the error if writing fails
+func (s *SolutionWriter) Add(soln []string) error {
+	s.Write(soln)
+	return s.err
+}
+
+// SpillStore keeps the first solutions in memory, writing the rest to a
+// temporary file, so that enumerations too large to hold in memory can still
+// be collected and read back in the order they were found. Close removes the
+// file once the solutions are no longer needed.
+type SpillStore struct {
+	dir string
+	max int
+	mem [][]string
+	// file holds the solutions beyond the first max as JSON lines, or is
+	// nil if there are none
+	file  *os.File
+	w     *bufio.Writer
+	count int64
+}
+
+// NewSpillStore creates a store keeping up to max solutions in memory, and
+// writing the rest to a file created in dir, or the default directory for
+// temporary files if dir is empty
+func NewSpillStore(dir string, max int) *SpillStore {
+	return &SpillStore{dir: dir, max: max}
+}
+
+// Add stores a solution, in memory while there is room and in the file
+// otherwise
+func (s *SpillStore) Add(soln []string) error {
+	if len(s.mem) < s.max {
+		s.mem = append(s.mem, soln)
+		s.count++
+		return nil
+	}
+	if s.file == nil {
+		f, err := os.CreateTemp(s.dir, "gox-store-*.jsonl")
+		if err != nil {
+			return err
+		}
+		s.file, s.w = f, bufio.NewWriter(f)
+	}
+	b, err := json.Marshal(soln)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// Len returns the number of solutions stored
+func (s *SpillStore) Len() int64 {
+	return s.count
+}
+
+// Each calls f with each solution stored, in the order they were added,
+// stopping at the first error, which is returned
+func (s *SpillStore) Each(f func([]string) error) error {
+	for _, soln := range s.mem {
+		if err := f(soln); err != nil {
+			return err
+		}
+	}
+	if s.file == nil {
+		return nil
+	}
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	r, err := os.Open(s.file.Name())
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		var soln []string
+		if err := json.Unmarshal(scanner.Bytes(), &soln); err != nil {
+			return err
+		}
+		if err := f(soln); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Close removes the file holding the solutions, after which the store is
+// empty
+func (s *SpillStore) Close() error {
+	s.mem, s.count = nil, 0
+	if s.file == nil {
+		return nil
+	}
+	f := s.file
+	s.file, s.w = nil, nil
+	err := f.Close()
+	if rmErr := os.Remove(f.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
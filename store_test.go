@@ -0,0 +1,94 @@
+package gox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// failingStore fails to add solutions once it holds n
+type failingStore struct {
+	SliceStore
+	n int
+}
+
+var errStoreFull = errors.New("store full")
+
+func (s *failingStore) Add(soln []string) error {
+	if len(s.Solutions) >= s.n {
+		return errStoreFull
+	}
+	return s.SliceStore.Add(soln)
+}
+
+func TestStores(t *testing.T) {
+	prob := dominoProblem(t, 6)
+	want, err := prob.SolveContext(context.Background())
+	if err != nil || len(want) != 13 {
+		t.Fatalf("Expected 13 solutions, got %d: %v", len(want), err)
+	}
+
+	var slice SliceStore
+	var count CountStore
+	var called int
+	solns, err := prob.SolveContext(context.Background(), WithStore(&slice), WithSolutionFunc(func([]string) { called++ }))
+	if err != nil || solns != nil || called != 13 || !reflect.DeepEqual(slice.Solutions, want) {
+		t.Fatalf("Unexpected solutions stored: %v %v %d %v", slice.Solutions, solns, called, err)
+	}
+	if _, err := prob.SolveParallel(context.Background(), 4, WithStore(&count)); err != nil || count.Count != 13 {
+		t.Fatalf("Expected 13 solutions counted, got %d: %v", count.Count, err)
+	}
+
+	// An error adding a solution stops the search
+	for _, solve := range []func(...Option) ([][]string, error){
+		func(opts ...Option) ([][]string, error) { return prob.SolveContext(context.Background(), opts...) },
+		func(opts ...Option) ([][]string, error) { return prob.SolveParallel(context.Background(), 4, opts...) },
+	} {
+		failing := &failingStore{n: 3}
+		if _, err := solve(WithStore(failing)); err != errStoreFull || !reflect.DeepEqual(failing.Solutions, want[:3]) {
+			t.Fatalf("Expected the store to fail after 3 solutions, got %v: %v", failing.Solutions, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	sw := NewSolutionWriter(&buf, prob)
+	if _, err := prob.SolveContext(context.Background(), WithStore(sw)); err != nil || strings.Count(buf.String(), "\n") != 13 {
+		t.Fatalf("Expected 13 solutions written, got %q: %v", buf.String(), err)
+	}
+}
+
+func TestSpillStore(t *testing.T) {
+	prob := dominoProblem(t, 6)
+	want, _ := prob.SolveContext(context.Background())
+	dir := t.TempDir()
+	s := NewSpillStore(dir, 4)
+	if _, err := prob.SolveContext(context.Background(), WithStore(s)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	var got [][]string
+	if err := s.Each(func(soln []string) error { got = append(got, soln); return nil }); err != nil {
+		t.Fatalf("Error reading solutions: %v", err)
+	}
+	if s.Len() != 13 || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %d solutions %v", want, s.Len(), got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("Expected the solutions to spill to a file, got %d files", len(files))
+	}
+
+	// Each stops at the first error
+	calls := 0
+	if err := s.Each(func([]string) error { calls++; return errStoreFull }); err != errStoreFull || calls != 1 {
+		t.Fatalf("Expected Each to stop, got %d calls: %v", calls, err)
+	}
+	if err := s.Close(); err != nil || s.Len() != 0 {
+		t.Fatalf("Error closing store: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("Expected the file to be removed, got %d files", len(files))
+	}
+}
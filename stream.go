@@ -0,0 +1,183 @@
+package gox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewExactCoverProblemReader creates an exact cover problem from the rows read
+// from r, linking each row into the matrix as soon as it is read, so that
+// instances too large to hold twice in memory can still be solved. Only the
+// line being read is held besides the problem itself.
+//
+// The input is in the format used by Knuth's dlx and xcc programs, as read by
+// format.ReadDLX: the first line lists the primary columns, then "|" and the
+// secondary columns, and each following line lists the items of a row, lines
+// starting with "|" being comments. An item is either the name of a column,
+// or for secondary columns, the name followed by a colon and a colour. Each
+// row is named after its items separated by single spaces, e.g. "a s:A",
+// including any ignored by LenientValidation.
+//
+// WithMergeDuplicates and WithDominatedRows need every row before any is
+// added, so are refused.
+func NewExactCoverProblemReader(r io.Reader, opts ...ProblemOption) (*exactCoverProblem, error) {
+	ret := &exactCoverProblem{}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	ret.rowsByName = make(map[string]*rowHeader, ret.rowsHint)
+	if ret.mergeDuplicates || ret.removeDominated {
+		return nil, fmt.Errorf("Rows cannot be merged or removed as dominated when read as a stream")
+	}
+	span := ret.startBuild()
+	if err := ret.readRows(r); err != nil {
+		ret.endBuild(span, err)
+		return nil, err
+	}
+	ret.endBuild(span, nil)
+	ret.setState(Ready)
+	return ret, nil
+}
+
+// readRows reads the columns and rows of NewExactCoverProblemReader from r
+func (p *exactCoverProblem) readRows(r io.Reader) error {
+	if p.forceRows {
+		p.report = &PreprocessReport{
+			DuplicateRows:    make(map[string][]string),
+			DuplicateColumns: make(map[string][]string),
+			Dominated:        make(map[string]string),
+		}
+	}
+
+	var colsByName map[string]int
+	colorsByName := make(map[string]int)
+	// seen holds the line of the last row covering each column, to find
+	// columns repeated in a row without allocating for each row
+	var seen []int
+	lenient := p.validation == LenientValidation
+	defer p.dropReserved()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "|") {
+			continue
+		}
+
+		if colsByName == nil {
+			var err error
+			if colsByName, err = p.readColumns(fields); err != nil {
+				return fmt.Errorf("%v: line %d", err, line)
+			}
+			seen = make([]int, p.numCols)
+			continue
+		}
+
+		row := sparseRow{name: p.names.join(fields), cols: make([]int, 0, len(fields))}
+		colored := false
+		for _, item := range fields {
+			colName, color := item, ""
+			if j := strings.Index(item, ":"); j >= 0 {
+				colName, color = item[:j], item[j+1:]
+			}
+			// The items LenientValidation ignores are skipped as by Builder
+			col, ok := colsByName[colName]
+			switch {
+			case !ok && lenient:
+				p.warn("Row %s refers to unknown column %s, which is ignored: line %d", row.name, colName, line)
+				continue
+			case !ok:
+				return fmt.Errorf("Row refers to unknown column %s: line %d", colName, line)
+			case seen[col] == line && lenient:
+				p.warn("Row %s contains column %s more than once, which is ignored: line %d", row.name, colName, line)
+				continue
+			case seen[col] == line:
+				return fmt.Errorf("Row contains column %s more than once: line %d", colName, line)
+			case color != "" && col < p.numPrimary && lenient:
+				p.warn("Row %s gives a colour to primary column %s, which is ignored: line %d", row.name, colName, line)
+				color = ""
+			case color != "" && col < p.numPrimary:
+				return fmt.Errorf("Row gives a colour to primary column %s: line %d", colName, line)
+			}
+			seen[col] = line
+			row.cols = append(row.cols, col)
+			if color == "" {
+				continue
+			}
+			if row.colors == nil {
+				row.colors = make([]int, len(fields))
+			}
+			value := colorsByName[color]
+			if value == 0 {
+				value = len(colorsByName) + 1
+				colorsByName[color] = value
+				p.colorNames = append(p.colorNames, color)
+			}
+			row.colors[len(row.cols)-1] = value
+			colored = true
+		}
+		if colored {
+			row.colors = row.colors[:len(row.cols)]
+		} else {
+			row.colors = nil
+		}
+		if err := p.addRow(row); err != nil {
+			return fmt.Errorf("%v: line %d", err, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if colsByName == nil {
+		return fmt.Errorf("Input must have a line listing the columns")
+	}
+	p.numRows = len(p.rowHeaders)
+	if err := p.checkStructure(); err != nil {
+		return err
+	}
+	if p.forceRows {
+		span := startSpan(p.traceCtx, p.tracer, "gox.preprocess", Attribute{"gox.rows", p.numRows})
+		p.force()
+		p.endPreprocess(span)
+	}
+	return nil
+}
+
+// readColumns creates the columns listed by the first line of the input of
+// NewExactCoverProblemReader, returning the index of each by name
+func (p *exactCoverProblem) readColumns(fields []string) (map[string]int, error) {
+	var primary, secondary []string
+	for _, f := range fields {
+		switch {
+		case f == "|" && secondary == nil:
+			secondary = []string{}
+		case strings.Contains(f, ":"):
+			return nil, fmt.Errorf("Column names must not contain ':': %s", f)
+		case secondary != nil:
+			secondary = append(secondary, f)
+		default:
+			primary = append(primary, f)
+		}
+	}
+	p.colNames = append(primary, secondary...)
+	p.numCols = len(p.colNames)
+	p.numPrimary = len(primary)
+	ret := make(map[string]int, p.numCols)
+	for i, name := range p.colNames {
+		if _, ok := ret[name]; ok {
+			return nil, fmt.Errorf("Duplicate column name present: %s", name)
+		}
+		ret[name] = i
+	}
+
+	// Create root, ensure the column index is invalid
+	p.root = &node{colIndex: -1}
+	p.root.right = p.root
+	p.root.left = p.root
+	p.allocateColHeaders()
+	p.initializeColHeaders()
+	p.reserve(p.rowsHint, p.cellsHint)
+	return ret, nil
+}
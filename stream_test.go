@@ -0,0 +1,120 @@
+package gox_test
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+	"github.com/ifross89/gox/internal/testutil"
+	"github.com/ifross89/gox/testgen"
+)
+
+func TestNewExactCoverProblemReader(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      rng.Intn(30),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		in := &format.Instance{Primary: inst.Primary, Secondary: inst.Secondary}
+		for _, r := range inst.Rows {
+			// Rows covering no columns cannot be written in this format
+			if len(r.Items) > 0 {
+				in.Rows = append(in.Rows, format.Row{Name: r.Name, Items: r.Items})
+			}
+		}
+		var buf bytes.Buffer
+		if err := format.WriteDLX(&buf, in); err != nil {
+			t.Fatalf("Error writing instance: %v", err)
+		}
+		text := buf.String()
+
+		read, err := format.ReadDLX(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("Error reading instance: %v", err)
+		}
+		want, wantErr := read.Problem()
+		got, err := gox.NewExactCoverProblemReader(strings.NewReader(text), gox.WithDebug())
+		if wantErr != nil {
+			// Rows covering the same columns have the same name
+			if err == nil {
+				t.Fatalf("Trial %d: expected error %v", trial, wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Trial %d: error streaming problem: %v", trial, err)
+		}
+		if w, g := want.(interface{ Fingerprint() string }).Fingerprint(), got.Fingerprint(); w != g {
+			t.Fatalf("Trial %d: expected fingerprint %s, got %s", trial, w, g)
+		}
+		wantSolns, err := want.SolveContext(context.Background())
+		if _, ok := err.(*gox.UncoverableError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		gotSolns, err := got.SolveContext(context.Background())
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		if !reflect.DeepEqual(testutil.Canonical(gotSolns), testutil.Canonical(wantSolns)) {
+			t.Fatalf("Trial %d: expected %v, got %v", trial, wantSolns, gotSolns)
+		}
+	}
+}
+
+func TestNewExactCoverProblemReaderErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"| only a comment\n",
+		"a a\n",
+		"a b:c\n",
+		"a | s\nb\n",
+		"a | s\na a\n",
+		"a | s\na:A\n",
+	} {
+		if _, err := gox.NewExactCoverProblemReader(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error reading %q", text)
+		}
+	}
+	if _, err := gox.NewExactCoverProblemReader(strings.NewReader("a\na\n"), gox.WithMergeDuplicates()); err == nil {
+		t.Errorf("Expected an error merging duplicates")
+	}
+}
+
+func TestNewExactCoverProblemReaderLenient(t *testing.T) {
+	text := "a b | s\na zz\nb b\na:red s:A\nzz\nb\n"
+	prob, err := gox.NewExactCoverProblemReader(strings.NewReader(text), gox.WithValidation(gox.LenientValidation))
+	if err != nil {
+		t.Fatalf("Error reading problem: %v", err)
+	}
+	if rows := prob.Rows(); !reflect.DeepEqual(rows, []string{"a zz", "b b", "a:red s:A", "b"}) {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+	want := []string{
+		"Row a zz refers to unknown column zz, which is ignored: line 2",
+		"Row b b contains column b more than once, which is ignored: line 3",
+		"Row a:red s:A gives a colour to primary column a, which is ignored: line 4",
+		"Row zz refers to unknown column zz, which is ignored: line 5",
+		"Row zz covers no columns, so is dropped",
+	}
+	if got := prob.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected warnings %q, got %q", want, got)
+	}
+	if solns := prob.Solve(); len(solns) != 4 {
+		t.Fatalf("Expected 4 solutions, got %v", solns)
+	}
+}
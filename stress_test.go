@@ -0,0 +1,52 @@
+package gox_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/testgen"
+)
+
+var stressNodes = flag.Int64("stress.nodes", 1<<20, "number of nodes in the instance of TestStressMemory, which takes about 200 bytes of heap for each")
+
+// stressBytesPerNode is the most heap TestStressMemory allows for each node of
+// a problem read with its capacity hinted, in rows of four columns with as
+// many columns as rows. About 135 bytes are kept once the problem is built:
+// 72 for the node, and a quarter of the header, name and table entry of its
+// row and of its column. The rest of the peak is garbage left by the reader.
+const stressBytesPerNode = 240
+
+func TestStressMemory(t *testing.T) {
+	cfg := testgen.StressNodes(*stressNodes, 4)
+	r, err := testgen.Stress(cfg)
+	if err != nil {
+		t.Fatalf("Error generating instance: %v", err)
+	}
+	var prob gox.Solver
+	var solveErr error
+	peak := testgen.PeakHeap(func() {
+		prob, err = gox.NewExactCoverProblemReader(r, gox.WithCapacity(cfg.Rows, int(cfg.Nodes())))
+		if err != nil {
+			return
+		}
+		// The search need not finish, only show that it adds little
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, solveErr = prob.SolveContext(ctx, gox.WithLimit(1), gox.WithoutSolutions())
+	})
+	if err != nil {
+		t.Fatalf("Error reading instance: %v", err)
+	}
+	if solveErr != nil && !errors.Is(solveErr, context.DeadlineExceeded) {
+		t.Fatalf("Error solving instance: %v", solveErr)
+	}
+	perNode := float64(peak) / float64(cfg.Nodes())
+	t.Logf("%d nodes took %d bytes at peak, %.1f bytes per node", cfg.Nodes(), peak, perNode)
+	if perNode > stressBytesPerNode {
+		t.Fatalf("Expected at most %d bytes per node, got %.1f", stressBytesPerNode, perNode)
+	}
+}
@@ -0,0 +1,119 @@
+package sudoku
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// The figures of a grid draw the digits given in the puzzle in black and the
+// digits found in blue, so that a solution can be shown beside its puzzle.
+var (
+	clueColor  = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	foundColor = color.RGBA{0x1f, 0x4e, 0xb4, 0xff}
+)
+
+// WriteSVG draws the grid as an SVG figure with cells of cell units, the
+// boxes outlined more heavily than the cells. The digits also in clues, such
+// as the puzzle the grid solves, are drawn in black and the others in blue;
+// clues may be the zero Grid.
+func (g Grid) WriteSVG(w io.Writer, clues Grid, cell float64) error {
+	side := cell * Size
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif" font-size="%g">`+"\n", side+2, side+2, side+2, side+2, cell*0.6)
+	fmt.Fprintf(bw, `<g transform="translate(1 1)">`+"\n"+`<rect width="%g" height="%g" fill="white"/>`+"\n", side, side)
+	for i := 0; i <= Size; i++ {
+		width := 1
+		if i%BoxSize == 0 {
+			width = 2
+		}
+		pos := float64(i) * cell
+		fmt.Fprintf(bw, `<line x1="%g" y1="0" x2="%g" y2="%g" stroke="black" stroke-width="%d"/>`+"\n", pos, pos, side, width)
+		fmt.Fprintf(bw, `<line x1="0" y1="%g" x2="%g" y2="%g" stroke="black" stroke-width="%d"/>`+"\n", pos, side, pos, width)
+	}
+	for r := range g {
+		for c, d := range g[r] {
+			if d == 0 {
+				continue
+			}
+			fill := foundColor
+			if clues[r][c] == d {
+				fill = clueColor
+			}
+			fmt.Fprintf(bw, `<text x="%g" y="%g" text-anchor="middle" dominant-baseline="central" fill="#%02x%02x%02x">%d</text>`+"\n",
+				(float64(c)+0.5)*cell, (float64(r)+0.5)*cell, fill.R, fill.G, fill.B, d)
+		}
+	}
+	fmt.Fprintln(bw, "</g>\n</svg>")
+	return bw.Flush()
+}
+
+// glyphs draws the digits 1 to 9 in 3 by 5 pixels, for images drawn without a
+// font
+var glyphs = [Size + 1][5]string{
+	1: {".#.", "##.", ".#.", ".#.", "###"},
+	2: {"##.", "..#", ".#.", "#..", "###"},
+	3: {"##.", "..#", ".#.", "..#", "##."},
+	4: {"#.#", "#.#", "###", "..#", "..#"},
+	5: {"###", "#..", "##.", "..#", "##."},
+	6: {".##", "#..", "###", "#.#", "###"},
+	7: {"###", "..#", ".#.", ".#.", ".#."},
+	8: {"###", "#.#", "###", "#.#", "###"},
+	9: {"###", "#.#", "###", "..#", "##."},
+}
+
+// WritePNG draws the grid as a PNG image like WriteSVG, with cells of cell
+// pixels, which should be at least 10 for the digits to be legible
+func (g Grid) WritePNG(w io.Writer, clues Grid, cell int) error {
+	side := cell*Size + 1
+	img := image.NewRGBA(image.Rect(0, 0, side, side))
+	fill := func(x0, y0, x1, y1 int, c color.RGBA) {
+		for y := y0; y < y1 && y < side; y++ {
+			for x := x0; x < x1 && x < side; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	fill(0, 0, side, side, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	for i := 0; i <= Size; i++ {
+		width := 1
+		if i%BoxSize == 0 {
+			width = 2
+		}
+		pos := i * cell
+		if pos+width > side {
+			pos = side - width
+		}
+		fill(pos, 0, pos+width, side, clueColor)
+		fill(0, pos, side, pos+width, clueColor)
+	}
+	// The digits are scaled to about half the height of a cell and centred
+	scale := cell / 10
+	if scale < 1 {
+		scale = 1
+	}
+	for r := range g {
+		for c, d := range g[r] {
+			if d < 1 || d > Size {
+				continue
+			}
+			col := foundColor
+			if clues[r][c] == d {
+				col = clueColor
+			}
+			x0 := c*cell + (cell-3*scale)/2
+			y0 := r*cell + (cell-5*scale)/2
+			for y, line := range glyphs[d] {
+				for x, px := range line {
+					if px == '#' {
+						fill(x0+x*scale, y0+y*scale, x0+(x+1)*scale, y0+(y+1)*scale, col)
+					}
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
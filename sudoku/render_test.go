@@ -0,0 +1,97 @@
+package sudoku
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func TestWriteSVG(t *testing.T) {
+	g, err := Parse(puzzles[0])
+	if err != nil {
+		t.Fatalf("Error parsing puzzle: %v", err)
+	}
+	solns, err := Solve(g)
+	if err != nil || len(solns) != 1 {
+		t.Fatalf("Expected a solution, got %v: %v", solns, err)
+	}
+	var buf bytes.Buffer
+	if err := solns[0].WriteSVG(&buf, g, 30); err != nil {
+		t.Fatalf("Error writing figure: %v", err)
+	}
+
+	// Every cell has a digit, the clues in black
+	texts, black := 0, 0
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Figure is not well formed: %v", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "text" {
+			texts++
+			for _, a := range start.Attr {
+				if a.Name.Local == "fill" && a.Value == "#000000" {
+					black++
+				}
+			}
+		}
+	}
+	if texts != Size*Size || black != g.Clues() {
+		t.Fatalf("Expected %d digits, %d in black, got %d and %d", Size*Size, g.Clues(), texts, black)
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	g, err := Parse(puzzles[0])
+	if err != nil {
+		t.Fatalf("Error parsing puzzle: %v", err)
+	}
+	solns, err := Solve(g)
+	if err != nil || len(solns) != 1 {
+		t.Fatalf("Expected a solution, got %v: %v", solns, err)
+	}
+	var buf bytes.Buffer
+	if err := solns[0].WritePNG(&buf, g, 20); err != nil {
+		t.Fatalf("Error writing image: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Error decoding image: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 181 || b.Dy() != 181 {
+		t.Fatalf("Expected a 181 pixel square, got %v", b)
+	}
+
+	// Each cell holds a digit, in the colour of a clue or a digit found
+	for r := 0; r < Size; r++ {
+		for c := 0; c < Size; c++ {
+			want := foundColor
+			if g[r][c] != 0 {
+				want = clueColor
+			}
+			if !cellHas(img, r, c, 20, want) {
+				t.Fatalf("Expected the digit at %d,%d in %v", r, c, want)
+			}
+		}
+	}
+}
+
+// cellHas reports whether the inside of a cell of an image has a pixel of the
+// colour given
+func cellHas(img image.Image, r, c, cell int, want color.RGBA) bool {
+	for y := r*cell + 3; y < (r+1)*cell-2; y++ {
+		for x := c*cell + 3; x < (c+1)*cell-2; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+// Package sudoku solves sudoku puzzles using the exact cover solver in gox.
+//
+// Every sudoku shares the same exact cover problem: each cell must hold one
+// digit, and each digit must appear once in each row, column and box, giving
+// 324 primary columns. The 729 rows place each digit in each cell. A puzzle's
+// given digits are applied to the problem with ApplyGivens, so that the
+// solver only searches for the remaining digits. Generate creates random
+// puzzles with a unique solution.
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/ifross89/gox"
+)
+
+const (
+	// Size is the number of rows, columns and digits of the grid
+	Size = 9
+	// BoxSize is the number of rows and columns of each box
+	BoxSize = 3
+)
+
+// Grid holds the digits of a sudoku by row then column. Empty cells are zero.
+type Grid [Size][Size]int
+
+// Parse reads a grid from a string listing the cells row by row. Each cell is
+// a digit, or '.' or '0' for an empty cell. Any other characters, such as
+// spaces and new lines, are ignored, so both
+//
+//	4.....8.5.3..........7......2.....6.....8.4......1.......6.3.7.5..2.....1.4......
+//
+// and the same digits laid out in 9 lines are accepted.
+func Parse(s string) (Grid, error) {
+	var g Grid
+	n := 0
+	for _, c := range s {
+		if c != '.' && (c < '0' || c > '9') {
+			continue
+		}
+		if n == Size*Size {
+			return g, fmt.Errorf("Grid has more than %d cells", Size*Size)
+		}
+		if c != '.' {
+			g[n/Size][n%Size] = int(c - '0')
+		}
+		n++
+	}
+	if n != Size*Size {
+		return g, fmt.Errorf("Grid must have %d cells, got %d", Size*Size, n)
+	}
+	return g, nil
+}
+
+// String returns the grid as 9 lines of digits, with '.' for empty cells
+func (g Grid) String() string {
+	lines := make([]string, Size)
+	for r := range g {
+		row := make([]byte, Size)
+		for c, d := range g[r] {
+			row[c] = '.'
+			if d != 0 {
+				row[c] = byte('0' + d)
+			}
+		}
+		lines[r] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Clues returns the number of digits given in the grid
+func (g Grid) Clues() int {
+	n := 0
+	for r := range g {
+		for _, d := range g[r] {
+			if d != 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// box returns the index of the box containing a cell
+func box(r, c int) int {
+	return r/BoxSize*BoxSize + c/BoxSize
+}
+
+// Check reports an error if a cell holds a value which is not a digit, or a
+// digit appears more than once in a row, column or box
+func (g Grid) Check() error {
+	var rows, cols, boxes [Size][Size + 1]bool
+	for r := range g {
+		for c, d := range g[r] {
+			if d == 0 {
+				continue
+			}
+			if d < 0 || d > Size {
+				return fmt.Errorf("Invalid digit at r%dc%d: %d", r+1, c+1, d)
+			}
+			b := box(r, c)
+			if rows[r][d] || cols[c][d] || boxes[b][d] {
+				return fmt.Errorf("Digit %d at r%dc%d repeats a digit in its row, column or box", d, r+1, c+1)
+			}
+			rows[r][d], cols[c][d], boxes[b][d] = true, true, true
+		}
+	}
+	return nil
+}
+
+// RowName is the name of the row of the problem placing digit d in a cell,
+// with rows and columns numbered from 1 as is usual for sudoku, e.g. "r1c2=3"
+func RowName(r, c, d int) string {
+	return fmt.Sprintf("r%dc%d=%d", r+1, c+1, d)
+}
+
+// Problem creates the exact cover problem for the puzzle, with the given
+// digits already applied
+func Problem(g Grid) (gox.Solver, error) {
+	if err := g.Check(); err != nil {
+		return nil, err
+	}
+
+	b := gox.NewBuilder()
+	for i := 0; i < Size; i++ {
+		for j := 0; j < Size; j++ {
+			if err := b.AddColumns(
+				fmt.Sprintf("r%dc%d", i+1, j+1),
+				fmt.Sprintf("r%d#%d", i+1, j+1),
+				fmt.Sprintf("c%d#%d", i+1, j+1),
+				fmt.Sprintf("b%d#%d", i+1, j+1),
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for r := 0; r < Size; r++ {
+		for c := 0; c < Size; c++ {
+			for d := 1; d <= Size; d++ {
+				if err := b.AddRow(RowName(r, c, d),
+					fmt.Sprintf("r%dc%d", r+1, c+1),
+					fmt.Sprintf("r%d#%d", r+1, d),
+					fmt.Sprintf("c%d#%d", c+1, d),
+					fmt.Sprintf("b%d#%d", box(r, c)+1, d),
+				); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	var givens []string
+	for r := range g {
+		for c, d := range g[r] {
+			if d != 0 {
+				givens = append(givens, RowName(r, c, d))
+			}
+		}
+	}
+	if err := prob.ApplyGivens(givens); err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Decode returns the grid filled by a solution to a puzzle's problem
+func Decode(solution []string) (Grid, error) {
+	var g Grid
+	for _, name := range solution {
+		var r, c, d int
+		if _, err := fmt.Sscanf(name, "r%dc%d=%d", &r, &c, &d); err != nil {
+			return g, fmt.Errorf("Invalid row name %s: %v", name, err)
+		}
+		if r < 1 || r > Size || c < 1 || c > Size || d < 1 || d > Size {
+			return g, fmt.Errorf("Invalid row name %s", name)
+		}
+		g[r-1][c-1] = d
+	}
+	return g, nil
+}
+
+// Solve finds every solution to the puzzle
+func Solve(g Grid) ([]Grid, error) {
+	prob, err := Problem(g)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Grid
+	for _, soln := range prob.Solve() {
+		filled, err := Decode(soln)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, filled)
+	}
+	return ret, nil
+}
+
+// MinClues is the fewest clues a puzzle with a unique solution can have
+const MinClues = 17
+
+// unique reports whether the puzzle has exactly one solution, stopping the
+// search as soon as a second is found
+func unique(g Grid) (bool, error) {
+	prob, err := Problem(g)
+	if err != nil {
+		return false, err
+	}
+	solns, err := prob.SolveContext(context.Background(), gox.WithLimit(2))
+	if err != nil {
+		return false, err
+	}
+	return len(solns) == 1, nil
+}
+
+// permutation returns a random permutation of the rows or columns of the
+// grid which keeps each within its band or stack
+func permutation(rng *rand.Rand) []int {
+	ret := make([]int, 0, Size)
+	for _, b := range rng.Perm(BoxSize) {
+		for _, i := range rng.Perm(BoxSize) {
+			ret = append(ret, b*BoxSize+i)
+		}
+	}
+	return ret
+}
+
+// Generate creates a random puzzle with the given number of clues and a
+// unique solution, using rng as the source of randomness so that puzzles can
+// be reproduced from a seed.
+//
+// A solved grid is made by shuffling the digits, rows and columns of a fixed
+// grid, then clues are removed in a random order while the solution stays
+// unique. Removing clues in this way rarely gets below 25, so an error is
+// returned if there are still too many clues once every cell has been tried.
+func Generate(rng *rand.Rand, clues int) (Grid, error) {
+	var g Grid
+	if clues < MinClues || clues > Size*Size {
+		return g, fmt.Errorf("Number of clues must be between %d and %d: %d", MinClues, Size*Size, clues)
+	}
+
+	digits := rng.Perm(Size)
+	rows, cols := permutation(rng), permutation(rng)
+	transpose := rng.Intn(2) == 1
+	for r := 0; r < Size; r++ {
+		for c := 0; c < Size; c++ {
+			i, j := rows[r], cols[c]
+			if transpose {
+				i, j = j, i
+			}
+			g[r][c] = digits[(i*BoxSize+i/BoxSize+j)%Size] + 1
+		}
+	}
+
+	n := Size * Size
+	for _, cell := range rng.Perm(Size * Size) {
+		if n == clues {
+			return g, nil
+		}
+		r, c := cell/Size, cell%Size
+		d := g[r][c]
+		g[r][c] = 0
+		ok, err := unique(g)
+		if err != nil {
+			return g, err
+		}
+		if ok {
+			n--
+		} else {
+			g[r][c] = d
+		}
+	}
+	if n != clues {
+		return g, fmt.Errorf("Could not remove enough clues for a unique solution, got %d", n)
+	}
+	return g, nil
+}
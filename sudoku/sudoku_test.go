@@ -0,0 +1,91 @@
+package sudoku
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// puzzles are well known puzzles which each have a unique solution
+var puzzles = []string{
+	"003020600900305001001806400008102900700000008006708200002609500800203009005010300",
+	"4.....8.5.3..........7......2.....6.....8.4......1.......6.3.7.5..2.....1.4......",
+	"8..........36......7..9.2...5...7.......457.....1...3...1....68..85...1..9....4..",
+}
+
+func TestSolve(t *testing.T) {
+	for _, p := range puzzles {
+		g, err := Parse(p)
+		if err != nil {
+			t.Fatalf("Error parsing puzzle: %v", err)
+		}
+		solns, err := Solve(g)
+		if err != nil {
+			t.Fatalf("Error solving puzzle: %v", err)
+		}
+		if len(solns) != 1 {
+			t.Fatalf("Expected 1 solution, got %d for\n%v", len(solns), g)
+		}
+		s := solns[0]
+		if s.Clues() != Size*Size || s.Check() != nil {
+			t.Fatalf("Invalid solution:\n%v", s)
+		}
+		for r := range g {
+			for c, d := range g[r] {
+				if d != 0 && s[r][c] != d {
+					t.Fatalf("Solution does not keep given digit at r%dc%d:\n%v", r+1, c+1, s)
+				}
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	g, err := Parse(puzzles[1])
+	if err != nil {
+		t.Fatalf("Error parsing puzzle: %v", err)
+	}
+	if g.Clues() != 17 {
+		t.Fatalf("Expected 17 clues, got %d", g.Clues())
+	}
+	again, err := Parse(g.String())
+	if err != nil || again != g {
+		t.Fatalf("Grid changed by String and Parse: %v", err)
+	}
+
+	if _, err := Parse("123"); err == nil {
+		t.Fatal("Expected error for short grid")
+	}
+}
+
+func TestInvalidGrid(t *testing.T) {
+	var g Grid
+	g[0][0], g[0][8] = 5, 5
+	if _, err := Problem(g); err == nil {
+		t.Fatal("Expected error for repeated digit in row")
+	}
+	g[0][8] = 0
+	g[4][4] = 10
+	if _, err := Problem(g); err == nil {
+		t.Fatal("Expected error for invalid digit")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, clues := range []int{81, 40, 30} {
+		g, err := Generate(rng, clues)
+		if err != nil {
+			t.Fatalf("Error generating puzzle with %d clues: %v", clues, err)
+		}
+		if g.Clues() != clues {
+			t.Fatalf("Expected %d clues, got %d:\n%v", clues, g.Clues(), g)
+		}
+		if solns, err := Solve(g); err != nil || len(solns) != 1 {
+			t.Fatalf("Expected a unique solution, got %d: %v\n%v", len(solns), err, g)
+		}
+	}
+
+	if _, err := Generate(rng, 16); err == nil {
+		t.Fatal("Expected error for too few clues")
+	}
+}
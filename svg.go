@@ -0,0 +1,141 @@
+package gox
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+)
+
+// SVGCell is a true cell of the matrix drawn by WriteSVG, passed to
+// SVGOptions.Cell to be drawn
+type SVGCell struct {
+	Row, Column string
+	// Color is the name of the colour the row gives the column, or empty if
+	// it gives none
+	Color string
+	// Chosen is set for the cells of the rows of the solution
+	Chosen bool
+	// X and Y are the top left corner of the cell in the figure, whose sides
+	// are Size long
+	X, Y, Size float64
+}
+
+// SVGOptions tunes the figures drawn by WriteSVG. The zero value draws cells
+// of 16 units with the default cells.
+type SVGOptions struct {
+	// CellSize is the length of the side of each cell
+	CellSize float64
+	// Cell, if not nil, draws each true cell in place of the default filled
+	// square, writing SVG elements to w. Puzzle packages supply their own,
+	// e.g. to colour each cell after the piece placed by its row.
+	Cell func(w io.Writer, c SVGCell) error
+}
+
+// svgCellSize is the side of each cell if SVGOptions.CellSize is not set
+const svgCellSize = 16
+
+// WriteSVG draws the matrix of the problem as an SVG figure with the rows of
+// the solution highlighted, as Format writes it as text: the columns are named
+// along the top and the rows down the left, with a square for each true cell,
+// filled more darkly for the rows of the solution and labelled with its colour
+// if it has one. opts may be nil for the defaults.
+func (s *Solution) WriteSVG(w io.Writer, opts *SVGOptions) error {
+	p := s.p
+	size := float64(svgCellSize)
+	var drawCell func(io.Writer, SVGCell) error
+	if opts != nil {
+		if opts.CellSize > 0 {
+			size = opts.CellSize
+		}
+		drawCell = opts.Cell
+	}
+	if drawCell == nil {
+		drawCell = defaultSVGCell
+	}
+	chosen := make(map[*rowHeader]bool, len(s.Rows))
+	for _, name := range s.Rows {
+		if r := p.row(name); r != nil {
+			chosen[r] = true
+		}
+	}
+
+	// The names are drawn in the margins, sized for their longest name at
+	// about 0.6 of the font size for each character
+	font := size * 0.75
+	left, top := 0.0, 0.0
+	for _, r := range p.rowHeaders {
+		if width := float64(len(r.label()))*font*0.6 + font; width > left {
+			left = width
+		}
+	}
+	for _, h := range p.colHeaders {
+		if height := float64(len(p.colName(h)))*font*0.6 + font; height > top {
+			top = height
+		}
+	}
+	width := left + float64(p.numCols)*size
+	height := top + float64(len(p.rowHeaders))*size
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif" font-size="%g">`+"\n", width, height, width, height, font)
+	fmt.Fprintf(bw, `<rect width="%g" height="%g" fill="white"/>`+"\n", width, height)
+	for i, h := range p.colHeaders {
+		x := left + (float64(i)+0.5)*size
+		fmt.Fprintf(bw, `<text transform="translate(%g %g) rotate(-90)" dominant-baseline="middle">%s</text>`+"\n", x, top-font/2, html.EscapeString(p.colName(h)))
+	}
+	for i, r := range p.rowHeaders {
+		y := top + float64(i)*size
+		if chosen[r] {
+			fmt.Fprintf(bw, `<rect x="0" y="%g" width="%g" height="%g" fill="#fde9a9"/>`+"\n", y, width, size)
+		}
+		fmt.Fprintf(bw, `<text x="%g" y="%g" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n", left-font/2, y+size/2, html.EscapeString(r.label()))
+	}
+	// The grid is drawn over the highlighted rows but under the cells
+	for i := 0; i <= p.numCols; i++ {
+		x := left + float64(i)*size
+		fmt.Fprintf(bw, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="#ddd"/>`+"\n", x, top, x, height)
+	}
+	for i := 0; i <= len(p.rowHeaders); i++ {
+		y := top + float64(i)*size
+		fmt.Fprintf(bw, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="#ddd"/>`+"\n", left, y, width, y)
+	}
+	for i, r := range p.rowHeaders {
+		for n := r.first; n != nil; {
+			c := SVGCell{
+				Row:    r.label(),
+				Column: p.colName(n.colHead),
+				Chosen: chosen[r],
+				X:      left + float64(n.colIndex)*size,
+				Y:      top + float64(i)*size,
+				Size:   size,
+			}
+			if n.color != 0 {
+				c.Color = p.colorName(abs(n.color))
+			}
+			if err := drawCell(bw, c); err != nil {
+				return err
+			}
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// defaultSVGCell draws a cell as a filled square, dark for the rows of the
+// solution and grey for the others, labelled with its colour if it has one
+func defaultSVGCell(w io.Writer, c SVGCell) error {
+	fill := "#999"
+	if c.Chosen {
+		fill = "#222"
+	}
+	inset := c.Size / 8
+	_, err := fmt.Fprintf(w, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`+"\n", c.X+inset, c.Y+inset, c.Size-2*inset, c.Size-2*inset, fill)
+	if err == nil && c.Color != "" {
+		_, err = fmt.Fprintf(w, `<text x="%g" y="%g" text-anchor="middle" dominant-baseline="middle" fill="white" font-size="%g">%s</text>`+"\n", c.X+c.Size/2, c.Y+c.Size/2, c.Size/2, html.EscapeString(c.Color))
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package gox
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// svgElements counts the elements of an SVG figure by name, failing if it is
+// not well formed
+func svgElements(t *testing.T, svg []byte) map[string]int {
+	ret := make(map[string]int)
+	dec := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return ret
+		} else if err != nil {
+			t.Fatalf("Figure is not well formed: %v\n%s", err, svg)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			ret[start.Name.Local]++
+		}
+	}
+}
+
+func TestSolutionWriteSVG(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("p", "q", "r")
+	b.AddSecondaryColumns("x")
+	b.AddRow("A", "p", "q", "x:<red>")
+	b.AddRow("B", "p", "r")
+	b.AddRow("C", "r", "x:<red>")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := prob.Solution([]string{"A", "C"}).WriteSVG(&buf, nil); err != nil {
+		t.Fatalf("Error writing figure: %v", err)
+	}
+	// A rect for the background, one for each of the two rows chosen, and
+	// one for each of the 7 true cells, with text for the 4 columns, 3 rows
+	// and 2 colours
+	elems := svgElements(t, buf.Bytes())
+	if elems["svg"] != 1 || elems["rect"] != 10 || elems["text"] != 9 || elems["line"] != 9 {
+		t.Fatalf("Unexpected elements %v:\n%s", elems, buf.String())
+	}
+	if !strings.Contains(buf.String(), "&lt;red&gt;") {
+		t.Fatalf("Expected colour to be escaped:\n%s", buf.String())
+	}
+
+	// A custom renderer draws every true cell
+	var cells []SVGCell
+	buf.Reset()
+	opts := &SVGOptions{CellSize: 10, Cell: func(w io.Writer, c SVGCell) error {
+		cells = append(cells, c)
+		return nil
+	}}
+	if err := prob.Solution([]string{"B"}).WriteSVG(&buf, opts); err != nil {
+		t.Fatalf("Error writing figure: %v", err)
+	}
+	chosen := 0
+	for _, c := range cells {
+		if c.Chosen {
+			chosen++
+			if c.Row != "B" {
+				t.Fatalf("Expected only the cells of B to be chosen, got %+v", c)
+			}
+		}
+		if c.Size != 10 || (c.Column == "x") != (c.Color == "<red>") {
+			t.Fatalf("Unexpected cell %+v", c)
+		}
+	}
+	if len(cells) != 7 || chosen != 2 {
+		t.Fatalf("Expected 7 cells, 2 chosen, got %+v", cells)
+	}
+
+	opts.Cell = func(io.Writer, SVGCell) error { return errors.New("no ink") }
+	if err := prob.Solution(nil).WriteSVG(&buf, opts); err == nil || err.Error() != "no ink" {
+		t.Fatalf("Expected the renderer's error, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package testgen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StressConfig describes a stress instance, large enough to measure the
+// memory taken by each node of the matrix
+type StressConfig struct {
+	// Rows is the number of rows, each covering Width of the Columns primary
+	// columns. Columns must be a multiple of Width, for the planted solution.
+	Rows, Width, Columns int
+	// Seed seeds the choice of the columns of each row
+	Seed int64
+}
+
+// Nodes returns the number of nodes in the matrix of the instance
+func (c StressConfig) Nodes() int64 {
+	return int64(c.Rows) * int64(c.Width)
+}
+
+// StressNodes returns the configuration of a stress instance with about nodes
+// nodes in rows of width columns, each column covered by width rows on
+// average
+func StressNodes(nodes int64, width int) StressConfig {
+	rows := int(nodes / int64(width))
+	cols := rows - rows%width
+	if cols < width {
+		cols = width
+	}
+	return StressConfig{Rows: rows, Width: width, Columns: cols, Seed: 1}
+}
+
+// Stress returns the stress instance in the format read by
+// gox.NewExactCoverProblemReader, generated as it is read so that instances
+// of hundreds of millions of nodes never need to be held in memory but by the
+// problem itself. The rows are chosen at random apart from a planted solution,
+// which partitions the columns and is spread through the rows. Instances with
+// few columns may choose a row twice, which the reader refuses.
+func Stress(c StressConfig) (io.Reader, error) {
+	if c.Width < 1 || c.Columns < c.Width || c.Columns%c.Width != 0 || c.Rows < c.Columns/c.Width {
+		return nil, fmt.Errorf("Stress instance must have a whole number of planted rows, and room for them: %+v", c)
+	}
+	rng := rand.New(rand.NewSource(c.Seed))
+	return &stressReader{c: c, rng: rng, perm: rng.Perm(c.Columns), stride: c.Rows / (c.Columns / c.Width)}, nil
+}
+
+// stressReader generates a stress instance a line at a time
+type stressReader struct {
+	c   StressConfig
+	rng *rand.Rand
+	// perm orders the columns covered by the rows of the planted solution,
+	// one of which is every stride rows
+	perm   []int
+	stride int
+	row    int
+	// buf holds the line generated but not yet read
+	buf bytes.Buffer
+}
+
+// Read reads the lines of the instance, generating them as they are needed
+func (r *stressReader) Read(p []byte) (int, error) {
+	for r.buf.Len() < len(p) && r.row <= r.c.Rows {
+		r.line()
+	}
+	if r.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.buf.Read(p)
+}
+
+// line generates the next line, the columns before the first row
+func (r *stressReader) line() {
+	defer func() { r.row++ }()
+	if r.row == 0 {
+		for col := 0; col < r.c.Columns; col++ {
+			r.item(col, col)
+		}
+		r.buf.WriteByte('\n')
+		return
+	}
+	i := r.row - 1
+	if planted := i / r.stride; i%r.stride == 0 && planted < r.c.Columns/r.c.Width {
+		// The columns of a planted row are in descending order, so that its
+		// name is never that of one of the others
+		cols := append([]int(nil), r.perm[planted*r.c.Width:(planted+1)*r.c.Width]...)
+		sort.Sort(sort.Reverse(sort.IntSlice(cols)))
+		for j, col := range cols {
+			r.item(j, col)
+		}
+		r.buf.WriteByte('\n')
+		return
+	}
+	// One column cycles through them all, so each is covered, and the
+	// others are drawn at random without repeats, in ascending order
+	cols := append(make([]int, 0, r.c.Width), i%r.c.Columns)
+	for len(cols) < r.c.Width {
+		col := r.rng.Intn(r.c.Columns)
+		repeat := false
+		for _, other := range cols {
+			repeat = repeat || other == col
+		}
+		if !repeat {
+			cols = append(cols, col)
+		}
+	}
+	sort.Ints(cols)
+	for j, col := range cols {
+		r.item(j, col)
+	}
+	r.buf.WriteByte('\n')
+}
+
+// item writes the name of column col, the jth item of its line
+func (r *stressReader) item(j, col int) {
+	if j > 0 {
+		r.buf.WriteByte(' ')
+	}
+	r.buf.WriteByte('c')
+	r.buf.WriteString(strconv.Itoa(col))
+}
+
+// PeakHeap calls f and returns the most bytes held by heap objects while it
+// ran, beyond those held when it was called, sampling the heap every
+// millisecond. Garbage not yet collected is counted, so the peak is an upper
+// bound on the memory f needed.
+func PeakHeap(f func()) uint64 {
+	runtime.GC()
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	read := func() uint64 {
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+	base := read()
+	peak := base
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				if n := read(); n > peak {
+					peak = n
+				}
+			}
+		}
+	}()
+	f()
+	close(done)
+	<-stopped
+	if n := read(); n > peak {
+		peak = n
+	}
+	return peak - base
+}
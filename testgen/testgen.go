@@ -0,0 +1,238 @@
+// Package testgen generates random exact cover problems for testing gox,
+// including patterns which are awkward for the solver and its preprocessing,
+// such as duplicated rows and columns or rows covering nothing. Together with
+// the fuzz targets of gox it is meant to catch mistakes which corrupt the
+// links of the matrix.
+package testgen
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/ifross89/gox"
+)
+
+// Pattern selects the structure of the rows generated
+type Pattern int
+
+const (
+	// Uniform rows cover each column independently with the probability
+	// given by the density
+	Uniform Pattern = iota
+	// Duplicates adds copies of some of the uniform rows, and columns which
+	// copy some of the primary columns
+	Duplicates
+	// Intervals rows cover a contiguous range of the primary columns, so that
+	// many rows contain others and many columns have few candidates
+	Intervals
+	// Empty adds rows which cover no columns to the uniform rows, and a
+	// primary column which no row covers unless a solution is planted
+	Empty
+)
+
+// Patterns lists every pattern, e.g. to choose one at random
+var Patterns = []Pattern{Uniform, Duplicates, Intervals, Empty}
+
+// String returns the name of the pattern
+func (p Pattern) String() string {
+	switch p {
+	case Uniform:
+		return "uniform"
+	case Duplicates:
+		return "duplicates"
+	case Intervals:
+		return "intervals"
+	case Empty:
+		return "empty"
+	}
+	return fmt.Sprintf("Pattern(%d)", int(p))
+}
+
+// Config describes the problems to generate
+type Config struct {
+	// Rows is the number of rows generated before the pattern adds any, and
+	// Columns the number of primary columns, which must be positive
+	Rows, Columns int
+	// Secondary is the number of secondary columns, and Colors the number of
+	// colours a row may give them, zero for rows which give no colours
+	Secondary, Colors int
+	// Density is the probability of a uniform row covering each column
+	Density float64
+	// Planted guarantees that the problem has a solution by including rows
+	// which partition the primary columns
+	Planted bool
+	Pattern Pattern
+}
+
+// Row is a row of a generated problem, whose items are as taken by
+// gox.Builder.AddRow
+type Row struct {
+	Name  string
+	Items []string
+}
+
+// Instance is a generated problem
+type Instance struct {
+	Primary, Secondary []string
+	Rows               []Row
+	// Planted holds the names of the rows of the planted solution, or is nil
+	// if no solution was planted
+	Planted []string
+}
+
+// Generate creates a random problem using rng as the source of randomness, so
+// that problems can be reproduced from a seed
+func Generate(rng *rand.Rand, c Config) (*Instance, error) {
+	if c.Columns < 1 || c.Rows < 0 || c.Secondary < 0 || c.Colors < 0 {
+		return nil, fmt.Errorf("Problem must have at least one column and no negative sizes: %+v", c)
+	}
+	if c.Density < 0 || c.Density > 1 {
+		return nil, fmt.Errorf("Density must be between 0 and 1: %v", c.Density)
+	}
+
+	inst := &Instance{}
+	for i := 0; i < c.Columns; i++ {
+		inst.Primary = append(inst.Primary, fmt.Sprintf("c%d", i+1))
+	}
+	for i := 0; i < c.Secondary; i++ {
+		inst.Secondary = append(inst.Secondary, fmt.Sprintf("s%d", i+1))
+	}
+
+	var rows [][]string
+	if c.Planted {
+		rows = plant(rng, inst.Primary, c)
+	}
+	numPlanted := len(rows)
+	for len(rows) < c.Rows {
+		if c.Pattern == Intervals {
+			i := rng.Intn(c.Columns)
+			j := i + 1 + rng.Intn(c.Columns-i)
+			rows = append(rows, append([]string(nil), inst.Primary[i:j]...))
+			continue
+		}
+		var row []string
+		for _, col := range inst.Primary {
+			if rng.Float64() < c.Density {
+				row = append(row, col)
+			}
+		}
+		for _, col := range inst.Secondary {
+			if rng.Float64() < c.Density {
+				if c.Colors > 0 {
+					col = fmt.Sprintf("%s:%c", col, 'A'+rng.Intn(c.Colors))
+				}
+				row = append(row, col)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	switch c.Pattern {
+	case Duplicates:
+		for i := range rows {
+			if rng.Intn(4) == 0 {
+				rows = append(rows, append([]string(nil), rows[i]...))
+			}
+		}
+		for _, col := range inst.Primary {
+			if rng.Intn(4) != 0 {
+				continue
+			}
+			dup := col + "'"
+			inst.Primary = append(inst.Primary, dup)
+			for i, row := range rows {
+				for _, item := range row {
+					if item == col {
+						rows[i] = append(rows[i], dup)
+						break
+					}
+				}
+			}
+		}
+	case Empty:
+		for i := 0; i <= len(rows)/8; i++ {
+			rows = append(rows, nil)
+		}
+		if !c.Planted {
+			inst.Primary = append(inst.Primary, "uncovered")
+		}
+	}
+
+	inst.Rows = make([]Row, len(rows))
+	for i, j := range rng.Perm(len(rows)) {
+		inst.Rows[j] = Row{Name: fmt.Sprintf("R%d", j+1), Items: rows[i]}
+		if i < numPlanted {
+			inst.Planted = append(inst.Planted, inst.Rows[j].Name)
+		}
+	}
+	return inst, nil
+}
+
+// plant returns rows which partition the primary columns. They are intervals
+// of the columns for the Intervals pattern, otherwise the columns are
+// shuffled first.
+func plant(rng *rand.Rand, cols []string, c Config) [][]string {
+	order := rng.Perm(len(cols))
+	if c.Pattern == Intervals {
+		for i := range order {
+			order[i] = i
+		}
+	}
+	size := c.Density * float64(len(cols))
+	var rows [][]string
+	var row []string
+	for _, i := range order {
+		if len(row) > 0 && rng.Float64()*size < 1 {
+			rows = append(rows, row)
+			row = nil
+		}
+		row = append(row, cols[i])
+	}
+	return append(rows, row)
+}
+
+// Problem builds the exact cover problem for the instance with the options
+// given
+func (inst *Instance) Problem(opts ...gox.ProblemOption) (gox.Solver, error) {
+	b := gox.NewBuilder()
+	if err := b.AddColumns(inst.Primary...); err != nil {
+		return nil, err
+	}
+	if err := b.AddSecondaryColumns(inst.Secondary...); err != nil {
+		return nil, err
+	}
+	for _, r := range inst.Rows {
+		if err := b.AddRow(r.Name, r.Items...); err != nil {
+			return nil, err
+		}
+	}
+	prob, err := b.Build(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
+
+// Matrix returns the instance as a matrix of bools with a row for each row of
+// the instance and a column for each primary column, along with the names of
+// the rows, as taken by gox.NewExactCoverProblem. Instances with secondary
+// columns cannot be written as a matrix.
+func (inst *Instance) Matrix() ([][]bool, []string, error) {
+	if len(inst.Secondary) > 0 {
+		return nil, nil, fmt.Errorf("Instance with secondary columns cannot be written as a matrix")
+	}
+	index := make(map[string]int, len(inst.Primary))
+	for i, col := range inst.Primary {
+		index[col] = i
+	}
+	m := make([][]bool, len(inst.Rows))
+	names := make([]string, len(inst.Rows))
+	for i, r := range inst.Rows {
+		m[i] = make([]bool, len(inst.Primary))
+		for _, item := range r.Items {
+			m[i][index[item]] = true
+		}
+		names[i] = r.Name
+	}
+	return m, names, nil
+}
@@ -0,0 +1,70 @@
+package testgen
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ifross89/gox"
+)
+
+func TestPlanted(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, p := range Patterns {
+		for i := 0; i < 20; i++ {
+			inst, err := Generate(rng, Config{Rows: 10, Columns: 8, Secondary: 2, Colors: 2, Density: 0.3, Planted: true, Pattern: p})
+			if err != nil {
+				t.Fatalf("Error generating %s instance: %v", p, err)
+			}
+			prob, err := inst.Problem()
+			if err != nil {
+				t.Fatalf("Error creating %s problem: %v", p, err)
+			}
+			if err := prob.Verify(inst.Planted); err != nil {
+				t.Fatalf("Planted solution of %s instance is invalid: %v", p, err)
+			}
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	inst, err := Generate(rand.New(rand.NewSource(1)), Config{Rows: 5, Columns: 4, Density: 0.5, Pattern: Empty})
+	if err != nil {
+		t.Fatalf("Error generating instance: %v", err)
+	}
+	m, names, err := inst.Matrix()
+	if err != nil {
+		t.Fatalf("Error creating matrix: %v", err)
+	}
+	if len(m) != len(inst.Rows) || len(names) != len(inst.Rows) || len(m[0]) != 5 {
+		t.Fatalf("Unexpected matrix size: %d rows of %d columns", len(m), len(m[0]))
+	}
+	inst.Secondary = []string{"s"}
+	if _, _, err := inst.Matrix(); err == nil {
+		t.Fatal("Expected error creating matrix with secondary columns")
+	}
+}
+
+func TestStress(t *testing.T) {
+	cfg := StressConfig{Rows: 200, Width: 3, Columns: 90, Seed: 1}
+	r, err := Stress(cfg)
+	if err != nil {
+		t.Fatalf("Error generating instance: %v", err)
+	}
+	prob, err := gox.NewExactCoverProblemReader(r)
+	if err != nil {
+		t.Fatalf("Error reading instance: %v", err)
+	}
+	if rows := len(prob.Rows()); rows != cfg.Rows {
+		t.Fatalf("Expected %d rows, got %d", cfg.Rows, rows)
+	}
+	// The planted solution is there to be found
+	solns, err := prob.SolveContext(context.Background(), gox.WithLimit(1))
+	if err != nil || len(solns) != 1 {
+		t.Fatalf("Expected a solution, got %v: %v", solns, err)
+	}
+
+	if _, err := Stress(StressConfig{Rows: 5, Width: 3, Columns: 20}); err == nil {
+		t.Fatal("Expected error for columns not a multiple of the width")
+	}
+}
@@ -0,0 +1,175 @@
+package gox
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// TieBreak chooses between the columns with fewest rows left when the
+// MinRemaining heuristic finds more than one. The choice makes no difference
+// to the solutions found, only to their order and the work done finding
+// them, which on some problems differs by orders of magnitude.
+type TieBreak int
+
+const (
+	// LeftmostTie chooses the leftmost column, as Knuth's programs do. This
+	// is the default.
+	LeftmostTie TieBreak = iota
+	// RightmostTie chooses the rightmost column
+	RightmostTie
+	// DegreeTie chooses the column whose rows cover the most columns
+	// altogether, so that choosing any of them removes the most from the
+	// problem
+	DegreeTie
+	// RandomTie chooses one of the columns at random, see WithRand
+	RandomTie
+	// RecentTie chooses the column most recently affected by a choice of the
+	// search, i.e. which shares a row with a column covered by the latest
+	// choice, so that the search keeps working on the same part of the
+	// problem
+	RecentTie
+)
+
+// tieBreakNames are the names used by ParseTieBreak and String
+var tieBreakNames = map[TieBreak]string{
+	LeftmostTie:  "left",
+	RightmostTie: "right",
+	DegreeTie:    "degree",
+	RandomTie:    "random",
+	RecentTie:    "recent",
+}
+
+// String returns the name of the tie-break as accepted by ParseTieBreak
+func (t TieBreak) String() string {
+	if name, ok := tieBreakNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TieBreak(%d)", int(t))
+}
+
+// ParseTieBreak returns the tie-break with the given name, "left", "right",
+// "degree", "random" or "recent"
+func ParseTieBreak(name string) (TieBreak, error) {
+	for t, n := range tieBreakNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown tie-break: %s", name)
+}
+
+// WithTieBreak sets how the MinRemaining heuristic chooses between the
+// columns with fewest rows left. Column weights set by SetColumnWeight are
+// compared first, so the tie-break only chooses between columns of the same
+// weight.
+//
+// RandomTie and RecentTie choose from the search so far, which SolveParallel,
+// SolveShard and SolvePage start afresh with each branch, shard and page, so
+// with them those find the solutions of SolveContext in another order.
+func WithTieBreak(t TieBreak) Option {
+	return func(c *config) {
+		c.tieBreak = t
+	}
+}
+
+// WithRand gives the source of the random choices of RandomTie. Without it
+// a source with a fixed seed is used, so that the search is repeatable. The
+// workers of SolveParallel each use a source with a fixed seed, as rng is
+// not safe for concurrent use.
+func WithRand(rng *rand.Rand) Option {
+	return func(c *config) {
+		c.rng = rng
+	}
+}
+
+// nextTiedCol is nextCol for a problem with column weights or priorities or
+// a search with a tie-break other than LeftmostTie, which only does the extra
+// work for the columns which tie. It chooses among ret, the first column of
+// the priority class top, and the columns of the class to its right. ties
+// counts the columns tied with ret so far, so that RandomTie chooses each
+// with the same chance.
+func (p *exactCoverProblem) nextTiedCol(c *config, ret *node, top int) *node {
+	ties := 1
+	retDegree := -1
+	for n := ret.right; n != p.root; n = n.right {
+		if n.colCount > ret.colCount || !p.inClass(n, top) {
+			continue
+		}
+		if n.colCount < ret.colCount {
+			ret, ties, retDegree = n, 1, -1
+			continue
+		}
+		if p.colWeights != nil {
+			w, retW := p.colWeights[n.colIndex], p.colWeights[ret.colIndex]
+			if w < retW {
+				continue
+			}
+			if w > retW {
+				ret, ties, retDegree = n, 1, -1
+				continue
+			}
+		}
+		ties++
+		switch c.tieBreak {
+		case RightmostTie:
+			ret = n
+		case DegreeTie:
+			if retDegree < 0 {
+				retDegree = colDegree(ret)
+			}
+			if d := colDegree(n); d > retDegree {
+				ret, retDegree = n, d
+			}
+		case RandomTie:
+			if c.rng == nil {
+				c.rng = rand.New(rand.NewSource(1))
+			}
+			if c.rng.Intn(ties) == 0 {
+				ret = n
+			}
+		case RecentTie:
+			if c.stamps != nil && c.stamps[n.colIndex] > c.stamps[ret.colIndex] {
+				ret = n
+			}
+		}
+	}
+	return ret
+}
+
+// colDegree returns the number of nodes in the rows left in a column
+func colDegree(head *node) int {
+	d := 0
+	for rowNode := head.down; rowNode != head; rowNode = rowNode.down {
+		d++
+		for n := rowNode.right; n != rowNode; n = n.right {
+			d++
+		}
+	}
+	return d
+}
+
+// stampNeighbours records for RecentTie that the columns sharing a row with
+// the columns of the row of rowNode were affected by choosing it, at the
+// step the search has reached
+func (p *exactCoverProblem) stampNeighbours(c *config, rowNode *node) {
+	if c.stamps == nil {
+		c.stamps = make([]int64, p.numCols)
+	}
+	n := rowNode
+	for {
+		// The column's own links are left alone when it is covered, so its
+		// rows can still be found
+		head := n.colHead
+		for m := head.down; m != head; m = m.down {
+			if m == n {
+				continue
+			}
+			for j := m.right; j != m; j = j.right {
+				c.stamps[j.colIndex] = c.steps
+			}
+		}
+		if n = n.right; n == rowNode {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package gox
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// chosenColumns returns the columns chosen by the search, in order, up to
+// the first solution
+func chosenColumns(t *testing.T, prob *exactCoverProblem, opts ...Option) []string {
+	var cols []string
+	opts = append(opts, WithLimit(1), WithTrace(func(e Event) {
+		if e.Kind == ChooseColumn {
+			cols = append(cols, e.Column)
+		}
+	}))
+	if _, err := prob.SolveContext(context.Background(), opts...); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	return cols
+}
+
+func TestSolvePageTieBreaks(t *testing.T) {
+	prob := dominoProblem(t, 8)
+	for tie := range tieBreakNames {
+		want, err := prob.SolveContext(context.Background(), WithTieBreak(tie))
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		got := pagedSolutions(t, prob, 5, len(want), WithTieBreak(tie))
+		// RandomTie and RecentTie start afresh with each page
+		if tie != RandomTie && tie != RecentTie {
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%v: expected %v, got %v", tie, want, got)
+			}
+		} else if !reflect.DeepEqual(canonicalSolutions(got), canonicalSolutions(want)) {
+			t.Fatalf("%v: expected the solutions %v in any order, got %v", tie, want, got)
+		}
+	}
+}
+
+func TestTieBreak(t *testing.T) {
+	// Rows A1 and A2 both cover a, and choosing A1 hides A2, leaving b and c
+	// with two rows each, c having been affected by the choice
+	b := NewBuilder()
+	b.AddColumns("a", "b", "c")
+	b.AddRow("A1", "a")
+	b.AddRow("A2", "a", "c")
+	b.AddRow("B1", "b")
+	b.AddRow("B2", "b")
+	b.AddRow("C1", "c")
+	b.AddRow("C2", "c")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	for _, test := range []struct {
+		tie  TieBreak
+		cols []string
+	}{
+		{LeftmostTie, []string{"a", "b"}},
+		{RightmostTie, []string{"b", "a"}},
+		{RecentTie, []string{"a", "c"}},
+	} {
+		cols := chosenColumns(t, prob, WithTieBreak(test.tie))
+		if cols[0] != test.cols[0] || cols[1] != test.cols[1] {
+			t.Fatalf("%s: expected to choose %v, got %v", test.tie, test.cols, cols)
+		}
+	}
+
+	// The rows of b and c cover more columns than those of a
+	b = NewBuilder()
+	b.AddColumns("a", "b", "c")
+	b.AddRow("A", "a")
+	b.AddRow("B", "a")
+	b.AddRow("C", "b", "c")
+	b.AddRow("D", "b", "c")
+	if prob, err = b.Build(); err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if cols := chosenColumns(t, prob, WithTieBreak(DegreeTie)); cols[0] != "b" {
+		t.Fatalf("Expected to choose b, got %v", cols)
+	}
+
+	// Random choices are repeatable with the same source, and the
+	// tie-breaks find the same solutions
+	prob = dominoProblem(t, 6)
+	first := chosenColumns(t, prob, WithTieBreak(RandomTie), WithRand(rand.New(rand.NewSource(7))))
+	again := chosenColumns(t, prob, WithTieBreak(RandomTie), WithRand(rand.New(rand.NewSource(7))))
+	if len(first) != len(again) || first[0] != again[0] {
+		t.Fatalf("Expected the same choices, got %v and %v", first, again)
+	}
+	for tie := range tieBreakNames {
+		solns, err := prob.SolveContext(context.Background(), WithTieBreak(tie))
+		if err != nil || len(solns) != 13 {
+			t.Fatalf("%s: expected 13 solutions, got %d: %v", tie, len(solns), err)
+		}
+		if parsed, err := ParseTieBreak(tie.String()); err != nil || parsed != tie {
+			t.Fatalf("Expected to parse %s, got %v: %v", tie, parsed, err)
+		}
+	}
+	if _, err := ParseTieBreak("middle"); err == nil {
+		t.Fatal("Expected error for unknown tie-break")
+	}
+}
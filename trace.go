@@ -0,0 +1,134 @@
+package gox
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// EventKind identifies the step of a search described by an Event
+type EventKind int
+
+const (
+	// ChooseColumn is sent when the search chooses the column to branch on.
+	// Column and Size describe the column.
+	ChooseColumn EventKind = iota
+	// DeadEnd is sent after ChooseColumn if the column has no rows left, so
+	// the search must backtrack
+	DeadEnd
+	// TryRow is sent once Row has been added to the partial solution and the
+	// columns it covers removed from the matrix
+	TryRow
+	// UndoRow is sent once Row has been removed from the partial solution
+	UndoRow
+	// FoundSolution is sent when the partial solution covers every primary
+	// column
+	FoundSolution
+	// Cover is sent once Column has been removed from the matrix along with
+	// the rows in it, Size giving their number, and Uncover once they have
+	// been restored. Only the stream of WithEventStream has them, as they
+	// are only needed to animate the links themselves.
+	Cover
+	Uncover
+)
+
+// eventKindNames are the names of the kinds of event, used when events are
+// written as text
+var eventKindNames = map[EventKind]string{
+	ChooseColumn:  "choose",
+	DeadEnd:       "deadend",
+	TryRow:        "try",
+	UndoRow:       "undo",
+	FoundSolution: "solution",
+	Cover:         "cover",
+	Uncover:       "uncover",
+}
+
+// String returns the name of the kind of event, e.g. "choose"
+func (k EventKind) String() string {
+	if name, ok := eventKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// MarshalText encodes the kind as its name, so that events can be written as
+// JSON
+func (k EventKind) MarshalText() ([]byte, error) {
+	name, ok := eventKindNames[k]
+	if !ok {
+		return nil, fmt.Errorf("Unknown event kind: %d", int(k))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText decodes a kind from its name
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for kind, name := range eventKindNames {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown event kind: %s", text)
+}
+
+// Event describes a step of a search, see WithTrace. A search can be replayed
+// from its events, e.g. to show how the heuristic chose the columns.
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// Depth is the number of rows in the partial solution, including the
+	// rows given with RowIsSolution
+	Depth int `json:"depth"`
+	// Column is the column chosen, for ChooseColumn and DeadEnd, or the
+	// column covered or uncovered
+	Column string `json:"column,omitempty"`
+	// Size is the number of rows left in Column when the event was sent
+	Size int `json:"size,omitempty"`
+	// Row is the row tried or undone, for TryRow and UndoRow
+	Row string `json:"row,omitempty"`
+	// Remaining is the number of primary columns not yet covered
+	Remaining int `json:"remaining"`
+}
+
+// WithTrace calls f with an Event for each step of the search, from the
+// goroutine performing the search. Tracing slows the search down, so is
+// intended for visualising and debugging searches.
+func WithTrace(f func(Event)) Option {
+	return func(c *config) {
+		c.trace = f
+	}
+}
+
+// colName returns the name of a column. Problems created from a matrix have
+// no column names, so their columns are named by index.
+func (p *exactCoverProblem) colName(col *node) string {
+	if p.colNames == nil {
+		return strconv.Itoa(col.colIndex)
+	}
+	return p.colNames[col.colIndex]
+}
+
+// emit sends an event to the trace function of the search, if there is one.
+// col and row may be nil for events which do not concern them.
+func (p *exactCoverProblem) emit(c *config, kind EventKind, col *node, row *rowHeader) {
+	if c.trace == nil && c.events == nil {
+		return
+	}
+	e := Event{Kind: kind, Depth: len(p.solutionRows)}
+	if col != nil {
+		e.Column = p.colName(col)
+		e.Size = col.colCount
+	}
+	if row != nil {
+		e.Row = row.label()
+	}
+	for n := p.root.right; n != p.root; n = n.right {
+		e.Remaining++
+	}
+	if c.trace != nil && kind != Cover && kind != Uncover {
+		c.trace(e)
+	}
+	if c.events != nil {
+		c.events.write(c, e)
+	}
+}
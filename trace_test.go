@@ -0,0 +1,107 @@
+package gox
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var events []Event
+	prob := dominoProblem(t, 2)
+	solns, err := prob.SolveContext(context.Background(), WithHeuristic(FirstColumn), WithTrace(func(e Event) {
+		events = append(events, e)
+	}))
+	if err != nil || len(solns) != 2 {
+		t.Fatalf("Expected 2 solutions, got %d: %v", len(solns), err)
+	}
+
+	// The rows tried and undone must balance, and the partial solution
+	// replayed from the events must match each solution found
+	var partial []string
+	found := 0
+	for _, e := range events {
+		switch e.Kind {
+		case TryRow:
+			partial = append(partial, e.Row)
+			if e.Depth != len(partial) {
+				t.Fatalf("Expected depth %d, got %+v", len(partial), e)
+			}
+		case UndoRow:
+			if len(partial) == 0 || partial[len(partial)-1] != e.Row {
+				t.Fatalf("Undid %s which was not the last row tried: %v", e.Row, partial)
+			}
+			partial = partial[:len(partial)-1]
+		case FoundSolution:
+			assertStringSliceEqual(t, solns[found], partial)
+			if e.Remaining != 0 {
+				t.Fatalf("Expected no columns remaining for solution, got %+v", e)
+			}
+			found++
+		}
+	}
+	if len(partial) != 0 || found != 2 {
+		t.Fatalf("Expected 2 solutions and empty partial solution, got %d and %v", found, partial)
+	}
+	if events[0].Kind != ChooseColumn || events[0].Column != "0,0" || events[0].Size != 2 || events[0].Remaining != 4 {
+		t.Fatalf("Unexpected first event: %+v", events[0])
+	}
+}
+
+func TestEventJSON(t *testing.T) {
+	e := Event{Kind: TryRow, Depth: 1, Row: "v0", Remaining: 2}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Error encoding event: %v", err)
+	}
+	if string(b) != `{"kind":"try","depth":1,"row":"v0","remaining":2}` {
+		t.Fatalf("Unexpected JSON: %s", b)
+	}
+	var decoded Event
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded != e {
+		t.Fatalf("Expected %+v, got %+v: %v", e, decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`{"kind":"jump"}`), &decoded); err == nil {
+		t.Fatal("Expected error for unknown kind")
+	}
+}
+
+func TestCurrentSolution(t *testing.T) {
+	prob := dominoProblem(t, 3)
+	var solver ExactCoverSolver = prob
+	if _, ok := solver.(PartialSolver); !ok {
+		t.Fatal("Expected the problem to be a PartialSolver")
+	}
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if current := prob.CurrentSolution(); !reflect.DeepEqual(current, []string{"v0"}) {
+		t.Fatalf("Expected the given row, got %v", current)
+	}
+
+	// From the hooks the partial solution holds the given row followed by
+	// the rows tried
+	partial := []string{"v0"}
+	_, err := prob.SolveContext(context.Background(), WithTrace(func(e Event) {
+		switch e.Kind {
+		case TryRow:
+			partial = append(partial, e.Row)
+		case UndoRow:
+			partial = partial[:len(partial)-1]
+		}
+		if current := prob.CurrentSolution(); !reflect.DeepEqual(current, partial) {
+			t.Fatalf("Expected partial solution %v, got %v", partial, current)
+		}
+	}), WithSolutionFunc(func(soln []string) {
+		if current := prob.CurrentSolution(); !reflect.DeepEqual(current, soln) {
+			t.Fatalf("Expected solution %v, got %v", soln, current)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if current := prob.CurrentSolution(); !reflect.DeepEqual(current, []string{"v0"}) {
+		t.Fatalf("Expected the given row, got %v", current)
+	}
+}
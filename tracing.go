@@ -0,0 +1,185 @@
+package gox
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer starts the spans of a distributed trace, so that the time spent
+// building and solving problems shows up in the traces of the services using
+// gox. Its methods follow those of the Tracer and Span of OpenTelemetry, so
+// that an adapter to an OpenTelemetry tracer, or any other, is a few lines
+// without gox depending on it.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx, returning a context
+	// holding the new span
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	// AddEvent records an event which happened during the span
+	AddEvent(name string, attrs ...Attribute)
+	// SetAttributes adds attributes to the span
+	SetAttributes(attrs ...Attribute)
+	// RecordError records the error which ended the span
+	RecordError(err error)
+	// End ends the span
+	End()
+}
+
+// Attribute is a key and value describing a span or an event. Values are
+// ints, int64s, float64s, strings or bools.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// WithTracer traces the search with t. The search is a span named after the
+// call, e.g. "gox.SolveContext", as a child of any span in the context
+// passed to the call. Its attributes give the size of the problem, the nodes
+// visited, the solutions found and the outcome: "complete", "limit",
+// "cancelled" or "error". Each solution is recorded as a "gox.solution"
+// event.
+func WithTracer(t Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+// WithBuildTracer traces the creation of a problem with t, as a span named
+// "gox.build" and a child "gox.preprocess" span if the problem is
+// preprocessed, as children of any span in ctx
+func WithBuildTracer(ctx context.Context, t Tracer) ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.tracer, p.traceCtx = t, ctx
+	}
+}
+
+// traceSpan is a span started by the tracer of a problem or search, nil if
+// there is no tracer, so that it may be ended without checking
+type traceSpan struct {
+	ctx  context.Context
+	span Span
+}
+
+// startSpan starts a span with t if it is not nil
+func startSpan(ctx context.Context, t Tracer, name string, attrs ...Attribute) *traceSpan {
+	if t == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s := &traceSpan{}
+	s.ctx, s.span = t.Start(ctx, name, attrs...)
+	return s
+}
+
+// event records an event during the span
+func (s *traceSpan) event(name string, attrs ...Attribute) {
+	if s != nil {
+		s.span.AddEvent(name, attrs...)
+	}
+}
+
+// end ends the span, recording err if it is not nil and setting attrs
+func (s *traceSpan) end(err error, attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	if len(attrs) > 0 {
+		s.span.SetAttributes(attrs...)
+	}
+	if err != nil {
+		s.span.RecordError(err)
+	}
+	s.span.End()
+}
+
+// startBuild starts the span of the creation of the problem, after which the
+// spans of preprocessing are its children
+func (p *exactCoverProblem) startBuild() *traceSpan {
+	s := startSpan(p.traceCtx, p.tracer, "gox.build")
+	if s != nil {
+		p.traceCtx = s.ctx
+	}
+	return s
+}
+
+// endBuild ends the span of the creation of the problem, describing its size
+func (p *exactCoverProblem) endBuild(s *traceSpan, err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.end(err)
+		return
+	}
+	r := p.Report()
+	s.end(nil,
+		Attribute{"gox.rows", r.Rows},
+		Attribute{"gox.columns.primary", r.PrimaryColumns},
+		Attribute{"gox.columns.secondary", r.SecondaryColumns},
+		Attribute{"gox.nodes", r.Nodes},
+	)
+}
+
+// endPreprocess ends the span of preprocessing, counting what it removed
+func (p *exactCoverProblem) endPreprocess(s *traceSpan) {
+	if s == nil {
+		return
+	}
+	merged := 0
+	for _, rows := range p.report.DuplicateRows {
+		merged += len(rows)
+	}
+	columns := 0
+	for _, cols := range p.report.DuplicateColumns {
+		columns += len(cols)
+	}
+	s.end(nil,
+		Attribute{"gox.preprocess.duplicate_rows", merged},
+		Attribute{"gox.preprocess.duplicate_columns", columns},
+		Attribute{"gox.preprocess.dominated", len(p.report.Dominated)},
+		Attribute{"gox.preprocess.forced", len(p.report.Forced)},
+	)
+}
+
+// startSearch starts the span of a search by the call op, describing the
+// size of the problem
+func (p *exactCoverProblem) startSearch(ctx context.Context, t Tracer, op string) *traceSpan {
+	if t == nil {
+		return nil
+	}
+	return startSpan(ctx, t, "gox."+op, p.sizeAttributes()...)
+}
+
+// sizeAttributes describes the size of the problem as it stands
+func (p *exactCoverProblem) sizeAttributes() []Attribute {
+	remaining := 0
+	for col := p.root.right; col != p.root; col = col.right {
+		remaining++
+	}
+	return []Attribute{
+		{"gox.rows", len(p.rowHeaders)},
+		{"gox.columns.primary", p.numPrimary},
+		{"gox.columns.secondary", p.numCols - p.numPrimary},
+		{"gox.columns.remaining", remaining},
+		{"gox.given", len(p.solutionRows)},
+	}
+}
+
+// outcome describes how a search ended, given the error it ended with and
+// whether it stopped at its limit
+func outcome(err error, limited bool) string {
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return "cancelled"
+	case err != nil:
+		return "error"
+	case limited:
+		return "limit"
+	}
+	return "complete"
+}
@@ -0,0 +1,127 @@
+package gox
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// recordedSpan is a span recorded by recordingTracer
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	events []string
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) AddEvent(name string, attrs ...Attribute) {
+	s.events = append(s.events, name)
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+type spanKey struct{}
+
+// recordingTracer records the spans started with it
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	s := &recordedSpan{name: name, parent: parent, attrs: make(map[string]interface{})}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func TestBuildTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	root := &recordedSpan{name: "request"}
+	ctx := context.WithValue(context.Background(), spanKey{}, root)
+	b := NewBuilder()
+	b.AddColumns("a", "b")
+	b.AddRow("r1", "a")
+	b.AddRow("r2", "b")
+	b.AddRow("r3", "a")
+	if _, err := b.Build(WithBuildTracer(ctx, tracer), WithMergeDuplicates()); err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected build and preprocess spans, got %d spans", len(tracer.spans))
+	}
+	build, pre := tracer.spans[0], tracer.spans[1]
+	if build.name != "gox.build" || build.parent != root || !build.ended {
+		t.Errorf("Unexpected build span %+v", build)
+	}
+	if build.attrs["gox.rows"] != 2 || build.attrs["gox.columns.primary"] != 2 {
+		t.Errorf("Unexpected build attributes %v", build.attrs)
+	}
+	if pre.name != "gox.preprocess" || pre.parent != build || !pre.ended {
+		t.Errorf("Unexpected preprocess span %+v", pre)
+	}
+	if pre.attrs["gox.preprocess.duplicate_rows"] != 1 {
+		t.Errorf("Unexpected preprocess attributes %v", pre.attrs)
+	}
+
+	tracer.spans = nil
+	m := [][]bool{{true, false}, {true}}
+	if _, err := NewExactCoverProblem(m, []string{"r1", "r2"}, WithBuildTracer(ctx, tracer)); err == nil {
+		t.Fatalf("Expected error creating problem with ragged matrix")
+	}
+	if len(tracer.spans) != 1 || tracer.spans[0].err == nil || !tracer.spans[0].ended {
+		t.Errorf("Expected build span to record the error, got %+v", tracer.spans)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	prob := dominoProblem(t, 4)
+	tracer := &recordingTracer{}
+	solns, err := prob.SolveContext(context.Background(), WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(tracer.spans))
+	}
+	s := tracer.spans[0]
+	if s.name != "gox.SolveContext" || !s.ended || s.err != nil {
+		t.Errorf("Unexpected span %+v", s)
+	}
+	if len(s.events) != len(solns) {
+		t.Errorf("Expected %d solution events, got %d", len(solns), len(s.events))
+	}
+	if s.attrs["gox.outcome"] != "complete" || s.attrs["gox.solutions"] != int64(len(solns)) {
+		t.Errorf("Unexpected attributes %v", s.attrs)
+	}
+	if s.attrs["gox.rows"] != 10 || s.attrs["gox.nodes"].(int64) <= 0 {
+		t.Errorf("Unexpected attributes %v", s.attrs)
+	}
+
+	tracer.spans = nil
+	if _, err := prob.SolveContext(context.Background(), WithTracer(tracer), WithLimit(2)); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	if s := tracer.spans[0]; s.attrs["gox.outcome"] != "limit" || len(s.events) != 2 {
+		t.Errorf("Expected search to stop at its limit, got %v with %d events", s.attrs, len(s.events))
+	}
+
+	tracer.spans = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prob.SolveContext(ctx, WithTracer(tracer)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected search to be cancelled, got %v", err)
+	}
+	if s := tracer.spans[0]; s.attrs["gox.outcome"] != "cancelled" || s.err == nil || !s.ended {
+		t.Errorf("Expected cancelled span, got %+v", s)
+	}
+}
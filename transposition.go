@@ -0,0 +1,98 @@
+package gox
+
+import "context"
+
+// WithTranspositions keeps a transposition table for CountSolutions: the
+// number of solutions of each subproblem solved is recorded against the
+// columns covered by the rows chosen, and the colours they give, which
+// determine the rows left. When the same subproblem is reached by choosing
+// different rows, such as the same part of a board tiled a different way,
+// its count is looked up rather than found again. On symmetric instances
+// this makes counting exponentially faster.
+//
+// At most capacity subproblems are kept, the oldest being forgotten once it
+// is reached. The number of subproblems looked up is reported by WithStats.
+// SolveContext must list every solution, so is not helped by the table; see
+// WithNogoods for skipping the subproblems which have no solutions.
+func WithTranspositions(capacity int) Option {
+	return func(c *config) {
+		c.transpositions = newStateTable(capacity)
+	}
+}
+
+// CountSolutions returns the number of solutions to the problem without
+// listing them, see SolveContext. If the search is interrupted the number
+// found so far is returned with the context's error, and if the problem is
+// found to have no solutions without searching, zero is returned with an
+// *UncoverableError. WithLimit and
+// WithParticipation have no effect. The count wraps around if it does not
+// fit in a uint64, see CountSolutionsBig for counts which may not.
+func (p *exactCoverProblem) CountSolutions(ctx context.Context, opts ...Option) (uint64, error) {
+	var c *config
+	opts = append(opts, func(cfg *config) {
+		cfg.counting = true
+		c = cfg
+	})
+	err := p.solve(ctx, "CountSolutions", opts, nil)
+	if c == nil {
+		// The problem was in use or in no state to be solved
+		return 0, err
+	}
+	return c.count, err
+}
+
+// searchCount is search for CountSolutions, returning the number of solutions
+// found and whether the search was interrupted. The matrix is restored either
+// way.
+func (p *exactCoverProblem) searchCount(c *config) (uint64, bool) {
+	if c.interrupted() {
+		return 0, true
+	}
+	if p.root == p.root.right {
+		p.emit(c, FoundSolution, nil, nil)
+		// The solutions counted so far show WithIdleTimeout the search is
+		// making progress
+		c.solutions++
+		return 1, false
+	}
+
+	colHead := p.nextCol(c)
+	p.chose(c, colHead)
+	if colHead.colCount == 0 {
+		p.emit(c, DeadEnd, colHead, nil)
+		return 0, false
+	}
+	var key []byte
+	if c.transpositions != nil {
+		key = p.stateKey()
+		if count, ok := c.transpositions.get(key); ok {
+			c.pruned++
+			c.solutions += int64(count)
+			return count, false
+		}
+	}
+
+	p.cover(colHead)
+	var total uint64
+	stopped := false
+	for rowNode := colHead.down; rowNode != colHead && !stopped; rowNode = rowNode.down {
+		p.pushRowToSolution(rowNode.rowHead)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		p.emit(c, TryRow, nil, rowNode.rowHead)
+		var count uint64
+		count, stopped = p.searchCount(c)
+		total += count
+		p.popRowFromSolution()
+		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+			p.uncommit(leftNode)
+		}
+		p.emit(c, UndoRow, nil, rowNode.rowHead)
+	}
+	p.uncover(colHead)
+	if c.transpositions != nil && !stopped {
+		c.transpositions.put(string(key), total)
+	}
+	return total, stopped
+}
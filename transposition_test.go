@@ -0,0 +1,65 @@
+package gox_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/testgen"
+)
+
+func TestCountSolutions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      rng.Intn(30),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		solns, err := solveGenerated(inst)
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		for _, capacity := range []int{0, 1, 4, 1 << 20} {
+			prob, err := inst.Problem(gox.WithDebug())
+			if err != nil {
+				t.Fatalf("Error building problem: %v", err)
+			}
+			count, err := prob.CountSolutions(context.Background(), gox.WithTranspositions(capacity))
+			if _, ok := err.(*gox.UncoverableError); err != nil && !ok {
+				t.Fatalf("Error counting solutions: %v", err)
+			}
+			if count != uint64(len(solns)) {
+				t.Fatalf("Trial %d, capacity %d: expected %d solutions, got %d", trial, capacity, len(solns), count)
+			}
+		}
+	}
+}
+
+func TestWithTranspositions(t *testing.T) {
+	// The tilings of a 2x60 board by dominoes are counted by the Fibonacci
+	// numbers, far too many to enumerate
+	prob, err := dominoBuilder(60).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var stats gox.Stats
+	count, err := prob.CountSolutions(context.Background(), gox.WithHeuristic(gox.FirstColumn), gox.WithTranspositions(1000), gox.WithStats(&stats))
+	if err != nil {
+		t.Fatalf("Error counting solutions: %v", err)
+	}
+	// F(61)
+	if count != 2504730781961 {
+		t.Fatalf("Expected 2504730781961 tilings, got %d", count)
+	}
+	if stats.Pruned == 0 || stats.Solutions != int64(count) {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
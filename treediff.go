@@ -0,0 +1,113 @@
+package gox
+
+import "sort"
+
+// TreeComparison compares the trees explored by two searches of the same
+// problem, A and B, e.g. with different heuristics, see CompareTrees
+type TreeComparison struct {
+	// NodesA and NodesB are the nodes of each tree, and SolutionsA and
+	// SolutionsB the solutions found by each search
+	NodesA, NodesB         int
+	SolutionsA, SolutionsB int
+	// Divergences are the partial solutions at which the searches first
+	// differ, the greatest difference in the nodes below first
+	Divergences []Divergence
+}
+
+// Divergence is a partial solution at which two searches differ: either they
+// branched on different columns, or only one of them reached it
+type Divergence struct {
+	// Path is the rows added to reach the partial solution, empty for the
+	// root
+	Path []string
+	// ColumnA and ColumnB are the columns each search branched on, with
+	// the number of their rows left, empty for a search which never reached
+	// the partial solution or did not branch there
+	ColumnA, ColumnB         string
+	CandidatesA, CandidatesB int
+	// NodesA and NodesB are the nodes each search explored below the
+	// partial solution, including it
+	NodesA, NodesB int
+}
+
+// Delta returns the nodes explored by B below the divergence less those
+// explored by A
+func (d Divergence) Delta() int {
+	return d.NodesB - d.NodesA
+}
+
+// CompareTrees walks the trees of two searches together from the root,
+// following the rows both tried below the columns both chose, and reports
+// the nodes at which they diverge along with the size of the subtrees each
+// explored there, showing where one heuristic saved work over another. The
+// trees should be recorded without MaxDepth, or with the same MaxDepth.
+func CompareTrees(a, b *SearchTree) TreeComparison {
+	var c TreeComparison
+	if len(a.Nodes) > 0 {
+		c.NodesA, c.SolutionsA = a.Nodes[0].Nodes, a.Nodes[0].Solutions
+	}
+	if len(b.Nodes) > 0 {
+		c.NodesB, c.SolutionsB = b.Nodes[0].Nodes, b.Nodes[0].Solutions
+	}
+	if len(a.Nodes) == 0 || len(b.Nodes) == 0 {
+		if c.NodesA != c.NodesB {
+			c.Divergences = []Divergence{{NodesA: c.NodesA, NodesB: c.NodesB}}
+		}
+		return c
+	}
+	ca, cb := a.children(), b.children()
+	var walk func(path []string, na, nb *TreeNode)
+	walk = func(path []string, na, nb *TreeNode) {
+		if na.Column != nb.Column {
+			c.Divergences = append(c.Divergences, Divergence{
+				Path:    append([]string(nil), path...),
+				ColumnA: na.Column, CandidatesA: na.Candidates, NodesA: na.Nodes,
+				ColumnB: nb.Column, CandidatesB: nb.Candidates, NodesB: nb.Nodes,
+			})
+			return
+		}
+		rowsB := make(map[string]*TreeNode, len(cb[nb.ID]))
+		for _, id := range cb[nb.ID] {
+			rowsB[b.Nodes[id].Row] = &b.Nodes[id]
+		}
+		for _, id := range ca[na.ID] {
+			childA := &a.Nodes[id]
+			childPath := append(path, childA.Row)
+			if childB, ok := rowsB[childA.Row]; ok {
+				delete(rowsB, childA.Row)
+				walk(childPath, childA, childB)
+				continue
+			}
+			c.Divergences = append(c.Divergences, Divergence{
+				Path:    append([]string(nil), childPath...),
+				ColumnA: childA.Column, CandidatesA: childA.Candidates, NodesA: childA.Nodes,
+			})
+		}
+		// The rows only B tried, in the order it tried them
+		for _, id := range cb[nb.ID] {
+			if childB, ok := rowsB[b.Nodes[id].Row]; ok {
+				c.Divergences = append(c.Divergences, Divergence{
+					Path:    append(append([]string(nil), path...), childB.Row),
+					ColumnB: childB.Column, CandidatesB: childB.Candidates, NodesB: childB.Nodes,
+				})
+			}
+		}
+	}
+	walk(nil, &a.Nodes[0], &b.Nodes[0])
+	sort.SliceStable(c.Divergences, func(i, j int) bool {
+		return abs(c.Divergences[i].Delta()) > abs(c.Divergences[j].Delta())
+	})
+	return c
+}
+
+// children returns the IDs of the children of each node of the tree, by ID,
+// in the order they were explored
+func (t *SearchTree) children() [][]int {
+	children := make([][]int, len(t.Nodes))
+	for _, n := range t.Nodes {
+		if n.Parent >= 0 {
+			children[n.Parent] = append(children[n.Parent], n.ID)
+		}
+	}
+	return children
+}
@@ -0,0 +1,63 @@
+package gox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// searchTree records the tree of a search of prob with opts
+func searchTree(t *testing.T, prob *exactCoverProblem, opts ...Option) *SearchTree {
+	var tree SearchTree
+	if _, err := prob.SolveContext(context.Background(), append(opts, WithTrace(tree.Event))...); err != nil {
+		t.Fatalf("Error solving problem: %v", err)
+	}
+	return &tree
+}
+
+func TestCompareTrees(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("a", "b")
+	b.AddRow("a", "a")
+	b.AddRow("a2", "a")
+	b.AddRow("ab", "a", "b")
+	b.AddRow("b", "b")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+
+	// The heuristics choose different columns at the root, and MinRemaining
+	// explores one node fewer
+	first, mrv := searchTree(t, prob, WithHeuristic(FirstColumn)), searchTree(t, prob, WithHeuristic(MinRemaining))
+	c := CompareTrees(first, mrv)
+	expected := TreeComparison{
+		NodesA: 6, NodesB: 5, SolutionsA: 3, SolutionsB: 3,
+		Divergences: []Divergence{{ColumnA: "a", CandidatesA: 3, NodesA: 6, ColumnB: "b", CandidatesB: 2, NodesB: 5}},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, c)
+	}
+	if d := c.Divergences[0].Delta(); d != -1 {
+		t.Fatalf("Expected delta -1, got %d", d)
+	}
+
+	if c := CompareTrees(first, first); len(c.Divergences) != 0 || c.NodesA != c.NodesB {
+		t.Fatalf("Expected no divergences comparing a tree with itself, got %+v", c)
+	}
+
+	// A search stopped early reaches fewer partial solutions, the largest
+	// subtree it missed first
+	limited := searchTree(t, prob, WithHeuristic(FirstColumn), WithLimit(1))
+	c = CompareTrees(limited, first)
+	expected = TreeComparison{
+		NodesA: 3, NodesB: 6, SolutionsA: 1, SolutionsB: 3,
+		Divergences: []Divergence{
+			{Path: []string{"a2"}, ColumnB: "b", CandidatesB: 1, NodesB: 2},
+			{Path: []string{"ab"}, NodesB: 1},
+		},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, c)
+	}
+}
@@ -0,0 +1,38 @@
+package gox
+
+// Many encodings give every row the same number of columns: each row of a
+// sudoku covers four, and each of an exact cover by 3-sets three. The nodes of
+// every row are allocated next to one another, see newNodes, and when every
+// row has the same width, of up to four columns, hide and unhide step through
+// the rest of a row without testing for its end at each node. The index kept
+// for BucketedMinRemaining is only updated by the general loop.
+
+// noteRowWidth records the width of a row added to the problem, rowWidth
+// being left as the width of every row so far, or 0 once they differ. Rows
+// with colours are never handled by the fast path, as hide skips the nodes
+// whose colour has been satisfied.
+func (p *exactCoverProblem) noteRowWidth(row sparseRow) {
+	switch {
+	case row.colors != nil:
+		p.rowWidth = 0
+	case len(p.rowHeaders) == 1:
+		p.rowWidth = len(row.cols)
+	case len(row.cols) != p.rowWidth:
+		p.rowWidth = 0
+	}
+}
+
+// hideNode removes n from its column, as hide does for each node
+func (p *exactCoverProblem) hideNode(n *node) {
+	n.up.down = n.down
+	n.down.up = n.up
+	p.updates++
+	n.colHead.colCount--
+}
+
+// unhideNode is the reverse of hideNode
+func (p *exactCoverProblem) unhideNode(n *node) {
+	n.up.down = n
+	n.down.up = n
+	n.colHead.colCount++
+}
@@ -0,0 +1,90 @@
+package gox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// subsetsProblem has a row for each k-subset of n columns
+func subsetsProblem(t *testing.T, n, k int) *exactCoverProblem {
+	b := NewBuilder()
+	for c := 0; c < n; c++ {
+		b.AddColumns(fmt.Sprint(c))
+	}
+	var add func(first int, cols []string)
+	add = func(first int, cols []string) {
+		if len(cols) == k {
+			b.AddRow(fmt.Sprint(cols), cols...)
+			return
+		}
+		for c := first; c < n; c++ {
+			add(c+1, append(cols[:len(cols):len(cols)], fmt.Sprint(c)))
+		}
+	}
+	add(0, nil)
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	return prob
+}
+
+func TestRowWidth(t *testing.T) {
+	if w := dominoProblem(t, 3).rowWidth; w != 2 {
+		t.Fatalf("Expected dominoes to have width 2, got %d", w)
+	}
+	if w := subsetsProblem(t, 6, 3).rowWidth; w != 3 {
+		t.Fatalf("Expected 3-subsets to have width 3, got %d", w)
+	}
+
+	b := NewBuilder()
+	b.AddColumns("a", "b")
+	b.AddRow("A", "a", "b")
+	b.AddRow("B", "a")
+	prob, err := b.Build()
+	if err != nil || prob.rowWidth != 0 {
+		t.Fatalf("Expected rows of different widths to have no width, got %d: %v", prob.rowWidth, err)
+	}
+
+	b = NewBuilder()
+	b.AddColumns("a")
+	b.AddSecondaryColumns("x")
+	b.AddRow("A", "a", "x:red")
+	b.AddRow("B", "a", "x:blue")
+	prob, err = b.Build()
+	if err != nil || prob.rowWidth != 0 {
+		t.Fatalf("Expected rows with colours to have no width, got %d: %v", prob.rowWidth, err)
+	}
+
+	// The copies searched by SolveParallel keep the width
+	q, err := subsetsProblem(t, 6, 3).clone()
+	if err != nil || q.rowWidth != 3 {
+		t.Fatalf("Expected the copy to have width 3, got %d: %v", q.rowWidth, err)
+	}
+}
+
+func TestRowWidthSearch(t *testing.T) {
+	// The unrolled loops must find the same solutions, with the same work,
+	// as the general loop
+	for _, prob := range []*exactCoverProblem{dominoProblem(t, 8), subsetsProblem(t, 9, 3), subsetsProblem(t, 8, 4)} {
+		width := prob.rowWidth
+		var fast, general Stats
+		want, err := prob.SolveContext(context.Background(), WithStats(&fast))
+		if err != nil || len(want) == 0 {
+			t.Fatalf("Width %d: expected solutions, got %d: %v", width, len(want), err)
+		}
+		if err := prob.CheckInvariants(); err != nil {
+			t.Fatalf("Width %d: matrix not restored: %v", width, err)
+		}
+		prob.rowWidth = 0
+		got, err := prob.SolveContext(context.Background(), WithStats(&general))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Width %d: expected %v, got %v: %v", width, want, got, err)
+		}
+		if !reflect.DeepEqual(fast, general) {
+			t.Fatalf("Width %d: expected %+v, got %+v", width, general, fast)
+		}
+	}
+}
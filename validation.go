@@ -0,0 +1,101 @@
+package gox
+
+import "fmt"
+
+// ValidationMode says how strictly the inputs of a problem are checked
+type ValidationMode int
+
+const (
+	// DefaultValidation rejects the inputs which cannot make a problem, such
+	// as an item naming an unknown column, and keeps the rest as given,
+	// following WithEmptyRows and WithDuplicateRows. This is the default.
+	DefaultValidation ValidationMode = iota
+	// StrictValidation also rejects the inputs which make a problem but are
+	// most likely mistakes, for use while developing an encoding: rows
+	// covering no columns, rows covering only secondary columns, which are
+	// never chosen, primary columns no row covers, which leave the problem
+	// without solutions, and secondary columns no row covers. Every such
+	// problem is listed in an *InputError.
+	StrictValidation
+	// LenientValidation fixes or ignores what it can, for ingesting messy
+	// generated data, recording a warning for each change, see Warnings. An
+	// item added to a Builder or read by NewExactCoverProblemReader naming an
+	// unknown column, or a column already in the row, is ignored, as is a
+	// colour given to a primary column; a row named like an earlier row is
+	// renamed as by SuffixDuplicateRows, and a row covering no columns is
+	// dropped as by DropEmptyRows.
+	LenientValidation
+)
+
+// WithValidation sets how strictly the inputs of a problem are checked,
+// setting the policies for empty and duplicate rows to match, which later
+// options may override. To build a problem with a Builder, which checks the
+// items of each row as it is added, give it to NewBuilder.
+func WithValidation(mode ValidationMode) ProblemOption {
+	return func(p *exactCoverProblem) {
+		p.validation = mode
+		switch mode {
+		case StrictValidation:
+			p.emptyRows = RejectEmptyRows
+			p.duplicateRows = RejectDuplicateRows
+		case LenientValidation:
+			p.emptyRows = DropEmptyRows
+			p.duplicateRows = SuffixDuplicateRows
+		}
+	}
+}
+
+// Warnings returns the inputs fixed or ignored in creating the problem with
+// LenientValidation, in the order they were found
+func (p *exactCoverProblem) Warnings() []string {
+	return p.warnings
+}
+
+// warn records a warning if the problem is validated leniently
+func (p *exactCoverProblem) warn(format string, args ...interface{}) {
+	if p.validation == LenientValidation {
+		p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+// checkStructure returns an *InputError listing the suspicious structures
+// StrictValidation rejects, once every row has been added
+func (p *exactCoverProblem) checkStructure() error {
+	if p.validation != StrictValidation {
+		return nil
+	}
+	var problems []InputProblem
+	add := func(row int, format string, args ...interface{}) {
+		problems = append(problems, InputProblem{Row: row, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	for _, r := range p.rowHeaders {
+		primary := false
+		for n := r.first; n != nil; {
+			if n.colIndex < p.numPrimary {
+				primary = true
+				break
+			}
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+		if r.first != nil && !primary {
+			add(r.index, "Row %s covers only secondary columns, so is never chosen", r.label())
+		}
+	}
+	for col, h := range p.colHeaders {
+		switch {
+		case h.colCount > 0 || (p.removedCols != nil && p.removedCols[col]):
+		case col < p.numPrimary:
+			add(-1, "No row covers primary column %s, so the problem has no solutions", p.colName(h))
+		default:
+			add(-1, "No row covers secondary column %s", p.colName(h))
+		}
+	}
+
+	if problems != nil {
+		return &InputError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package gox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStrictValidation(t *testing.T) {
+	b := NewBuilder(WithValidation(StrictValidation))
+	b.AddColumns("a", "b")
+	b.AddSecondaryColumns("s", "t")
+	b.AddRow("r1", "a")
+	b.AddRow("r2", "s")
+	_, err := b.Build()
+	inputErr, ok := err.(*InputError)
+	if !ok {
+		t.Fatalf("Expected an *InputError, got %v", err)
+	}
+	want := []InputProblem{
+		{Row: 1, Msg: "Row r2 covers only secondary columns, so is never chosen"},
+		{Row: -1, Msg: "No row covers primary column b, so the problem has no solutions"},
+		{Row: -1, Msg: "No row covers secondary column t"},
+	}
+	if !reflect.DeepEqual(inputErr.Problems, want) {
+		t.Fatalf("Expected %v, got %v", want, inputErr.Problems)
+	}
+
+	b = NewBuilder(WithValidation(StrictValidation))
+	b.AddColumns("a")
+	b.AddRow("r1", "a")
+	b.AddRow("empty")
+	if _, err := b.Build(); err == nil {
+		t.Fatalf("Expected an error for an empty row")
+	}
+	// The default keeps both
+	if _, err := NewExactCoverProblem([][]bool{{true, false}, {false, false}}, []string{"r1", "empty"}); err != nil {
+		t.Fatalf("Error creating problem: %v", err)
+	}
+}
+
+func TestLenientValidation(t *testing.T) {
+	b := NewBuilder(WithValidation(LenientValidation))
+	b.AddColumns("a", "b")
+	for _, row := range [][]string{
+		{"x", "a", "zz"},
+		{"y", "b", "b"},
+		{"x", "b"},
+		{"e", "zz"},
+		{"z", "a:red", "b"},
+	} {
+		if err := b.AddRow(row[0], row[1:]...); err != nil {
+			t.Fatalf("Error adding row %v: %v", row, err)
+		}
+	}
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if rows := prob.Rows(); !reflect.DeepEqual(rows, []string{"x", "y", "x#2", "z"}) {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+	if dropped := prob.DroppedRows(); !reflect.DeepEqual(dropped, []string{"e"}) {
+		t.Fatalf("Unexpected rows dropped %v", dropped)
+	}
+	want := []string{
+		"Row x refers to unknown column zz, which is ignored",
+		"Row y contains column b more than once, which is ignored",
+		"Row e refers to unknown column zz, which is ignored",
+		"Row z gives a colour to primary column a, which is ignored",
+		"Row x is named like an earlier row, so is renamed x#2",
+		"Row e covers no columns, so is dropped",
+	}
+	if got := prob.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected warnings %q, got %q", want, got)
+	}
+	if solns := prob.Solve(); len(solns) != 3 {
+		t.Fatalf("Expected 3 solutions, got %v", solns)
+	}
+
+	// The default rejects the same rows, and warns of nothing
+	b = NewBuilder()
+	b.AddColumns("a")
+	if err := b.AddRow("x", "a", "zz"); err == nil {
+		t.Fatalf("Expected an error for an unknown column")
+	}
+	b.AddRow("x", "a")
+	prob, err = b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if warnings := prob.Warnings(); warnings != nil {
+		t.Fatalf("Expected no warnings, got %v", warnings)
+	}
+}
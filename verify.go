@@ -0,0 +1,124 @@
+package gox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ViolationKind identifies the way in which a solution breaks the rules of a
+// problem
+type ViolationKind int
+
+const (
+	// UnknownRow is a row of the solution which is not in the problem
+	UnknownRow ViolationKind = iota
+	// RepeatedRow is a row which appears more than once in the solution
+	RepeatedRow
+	// Uncovered is a primary column which no row of the solution covers
+	Uncovered
+	// Overcovered is a column covered by more than one row of the solution
+	// without them agreeing on its colour
+	Overcovered
+)
+
+// Violation describes one way in which a solution is invalid
+type Violation struct {
+	Kind ViolationKind
+	// Column is the name of the column concerned, for Uncovered and
+	// Overcovered
+	Column string
+	// Rows are the rows concerned: the row which is unknown or repeated, or
+	// the rows which cover a column more than once
+	Rows []string
+}
+
+// String describes the violation, e.g. "column a is not covered"
+func (v Violation) String() string {
+	switch v.Kind {
+	case UnknownRow:
+		return fmt.Sprintf("row %s is not in the problem", v.Rows[0])
+	case RepeatedRow:
+		return fmt.Sprintf("row %s appears more than once", v.Rows[0])
+	case Uncovered:
+		return fmt.Sprintf("column %s is not covered", v.Column)
+	case Overcovered:
+		return fmt.Sprintf("column %s is covered by more than one row: %s", v.Column, strings.Join(v.Rows, ", "))
+	}
+	return fmt.Sprintf("Violation(%d)", int(v.Kind))
+}
+
+// VerifyError is returned by Verify for an invalid solution, listing every
+// way in which it is invalid
+type VerifyError struct {
+	Violations []Violation
+}
+
+func (e *VerifyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "Invalid solution: " + strings.Join(msgs, "; ")
+}
+
+// Verify checks that the rows named by solution are a solution to the
+// problem: every primary column is covered exactly once, and every secondary
+// column at most once unless the rows covering it agree on its colour. If
+// they are not, the error returned is a *VerifyError. The rows given with
+// RowIsSolution are not assumed to be part of the solution.
+func (p *exactCoverProblem) Verify(solution []string) error {
+	var violations []Violation
+	// covering holds the rows covering each column, and colors the colour
+	// given to the column by the first of them
+	covering := make([][]string, p.numCols)
+	colors := make([]int, p.numCols)
+	clash := make([]bool, p.numCols)
+	seen := make(map[string]bool, len(solution))
+	for _, name := range solution {
+		r := p.row(name)
+		if r == nil {
+			violations = append(violations, Violation{Kind: UnknownRow, Rows: []string{name}})
+			continue
+		}
+		if seen[name] {
+			violations = append(violations, Violation{Kind: RepeatedRow, Rows: []string{name}})
+			continue
+		}
+		seen[name] = true
+
+		// Rows without any nodes cover nothing
+		for n := r.first; n != nil; {
+			// The colour of a node is negated while its column is purified
+			color := n.color
+			if color < 0 {
+				color = -color
+			}
+			if len(covering[n.colIndex]) == 0 {
+				colors[n.colIndex] = color
+			} else if color == 0 || color != colors[n.colIndex] {
+				clash[n.colIndex] = true
+			}
+			covering[n.colIndex] = append(covering[n.colIndex], name)
+			if n = n.right; n == r.first {
+				n = nil
+			}
+		}
+	}
+
+	for i, rows := range covering {
+		// A column removed by preprocessing is covered whenever the column
+		// it duplicates is
+		if p.removedCols != nil && p.removedCols[i] {
+			continue
+		}
+		if i < p.numPrimary && len(rows) == 0 {
+			violations = append(violations, Violation{Kind: Uncovered, Column: p.colName(p.colHeaders[i])})
+		} else if clash[i] {
+			violations = append(violations, Violation{Kind: Overcovered, Column: p.colName(p.colHeaders[i]), Rows: rows})
+		}
+	}
+	if len(violations) > 0 {
+		return &VerifyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package gox
+
+import (
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	prob := dominoProblem(t, 3)
+	for _, soln := range prob.Solve() {
+		if err := prob.Verify(soln); err != nil {
+			t.Fatalf("Expected solution %v to be valid: %v", soln, err)
+		}
+	}
+
+	err := prob.Verify([]string{"v0", "v0", "h0,1", "v1", "x"})
+	verr, ok := err.(*VerifyError)
+	if !ok {
+		t.Fatalf("Expected *VerifyError, got %v", err)
+	}
+	var got []string
+	for _, v := range verr.Violations {
+		got = append(got, v.String())
+	}
+	assertStringSliceEqual(t, []string{
+		"row v0 appears more than once",
+		"row x is not in the problem",
+		"column 0,1 is covered by more than one row: h0,1, v1",
+		"column 1,2 is not covered",
+	}, got)
+}
+
+func TestVerifyColors(t *testing.T) {
+	b := NewBuilder()
+	b.AddColumns("p", "q")
+	b.AddSecondaryColumns("x")
+	b.AddRow("A", "p", "x:red")
+	b.AddRow("B", "q", "x:red")
+	b.AddRow("C", "q", "x:blue")
+	b.AddRow("D", "q", "x")
+	prob, err := b.Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if err := prob.Verify([]string{"A", "B"}); err != nil {
+		t.Fatalf("Expected rows agreeing on colour to be valid: %v", err)
+	}
+	for _, soln := range [][]string{{"A", "C"}, {"A", "D"}} {
+		err := prob.Verify(soln)
+		if verr, ok := err.(*VerifyError); !ok || len(verr.Violations) != 1 || verr.Violations[0].Kind != Overcovered {
+			t.Fatalf("Expected x to be overcovered by %v, got %v", soln, err)
+		}
+	}
+
+	// Purifying the column for a given row leaves the colours intact
+	if err := prob.RowIsSolution("A"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	if err := prob.Verify([]string{"A", "C"}); err == nil {
+		t.Fatal("Expected clash of colours after giving row")
+	}
+}
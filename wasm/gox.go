@@ -0,0 +1,213 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/format"
+)
+
+// register defines the gox object holding the functions called by
+// JavaScript on global
+func register(global js.Value) {
+	obj := js.Global().Get("Object").New()
+	obj.Set("solve", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			prob, err := load(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			return solve(prob, arg(args, 1))
+		})
+	}))
+	obj.Set("stream", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			prob, err := load(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			return stream(prob, arg(args, 1), arg(args, 2))
+		})
+	}))
+	obj.Set("problem", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			prob, err := load(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			return wrap(prob), nil
+		})
+	}))
+	global.Set("gox", obj)
+}
+
+// wrap returns the JavaScript object for a problem, see Problem in the
+// package documentation
+func wrap(prob gox.Solver) js.Value {
+	obj := js.Global().Get("Object").New()
+	obj.Set("rows", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return array(prob.Rows())
+	}))
+	obj.Set("give", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			row := arg(args, 0)
+			if row.Type() != js.TypeString {
+				return nil, fmt.Errorf("Row must be given by name")
+			}
+			return js.Undefined(), prob.RowIsSolution(row.String())
+		})
+	}))
+	obj.Set("solve", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			return solve(prob, arg(args, 0))
+		})
+	}))
+	obj.Set("stream", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			return stream(prob, arg(args, 0), arg(args, 1))
+		})
+	}))
+	obj.Set("count", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			n, err := prob.CountSolutions(context.Background())
+			if _, ok := err.(*gox.UncoverableError); ok {
+				return 0, nil
+			}
+			return float64(n), err
+		})
+	}))
+	return obj
+}
+
+// load creates the problem described by a JSON object or string
+func load(v js.Value) (gox.Solver, error) {
+	text := ""
+	switch v.Type() {
+	case js.TypeString:
+		text = v.String()
+	case js.TypeObject:
+		text = js.Global().Get("JSON").Call("stringify", v).String()
+	default:
+		return nil, fmt.Errorf("Problem must be an object or a JSON string")
+	}
+	in, err := format.ReadJSON(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	return in.Problem()
+}
+
+// options returns the solve options set by a JavaScript object, which may be
+// undefined
+func options(v js.Value) ([]gox.Option, error) {
+	if v.Type() != js.TypeObject {
+		return nil, nil
+	}
+	var opts []gox.Option
+	if limit := v.Get("limit"); limit.Type() == js.TypeNumber {
+		opts = append(opts, gox.WithLimit(limit.Int()))
+	}
+	if name := v.Get("heuristic"); name.Type() == js.TypeString {
+		h, err := gox.ParseHeuristic(name.String())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, gox.WithHeuristic(h))
+	}
+	return opts, nil
+}
+
+// solve finds the solutions to a problem as a JavaScript array. A problem
+// with an uncoverable column has no solutions rather than failing, as there
+// is nothing wrong with the problem.
+func solve(prob gox.Solver, opts js.Value) (interface{}, error) {
+	o, err := options(opts)
+	if err != nil {
+		return nil, err
+	}
+	solns, err := prob.SolveContext(context.Background(), o...)
+	if _, ok := err.(*gox.UncoverableError); ok {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]interface{}, len(solns))
+	for i, s := range solns {
+		ret[i] = array(s)
+	}
+	return ret, nil
+}
+
+// stream calls f with each solution to a problem until it returns false,
+// returning the number of solutions passed to it
+func stream(prob gox.Solver, f, opts js.Value) (interface{}, error) {
+	if f.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("onSolution must be a function")
+	}
+	o, err := options(opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	o = append(o, gox.WithoutSolutions(), gox.WithSolutionFunc(func(soln []string) {
+		if ctx.Err() != nil {
+			return
+		}
+		count++
+		if result := f.Invoke(array(soln)); result.Type() == js.TypeBoolean && !result.Bool() {
+			cancel()
+		}
+	}))
+	_, err = prob.SolveContext(ctx, o...)
+	if _, ok := err.(*gox.UncoverableError); ok || err == context.Canceled {
+		err = nil
+	}
+	return count, err
+}
+
+// array converts a slice of strings to a value which becomes a JavaScript
+// array
+func array(s []string) []interface{} {
+	ret := make([]interface{}, len(s))
+	for i, v := range s {
+		ret[i] = v
+	}
+	return ret
+}
+
+// arg returns the i-th argument, or undefined if there are fewer
+func arg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+// promise returns a Promise settled by the result of f, which runs in its own
+// goroutine so that it may wait on JavaScript, e.g. to call a function passed
+// to it
+func promise(f func() (interface{}, error)) js.Value {
+	executor := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			v, err := f()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return nil
+	})
+	// The executor is called before the Promise is returned
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
@@ -0,0 +1,116 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"reflect"
+	"syscall/js"
+	"testing"
+)
+
+// await waits for a Promise to settle, returning its value or the message of
+// the Error it was rejected with
+func await(p js.Value) (js.Value, string) {
+	done := make(chan struct{})
+	var value js.Value
+	var msg string
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		value = args[0]
+		close(done)
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		msg = args[0].Get("message").String()
+		close(done)
+		return nil
+	})
+	defer onReject.Release()
+	p.Call("then", onResolve, onReject)
+	<-done
+	return value, msg
+}
+
+// solutions converts a JavaScript array of solutions
+func solutions(v js.Value) [][]string {
+	var ret [][]string
+	for i := 0; i < v.Length(); i++ {
+		var soln []string
+		for j := 0; j < v.Index(i).Length(); j++ {
+			soln = append(soln, v.Index(i).Index(j).String())
+		}
+		ret = append(ret, soln)
+	}
+	return ret
+}
+
+const problem = `{
+	"primary": ["a", "b", "c"],
+	"rows": [
+		{"name": "ab", "items": ["a", "b"]},
+		{"name": "c", "items": ["c"]},
+		{"name": "a", "items": ["a"]},
+		{"name": "bc", "items": ["b", "c"]}
+	]
+}`
+
+func TestSolve(t *testing.T) {
+	register(js.Global())
+	gox := js.Global().Get("gox")
+	want := [][]string{{"ab", "c"}, {"a", "bc"}}
+
+	v, msg := await(gox.Call("solve", problem))
+	if msg != "" {
+		t.Fatalf("Error solving problem: %s", msg)
+	}
+	if got := solutions(v); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	// A problem may also be given as an object, and the search stopped by
+	// returning false
+	obj := js.Global().Get("JSON").Call("parse", problem)
+	var streamed [][]string
+	onSolution := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		streamed = append(streamed, solutions(js.ValueOf([]interface{}{args[0]}))...)
+		return false
+	})
+	defer onSolution.Release()
+	v, msg = await(gox.Call("stream", obj, onSolution))
+	if msg != "" || v.Int() != 1 || !reflect.DeepEqual(streamed, want[:1]) {
+		t.Fatalf("Expected to stream %v, got %v, %d, %s", want[:1], streamed, v.Int(), msg)
+	}
+
+	if _, msg := await(gox.Call("solve", "{")); msg == "" {
+		t.Fatalf("Expected an error solving invalid JSON")
+	}
+	opts := js.Global().Get("JSON").Call("parse", `{"heuristic": "best"}`)
+	if _, msg := await(gox.Call("solve", problem, opts)); msg == "" {
+		t.Fatalf("Expected an error with an unknown heuristic")
+	}
+}
+
+func TestProblem(t *testing.T) {
+	register(js.Global())
+	prob, msg := await(js.Global().Get("gox").Call("problem", problem))
+	if msg != "" {
+		t.Fatalf("Error creating problem: %s", msg)
+	}
+	if rows := prob.Call("rows"); rows.Length() != 4 {
+		t.Fatalf("Expected 4 rows, got %d", rows.Length())
+	}
+	if n, msg := await(prob.Call("count")); msg != "" || n.Int() != 2 {
+		t.Fatalf("Expected 2 solutions, got %v, %s", n, msg)
+	}
+	if _, msg := await(prob.Call("give", "a")); msg != "" {
+		t.Fatalf("Error giving row: %s", msg)
+	}
+	if _, msg := await(prob.Call("give", "no such row")); msg == "" {
+		t.Fatalf("Expected an error giving an unknown row")
+	}
+	opts := js.Global().Get("JSON").Call("parse", `{"limit": 1}`)
+	v, msg := await(prob.Call("solve", opts))
+	if got := solutions(v); msg != "" || !reflect.DeepEqual(got, [][]string{{"a", "bc"}}) {
+		t.Fatalf("Expected the solution with row a, got %v, %s", got, msg)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build js && wasm
+
+// Command wasm exposes gox to JavaScript when built for WebAssembly, so that
+// browser applications such as puzzle solvers can solve problems on the
+// client. Build it with make in this directory, which writes gox.wasm and
+// copies wasm_exec.js, the loader of Go programs, from the Go distribution:
+//
+//	<script src="wasm_exec.js"></script>
+//	<script>
+//	  const go = new Go();
+//	  WebAssembly.instantiateStreaming(fetch("gox.wasm"), go.importObject)
+//	    .then((result) => {
+//	      go.run(result.instance);
+//	      return gox.solve({primary: ["a", "b"], rows: [
+//	        {name: "R1", items: ["a"]}, {name: "R2", items: ["b"]}]});
+//	    })
+//	    .then((solutions) => console.log(solutions));
+//	</script>
+//
+// Running the program defines the global gox, whose functions take problems
+// as JSON objects, or strings holding them, in the format read by
+// format.ReadJSON. Every function returns a Promise, rejected with an Error
+// if the problem or options are invalid:
+//
+//	gox.solve(problem, options)           // resolves to the solutions
+//	gox.stream(problem, onSolution, opts) // resolves to the number found
+//	gox.problem(problem)                  // resolves to a Problem
+//
+// onSolution is called with each solution as it is found, and stops the
+// search by returning false. options may set limit, the most solutions to
+// find, and heuristic, "mrv" or "first". A Problem keeps its rows given
+// between calls, with the methods
+//
+//	rows()                       // the names of the rows
+//	give(row)                    // gives a row, see RowIsSolution
+//	solve(options)               // as gox.solve
+//	stream(onSolution, options)  // as gox.stream
+//	count()                      // resolves to the number of solutions
+package main
+
+import "syscall/js"
+
+func main() {
+	register(js.Global())
+	// The functions must stay defined for as long as the page uses them
+	select {}
+}
@@ -0,0 +1,54 @@
+package gox
+
+import (
+	"context"
+	"testing"
+)
+
+func TestColumnWeights(t *testing.T) {
+	prob := dominoProblem(t, 3)
+	// firstColumn returns the column the search branches on first
+	firstColumn := func(opts ...Option) string {
+		col := ""
+		opts = append(opts, WithLimit(1), WithTrace(func(e Event) {
+			if e.Kind == ChooseColumn && col == "" {
+				col = e.Column
+			}
+		}))
+		if _, err := prob.SolveContext(context.Background(), opts...); err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		return col
+	}
+
+	// The columns at the ends have two rows, so the leftmost is chosen
+	if col := firstColumn(); col != "0,0" {
+		t.Fatalf("Expected to branch on 0,0, got %s", col)
+	}
+	if err := prob.SetColumnWeight("1,2", 2); err != nil {
+		t.Fatalf("Error setting weight: %v", err)
+	}
+	if err := prob.SetColumnWeight("0,2", 1); err != nil {
+		t.Fatalf("Error setting weight: %v", err)
+	}
+	if col := firstColumn(); col != "1,2" {
+		t.Fatalf("Expected to branch on the heaviest column 1,2, got %s", col)
+	}
+	if col := firstColumn(WithHeuristic(FirstColumn)); col != "0,0" {
+		t.Fatalf("Expected weights to be ignored by FirstColumn, got %s", col)
+	}
+	// Weights only break ties, and do not change the solutions
+	if solns, err := prob.SolveContext(context.Background()); err != nil || len(solns) != 3 {
+		t.Fatalf("Expected 3 solutions, got %v: %v", solns, err)
+	}
+
+	if w, err := prob.ColumnWeight("1,2"); err != nil || w != 2 {
+		t.Fatalf("Expected weight 2, got %v: %v", w, err)
+	}
+	if w, err := prob.ColumnWeight("0,0"); err != nil || w != 0 {
+		t.Fatalf("Expected weight 0, got %v: %v", w, err)
+	}
+	if err := prob.SetColumnWeight("9,9", 1); err == nil {
+		t.Fatal("Expected error for unknown column")
+	}
+}
@@ -0,0 +1,133 @@
+// Package x3c generates random instances of exact cover by 3-sets (X3C), for
+// benchmarking and fuzzing the exact cover solver in gox.
+//
+// An instance of X3C is a universe of elements, whose size is a multiple of 3,
+// and a collection of triples of elements. The problem is to choose triples so
+// that every element is in exactly one of them. X3C is NP-complete, and is the
+// canonical hard case of exact cover.
+package x3c
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/ifross89/gox"
+)
+
+// Config describes the instances to generate
+type Config struct {
+	// Elements is the size of the universe, which must be a positive multiple
+	// of 3
+	Elements int
+	// Triples is the number of triples in the instance
+	Triples int
+	// Planted guarantees that the instance has a solution by including the
+	// triples of a random partition of the universe
+	Planted bool
+}
+
+// Instance is an instance of X3C
+type Instance struct {
+	// Elements is the size of the universe, the elements are numbered from 0
+	Elements int
+	// Triples holds the triples of the instance, the elements of each triple
+	// are in increasing order and no triple appears more than once
+	Triples [][3]int
+	// Planted holds the indices of the triples of the planted solution, or is
+	// nil if no solution was planted
+	Planted []int
+}
+
+// maxTriples returns the number of distinct triples of n elements
+func maxTriples(n int) int {
+	return n * (n - 1) * (n - 2) / 6
+}
+
+// Generate creates a random instance of X3C using rng as the source of
+// randomness, so that instances can be reproduced from a seed
+func Generate(rng *rand.Rand, c Config) (*Instance, error) {
+	if c.Elements <= 0 || c.Elements%3 != 0 {
+		return nil, fmt.Errorf("Number of elements must be a positive multiple of 3: %d", c.Elements)
+	}
+	if c.Triples > maxTriples(c.Elements) {
+		return nil, fmt.Errorf("Number of triples must not exceed %d: %d", maxTriples(c.Elements), c.Triples)
+	}
+	if c.Planted && c.Triples < c.Elements/3 {
+		return nil, fmt.Errorf("Number of triples must be at least %d to plant a solution: %d", c.Elements/3, c.Triples)
+	}
+
+	var triples [][3]int
+	seen := make(map[[3]int]bool)
+	add := func(t [3]int) bool {
+		sort.Ints(t[:])
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		triples = append(triples, t)
+		return true
+	}
+
+	// The planted solution is added first, and the position of each of its
+	// triples recorded once the triples have been shuffled
+	if c.Planted {
+		perm := rng.Perm(c.Elements)
+		for i := 0; i < c.Elements; i += 3 {
+			add([3]int{perm[i], perm[i+1], perm[i+2]})
+		}
+	}
+	numPlanted := len(triples)
+
+	for len(triples) < c.Triples {
+		a := rng.Intn(c.Elements)
+		b := rng.Intn(c.Elements)
+		d := rng.Intn(c.Elements)
+		if a == b || a == d || b == d {
+			continue
+		}
+		add([3]int{a, b, d})
+	}
+
+	ret := &Instance{Elements: c.Elements, Triples: make([][3]int, len(triples))}
+	for i, j := range rng.Perm(len(triples)) {
+		ret.Triples[j] = triples[i]
+		if i < numPlanted {
+			ret.Planted = append(ret.Planted, j)
+		}
+	}
+	sort.Ints(ret.Planted)
+	return ret, nil
+}
+
+// Name returns the name of the i-th triple, which is used as the name of its
+// row in the exact cover problem, e.g. "1,5,7"
+func (inst *Instance) Name(i int) string {
+	t := inst.Triples[i]
+	return fmt.Sprintf("%d,%d,%d", t[0], t[1], t[2])
+}
+
+// Matrix returns the instance as a matrix of bools with a row for each triple
+// and a column for each element, along with the names of the rows
+func (inst *Instance) Matrix() ([][]bool, []string) {
+	m := make([][]bool, len(inst.Triples))
+	names := make([]string, len(inst.Triples))
+	for i, t := range inst.Triples {
+		m[i] = make([]bool, inst.Elements)
+		for _, e := range t {
+			m[i][e] = true
+		}
+		names[i] = inst.Name(i)
+	}
+	return m, names
+}
+
+// Problem creates the exact cover problem for the instance
+func (inst *Instance) Problem() (gox.Solver, error) {
+	m, names := inst.Matrix()
+	prob, err := gox.NewExactCoverProblem(m, names)
+	if err != nil {
+		return nil, err
+	}
+	return prob, nil
+}
@@ -0,0 +1,88 @@
+package x3c
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	inst, err := Generate(rand.New(rand.NewSource(1)), Config{Elements: 12, Triples: 30})
+	if err != nil {
+		t.Fatalf("Error generating instance: %v", err)
+	}
+	if len(inst.Triples) != 30 || inst.Planted != nil {
+		t.Fatalf("Unexpected instance %+v", inst)
+	}
+	seen := make(map[[3]int]bool)
+	for _, tr := range inst.Triples {
+		if !(0 <= tr[0] && tr[0] < tr[1] && tr[1] < tr[2] && tr[2] < 12) {
+			t.Fatalf("Invalid triple %v", tr)
+		}
+		if seen[tr] {
+			t.Fatalf("Triple %v appears more than once", tr)
+		}
+		seen[tr] = true
+	}
+
+	// The same seed gives the same instance
+	again, _ := Generate(rand.New(rand.NewSource(1)), Config{Elements: 12, Triples: 30})
+	for i := range inst.Triples {
+		if inst.Triples[i] != again.Triples[i] {
+			t.Fatalf("Instances differ at triple %d: %v != %v", i, inst.Triples[i], again.Triples[i])
+		}
+	}
+}
+
+func TestPlanted(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 10; i++ {
+		inst, err := Generate(rng, Config{Elements: 15, Triples: 25, Planted: true})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		if len(inst.Planted) != 5 {
+			t.Fatalf("Expected 5 planted triples, got %v", inst.Planted)
+		}
+		covered := make([]bool, 15)
+		planted := make(map[string]bool)
+		for _, p := range inst.Planted {
+			for _, e := range inst.Triples[p] {
+				if covered[e] {
+					t.Fatalf("Element %d covered more than once by planted solution", e)
+				}
+				covered[e] = true
+			}
+			planted[inst.Name(p)] = true
+		}
+
+		prob, err := inst.Problem()
+		if err != nil {
+			t.Fatalf("Error creating problem: %v", err)
+		}
+		found := false
+		for _, soln := range prob.Solve() {
+			match := len(soln) == len(planted)
+			for _, name := range soln {
+				match = match && planted[name]
+			}
+			found = found || match
+		}
+		if !found {
+			t.Fatalf("Planted solution %v not found", inst.Planted)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, c := range []Config{
+		{Elements: 10, Triples: 5},
+		{Elements: 0, Triples: 5},
+		{Elements: 6, Triples: 21},
+		{Elements: 9, Triples: 2, Planted: true},
+	} {
+		if _, err := Generate(rng, c); err == nil {
+			t.Fatalf("Expected error for config %+v", c)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package gox
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+)
+
+// ZDD represents every solution of a problem at once as a zero-suppressed
+// binary decision diagram. Each node of the diagram asks whether a row is in
+// the solution, going to its hi node if it is and its lo node if not, and the
+// paths reaching the true terminal are the solutions. Subproblems reached by
+// different choices of rows share their nodes, so the diagram is often
+// exponentially smaller than the list of solutions: the solutions can be
+// counted, sampled and iterated over without being listed first.
+type ZDD struct {
+	// rows holds the names of the rows of the problem, by index
+	rows []string
+	// given holds the names of the rows given with RowIsSolution, which are
+	// part of every solution
+	given []string
+	// nodes holds the nodes of the diagram, the first two being the false
+	// and true terminals
+	nodes []zddNode
+	root  int
+	// counts holds the number of solutions below each node, see count
+	counts []*big.Int
+}
+
+// zddNode is a node of a ZDD. row is the index of the row it decides, and lo
+// and hi the nodes for the solutions without and with it.
+type zddNode struct {
+	row, lo, hi int
+}
+
+// zddFalse and zddTrue are the terminals of a ZDD: the empty family, and the
+// family holding only the empty solution
+const (
+	zddFalse = 0
+	zddTrue  = 1
+)
+
+// ZDD builds the diagram of the solutions of the problem, see ZDD. The
+// diagram is built by a search which always chooses the first primary column
+// left, so that the rows are decided in the same order on every path, and
+// which solves each subproblem once. The rows given with RowIsSolution are
+// part of every solution. If the context is cancelled the diagram is not
+// finished, so only the context's error is returned.
+func (p *exactCoverProblem) ZDD(ctx context.Context) (_ *ZDD, err error) {
+	if err := p.startSolve("ZDD"); err != nil {
+		return nil, err
+	}
+	defer p.finishSolve(&err)
+	z := &ZDD{
+		rows:  p.Rows(),
+		given: rowNames(p.solutionRows),
+		nodes: []zddNode{{row: -1}, {row: -1}},
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b := &zddBuilder{
+		z:      z,
+		c:      newConfig(ctx, nil),
+		unique: make(map[zddNode]int),
+		memo:   make(map[string]int),
+	}
+	if p.uncoverable() == nil {
+		z.root = p.buildZDD(b)
+	}
+	if b.c.err != nil {
+		return nil, b.c.err
+	}
+	return z, nil
+}
+
+// CountSolutionsBig returns the number of solutions to the problem, however
+// large, see CountSolutions. The solutions are counted on their ZDD, so each
+// subproblem is only solved once and counting is often exponentially faster
+// than listing the solutions. If the context is cancelled only its error is
+// returned.
+func (p *exactCoverProblem) CountSolutionsBig(ctx context.Context) (*big.Int, error) {
+	z, err := p.ZDD(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return z.Count(), nil
+}
+
+// zddBuilder holds the state of the construction of a ZDD. unique holds the
+// nodes by their contents so that no node is created twice, and memo the
+// node of each subproblem solved, by stateKey.
+type zddBuilder struct {
+	z      *ZDD
+	c      *config
+	unique map[zddNode]int
+	memo   map[string]int
+}
+
+// node returns the node deciding row, creating it if need be. A node whose
+// hi node is false is never created, as no solution contains its row.
+func (b *zddBuilder) node(row, lo, hi int) int {
+	if hi == zddFalse {
+		return lo
+	}
+	n := zddNode{row: row, lo: lo, hi: hi}
+	if id, ok := b.unique[n]; ok {
+		return id
+	}
+	id := len(b.z.nodes)
+	b.z.nodes = append(b.z.nodes, n)
+	b.unique[n] = id
+	return id
+}
+
+// buildZDD returns the node representing the solutions of the matrix as it
+// stands. The rows of the first column left are decided in order: the rows
+// left all cover only columns after those covered, so on any path the rows
+// are decided in order of their first column. The matrix is restored.
+func (p *exactCoverProblem) buildZDD(b *zddBuilder) int {
+	if b.c.interrupted() {
+		return zddFalse
+	}
+	if p.root == p.root.right {
+		return zddTrue
+	}
+	colHead := p.root.right
+	if colHead.colCount == 0 {
+		return zddFalse
+	}
+	key := string(p.stateKey())
+	if id, ok := b.memo[key]; ok {
+		return id
+	}
+
+	p.cover(colHead)
+	var his []int
+	for rowNode := colHead.down; rowNode != colHead; rowNode = rowNode.down {
+		p.pushRowToSolution(rowNode.rowHead)
+		for rightNode := rowNode.right; rightNode != rowNode; rightNode = rightNode.right {
+			p.commit(rightNode)
+		}
+		his = append(his, p.buildZDD(b))
+		p.popRowFromSolution()
+		for leftNode := rowNode.left; leftNode != rowNode; leftNode = leftNode.left {
+			p.uncommit(leftNode)
+		}
+	}
+	p.uncover(colHead)
+
+	// Chain the rows of the column from the last, so that the first is
+	// decided first
+	id := zddFalse
+	i := len(his) - 1
+	for rowNode := colHead.up; rowNode != colHead; rowNode = rowNode.up {
+		id = b.node(rowNode.rowHead.index, id, his[i])
+		i--
+	}
+	b.memo[key] = id
+	return id
+}
+
+// Nodes returns the number of nodes of the diagram, not counting the
+// terminals
+func (z *ZDD) Nodes() int {
+	return len(z.nodes) - 2
+}
+
+// Count returns the number of solutions
+func (z *ZDD) Count() *big.Int {
+	return new(big.Int).Set(z.count(z.root))
+}
+
+// count returns the number of solutions below a node, which must not be
+// changed by the caller
+func (z *ZDD) count(id int) *big.Int {
+	if z.counts == nil {
+		// The nodes are created after their lo and hi nodes, so can be
+		// counted in order
+		z.counts = make([]*big.Int, len(z.nodes))
+		z.counts[zddFalse] = big.NewInt(0)
+		z.counts[zddTrue] = big.NewInt(1)
+		for i := 2; i < len(z.nodes); i++ {
+			n := z.nodes[i]
+			z.counts[i] = new(big.Int).Add(z.counts[n.lo], z.counts[n.hi])
+		}
+	}
+	return z.counts[id]
+}
+
+// Sample returns a solution chosen uniformly at random using rng, or nil if
+// there are no solutions
+func (z *ZDD) Sample(rng *rand.Rand) []string {
+	total := z.count(z.root)
+	if total.Sign() == 0 {
+		return nil
+	}
+	// Choose the index of the solution, then follow the path to it, the
+	// solutions without the row of a node coming before those with it
+	r := new(big.Int).Rand(rng, total)
+	soln := append([]string(nil), z.given...)
+	for id := z.root; id != zddTrue; {
+		n := z.nodes[id]
+		if lo := z.count(n.lo); r.Cmp(lo) < 0 {
+			id = n.lo
+		} else {
+			r.Sub(r, lo)
+			soln = append(soln, z.rows[n.row])
+			id = n.hi
+		}
+	}
+	return soln
+}
+
+// Each calls f with each solution in turn until it returns false. The slice
+// passed to f is only valid during the call.
+func (z *ZDD) Each(f func([]string) bool) {
+	soln := append([]string(nil), z.given...)
+	z.each(z.root, soln, f)
+}
+
+// each calls f with soln extended by each solution below a node, returning
+// false once f has
+func (z *ZDD) each(id int, soln []string, f func([]string) bool) bool {
+	switch id {
+	case zddFalse:
+		return true
+	case zddTrue:
+		return f(soln)
+	}
+	n := z.nodes[id]
+	if !z.each(n.lo, soln, f) {
+		return false
+	}
+	return z.each(n.hi, append(soln, z.rows[n.row]), f)
+}
@@ -0,0 +1,141 @@
+package gox_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ifross89/gox"
+	"github.com/ifross89/gox/internal/testutil"
+	"github.com/ifross89/gox/testgen"
+)
+
+// zddProblem is implemented by the problems created by gox
+type zddProblem interface {
+	ZDD(context.Context) (*gox.ZDD, error)
+}
+
+func TestZDD(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		inst, err := testgen.Generate(rng, testgen.Config{
+			Rows:      rng.Intn(30),
+			Columns:   1 + rng.Intn(10),
+			Secondary: rng.Intn(3),
+			Colors:    rng.Intn(3),
+			Density:   0.3,
+			Planted:   true,
+		})
+		if err != nil {
+			t.Fatalf("Error generating instance: %v", err)
+		}
+		want, err := solveGenerated(inst)
+		if err != nil {
+			t.Fatalf("Error solving problem: %v", err)
+		}
+		prob, err := inst.Problem(gox.WithDebug())
+		if err != nil {
+			t.Fatalf("Error building problem: %v", err)
+		}
+		z, err := prob.(zddProblem).ZDD(context.Background())
+		if err != nil {
+			t.Fatalf("Error building ZDD: %v", err)
+		}
+		if z.Count().Int64() != int64(len(want)) {
+			t.Fatalf("Trial %d: expected %d solutions, counted %v", trial, len(want), z.Count())
+		}
+		var got [][]string
+		z.Each(func(soln []string) bool {
+			got = append(got, append([]string(nil), soln...))
+			return true
+		})
+		if !reflect.DeepEqual(testutil.Canonical(got), testutil.Canonical(want)) {
+			t.Fatalf("Trial %d: expected %v, got %v", trial, want, got)
+		}
+		if soln := z.Sample(rng); len(want) == 0 && soln != nil {
+			t.Fatalf("Trial %d: sampled %v from no solutions", trial, soln)
+		} else if len(want) > 0 {
+			if err := prob.Verify(soln); err != nil {
+				t.Fatalf("Trial %d: sampled invalid solution %v: %v", trial, soln, err)
+			}
+		}
+	}
+}
+
+// dominoBuilder returns a builder for the tilings of a 2xn board by dominoes
+func dominoBuilder(n int) *gox.Builder {
+	b := gox.NewBuilder()
+	for c := 0; c < n; c++ {
+		b.AddColumns(fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+	}
+	for c := 0; c < n; c++ {
+		b.AddRow(fmt.Sprintf("v%d", c), fmt.Sprintf("0,%d", c), fmt.Sprintf("1,%d", c))
+		if c < n-1 {
+			for r := 0; r < 2; r++ {
+				b.AddRow(fmt.Sprintf("h%d,%d", r, c), fmt.Sprintf("%d,%d", r, c), fmt.Sprintf("%d,%d", r, c+1))
+			}
+		}
+	}
+	return b
+}
+
+func TestZDDDominoes(t *testing.T) {
+	prob, err := dominoBuilder(100).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	if err := prob.RowIsSolution("v0"); err != nil {
+		t.Fatalf("Error giving row: %v", err)
+	}
+	z, err := prob.ZDD(context.Background())
+	if err != nil {
+		t.Fatalf("Error building ZDD: %v", err)
+	}
+	// The tilings of the remaining 2x99 board are counted by F(100)
+	if want := "354224848179261915075"; z.Count().String() != want {
+		t.Fatalf("Expected %s tilings, got %v", want, z.Count())
+	}
+	if z.Nodes() > 300 {
+		t.Fatalf("Expected a small diagram, got %d nodes", z.Nodes())
+	}
+	soln := z.Sample(rand.New(rand.NewSource(1)))
+	if soln[0] != "v0" {
+		t.Fatalf("Expected the given row in the sample, got %v", soln)
+	}
+	if err := prob.Verify(soln); err != nil {
+		t.Fatalf("Sampled invalid solution %v: %v", soln, err)
+	}
+
+	n := 0
+	z.Each(func([]string) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Fatalf("Expected iteration to stop after 10 solutions, got %d", n)
+	}
+}
+
+func TestCountSolutionsBig(t *testing.T) {
+	prob, err := dominoBuilder(100).Build()
+	if err != nil {
+		t.Fatalf("Error building problem: %v", err)
+	}
+	var solver gox.Solver = prob
+	count, err := solver.CountSolutionsBig(context.Background())
+	if err != nil {
+		t.Fatalf("Error counting solutions: %v", err)
+	}
+	// F(101), which does not fit in a uint64
+	if want := "573147844013817084101"; count.String() != want {
+		t.Fatalf("Expected %s tilings, got %v", want, count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := solver.CountSolutionsBig(ctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}